@@ -0,0 +1,79 @@
+package ctxerrors
+
+import (
+	"errors"
+	"slices"
+)
+
+// Accumulator gathers independent failures, each wrapped with its own
+// message and location, into one error, for validation passes and teardown
+// code that should keep going after the first failure:
+//
+//	var errs ctxerrors.Accumulator
+//	errs.Add(cache.Close(), "failed to close cache")
+//	errs.Addf(db.Close(), "failed to close database %s", name)
+//	return errs.Err()
+//
+// Nil errors are skipped, so results can be passed in unchecked. The zero
+// value is ready to use. It's not safe for concurrent use.
+type Accumulator struct {
+	errs []error
+}
+
+// Add wraps err with message, located at the caller, and gathers it,
+// reporting whether err was non-nil.
+func (a *Accumulator) Add(err error, message string) bool {
+	// Skip Add() and wrap() to get user's caller
+	framesToSkip := 2
+
+	if err == nil {
+		return false
+	}
+
+	a.errs = append(a.errs, wrap(err, message, framesToSkip, nil))
+
+	return true
+}
+
+// Addf wraps err with a formatted message, %w included, located at the
+// caller, and gathers it, reporting whether err was non-nil.
+func (a *Accumulator) Addf(err error, format string, args ...any) bool {
+	// Skip Addf() and newLayer() to get user's caller
+	framesToSkip := 2
+
+	if err == nil {
+		return false
+	}
+
+	message, refs := formatMessage(format, args...)
+
+	layer := newLayer(err, message, framesToSkip)
+	layer.refs = refs
+
+	checkFormat(layer, format)
+
+	a.errs = append(a.errs, finishLayer(layer, nil))
+
+	return true
+}
+
+// Empty reports whether no errors were gathered.
+func (a *Accumulator) Empty() bool {
+	return len(a.errs) == 0
+}
+
+// Len returns how many errors were gathered.
+func (a *Accumulator) Len() int {
+	return len(a.errs)
+}
+
+// Err returns the gathered errors joined with errors.Join, in the order they
+// were added, or nil if there are none. Adding more afterwards doesn't change
+// an error it already returned.
+func (a *Accumulator) Err() error {
+	if a.Empty() {
+		return nil
+	}
+
+	return errors.Join(slices.Clone(a.errs)...)
+}
@@ -0,0 +1,53 @@
+package ctxerrors
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccumulator(t *testing.T) {
+	baseErr := errors.New("connection reset") //nolint:err113
+
+	var errs Accumulator
+
+	require.True(t, errs.Empty())
+	require.NoError(t, errs.Err())
+
+	require.False(t, errs.Add(nil, "ignored"))
+	require.True(t, errs.Add(baseErr, "failed to close cache"))
+	require.True(t, errs.Addf(io.ErrUnexpectedEOF, "failed to close database %s: %w", "main", io.ErrClosedPipe))
+
+	require.False(t, errs.Empty())
+	require.Equal(t, 2, errs.Len())
+
+	err := errs.Err()
+	require.ErrorIs(t, err, baseErr)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+
+	joined, ok := members(err)
+	require.True(t, ok)
+	require.Len(t, joined, 2)
+
+	var messages []string
+
+	for _, member := range joined {
+		layer, ok := asCTXError(member)
+		require.True(t, ok)
+		require.Contains(t, layer.FuncName(), "TestAccumulator")
+		require.Contains(t, layer.File(), "accumulate_internal_test.go")
+
+		messages = append(messages, layer.Message())
+	}
+
+	require.Equal(t, []string{"failed to close cache", "failed to close database main: io: read/write on closed pipe"}, messages)
+
+	errs.Add(baseErr, "one more")
+	require.Equal(t, 3, errs.Len())
+
+	joined, _ = members(err)
+	require.Len(t, joined, 2)
+}
@@ -0,0 +1,122 @@
+package ctxerrors
+
+import (
+	"sync"
+	"time"
+)
+
+// RateAlarm is an EnrichHook counting created errors per Fingerprint over a
+// sliding window and calling back when a fingerprint goes over its threshold,
+// for in-process circuit breaking or alerting without a metrics pipeline:
+//
+//	alarm := ctxerrors.NewRateAlarm(time.Minute, 100, func(fingerprint string, err error) {
+//		slog.Error("error storm", "fingerprint", fingerprint, "error", err)
+//	})
+//	defer ctxerrors.RegisterEnrichHook(alarm)()
+//
+// Every layer counts, so a failure wrapped three times on its way up counts
+// once under each of the three fingerprints its layers have. Time comes from
+// SetClock's clock. It attaches no fields.
+type RateAlarm struct {
+	window     time.Duration
+	threshold  int
+	onExceeded func(fingerprint string, err error)
+
+	mu        sync.Mutex // Guards the fields below
+	rates     map[string]*rate
+	lastSweep time.Time
+}
+
+// rate is what a RateAlarm knows about one fingerprint.
+type rate struct {
+	times   []time.Time // Latest threshold+1 creation times, oldest first
+	tripped bool        // Over the threshold since the last call back
+}
+
+// NewRateAlarm returns a RateAlarm calling onExceeded with the fingerprint and
+// the error that took it there whenever more than threshold errors with the
+// same fingerprint were created within window. It calls once per excursion:
+// a fingerprint has to get back to at most threshold errors per window before
+// it can trip again. onExceeded runs on the goroutine creating the error, so
+// keep it quick.
+func NewRateAlarm(window time.Duration, threshold int, onExceeded func(fingerprint string, err error)) *RateAlarm {
+	return &RateAlarm{
+		window:     window,
+		threshold:  max(threshold, 0),
+		onExceeded: onExceeded,
+		rates:      make(map[string]*rate),
+	}
+}
+
+// Enrich counts err under its fingerprint and calls back if that takes the
+// fingerprint over the threshold.
+func (a *RateAlarm) Enrich(err *CTXError) map[string]any {
+	fingerprint := Fingerprint(err)
+	now := Now()
+
+	a.mu.Lock()
+
+	a.sweep(now)
+
+	current, ok := a.rates[fingerprint]
+	if !ok {
+		current = &rate{times: make([]time.Time, 0, a.threshold+1)}
+		a.rates[fingerprint] = current
+	}
+
+	if len(current.times) == a.threshold+1 {
+		current.times = append(current.times[:0], current.times[1:]...)
+	}
+
+	current.times = append(current.times, now)
+
+	exceeded := a.exceeded(current, now)
+	trip := exceeded && !current.tripped
+	current.tripped = exceeded
+
+	a.mu.Unlock()
+
+	if trip && a.onExceeded != nil {
+		a.onExceeded(fingerprint, err)
+	}
+
+	return nil
+}
+
+// Exceeded reports whether more than the threshold of errors with err's
+// fingerprint were created within the window up to now, so callers can shed
+// load on a path that's failing in a storm.
+func (a *RateAlarm) Exceeded(err error) bool {
+	fingerprint := Fingerprint(err)
+	now := Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	current, ok := a.rates[fingerprint]
+
+	return ok && a.exceeded(current, now)
+}
+
+// exceeded reports whether current holds more than the threshold of creation
+// times within the window up to now.
+func (a *RateAlarm) exceeded(current *rate, now time.Time) bool {
+	return len(current.times) == a.threshold+1 && now.Sub(current.times[0]) < a.window
+}
+
+// sweep forgets the fingerprints with no errors within the window up to now,
+// at most once a window so it doesn't cost every call a pass over all of
+// them. a.mu must be held.
+func (a *RateAlarm) sweep(now time.Time) {
+	if now.Sub(a.lastSweep) < a.window {
+		return
+	}
+
+	a.lastSweep = now
+
+	for fingerprint, current := range a.rates {
+		if now.Sub(current.times[len(current.times)-1]) >= a.window {
+			delete(a.rates, fingerprint)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func alarmLoad() error {
+	return Wrap(errors.New("connection refused"), "load user") //nolint:err113
+}
+
+func TestRateAlarm(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	SetClock(func() time.Time { return now })
+	t.Cleanup(func() { SetClock(nil) })
+
+	var tripped []string
+
+	alarm := NewRateAlarm(time.Minute, 2, func(fingerprint string, err error) {
+		require.Equal(t, fingerprint, Fingerprint(err))
+
+		tripped = append(tripped, fingerprint)
+	})
+
+	unregister := RegisterEnrichHook(alarm)
+	t.Cleanup(unregister)
+
+	fingerprint := Fingerprint(alarmLoad())
+	require.Empty(t, tripped)
+
+	now = now.Add(10 * time.Second)
+	_ = alarmLoad()
+	require.Empty(t, tripped)
+
+	// The third within the window trips it, once
+	err := alarmLoad()
+	require.True(t, alarm.Exceeded(err))
+	require.Equal(t, []string{fingerprint}, tripped)
+
+	now = now.Add(time.Second)
+	_ = alarmLoad()
+	require.Len(t, tripped, 1)
+
+	// Other code paths have their own count
+	_ = New("unrelated")
+	require.False(t, alarm.Exceeded(New("unrelated")))
+
+	// The window slides past the burst and the alarm rearms
+	now = now.Add(2 * time.Minute)
+	require.False(t, alarm.Exceeded(err))
+	_ = alarmLoad()
+	require.Len(t, tripped, 1)
+	_ = alarmLoad()
+	_ = alarmLoad()
+	require.Equal(t, []string{fingerprint, fingerprint}, tripped)
+}
@@ -0,0 +1,177 @@
+package ctxerrors
+
+import (
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Bounds of the buffer the current goroutine's stack is read into. The go
+// statements are at the end of it, so it's grown until the stack fits.
+const (
+	ancestryStackSize    = 4 << 10
+	maxAncestryStackSize = 1 << 20
+)
+
+// Spawn is a go statement that started a goroutine.
+type Spawn struct {
+	Frame            // The go statement
+	Goroutine uint64 // The goroutine that ran it, zero if unknown
+}
+
+// goroutineAncestry is where the goroutine a layer was created in came from.
+type goroutineAncestry struct {
+	goroutine uint64  // The goroutine the layer was created in
+	spawns    []Spawn // The go statement that started it first, then its ancestors'
+}
+
+// SetGoroutineAncestry controls whether every error created from now on in a
+// goroutine records the go statement that started it, available through
+// Spawns() and printed by %+v, so "who started this goroutine" is answerable
+// from the error alone. Run the program with GODEBUG=tracebackancestors=N and
+// the go statements that started the N goroutines before it are recorded too.
+// A layer created in the same goroutine as the one it wraps doesn't record
+// them again. It's off by default since it reads the goroutine's stack on
+// every error.
+func SetGoroutineAncestry(enabled bool) {
+	updateConfig(func(c *config) {
+		c.ancestry = enabled
+	})
+}
+
+// Spawns returns the go statements that started the goroutine this layer was
+// created in, that goroutine's own first, then those of its ancestors if
+// GODEBUG=tracebackancestors kept them, or nil if SetGoroutineAncestry
+// didn't ask for them or the layer is in the same goroutine as the one it
+// wraps.
+func (e *CTXError) Spawns() []Spawn {
+	if e == nil || e.ancestry == nil {
+		return nil
+	}
+
+	return slices.Clone(e.ancestry.spawns)
+}
+
+// captureAncestry records the go statements that started the goroutine err is
+// being created in, unless the layer it wraps already has them.
+func captureAncestry(cfg *config, err *CTXError) {
+	ancestry := parseAncestry(currentStack())
+	if ancestry == nil || len(ancestry.spawns) == 0 {
+		return
+	}
+
+	if below, ok := asCTXError(err.err); ok && below.ancestry != nil && below.ancestry.goroutine == ancestry.goroutine {
+		return
+	}
+
+	for i, spawn := range ancestry.spawns {
+		if cfg.captureMode == CaptureFuncOnly {
+			spawn.File, spawn.Line = "", 0
+		} else if spawn.File != "" {
+			spawn.File, spawn.Line = cfg.mapLocation(spawn.File, spawn.Line)
+		}
+
+		if cfg.deterministic {
+			spawn.Goroutine = 0
+		}
+
+		ancestry.spawns[i] = spawn
+	}
+
+	err.ancestry = ancestry
+}
+
+// currentStack returns the current goroutine's stack as runtime.Stack renders
+// it, as much of it as fits in maxAncestryStackSize.
+func currentStack() string {
+	buf := make([]byte, ancestryStackSize)
+
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) || len(buf) >= maxAncestryStackSize {
+			return string(buf[:n])
+		}
+
+		buf = make([]byte, 2*len(buf)) //nolint:mnd
+	}
+}
+
+// parseAncestry reads the goroutine's ID and the "created by" entries out of
+// a stack runtime.Stack rendered, along with those of the "[originating from
+// goroutine N]" sections GODEBUG=tracebackancestors adds. It returns nil if
+// the stack doesn't start with the goroutine header.
+func parseAncestry(stack string) *goroutineAncestry {
+	lines := strings.Split(stack, "\n")
+
+	header, ok := strings.CutPrefix(lines[0], "goroutine ")
+	if !ok {
+		return nil
+	}
+
+	id, _, _ := strings.Cut(header, " ")
+
+	ancestry := &goroutineAncestry{goroutine: parseGoroutineID(id), spawns: nil}
+
+	for i, line := range lines {
+		if section, ok := strings.CutPrefix(line, "[originating from goroutine "); ok {
+			// Ancestors' "created by" lines don't say which goroutine ran
+			// the go statement, the next section is that goroutine's
+			last := len(ancestry.spawns) - 1
+			if last >= 0 && ancestry.spawns[last].Goroutine == 0 {
+				ancestry.spawns[last].Goroutine = parseGoroutineID(strings.TrimSuffix(section, "]:"))
+			}
+
+			continue
+		}
+
+		creator, ok := strings.CutPrefix(line, "created by ")
+		if !ok {
+			continue
+		}
+
+		funcName, goroutine, _ := strings.Cut(creator, " in goroutine ")
+		spawn := Spawn{Frame: Frame{File: "", Line: 0, FuncName: funcName}, Goroutine: parseGoroutineID(goroutine)}
+
+		if i+1 < len(lines) {
+			spawn.File, spawn.Line = parseStackLocation(lines[i+1])
+		}
+
+		ancestry.spawns = append(ancestry.spawns, spawn)
+	}
+
+	return ancestry
+}
+
+// parseGoroutineID parses a goroutine ID, returning zero if it isn't one.
+func parseGoroutineID(id string) uint64 {
+	parsed, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return parsed
+}
+
+// parseStackLocation parses the "\tfile:line +0x1f" line following a function
+// in a runtime.Stack trace.
+func parseStackLocation(line string) (string, int) {
+	location, ok := strings.CutPrefix(line, "\t")
+	if !ok {
+		return "", 0
+	}
+
+	location, _, _ = strings.Cut(location, " +0x")
+
+	colon := strings.LastIndex(location, ":")
+	if colon < 0 {
+		return "", 0
+	}
+
+	number, err := strconv.Atoi(location[colon+1:])
+	if err != nil {
+		return "", 0
+	}
+
+	return location[:colon], number
+}
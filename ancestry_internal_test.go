@@ -0,0 +1,125 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func ancestrySpawner(fn func() error) error {
+	result := make(chan error, 1)
+
+	go func() {
+		result <- fn()
+	}()
+
+	return <-result
+}
+
+func TestSetGoroutineAncestry(t *testing.T) {
+	baseErr := errors.New("connection reset") //nolint:err113
+
+	t.Run("disabled", func(t *testing.T) {
+		err := ancestrySpawner(func() error { return New("lookup failed") })
+
+		layer, ok := asCTXError(err)
+		require.True(t, ok)
+		require.Nil(t, layer.Spawns())
+	})
+
+	SetGoroutineAncestry(true)
+	t.Cleanup(func() { SetGoroutineAncestry(false) })
+
+	require.True(t, CurrentConfig().Ancestry)
+
+	t.Run("records the go statement", func(t *testing.T) {
+		err := ancestrySpawner(func() error { return Wrap(baseErr, "query failed") })
+
+		layer, ok := asCTXError(err)
+		require.True(t, ok)
+
+		spawns := layer.Spawns()
+		require.NotEmpty(t, spawns)
+		require.Contains(t, spawns[0].FuncName, "ancestrySpawner")
+		require.Contains(t, spawns[0].File, "ancestry_internal_test.go")
+		require.Positive(t, spawns[0].Line)
+		require.NotZero(t, spawns[0].Goroutine)
+
+		detail := fmt.Sprintf("%+v", err)
+		require.Contains(t, detail, "started by:")
+		require.Contains(t, detail, "ancestrySpawner in goroutine")
+	})
+
+	t.Run("not repeated in the same goroutine", func(t *testing.T) {
+		err := ancestrySpawner(func() error { return Wrap(New("lookup failed"), "query failed") })
+
+		outer, ok := asCTXError(err)
+		require.True(t, ok)
+		require.Nil(t, outer.Spawns())
+
+		inner, ok := asCTXError(outer.Unwrap())
+		require.True(t, ok)
+		require.NotEmpty(t, inner.Spawns())
+	})
+
+	t.Run("recorded again in another goroutine", func(t *testing.T) {
+		inner := ancestrySpawner(func() error { return New("lookup failed") })
+		err := ancestrySpawner(func() error { return Wrap(inner, "query failed") })
+
+		outer, ok := asCTXError(err)
+		require.True(t, ok)
+		require.NotEmpty(t, outer.Spawns())
+	})
+}
+
+func TestParseAncestry(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stack    string
+		expected *goroutineAncestry
+	}{
+		{
+			name: "created by",
+			stack: "goroutine 7 [running]:\n" +
+				"main.leaf(0x0?)\n\t/app/main.go:12 +0x65\n" +
+				"created by main.mid in goroutine 6\n\t/app/main.go:16 +0x59\n",
+			expected: &goroutineAncestry{goroutine: 7, spawns: []Spawn{
+				{Frame: Frame{File: "/app/main.go", Line: 16, FuncName: "main.mid"}, Goroutine: 6},
+			}},
+		},
+		{
+			name: "traceback ancestors",
+			stack: "goroutine 7 [running]:\n" +
+				"main.leaf(0x0?)\n\t/app/main.go:12 +0x65\n" +
+				"created by main.mid in goroutine 6\n\t/app/main.go:16 +0x59\n" +
+				"[originating from goroutine 6]:\n" +
+				"main.mid(...)\n\t/app/main.go:17 +0x59\n" +
+				"created by main.main\n\t/app/main.go:22 +0x7f\n" +
+				"[originating from goroutine 1]:\n" +
+				"main.main(...)\n\t/app/main.go:23 +0x7f\n",
+			expected: &goroutineAncestry{goroutine: 7, spawns: []Spawn{
+				{Frame: Frame{File: "/app/main.go", Line: 16, FuncName: "main.mid"}, Goroutine: 6},
+				{Frame: Frame{File: "/app/main.go", Line: 22, FuncName: "main.main"}, Goroutine: 1},
+			}},
+		},
+		{
+			name:     "main goroutine",
+			stack:    "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:25 +0xa5\n",
+			expected: &goroutineAncestry{goroutine: 1, spawns: nil},
+		},
+		{
+			name:     "not a stack",
+			stack:    "garbage",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, parseAncestry(tc.stack))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package ctxerrors
+
+import (
+	"maps"
+	"reflect"
+)
+
+// WithField returns err with key set to value. err itself is never modified:
+// if it's a *CTXError the result is a shallow copy of its outermost layer that
+// shares everything below it, so any number of goroutines can annotate the
+// same error at once. Anything else is wrapped in a new layer with an empty
+// message created at the caller. It returns nil if err is nil, a typed nil
+// like a nil *CTXError or *os.PathError included, like every other With
+// helper, so annotations can be chained unconditionally after calls that may
+// have succeeded.
+func WithField(err error, key string, value any) error {
+	// Skip WithField(), annotate() and wrap() to get user's caller
+	framesToSkip := 3
+
+	return annotate(err, map[string]any{key: value}, framesToSkip)
+}
+
+// annotate returns a copy of err's outermost *CTXError layer with fields set on
+// top of the ones it already has, or err wrapped in a new layer with them if
+// it isn't a *CTXError. skip is passed to wrap for the latter.
+func annotate(err error, fields map[string]any, skip int) error {
+	if isNilError(err) {
+		return nil
+	}
+
+	layer, ok := asCTXError(err)
+	if !ok {
+		return wrap(err, "", skip, fields)
+	}
+
+	annotated := *layer
+	annotated.fields = make(map[string]any, len(layer.fields)+len(fields))
+
+	maps.Copy(annotated.fields, layer.fields)
+	maps.Copy(annotated.fields, fields)
+
+	return &annotated
+}
+
+// isNilError reports whether err is nil or a typed nil stored in an error,
+// like a nil *CTXError or *os.PathError returned as error, which annotation
+// helpers treat the same instead of wrapping it in a layer claiming there's an
+// error.
+func isNilError(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	if layer, ok := err.(*CTXError); ok { //nolint:errorlint
+		return layer == nil
+	}
+
+	value := reflect.ValueOf(err)
+
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
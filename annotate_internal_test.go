@@ -0,0 +1,142 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithField(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WithField(nil, "key", "value"))
+	})
+
+	t.Run("copies the outermost layer", func(t *testing.T) {
+		inner := Wrap(baseErr, "inner")
+		original := annotate(Wrap(inner, "outer"), map[string]any{"existing": 1}, 0)
+
+		actual := WithField(original, "key", "value")
+
+		require.NotSame(t, original, actual)
+		require.Equal(t, original.Error(), actual.Error())
+		require.Equal(t, map[string]any{"existing": 1, "key": "value"}, actual.(*CTXError).Fields()) //nolint:errorlint,forcetypeassert
+		require.Equal(t, map[string]any{"existing": 1}, original.(*CTXError).Fields())               //nolint:errorlint,forcetypeassert
+		require.Same(t, inner, errors.Unwrap(actual))
+		require.ErrorIs(t, actual, baseErr)
+	})
+
+	t.Run("overrides existing key", func(t *testing.T) {
+		original := annotate(New("something went wrong"), map[string]any{"key": "old"}, 0)
+
+		actual := WithField(original, "key", "new")
+
+		value, ok := FieldString(actual, "key")
+		require.True(t, ok)
+		require.Equal(t, "new", value)
+
+		value, ok = FieldString(original, "key")
+		require.True(t, ok)
+		require.Equal(t, "old", value)
+	})
+
+	t.Run("wraps foreign error", func(t *testing.T) {
+		actual := WithField(baseErr, "key", "value")
+
+		layer, ok := asCTXError(actual)
+		require.True(t, ok)
+		require.Empty(t, layer.Message())
+		require.Equal(t, map[string]any{"key": "value"}, layer.Fields())
+		require.Equal(t, "github.com/psyb0t/ctxerrors.TestWithField.func4", layer.FuncName())
+		require.True(t, strings.HasSuffix(layer.File(), "annotate_internal_test.go"))
+		require.Same(t, baseErr, errors.Unwrap(actual))
+	})
+
+	t.Run("concurrent annotation", func(t *testing.T) {
+		original := Wrap(baseErr, "shared")
+
+		var wg sync.WaitGroup
+
+		annotated := make([]error, 50)
+		for i := range annotated {
+			wg.Go(func() {
+				annotated[i] = WithCode(WithKind(WithField(original, "worker", i), KindInternal), fmt.Sprint(i))
+			})
+		}
+
+		wg.Wait()
+
+		require.Nil(t, original.(*CTXError).Fields()) //nolint:errorlint,forcetypeassert
+
+		for i, err := range annotated {
+			worker, ok := FieldInt(err, "worker")
+			require.True(t, ok)
+			require.Equal(t, i, worker)
+			require.Equal(t, fmt.Sprint(i), CodeOf(err))
+			require.Equal(t, KindInternal, KindOf(err))
+		}
+	})
+}
+
+func TestAnnotationHelpersNil(t *testing.T) {
+	origin := Origin{Frames: []Frame{{File: "remote.go", Line: 1, FuncName: "remote.Handle"}}}
+
+	helpers := map[string]func(err error) error{
+		"WithField":      func(err error) error { return WithField(err, "user_id", 42) },
+		"WithKind":       func(err error) error { return WithKind(err, KindInternal) },
+		"WithCode":       func(err error) error { return WithCode(err, "E_DB") },
+		"WithOp":         func(err error) error { return WithOp(err, "load user") },
+		"WithLink":       func(err error) error { return WithLink(err, "runbook", "https://example.com") },
+		"WithDebugField": func(err error) error { return WithDebugField(err, "dump", func() any { return "state" }) },
+		"WithPayload":    func(err error) error { return WithPayload(err, "body", []byte("{}"), 0) },
+		"WithRetryAfter": func(err error) error { return WithRetryAfter(err, time.Second) },
+		"WithCause":      func(err error) error { return WithCause(err, io.EOF) },
+		"CaptureArgs":    func(err error) error { return CaptureArgs(err, struct{ ID int }{ID: 1}) },
+		"AnnotateFrame":  func(err error) error { return AnnotateFrame(err, 0, "inside retry loop") },
+		"LinkOrigin":     func(err error) error { return LinkOrigin(err, origin) },
+	}
+
+	var (
+		typedNil    *CTXError
+		pathErrNil  *os.PathError
+		sliceErrNil nilSliceError
+		funcErrNil  nilFuncError
+	)
+
+	for name, helper := range helpers {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, helper(nil))
+			require.NoError(t, helper(typedNil))
+			require.NoError(t, helper(pathErrNil))
+			require.NoError(t, helper(sliceErrNil))
+			require.NoError(t, helper(funcErrNil))
+
+			// Chained the way callers do after a call that may have succeeded
+			require.NoError(t, WithField(helper(WithKind(nil, KindInternal)), "attempt", 1))
+
+			require.Error(t, helper(New("boom")))
+		})
+	}
+}
+
+// nilSliceError is an error type whose nil value is a typed nil.
+type nilSliceError []string
+
+func (e nilSliceError) Error() string {
+	return fmt.Sprint([]string(e))
+}
+
+// nilFuncError is an error type whose nil value is a typed nil.
+type nilFuncError func() string
+
+func (e nilFuncError) Error() string {
+	return e()
+}
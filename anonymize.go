@@ -0,0 +1,49 @@
+package ctxerrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"sync"
+)
+
+// anonymizedDirBytes is how many bytes of a directory's hash go into an
+// anonymized path, rendered as twice as many hex digits.
+const anonymizedDirBytes = 3
+
+// anonymizedDirs caches the hashes of directories seen so far, since errors
+// tend to come from the same few files over and over.
+var anonymizedDirs sync.Map //nolint:gochecknoglobals
+
+// SetAnonymizePaths controls whether captured file paths are replaced with a
+// short hash of their directory followed by their base name, e.g.
+// "a1b2c3/handler.go", for products whose error strings reach end users but
+// whose source tree layout is considered sensitive. The hash is stable across
+// processes and machines, so the same directory always maps to the same
+// prefix and errors still group together. It applies to errors created from
+// now on and is off by default.
+func SetAnonymizePaths(anonymize bool) {
+	updateConfig(func(c *config) {
+		c.anonymizePaths = anonymize
+	})
+}
+
+// anonymizePath returns the anonymized form of file, which has forward
+// slashes. Files without a directory are left as is.
+func anonymizePath(file string) string {
+	dir, base := path.Split(file)
+	if dir == "" {
+		return file
+	}
+
+	if hashed, ok := anonymizedDirs.Load(dir); ok {
+		return hashed.(string) + "/" + base //nolint:forcetypeassert
+	}
+
+	sum := sha256.Sum256([]byte(dir))
+	hashed := hex.EncodeToString(sum[:anonymizedDirBytes])
+
+	anonymizedDirs.Store(dir, hashed)
+
+	return hashed + "/" + base
+}
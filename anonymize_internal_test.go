@@ -0,0 +1,70 @@
+package ctxerrors
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAnonymizePaths(t *testing.T) {
+	t.Cleanup(func() { SetAnonymizePaths(false) })
+
+	SetAnonymizePaths(true)
+
+	var first, second *CTXError
+
+	require.True(t, errors.As(New("first"), &first))
+	require.True(t, errors.As(Wrap(errors.New("base error"), "second"), &second)) //nolint:err113
+
+	require.Regexp(t, regexp.MustCompile(`^[0-9a-f]{6}/anonymize_internal_test\.go$`), first.File())
+	require.Equal(t, first.File(), second.File())
+	require.NotZero(t, first.Line())
+
+	_, file, _, ok := runtime.Caller(0)
+	require.True(t, ok)
+	require.NotContains(t, first.Error(), filepath.Dir(file))
+
+	SetAnonymizePaths(false)
+
+	require.True(t, errors.As(New("as captured"), &first))
+	require.Equal(t, filepath.ToSlash(file), first.File())
+}
+
+func TestAnonymizePath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		expected string
+	}{
+		{
+			name:     "no directory",
+			file:     "main.go",
+			expected: "main.go",
+		},
+		{
+			name:     "absolute path",
+			file:     "/home/someone/src/app/handler.go",
+			expected: "6a06cd/handler.go",
+		},
+		{
+			name:     "same directory",
+			file:     "/home/someone/src/app/main.go",
+			expected: "6a06cd/main.go",
+		},
+		{
+			name:     "other directory",
+			file:     "/home/someone/src/lib/handler.go",
+			expected: "e88d56/handler.go",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, anonymizePath(tc.file))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package ctxerrors
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// NewAt is like New but records the given location instead of its caller's,
+// for code bridging errors from elsewhere, e.g. FFI layers, scripting engines
+// or log replays, that knows where they really happened. The location goes
+// through the same source mapping, path normalization and capture mode as a
+// captured one. No callers are captured, the Go stack creating the error
+// isn't where it happened.
+func NewAt(message, file string, line int, funcName string) error {
+	return newAt(nil, message, file, line, funcName)
+}
+
+// WrapAt is like Wrap but records the given location instead of its caller's,
+// like NewAt. It returns nil if err is nil.
+func WrapAt(err error, message, file string, line int, funcName string) error {
+	if err == nil {
+		return nil
+	}
+
+	return newAt(err, message, file, line, funcName)
+}
+
+// WrapFrom is like Wrap but records where the function fn starts instead of
+// the wrap site, for dispatchers, routers and job runners wrapping errors on
+// behalf of handlers they picked at runtime, so the error points at the
+// handler rather than at the one dispatch line every failure goes through:
+//
+//	if err := handler(ctx, job); err != nil {
+//		return ctxerrors.WrapFrom(err, handler, "run job "+job.Name)
+//	}
+//
+// A method value, e.g. svc.Handle, gets just the method's name, Go doesn't
+// keep where it's declared. Like WrapAt it captures no callers. If fn is nil or
+// not a function it records its caller like Wrap does. It returns nil if err
+// is nil.
+func WrapFrom(err error, fn any, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	file, line, funcName, ok := funcLocation(fn)
+	if !ok {
+		// Skip WrapFrom() and wrap() to get user's caller
+		framesToSkip := 2
+
+		return wrap(err, message, framesToSkip, nil)
+	}
+
+	return newAt(err, message, file, line, funcName)
+}
+
+// funcLocation returns the file, line and name of the function fn, or false
+// if fn isn't a non-nil function.
+func funcLocation(fn any) (string, int, string, bool) {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return "", 0, "", false
+	}
+
+	function := runtime.FuncForPC(value.Pointer())
+	if function == nil {
+		return "", 0, "", false
+	}
+
+	// Method values are compiler generated wrappers named after the method
+	// plus -fm, with no source of their own
+	if name, ok := strings.CutSuffix(function.Name(), "-fm"); ok {
+		return "", 0, name, true
+	}
+
+	file, line := function.FileLine(function.Entry())
+
+	return file, line, function.Name(), true
+}
+
+// newAt creates a layer wrapping err, which may be nil, located at file, line
+// and funcName.
+func newAt(err error, message, file string, line int, funcName string) error {
+	cfg := currentConfig()
+
+	if cfg.captureMode == CaptureFuncOnly {
+		file, line = "", 0
+	} else {
+		file, line = cfg.mapLocation(file, line)
+	}
+
+	ctxErr := &CTXError{
+		err:      err,
+		message:  message,
+		file:     file,
+		line:     line,
+		funcName: funcName,
+		id:       newInstanceID(),
+	}
+
+	return finishLayer(ctxErr, nil)
+}
@@ -0,0 +1,120 @@
+package ctxerrors
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAt(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name    string
+		err     error
+		wrapped bool
+	}{
+		{
+			name: "new",
+			err:  NewAt("script failed", "scripts/deploy.lua", 42, "deploy.main"),
+		},
+		{
+			name:    "wrap",
+			err:     WrapAt(baseErr, "script failed", "scripts/deploy.lua", 42, "deploy.main"),
+			wrapped: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(tc.err, &ctxErr))
+			require.Equal(t, "scripts/deploy.lua", ctxErr.File())
+			require.Equal(t, 42, ctxErr.Line())
+			require.Equal(t, "deploy.main", ctxErr.FuncName())
+			require.Empty(t, ctxErr.Callers())
+			require.Contains(t, tc.err.Error(), "scripts/deploy.lua:42")
+			require.Equal(t, tc.wrapped, errors.Is(tc.err, baseErr))
+		})
+	}
+
+	require.NoError(t, WrapAt(nil, "script failed", "scripts/deploy.lua", 42, "deploy.main"))
+}
+
+func TestNewAtFuncOnly(t *testing.T) {
+	SetCaptureMode(CaptureFuncOnly)
+	t.Cleanup(func() { SetCaptureMode(CaptureFull) })
+
+	var ctxErr *CTXError
+
+	require.True(t, errors.As(NewAt("script failed", "scripts/deploy.lua", 42, "deploy.main"), &ctxErr))
+	require.Empty(t, ctxErr.File())
+	require.Zero(t, ctxErr.Line())
+	require.Equal(t, "deploy.main", ctxErr.FuncName())
+}
+
+//go:noinline
+func wrapFromHandler() error {
+	return nil
+}
+
+type wrapFromService struct{}
+
+//go:noinline
+func (wrapFromService) Handle() error {
+	return nil
+}
+
+func TestWrapFrom(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name             string
+		fn               any
+		expectedFuncName string
+		expectedFile     string
+	}{
+		{
+			name:             "function",
+			fn:               wrapFromHandler,
+			expectedFuncName: "github.com/psyb0t/ctxerrors.wrapFromHandler",
+			expectedFile:     "at_internal_test.go",
+		},
+		{
+			name:             "method value",
+			fn:               wrapFromService{}.Handle,
+			expectedFuncName: "github.com/psyb0t/ctxerrors.wrapFromService.Handle",
+			expectedFile:     ".",
+		},
+		{
+			name:             "not a function",
+			fn:               "handler",
+			expectedFuncName: "github.com/psyb0t/ctxerrors.TestWrapFrom.func1",
+			expectedFile:     "at_internal_test.go",
+		},
+		{
+			name:             "nil function",
+			fn:               (func() error)(nil),
+			expectedFuncName: "github.com/psyb0t/ctxerrors.TestWrapFrom.func1",
+			expectedFile:     "at_internal_test.go",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctxErr *CTXError
+
+			err := WrapFrom(baseErr, tc.fn, "run job")
+
+			require.True(t, errors.As(err, &ctxErr))
+			require.Equal(t, tc.expectedFuncName, ctxErr.FuncName())
+			require.Equal(t, tc.expectedFile, filepath.Base(ctxErr.File()))
+			require.ErrorIs(t, err, baseErr)
+		})
+	}
+
+	require.NoError(t, WrapFrom(nil, wrapFromHandler, "run job"))
+}
@@ -0,0 +1,42 @@
+package ctxerrors
+
+// Barrier returns a new error with message that hides err from everything
+// consumers of a public API can do with it: errors.Is, errors.As, Unwrap and
+// the chain walking helpers all stop at the barrier, Error() doesn't include
+// err's text and Marshal doesn't send it. Privileged code like logging and
+// internal tooling can still get at err with UnwrapBarrier. The barrier is a
+// *CTXError created at the caller like New creates one. It returns nil if err
+// is nil.
+func Barrier(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	// Skip Barrier() and newLayer() to get user's caller
+	framesToSkip := 2
+
+	layer := newLayer(nil, message, framesToSkip)
+	layer.barrier = err
+
+	return finishLayer(layer, nil)
+}
+
+// UnwrapBarrier returns the error hidden by the outermost Barrier in err's
+// chain, including layers inside joined errors, or nil if there's none. It's
+// the escape hatch for privileged code paths such as logging, don't hand what
+// it returns to the consumers the barrier is there for.
+func UnwrapBarrier(err error) error {
+	var hidden error
+
+	walk(err, func(current error) {
+		if hidden != nil {
+			return
+		}
+
+		if layer, ok := asCTXError(current); ok {
+			hidden = layer.barrier
+		}
+	})
+
+	return hidden
+}
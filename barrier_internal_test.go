@@ -0,0 +1,78 @@
+package ctxerrors
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBarrier(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "/etc/secret", Err: fs.ErrPermission}
+	hidden := WithKind(Wrap(pathErr, "failed to load key"), KindPermissionDenied)
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, Barrier(nil, "internal error"))
+	})
+
+	t.Run("hides the cause", func(t *testing.T) {
+		err := Barrier(hidden, "internal error")
+
+		var layer *CTXError
+		require.ErrorAs(t, err, &layer)
+		require.Equal(t, "internal error", layer.Message())
+		require.Contains(t, layer.FuncName(), "TestBarrier")
+		require.Nil(t, layer.Unwrap())
+
+		require.NotErrorIs(t, err, fs.ErrPermission)
+		require.False(t, errors.As(err, new(*fs.PathError)))
+		require.NotContains(t, err.Error(), "secret")
+		require.Equal(t, KindUnknown, KindOf(err))
+		require.Equal(t, []string{"internal error"}, Messages(err))
+
+		data, marshalErr := Marshal(err)
+		require.NoError(t, marshalErr)
+		require.NotContains(t, string(data), "secret")
+	})
+
+	t.Run("outer layers", func(t *testing.T) {
+		err := Wrap(Barrier(hidden, "internal error"), "handler failed")
+
+		require.NotErrorIs(t, err, fs.ErrPermission)
+		require.Same(t, hidden, UnwrapBarrier(err))
+	})
+
+	t.Run("survives cloning", func(t *testing.T) {
+		require.Same(t, hidden, UnwrapBarrier(Clone(Barrier(hidden, "internal error"))))
+	})
+}
+
+func TestUnwrapBarrier(t *testing.T) {
+	t.Run("no barrier", func(t *testing.T) {
+		require.NoError(t, UnwrapBarrier(nil))
+		require.NoError(t, UnwrapBarrier(Wrap(io.EOF, "read failed")))
+	})
+
+	t.Run("reveals the cause", func(t *testing.T) {
+		err := UnwrapBarrier(Barrier(io.EOF, "internal error"))
+
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("outermost barrier", func(t *testing.T) {
+		inner := Barrier(io.EOF, "storage error")
+		outer := Barrier(Wrap(inner, "lookup failed"), "internal error")
+
+		revealed := UnwrapBarrier(outer)
+		require.Equal(t, []string{"lookup failed", "storage error"}, Messages(revealed))
+		require.ErrorIs(t, UnwrapBarrier(revealed), io.EOF)
+	})
+
+	t.Run("joined errors", func(t *testing.T) {
+		err := errors.Join(io.ErrUnexpectedEOF, Barrier(io.EOF, "internal error"))
+
+		require.ErrorIs(t, UnwrapBarrier(err), io.EOF)
+	})
+}
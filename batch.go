@@ -0,0 +1,84 @@
+package ctxerrors
+
+import (
+	"errors"
+	"slices"
+)
+
+// WrapAll wraps every non-nil error in errs with the same formatted context,
+// %w included like with Wrapf, for batch operations that collect per-item
+// failures. The result has the same
+// length as errs and nil entries stay nil so indexes still line up with items.
+func WrapAll(errs []error, format string, args ...any) []error {
+	// Skip WrapAll(), wrapAll() and newLayer() to get user's caller
+	framesToSkip := 3
+
+	return wrapAll(errs, framesToSkip, format, args...)
+}
+
+// WrapJoin is like WrapAll but joins the wrapped errors into a single error
+// with errors.Join. It returns nil if every error in errs is nil.
+func WrapJoin(errs []error, format string, args ...any) error {
+	// Skip WrapJoin(), wrapAll() and newLayer() to get user's caller
+	framesToSkip := 3
+
+	return errors.Join(wrapAll(errs, framesToSkip, format, args...)...)
+}
+
+// AppendWrap wraps err with message and appends it to the errors accumulated
+// in *errs, the way multierr.AppendInto does, reporting whether err was
+// non-nil. A nil err leaves *errs alone. Errors already joined in *errs, with
+// errors.Join or uber-go/multierr alike, are flattened into a single
+// errors.Join error along with the new one.
+func AppendWrap(errs *error, err error, message string) bool {
+	// Skip AppendWrap() and wrap() to get user's caller
+	framesToSkip := 2
+
+	if err == nil {
+		return false
+	}
+
+	wrapped := wrap(err, message, framesToSkip, nil)
+
+	if *errs == nil {
+		*errs = wrapped
+
+		return true
+	}
+
+	if joined, ok := members(*errs); ok {
+		*errs = errors.Join(append(slices.Clone(joined), wrapped)...)
+
+		return true
+	}
+
+	*errs = errors.Join(*errs, wrapped)
+
+	return true
+}
+
+// wrapAll wraps every non-nil error in errs with the message format renders
+// to the way WrapfSkip does it, located skip frames up the stack from it.
+func wrapAll(errs []error, skip int, format string, args ...any) []error {
+	if errs == nil {
+		return nil
+	}
+
+	message, refs := formatMessage(format, args...)
+	wrapped := make([]error, len(errs))
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		layer := newLayer(err, message, skip)
+		layer.refs = refs
+
+		checkFormat(layer, format)
+
+		wrapped[i] = finishLayer(layer, nil)
+	}
+
+	return wrapped
+}
@@ -0,0 +1,179 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapAll(t *testing.T) {
+	firstErr := errors.New("first error")   //nolint:err113
+	secondErr := errors.New("second error") //nolint:err113
+
+	testCases := []struct {
+		name string
+		errs []error
+	}{
+		{
+			name: "nil slice",
+			errs: nil,
+		},
+		{
+			name: "all nil",
+			errs: []error{nil, nil},
+		},
+		{
+			name: "mixed",
+			errs: []error{firstErr, nil, secondErr},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := WrapAll(tc.errs, "batch %s", "import")
+
+			if tc.errs == nil {
+				require.Nil(t, actual)
+
+				return
+			}
+
+			require.Len(t, actual, len(tc.errs))
+
+			for i, err := range tc.errs {
+				if err == nil {
+					require.NoError(t, actual[i])
+
+					continue
+				}
+
+				var ctxErr *CTXError
+
+				require.True(t, errors.As(actual[i], &ctxErr))
+				require.Equal(t, "batch import", ctxErr.Message())
+				require.Contains(t, ctxErr.FuncName(), "TestWrapAll")
+				require.ErrorIs(t, actual[i], err)
+			}
+		})
+	}
+}
+
+func TestWrapJoin(t *testing.T) {
+	firstErr := errors.New("first error")   //nolint:err113
+	secondErr := errors.New("second error") //nolint:err113
+
+	t.Run("all nil", func(t *testing.T) {
+		require.NoError(t, WrapJoin([]error{nil, nil}, "batch"))
+		require.NoError(t, WrapJoin(nil, "batch"))
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		actual := WrapJoin([]error{firstErr, nil, secondErr}, "batch %d", 7)
+		require.Error(t, actual)
+		require.ErrorIs(t, actual, firstErr)
+		require.ErrorIs(t, actual, secondErr)
+
+		joined, ok := actual.(interface{ Unwrap() []error })
+		require.True(t, ok)
+		require.Len(t, joined.Unwrap(), 2)
+
+		for _, err := range joined.Unwrap() {
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(err, &ctxErr))
+			require.Equal(t, "batch 7", ctxErr.Message())
+			require.Contains(t, ctxErr.FuncName(), "TestWrapJoin")
+		}
+	})
+}
+
+func TestWrapAllFormat(t *testing.T) {
+	itemErr := errors.New("item error")   //nolint:err113
+	batchErr := errors.New("batch error") //nolint:err113
+
+	t.Run("%w is matched", func(t *testing.T) {
+		actual := WrapAll([]error{itemErr}, "import: %w", batchErr)
+
+		require.Equal(t, "import: batch error", actual[0].(*CTXError).Message()) //nolint:errorlint,forcetypeassert
+		require.ErrorIs(t, actual[0], batchErr)
+		require.ErrorIs(t, actual[0], itemErr)
+
+		joined := WrapJoin([]error{itemErr}, "import: %w", batchErr)
+		require.ErrorIs(t, joined, batchErr)
+		require.NotContains(t, joined.Error(), "%!w")
+	})
+
+	t.Run("bad format is checked", func(t *testing.T) {
+		SetFormatCheck(FormatCheckField)
+		t.Cleanup(func() { SetFormatCheck(FormatCheckOff) })
+
+		args := []any{"alice"}
+		actual := WrapAll([]error{itemErr}, "item %d", args...)
+
+		require.Equal(t, "item %d", Fields(actual[0])[FieldFormatError])
+	})
+}
+
+// multierrError mimics an older uber-go/multierr combined error, which exposes
+// its members through Errors() only.
+type multierrError struct {
+	errs []error
+}
+
+func (e *multierrError) Error() string {
+	return "combined errors"
+}
+
+func (e *multierrError) Errors() []error {
+	return e.errs
+}
+
+func TestAppendWrap(t *testing.T) {
+	firstErr := errors.New("first error")   //nolint:err113
+	secondErr := errors.New("second error") //nolint:err113
+
+	t.Run("nil error", func(t *testing.T) {
+		var errs error
+
+		require.False(t, AppendWrap(&errs, nil, "step"))
+		require.NoError(t, errs)
+	})
+
+	t.Run("accumulates flat", func(t *testing.T) {
+		var errs error
+
+		require.True(t, AppendWrap(&errs, firstErr, "step 1"))
+		require.False(t, AppendWrap(&errs, nil, "step 2"))
+		require.True(t, AppendWrap(&errs, secondErr, "step 3"))
+		require.True(t, AppendWrap(&errs, firstErr, "step 4"))
+
+		joined, ok := errs.(interface{ Unwrap() []error }) //nolint:errorlint
+		require.True(t, ok)
+		require.Len(t, joined.Unwrap(), 3)
+		require.ErrorIs(t, errs, firstErr)
+		require.ErrorIs(t, errs, secondErr)
+		require.Equal(t, []string{"step 1", "first error", "step 3", "second error", "step 4", "first error"}, Messages(errs))
+
+		for _, err := range joined.Unwrap() {
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(err, &ctxErr))
+			require.Contains(t, ctxErr.FuncName(), "TestAppendWrap")
+		}
+	})
+
+	t.Run("flattens multierr", func(t *testing.T) {
+		var errs error = &multierrError{errs: []error{firstErr}}
+
+		require.True(t, AppendWrap(&errs, secondErr, "step 2"))
+		require.Equal(t, []string{"first error", "step 2", "second error"}, Messages(errs))
+	})
+
+	t.Run("joins single error", func(t *testing.T) {
+		errs := firstErr
+
+		require.True(t, AppendWrap(&errs, secondErr, "step 2"))
+		require.Equal(t, []string{"first error", "step 2", "second error"}, Messages(errs))
+	})
+}
@@ -0,0 +1,164 @@
+package ctxerrors
+
+import (
+	"reflect"
+	"strings"
+)
+
+// kindBehaviorAliases are the predicate methods AsKind accepts for a kind on
+// top of the one named after it, as other libraries spell them.
+var kindBehaviorAliases = map[Kind][]string{ //nolint:gochecknoglobals
+	KindNotFound:         {"IsNotFound"},
+	KindAlreadyExists:    {"Conflict", "IsConflict"},
+	KindPermissionDenied: {"Forbidden", "IsForbidden"},
+	KindUnauthenticated:  {"Unauthorized", "IsUnauthorized"},
+	KindUnavailable:      {"Temporary"},
+	KindDeadlineExceeded: {"Timeout"},
+}
+
+// Delegate returns the first error in err's chain, including those inside
+// joined errors, that implements the behavior interface T, looking straight
+// past *CTXError layers. Go can't grow methods on *CTXError at runtime, so this
+// is how callers reach behavioral contracts of a cause that wrapping would
+// otherwise hide, e.g.
+//
+//	u, ok := Delegate[interface{ Unauthorized() bool }](err)
+//	if ok && u.Unauthorized() { ... }
+func Delegate[T any](err error) (T, bool) {
+	var (
+		target T
+		found  bool
+	)
+
+	walk(err, func(current error) {
+		if found {
+			return
+		}
+
+		if _, ok := asCTXError(current); ok {
+			return
+		}
+
+		target, found = current.(T) //nolint:errorlint
+	})
+
+	return target, found
+}
+
+// HasBehavior reports whether an error in err's chain, including those inside
+// joined errors but not *CTXError layers, implements the behavior interface T
+// and answers true to all of its predicates, the methods taking nothing and
+// returning just a bool. So with an interface{ NotFound() bool } cause
+//
+//	if ctxerrors.HasBehavior[interface{ NotFound() bool }](err) { ... }
+//
+// is true only when NotFound() says so, not just because the method exists.
+func HasBehavior[T any](err error) bool {
+	found := false
+	methods := reflect.TypeFor[T]()
+
+	walk(err, func(current error) {
+		if found {
+			return
+		}
+
+		if _, ok := asCTXError(current); ok {
+			return
+		}
+
+		if _, ok := current.(T); !ok { //nolint:errorlint
+			return
+		}
+
+		found = predicatesHold(reflect.ValueOf(current), methods)
+	})
+
+	return found
+}
+
+// AsKind returns the first error in err's chain, including those inside
+// joined errors but not *CTXError layers, whose behavior methods say it's of
+// kind, for codebases classifying errors by interface rather than by Kind. A
+// method counts if it takes nothing, returns a bool and returns true, and is
+// named after kind in CamelCase (NotFound() for KindNotFound, so custom kinds
+// work too) or is a common alias: IsNotFound(); Conflict() and IsConflict() for
+// KindAlreadyExists; Forbidden() and IsForbidden() for KindPermissionDenied;
+// Unauthorized() and IsUnauthorized() for KindUnauthenticated; Temporary() for
+// KindUnavailable and Timeout() for KindDeadlineExceeded.
+func AsKind(err error, kind Kind) (error, bool) { //nolint:revive
+	if kind == KindUnknown {
+		return nil, false
+	}
+
+	names := append([]string{kindMethodName(kind)}, kindBehaviorAliases[kind]...)
+
+	var target error
+
+	walk(err, func(current error) {
+		if target != nil {
+			return
+		}
+
+		if _, ok := asCTXError(current); ok {
+			return
+		}
+
+		value := reflect.ValueOf(current)
+		for _, name := range names {
+			if callPredicate(value.MethodByName(name)) {
+				target = current
+
+				return
+			}
+		}
+	})
+
+	return target, target != nil
+}
+
+// kindMethodName returns the behavior method named after kind, e.g. NotFound
+// for not_found.
+func kindMethodName(kind Kind) string {
+	var name strings.Builder
+
+	for part := range strings.SplitSeq(string(kind), "_") {
+		if part != "" {
+			name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+
+	return name.String()
+}
+
+// predicatesHold reports whether every predicate method of the interface type
+// methods answers true on value.
+func predicatesHold(value reflect.Value, methods reflect.Type) bool {
+	if methods.Kind() != reflect.Interface {
+		return true
+	}
+
+	for i := range methods.NumMethod() {
+		method := value.MethodByName(methods.Method(i).Name)
+		if isPredicate(method) && !callPredicate(method) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isPredicate reports whether method takes nothing and returns just a bool.
+func isPredicate(method reflect.Value) bool {
+	if !method.IsValid() {
+		return false
+	}
+
+	typ := method.Type()
+
+	return typ.NumIn() == 0 && typ.NumOut() == 1 && typ.Out(0).Kind() == reflect.Bool
+}
+
+// callPredicate reports whether method is a predicate answering true.
+func callPredicate(method reflect.Value) bool {
+	return isPredicate(method) && method.Call(nil)[0].Bool()
+}
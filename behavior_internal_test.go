@@ -0,0 +1,261 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type behaviorError struct {
+	timeout   bool
+	temporary bool
+}
+
+func (e behaviorError) Error() string   { return "behavior error" }
+func (e behaviorError) Timeout() bool   { return e.timeout }
+func (e behaviorError) Temporary() bool { return e.temporary }
+
+func TestNetErrorThroughWrapping(t *testing.T) {
+	testCases := []struct {
+		name              string
+		err               error
+		expectedFound     bool
+		expectedTimeout   bool
+		expectedTemporary bool
+	}{
+		{
+			name:          "plain cause",
+			err:           Wrap(errors.New("plain"), "wrapped"), //nolint:err113
+			expectedFound: false,
+		},
+		{
+			name:          "new error",
+			err:           New("x"),
+			expectedFound: false,
+		},
+		{
+			name:            "timeout cause",
+			err:             Wrap(behaviorError{timeout: true}, "wrapped"),
+			expectedFound:   true,
+			expectedTimeout: true,
+		},
+		{
+			name:              "temporary cause",
+			err:               Wrap(behaviorError{temporary: true}, "wrapped"),
+			expectedFound:     true,
+			expectedTemporary: true,
+		},
+		{
+			name:              "deep in chain",
+			err:               Wrap(Wrap(behaviorError{timeout: true, temporary: true}, "inner"), "outer"),
+			expectedFound:     true,
+			expectedTimeout:   true,
+			expectedTemporary: true,
+		},
+		{
+			name:              "deadline exceeded",
+			err:               Wrap(context.DeadlineExceeded, "wrapped"),
+			expectedFound:     true,
+			expectedTimeout:   true,
+			expectedTemporary: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var netErr net.Error
+
+			// *CTXError itself must never pass for a net.Error
+			require.Equal(t, tc.expectedFound, errors.As(tc.err, &netErr))
+
+			if !tc.expectedFound {
+				return
+			}
+
+			require.Equal(t, tc.expectedTimeout, netErr.Timeout())
+			require.Equal(t, tc.expectedTemporary, netErr.Temporary()) //nolint:staticcheck
+		})
+	}
+}
+
+type unauthorizedError struct{}
+
+func (unauthorizedError) Error() string      { return "unauthorized" }
+func (unauthorizedError) Unauthorized() bool { return true }
+
+func TestDelegate(t *testing.T) {
+	type unauthorizer interface{ Unauthorized() bool }
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "no implementer",
+			err:      Wrap(errors.New("plain"), "wrapped"), //nolint:err113
+			expected: false,
+		},
+		{
+			name:     "wrapped implementer",
+			err:      Wrap(Wrap(unauthorizedError{}, "inner"), "outer"),
+			expected: true,
+		},
+		{
+			name:     "implementer inside join",
+			err:      Wrap(errors.Join(errors.New("plain"), unauthorizedError{}), "outer"), //nolint:err113
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := Delegate[unauthorizer](tc.err)
+
+			require.Equal(t, tc.expected, ok)
+
+			if tc.expected {
+				require.True(t, actual.Unauthorized())
+			}
+		})
+	}
+
+	t.Run("skips ctxerrors layers", func(t *testing.T) {
+		actual, ok := Delegate[interface{ Timeout() bool }](Wrap(errors.New("plain"), "wrapped")) //nolint:err113
+
+		require.False(t, ok)
+		require.Nil(t, actual)
+	})
+}
+
+type notFoundError struct{ notFound bool }
+
+func (notFoundError) Error() string    { return "not found" }
+func (e notFoundError) NotFound() bool { return e.notFound }
+
+type forbiddenError struct{}
+
+func (forbiddenError) Error() string     { return "forbidden" }
+func (forbiddenError) IsForbidden() bool { return true }
+
+func TestHasBehavior(t *testing.T) {
+	type notFounder interface{ NotFound() bool }
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "no implementer",
+			err:      Wrap(errors.New("plain"), "wrapped"), //nolint:err113
+			expected: false,
+		},
+		{
+			name:     "predicate true",
+			err:      Wrap(Wrap(notFoundError{notFound: true}, "inner"), "outer"),
+			expected: true,
+		},
+		{
+			name:     "predicate false",
+			err:      Wrap(notFoundError{notFound: false}, "wrapped"),
+			expected: false,
+		},
+		{
+			name:     "inside join",
+			err:      Wrap(errors.Join(errors.New("plain"), notFoundError{notFound: true}), "outer"), //nolint:err113
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, HasBehavior[notFounder](tc.err))
+		})
+	}
+
+	t.Run("non predicate methods only need to exist", func(t *testing.T) {
+		require.True(t, HasBehavior[interface{ Error() string }](Wrap(notFoundError{}, "wrapped")))
+	})
+}
+
+func TestAsKind(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		kind     Kind
+		expected error
+	}{
+		{
+			name:     "method named after kind",
+			err:      Wrap(notFoundError{notFound: true}, "wrapped"),
+			kind:     KindNotFound,
+			expected: notFoundError{notFound: true},
+		},
+		{
+			name:     "predicate false",
+			err:      Wrap(notFoundError{notFound: false}, "wrapped"),
+			kind:     KindNotFound,
+			expected: nil,
+		},
+		{
+			name:     "alias",
+			err:      Wrap(forbiddenError{}, "wrapped"),
+			kind:     KindPermissionDenied,
+			expected: forbiddenError{},
+		},
+		{
+			name:     "timeout alias",
+			err:      Wrap(behaviorError{timeout: true}, "wrapped"),
+			kind:     KindDeadlineExceeded,
+			expected: behaviorError{timeout: true},
+		},
+		{
+			name:     "other kind",
+			err:      Wrap(forbiddenError{}, "wrapped"),
+			kind:     KindNotFound,
+			expected: nil,
+		},
+		{
+			name:     "ctxerrors kind alone",
+			err:      WithKind(errors.New("plain"), KindNotFound), //nolint:err113
+			kind:     KindNotFound,
+			expected: nil,
+		},
+		{
+			name:     "unknown kind",
+			err:      Wrap(notFoundError{notFound: true}, "wrapped"),
+			kind:     KindUnknown,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := AsKind(tc.err, tc.kind)
+
+			require.Equal(t, tc.expected != nil, ok)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestKindMethodName(t *testing.T) {
+	require.Equal(t, "NotFound", kindMethodName(KindNotFound))
+	require.Equal(t, "DeadlineExceeded", kindMethodName(KindDeadlineExceeded))
+	require.Equal(t, "Internal", kindMethodName(KindInternal))
+	require.Equal(t, "RateLimited", kindMethodName("rate_limited"))
+}
@@ -0,0 +1,47 @@
+package ctxerrors
+
+// WrapStackBoundary is like Wrap but marks the layer it creates as where a
+// library's internals end, for library authors wrapping errors on their way
+// out of the public API so users aren't handed a location inside the library
+// for every helper it went through:
+//
+//	func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+//		value, err := c.roundTrip(ctx, "GET", key)
+//		if err != nil {
+//			return nil, ctxerrors.WrapStackBoundary(err, "get "+key)
+//		}
+//		...
+//	}
+//
+// Error() and MergedStack leave out the locations of the *CTXError layers
+// below the outermost boundary in a chain, keeping their messages, and %+v
+// still prints all of them, with a line marking the boundary, for full
+// debugging. It returns nil if err is nil.
+func WrapStackBoundary(err error, message string) error {
+	// Skip WrapStackBoundary() and newLayer() to get user's caller
+	framesToSkip := 2
+
+	if err == nil {
+		// Skip WrapStackBoundary() and logNilWrap() instead
+		logNilWrap(framesToSkip)
+
+		return nil
+	}
+
+	layer := newLayer(err, message, framesToSkip)
+	layer.boundary = true
+
+	return finishLayer(layer, nil)
+}
+
+// publicLayers returns layers, a chain's *CTXError layers outermost first, up
+// to and including the outermost WrapStackBoundary one.
+func publicLayers(layers []*CTXError) []*CTXError {
+	for i, layer := range layers {
+		if layer.boundary {
+			return layers[:i+1]
+		}
+	}
+
+	return layers
+}
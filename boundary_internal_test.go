@@ -0,0 +1,64 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func boundaryLibraryInternal() error {
+	return Wrap(Wrap(errors.New("connection reset"), "read frame"), "round trip") //nolint:err113
+}
+
+//go:noinline
+func boundaryLibraryGet() error {
+	return WrapStackBoundary(boundaryLibraryInternal(), "get user:42")
+}
+
+func TestWrapStackBoundary(t *testing.T) {
+	err := Wrap(boundaryLibraryGet(), "load profile")
+	layers := ctxLayers(err)
+
+	require.Len(t, layers, 4)
+
+	text := err.Error()
+
+	require.True(t, strings.HasPrefix(text, "load profile: get user:42: round trip: read frame: connection reset ["))
+	require.Equal(t, 2, strings.Count(text, "[/"))
+	require.Contains(t, text, "boundaryLibraryGet]")
+	require.NotContains(t, text, "boundaryLibraryInternal")
+
+	require.Equal(t, []string{
+		"github.com/psyb0t/ctxerrors.boundaryLibraryGet",
+		"github.com/psyb0t/ctxerrors.TestWrapStackBoundary",
+	}, funcNames(MergedStack(err)))
+
+	detail := fmt.Sprintf("%+v", err)
+	require.Contains(t, detail, "boundaryLibraryInternal")
+	require.Contains(t, detail, "(library internals below)")
+
+	require.NoError(t, WrapStackBoundary(nil, "get user:42"))
+
+	t.Run("collapsed repeats", func(t *testing.T) {
+		SetCollapseRepeats(true)
+		t.Cleanup(func() { SetCollapseRepeats(false) })
+
+		err := WrapStackBoundary(Wrap(Wrap(errors.New("reset"), "retry"), "retry"), "retry") //nolint:err113
+
+		require.Equal(t, 1, strings.Count(err.Error(), "[/"))
+	})
+}
+
+// funcNames returns the function names of frames.
+func funcNames(frames []Frame) []string {
+	names := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		names = append(names, frame.FuncName)
+	}
+
+	return names
+}
@@ -0,0 +1,147 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrOverBudget is returned by Marshaler.Marshal when a chain doesn't fit in
+// MaxBytes even with everything optional given up.
+var ErrOverBudget = errors.New("error chain over size budget")
+
+// fitBudget gives up parts of chain until it encodes to at most budget bytes,
+// in the order Marshaler.MaxBytes describes.
+func fitBudget(chain *wireChain, budget int) ([]byte, error) {
+	layers := flattenLayers(chain.Layers)
+
+	for _, layer := range layers {
+		if len(layer.Stack) == 0 {
+			continue
+		}
+
+		layer.Stack = nil
+
+		if data, ok, err := fits(chain, budget); ok || err != nil {
+			return data, err
+		}
+	}
+
+	for {
+		layer, key := biggestField(layers)
+		if layer == nil {
+			break
+		}
+
+		delete(layer.Fields, key)
+
+		if data, ok, err := fits(chain, budget); ok || err != nil {
+			return data, err
+		}
+	}
+
+	for _, layer := range layers {
+		if len(layer.Details) == 0 {
+			continue
+		}
+
+		layer.Details = nil
+
+		if data, ok, err := fits(chain, budget); ok || err != nil {
+			return data, err
+		}
+	}
+
+	for {
+		layer := longestMessage(layers)
+		if layer == nil {
+			break
+		}
+
+		data, _, err := fits(chain, budget)
+		if err != nil {
+			return nil, err
+		}
+
+		layer.Message = cutMessage(layer.Message, len(data)-budget)
+
+		if data, ok, err := fits(chain, budget); ok || err != nil {
+			return data, err
+		}
+	}
+
+	return nil, Wrapf(ErrOverBudget, "chain doesn't fit in %d bytes", budget)
+}
+
+// fits returns chain encoded and whether that's at most budget bytes.
+func fits(chain *wireChain, budget int) ([]byte, bool, error) {
+	data, err := json.Marshal(chain)
+	if err != nil {
+		return nil, false, Wrap(err, "failed to marshal error chain")
+	}
+
+	return data, len(data) <= budget, nil
+}
+
+// flattenLayers returns every layer of layers including those inside joined
+// errors, outermost first.
+func flattenLayers(layers []wireLayer) []*wireLayer {
+	var flat []*wireLayer
+
+	for i := range layers {
+		flat = append(flat, &layers[i])
+
+		for _, member := range layers[i].Joined {
+			flat = append(flat, flattenLayers(member)...)
+		}
+	}
+
+	return flat
+}
+
+// biggestField returns the layer and key of the biggest encoded field, or nil
+// if there are no fields left.
+func biggestField(layers []*wireLayer) (*wireLayer, string) {
+	var (
+		biggest *wireLayer
+		key     string
+		size    int
+	)
+
+	for _, layer := range layers {
+		for fieldKey, value := range layer.Fields {
+			if fieldSize := len(fieldKey) + len(value); biggest == nil || fieldSize > size {
+				biggest, key, size = layer, fieldKey, fieldSize
+			}
+		}
+	}
+
+	return biggest, key
+}
+
+// longestMessage returns the layer with the longest message, or nil if every
+// message is empty.
+func longestMessage(layers []*wireLayer) *wireLayer {
+	var longest *wireLayer
+
+	for _, layer := range layers {
+		if layer.Message != "" && (longest == nil || len(layer.Message) > len(longest.Message)) {
+			longest = layer
+		}
+	}
+
+	return longest
+}
+
+// cutMessage shortens message by at least over bytes, counting the marker
+// replacing what's cut, or empties it if what would be left isn't worth the
+// marker.
+func cutMessage(message string, over int) string {
+	marker := truncationMarker(len(message))
+
+	keep := runeBoundary(message, len(message)-over-len(marker))
+	if keep <= 0 {
+		return ""
+	}
+
+	return message[:keep] + truncationMarker(len(message)-keep)
+}
@@ -0,0 +1,110 @@
+package ctxerrors
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalerMaxBytes(t *testing.T) {
+	t.Cleanup(func() { SetCallerDepth(0) })
+
+	SetCallerDepth(3)
+
+	inner := WithField(New("connection refused"), "payload", strings.Repeat("x", 200))
+	err := WithField(WrapDepth(inner, 3, "load user "+strings.Repeat("é", 100)), "user", 42)
+
+	full, marshalErr := Marshal(err)
+	require.NoError(t, marshalErr)
+
+	decode := func(t *testing.T, data []byte) []*CTXError {
+		t.Helper()
+
+		decoded, unmarshalErr := Unmarshal(data)
+		require.NoError(t, unmarshalErr)
+
+		return ctxLayers(decoded)
+	}
+
+	t.Run("fits as is", func(t *testing.T) {
+		data, marshalErr := Marshaler{MaxBytes: len(full)}.Marshal(err)
+		require.NoError(t, marshalErr)
+		require.Equal(t, full, data)
+	})
+
+	t.Run("outer stacks go first", func(t *testing.T) {
+		data, marshalErr := Marshaler{MaxBytes: len(full) - 1}.Marshal(err)
+		require.NoError(t, marshalErr)
+		require.LessOrEqual(t, len(data), len(full)-1)
+
+		layers := decode(t, data)
+		require.Empty(t, layers[0].Callers())
+		require.NotEmpty(t, layers[1].Callers())
+		require.Len(t, layers[0].fields, 1)
+		require.Len(t, layers[1].fields, 1)
+	})
+
+	t.Run("then fields, biggest first", func(t *testing.T) {
+		stripped, marshalErr := Marshal(withoutCallers(t, err))
+		require.NoError(t, marshalErr)
+
+		data, marshalErr := Marshaler{MaxBytes: len(stripped) - 1}.Marshal(err)
+		require.NoError(t, marshalErr)
+
+		layers := decode(t, data)
+		require.Empty(t, layers[0].Callers())
+		require.Empty(t, layers[1].Callers())
+		require.Empty(t, layers[1].fields)
+		require.Len(t, layers[0].fields, 1)
+		require.Equal(t, "connection refused", layers[1].message)
+	})
+
+	t.Run("then messages, longest first", func(t *testing.T) {
+		data, marshalErr := Marshaler{MaxBytes: 400}.Marshal(err)
+		require.NoError(t, marshalErr)
+		require.LessOrEqual(t, len(data), 400)
+
+		layers := decode(t, data)
+		require.Empty(t, layers[0].fields)
+		require.Empty(t, layers[1].fields)
+		require.Equal(t, "connection refused", layers[1].message)
+		require.True(t, strings.HasPrefix(layers[0].message, "load user é"))
+		require.Regexp(t, `é…\(\+\d+ bytes\)$`, layers[0].message)
+	})
+
+	t.Run("structure alone too big", func(t *testing.T) {
+		_, marshalErr := Marshaler{MaxBytes: 50}.Marshal(err)
+		require.ErrorIs(t, marshalErr, ErrOverBudget)
+	})
+}
+
+func TestCutMessage(t *testing.T) {
+	message := strings.Repeat("a", 50)
+
+	cut := cutMessage(message, 10)
+	require.Equal(t, strings.Repeat("a", 26)+"…(+24 bytes)", cut)
+	require.LessOrEqual(t, len(cut), len(message)-10)
+
+	require.Empty(t, cutMessage("short", 2))
+	require.Equal(t, "éé…(+16 bytes)", cutMessage(strings.Repeat("é", 10), 1))
+}
+
+// withoutCallers returns a copy of err's chain with no captured callers.
+func withoutCallers(t *testing.T, err error) error {
+	t.Helper()
+
+	return copyChain(err, func(layer *CTXError) {
+		layer.callers = nil
+	})
+}
+
+func TestFlattenLayers(t *testing.T) {
+	data, err := Marshal(Wrap(errors.Join(New("a"), Wrap(New("b"), "c")), "top"))
+	require.NoError(t, err)
+
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+	require.Len(t, flattenLayers(encodeLayers(decoded, false)), 5)
+}
@@ -0,0 +1,247 @@
+package ctxerrors
+
+import (
+	"reflect"
+	"runtime"
+	"slices"
+)
+
+// inlineCallers is how many extra caller frames fit in a CTXError without
+// another allocation.
+const inlineCallers = 4
+
+// Frame is one resolved stack frame.
+type Frame struct {
+	File     string // Empty if it couldn't be resolved
+	Line     int    // Zero if it couldn't be resolved
+	FuncName string // Fully qualified function name
+}
+
+// SetCallerDepth sets how many frames above the immediate caller get captured
+// for errors created from now on, available through Callers and printed by
+// %+v. It's a middle ground between the single location every error has and a
+// full stack. Zero, the default, captures none.
+func SetCallerDepth(depth int) {
+	updateConfig(func(c *config) {
+		c.callerDepth = max(depth, 0)
+	})
+}
+
+// StackPredicate decides whether a newly created error gets the frames
+// SetCallerDepth asks for.
+type StackPredicate func(err error) bool
+
+// SetStackIf sets the predicate run on every error created from now on to
+// decide whether it gets the frames SetCallerDepth asks for, so stacks are only
+// paid for on the paths worth debugging and the frequent, boring ones keep just
+// their location:
+//
+//	ctxerrors.SetStackIf(func(err error) bool {
+//		return !errors.Is(err, context.Canceled)
+//	})
+//
+// The predicate gets the new layer before enrich hooks run, so it sees the
+// message, location and cause but not fields added later like WithKind ones.
+// Match on the cause, KindOf and Classify look through to it. It doesn't
+// affect NewDepth and WrapDepth. Pass nil to capture for every error, which is
+// the default.
+func SetStackIf(predicate StackPredicate) {
+	updateConfig(func(c *config) {
+		c.stackIf = predicate
+	})
+}
+
+// Callers returns the frames captured above the location of this layer, nearest
+// first, as many as SetCallerDepth asked for when it was created.
+func (e *CTXError) Callers() []Frame {
+	if e == nil {
+		return nil
+	}
+
+	if len(e.frames) > 0 {
+		return slices.Clone(e.frames)
+	}
+
+	return resolveCallers(e.callers)
+}
+
+// resolveCallers resolves captured caller PCs into frames, with the
+// configured Resolver if there is one and the runtime otherwise.
+func resolveCallers(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	cfg := currentConfig()
+	frames := make([]Frame, 0, len(pcs))
+
+	if cfg.resolver != nil {
+		for _, pc := range pcs {
+			// The PC is a return address, step back into the call instruction
+			frame := cfg.resolver.Resolve(pc - 1)
+			frame.File, frame.Line = cfg.mapLocation(frame.File, frame.Line)
+
+			frames = append(frames, frame)
+		}
+
+		return frames
+	}
+
+	iter := runtime.CallersFrames(pcs)
+
+	for {
+		frame, more := iter.Next()
+
+		file, line := frame.File, frame.Line
+		file, line = cfg.mapLocation(file, line)
+
+		frames = append(frames, Frame{File: file, Line: line, FuncName: frame.Function})
+
+		if !more {
+			break
+		}
+	}
+
+	return frames
+}
+
+// NewDepth is like New but captures depth frames above the caller whatever
+// SetCallerDepth says, so critical entry points can capture more and noisy
+// deep call paths fewer.
+func NewDepth(depth int, message string) error {
+	// Skip NewDepth() and newLayerDepth() to get user's caller
+	framesToSkip := 2
+
+	layer := newLayerDepth(nil, message, framesToSkip, depth)
+
+	return finishLayer(layer, nil)
+}
+
+// WrapDepth is like Wrap but captures depth frames above the caller whatever
+// SetCallerDepth says.
+func WrapDepth(err error, depth int, message string) error {
+	// Skip WrapDepth() and newLayerDepth() to get user's caller
+	framesToSkip := 2
+
+	if err == nil {
+		// Skip WrapDepth() and logNilWrap() instead
+		logNilWrap(framesToSkip)
+
+		return nil
+	}
+
+	layer := newLayerDepth(err, message, framesToSkip, depth)
+
+	return finishLayer(layer, nil)
+}
+
+// captureCallers records the PCs of the extra frames SetCallerDepth asks for
+// above the frame getCallerInfo resolves for the same skip, unless the wrapped
+// error already carries a stack, in which case the frames would only repeat
+// what's there and this layer keeps just its own location. The SetStackIf
+// predicate gets the last word.
+func (e *CTXError) captureCallers(skip int) {
+	cfg := currentConfig()
+
+	depth := cfg.callerDepth
+	if depth > 0 && (hasStack(e.err) || cfg.stackIf != nil && !cfg.stackIf(e)) {
+		depth = 0
+	}
+
+	// Skip captureCallers too
+	e.captureCallersDepth(skip+1, depth)
+}
+
+// hasStack reports whether err's chain, including layers inside joined
+// errors, holds a stack: a *CTXError with captured callers, or an error with
+// the StackTrace() method of github.com/pkg/errors or the Callers() []uintptr
+// method of github.com/go-errors/errors.
+func hasStack(err error) bool {
+	found := false
+
+	walk(err, func(current error) {
+		if found {
+			return
+		}
+
+		if layer, ok := asCTXError(current); ok {
+			found = len(layer.callers) > 0 || len(layer.frames) > 0
+
+			return
+		}
+
+		if _, ok := current.(interface{ Callers() []uintptr }); ok { //nolint:errorlint
+			found = true
+
+			return
+		}
+
+		// pkg/errors' StackTrace() returns its own type, so look the method up
+		// by name rather than depend on the package
+		method := reflect.ValueOf(current).MethodByName("StackTrace")
+		found = method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() == 1
+	})
+
+	return found
+}
+
+// captureCallersDepth records the PCs of depth frames above the frame
+// getCallerInfo resolves for the same skip, in the inline array if they fit so
+// shallow captures don't cost another allocation.
+func (e *CTXError) captureCallersDepth(skip, depth int) {
+	if depth <= 0 {
+		e.callers = nil
+
+		return
+	}
+
+	pcs := e.callerPCs[:]
+	if depth > len(pcs) {
+		pcs = make([]uintptr, depth)
+	}
+
+	// Skip runtime.Callers, captureCallersDepth and the caller's own frame
+	n := runtime.Callers(skip+3, pcs[:depth]) //nolint:mnd
+	if n > 0 {
+		countActivity(&activity.stacks)
+	}
+
+	e.callers = pcs[:n:n]
+}
+
+// MergedStack combines the locations and captured callers of every *CTXError
+// layer in err's chain, following Unwrap() error, into a single trace, origin
+// first: the innermost layer's location, then the frames above it, then the
+// next layer out and so on. Frames already in the trace are left out, so
+// reporters get one coherent stack even when context was added piecemeal up
+// the call chain. Layers below a WrapStackBoundary one are library internals
+// and left out. It returns nil if there are no *CTXError layers.
+func MergedStack(err error) []Frame {
+	layers := publicLayers(ctxLayers(err))
+	if len(layers) == 0 {
+		return nil
+	}
+
+	var frames []Frame
+
+	seen := make(map[Frame]bool)
+
+	add := func(frame Frame) {
+		if !seen[frame] {
+			seen[frame] = true
+			frames = append(frames, frame)
+		}
+	}
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+
+		add(Frame{File: layer.file, Line: layer.line, FuncName: layer.funcName})
+
+		for _, frame := range layer.Callers() {
+			add(frame)
+		}
+	}
+
+	return frames
+}
@@ -0,0 +1,338 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func callersLevel2() error {
+	return Wrap(errors.New("base error"), "level 2") //nolint:err113
+}
+
+//go:noinline
+func callersLevel1() error {
+	return callersLevel2()
+}
+
+func TestSetCallerDepth(t *testing.T) {
+	t.Cleanup(func() { SetCallerDepth(0) })
+
+	t.Run("off by default", func(t *testing.T) {
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(callersLevel1(), &ctxErr))
+		require.Nil(t, ctxErr.Callers())
+	})
+
+	t.Run("captures frames above the caller", func(t *testing.T) {
+		SetCallerDepth(2)
+
+		err := callersLevel1()
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(err, &ctxErr))
+		require.Contains(t, ctxErr.FuncName(), "callersLevel2")
+
+		frames := ctxErr.Callers()
+		require.Len(t, frames, 2)
+		require.Contains(t, frames[0].FuncName, "callersLevel1")
+		require.Contains(t, frames[0].File, goFileExtension)
+		require.NotZero(t, frames[0].Line)
+		require.Contains(t, frames[1].FuncName, "TestSetCallerDepth")
+
+		detail := fmt.Sprintf("%+v", err)
+		require.Contains(t, detail, "callersLevel2")
+		require.Contains(t, detail, "callersLevel1")
+	})
+
+	t.Run("new errors too", func(t *testing.T) {
+		SetCallerDepth(1)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(New("standalone"), &ctxErr))
+		require.Len(t, ctxErr.Callers(), 1)
+	})
+
+	t.Run("skipped when the cause has a stack", func(t *testing.T) {
+		SetCallerDepth(2)
+
+		inner := callersLevel1()
+
+		var outer, innermost *CTXError
+
+		require.True(t, errors.As(Wrap(inner, "outer"), &outer))
+		require.True(t, errors.As(inner, &innermost))
+		require.Nil(t, outer.Callers())
+		require.NotZero(t, outer.Line())
+		require.Len(t, innermost.Callers(), 2)
+
+		require.True(t, errors.As(WrapDepth(inner, 1, "explicit"), &outer))
+		require.Len(t, outer.Callers(), 1)
+	})
+
+	t.Run("negative depth", func(t *testing.T) {
+		SetCallerDepth(-3)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(callersLevel1(), &ctxErr))
+		require.Nil(t, ctxErr.Callers())
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		var ctxErr *CTXError
+
+		require.Nil(t, ctxErr.Callers())
+	})
+}
+
+func TestSetStackIf(t *testing.T) {
+	t.Cleanup(func() {
+		SetCallerDepth(0)
+		SetStackIf(nil)
+	})
+
+	SetCallerDepth(2)
+	SetStackIf(func(err error) bool {
+		return !errors.Is(err, context.Canceled)
+	})
+
+	testCases := []struct {
+		name           string
+		err            func() error
+		expectedFrames int
+	}{
+		{
+			name:           "matching error",
+			err:            func() error { return Wrap(io.ErrUnexpectedEOF, "read body") },
+			expectedFrames: 2,
+		},
+		{
+			name:           "new error",
+			err:            func() error { return New("standalone") },
+			expectedFrames: 2,
+		},
+		{
+			name:           "predicate says no",
+			err:            func() error { return Wrap(context.Canceled, "read body") },
+			expectedFrames: 0,
+		},
+		{
+			name:           "explicit depth ignores predicate",
+			err:            func() error { return WrapDepth(context.Canceled, 1, "read body") },
+			expectedFrames: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(tc.err(), &ctxErr))
+			require.Len(t, ctxErr.Callers(), tc.expectedFrames)
+			require.NotZero(t, ctxErr.Line())
+		})
+	}
+}
+
+//go:noinline
+func depthLevel2(depth int) (error, error) {
+	return NewDepth(depth, "level 2"), WrapDepth(errors.New("base error"), depth, "level 2") //nolint:err113
+}
+
+//go:noinline
+func depthLevel1(depth int) (error, error) {
+	return depthLevel2(depth)
+}
+
+func TestNewDepthAndWrapDepth(t *testing.T) {
+	t.Cleanup(func() { SetCallerDepth(0) })
+
+	SetCallerDepth(1)
+
+	testCases := []struct {
+		name           string
+		depth          int
+		expectedFrames []string
+	}{
+		{
+			name:           "same as global",
+			depth:          1,
+			expectedFrames: []string{"depthLevel1"},
+		},
+		{
+			name:           "more than global",
+			depth:          2,
+			expectedFrames: []string{"depthLevel1", "TestNewDepthAndWrapDepth"},
+		},
+		{
+			name:           "fewer than global",
+			depth:          0,
+			expectedFrames: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			created, wrapped := depthLevel1(tc.depth)
+
+			for _, err := range []error{created, wrapped} {
+				var ctxErr *CTXError
+
+				require.True(t, errors.As(err, &ctxErr))
+				require.Contains(t, ctxErr.FuncName(), "depthLevel2")
+				require.Equal(t, "level 2", ctxErr.Message())
+
+				frames := ctxErr.Callers()
+				require.Len(t, frames, len(tc.expectedFrames))
+
+				for i, expected := range tc.expectedFrames {
+					require.Contains(t, frames[i].FuncName, expected)
+				}
+			}
+		})
+	}
+}
+
+//go:noinline
+func deepWrapDepth(err error, depth int) error {
+	if depth == 0 {
+		return WrapDepth(err, inlineCallers+2, "wrapped")
+	}
+
+	return deepWrapDepth(err, depth-1)
+}
+
+func TestCallerStorage(t *testing.T) {
+	t.Cleanup(func() { SetCallerDepth(0) })
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	t.Run("shallow captures stay inline", func(t *testing.T) {
+		SetCallerDepth(inlineCallers)
+
+		allocs := testing.AllocsPerRun(100, func() { _ = Wrap(baseErr, "wrapped") })
+		require.LessOrEqual(t, allocs, 1.0)
+	})
+
+	t.Run("deep captures overflow", func(t *testing.T) {
+		SetCallerDepth(0)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(deepWrapDepth(baseErr, inlineCallers+2), &ctxErr))
+		require.Len(t, ctxErr.callers, inlineCallers+2)
+		require.NotSame(t, &ctxErr.callerPCs[0], &ctxErr.callers[0])
+	})
+
+	t.Run("clones keep their frames", func(t *testing.T) {
+		SetCallerDepth(2)
+
+		original := Wrap(baseErr, "wrapped")
+		clone := Clone(original)
+
+		require.Equal(t, original.(*CTXError).Callers(), clone.(*CTXError).Callers()) //nolint:errorlint,forcetypeassert
+	})
+}
+
+// stackTraceError mimics the errors of github.com/pkg/errors, whose
+// StackTrace() returns a type of that package.
+type stackTraceError struct{ error }
+
+type stackTrace []uintptr
+
+func (e stackTraceError) StackTrace() stackTrace { return nil }
+
+// callersError mimics the errors of github.com/go-errors/errors.
+type callersError struct{ error }
+
+func (e callersError) Callers() []uintptr { return nil }
+
+func TestHasStack(t *testing.T) {
+	t.Cleanup(func() { SetCallerDepth(0) })
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	withoutStack := Wrap(baseErr, "no stack")
+
+	SetCallerDepth(1)
+
+	withStack := Wrap(baseErr, "stack")
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil error", err: nil, expected: false},
+		{name: "plain error", err: baseErr, expected: false},
+		{name: "layer without callers", err: withoutStack, expected: false},
+		{name: "layer with callers", err: withStack, expected: true},
+		{name: "deep in chain", err: fmt.Errorf("foreign: %w", withStack), expected: true},
+		{name: "inside joined errors", err: errors.Join(baseErr, withStack), expected: true},
+		{name: "pkg/errors", err: fmt.Errorf("foreign: %w", stackTraceError{baseErr}), expected: true},
+		{name: "go-errors", err: callersError{baseErr}, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, hasStack(tc.err))
+		})
+	}
+}
+
+//go:noinline
+func mergedLevel2() error {
+	return NewDepth(2, "origin")
+}
+
+//go:noinline
+func mergedLevel1() error {
+	return WrapDepth(mergedLevel2(), 1, "middle")
+}
+
+func TestMergedStack(t *testing.T) {
+	t.Run("nil error", func(t *testing.T) {
+		require.Nil(t, MergedStack(nil))
+	})
+
+	t.Run("no layers", func(t *testing.T) {
+		require.Nil(t, MergedStack(errors.New("base error"))) //nolint:err113
+	})
+
+	t.Run("layers without callers", func(t *testing.T) {
+		inner := &CTXError{message: "inner", file: "/app/db.go", line: 10, funcName: "main.query"}
+		outer := &CTXError{err: fmt.Errorf("foreign: %w", inner), message: "outer", file: "/app/main.go", line: 5, funcName: "main.main"}
+
+		require.Equal(t, []Frame{
+			{File: "/app/db.go", Line: 10, FuncName: "main.query"},
+			{File: "/app/main.go", Line: 5, FuncName: "main.main"},
+		}, MergedStack(outer))
+	})
+
+	t.Run("overlapping callers", func(t *testing.T) {
+		err := Wrap(mergedLevel1(), "outer")
+
+		frames := MergedStack(err)
+
+		names := make([]string, 0, len(frames))
+		for _, frame := range frames {
+			names = append(names, frame.FuncName)
+		}
+
+		// The middle and outer layers sit on the same lines as the callers
+		// captured by the origin, so they add nothing
+		require.Len(t, frames, 3)
+		require.Contains(t, names[0], "mergedLevel2")
+		require.Contains(t, names[1], "mergedLevel1")
+		require.Contains(t, names[2], "TestMergedStack")
+	})
+}
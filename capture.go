@@ -0,0 +1,114 @@
+package ctxerrors
+
+import (
+	"reflect"
+	"strings"
+)
+
+// captureTag is the struct tag CaptureArgs reads.
+const captureTag = "ctxerr"
+
+// CaptureArgs returns err with the exported fields of the struct s, or of the
+// struct it points to, set as fields like WithField sets them, so the
+// parameters of a request or job are captured in one call instead of field by
+// field:
+//
+//	type ChargeRequest struct {
+//		CustomerID string `ctxerr:"customer_id"`
+//		Amount     int64
+//		CardNumber string `ctxerr:"redact"`
+//		Callback   func() `ctxerr:"-"`
+//	}
+//
+// The key is the Go field name unless the tag names one. A tag of "-" leaves
+// the field out and "redact", alone or after the name like "card,redact",
+// records RedactedValue instead of the value. Fields of exported embedded
+// structs are captured as if they were the outer struct's, other struct values
+// as they are. If s is neither a struct nor a non-nil pointer to one err is
+// returned as is. It returns nil if err is nil.
+func CaptureArgs(err error, s any) error {
+	// Skip CaptureArgs(), annotate() and wrap() to get user's caller
+	framesToSkip := 3
+
+	value := reflect.ValueOf(s)
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	if isNilError(err) {
+		return nil
+	}
+
+	if value.Kind() != reflect.Struct {
+		return err
+	}
+
+	fields := make(map[string]any)
+	captureStruct(value, fields)
+
+	if len(fields) == 0 {
+		return err
+	}
+
+	return annotate(err, fields, framesToSkip)
+}
+
+// captureStruct adds the exported fields of the struct value to fields as
+// CaptureArgs describes.
+func captureStruct(value reflect.Value, fields map[string]any) {
+	structType := value.Type()
+
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+
+		name, redact, skip := parseCaptureTag(field)
+		if skip || !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := value.Field(i)
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				captureStruct(embedded, fields)
+
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if redact {
+			fields[name] = RedactedValue
+
+			continue
+		}
+
+		fields[name] = value.Field(i).Interface()
+	}
+}
+
+// parseCaptureTag returns the key the tag of field names, whether it asks for
+// redaction and whether it leaves the field out.
+func parseCaptureTag(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get(captureTag)
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "redact" && options == "" {
+		return "", true, false
+	}
+
+	return name, options == "redact", false
+}
@@ -0,0 +1,103 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type CaptureBase struct {
+	TenantID string `ctxerr:"tenant_id"`
+}
+
+type captureRequest struct {
+	CaptureBase
+
+	CustomerID string `ctxerr:"customer_id"`
+	Amount     int64
+	CardNumber string `ctxerr:"redact"`
+	Token      string `ctxerr:"token,redact"`
+	Callback   func() `ctxerr:"-"`
+	retries    int
+}
+
+func TestCaptureArgs(t *testing.T) {
+	baseErr := errors.New("card declined") //nolint:err113
+
+	request := captureRequest{
+		CaptureBase: CaptureBase{TenantID: "acme"},
+		CustomerID:  "cus_42",
+		Amount:      1999,
+		CardNumber:  "4242424242424242",
+		Token:       "tok_secret",
+		Callback:    func() {},
+		retries:     3,
+	}
+
+	expected := map[string]any{
+		"tenant_id":   "acme",
+		"customer_id": "cus_42",
+		"Amount":      int64(1999),
+		"CardNumber":  RedactedValue,
+		"token":       RedactedValue,
+	}
+
+	testCases := []struct {
+		name     string
+		s        any
+		expected map[string]any
+	}{
+		{
+			name:     "struct",
+			s:        request,
+			expected: expected,
+		},
+		{
+			name:     "pointer",
+			s:        &request,
+			expected: expected,
+		},
+		{
+			name:     "nil pointer",
+			s:        (*captureRequest)(nil),
+			expected: nil,
+		},
+		{
+			name:     "not a struct",
+			s:        42,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := CaptureArgs(baseErr, tc.s)
+			if tc.expected == nil {
+				require.Same(t, baseErr, actual)
+
+				return
+			}
+
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(actual, &ctxErr))
+			require.Equal(t, tc.expected, ctxErr.Fields())
+			require.Contains(t, ctxErr.FuncName(), "TestCaptureArgs")
+			require.ErrorIs(t, actual, baseErr)
+		})
+	}
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, CaptureArgs(nil, request))
+	})
+
+	t.Run("annotates a copy", func(t *testing.T) {
+		original := Wrap(baseErr, "charge")
+		actual := CaptureArgs(original, request)
+
+		require.Equal(t, "charge", FirstContext(actual).Message())
+		require.Empty(t, FirstContext(original).Fields())
+		require.Equal(t, expected, FirstContext(actual).Fields())
+	})
+}
@@ -0,0 +1,161 @@
+package ctxerrors
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"reflect"
+	"syscall"
+)
+
+// postgresErrorTypes are the PostgreSQL driver error types CauseDetails knows,
+// by package name and type name. They're read by reflection so the drivers
+// aren't dependencies.
+var postgresErrorTypes = map[string]bool{ //nolint:gochecknoglobals
+	"pq.Error":       true, // github.com/lib/pq
+	"pgconn.PgError": true, // github.com/jackc/pgx/v5/pgconn and github.com/jackc/pgconn
+}
+
+// postgresFields maps the fields of the PostgreSQL driver error types to the
+// keys CauseDetails uses for them, which are the same for both drivers.
+var postgresFields = map[string]string{ //nolint:gochecknoglobals
+	"Severity":         "severity",
+	"Code":             "code",
+	"Message":          "message",
+	"Detail":           "detail",
+	"Hint":             "hint",
+	"Position":         "position",
+	"InternalPosition": "internal_position",
+	"InternalQuery":    "internal_query",
+	"Where":            "where",
+	"Schema":           "schema",
+	"SchemaName":       "schema",
+	"Table":            "table",
+	"TableName":        "table",
+	"Column":           "column",
+	"ColumnName":       "column",
+	"DataTypeName":     "data_type",
+	"Constraint":       "constraint",
+	"ConstraintName":   "constraint",
+	"File":             "file",
+	"Line":             "line",
+	"Routine":          "routine",
+}
+
+// CauseDetails returns the structured details of the innermost error in err's
+// chain, following Unwrap() error, that's of a type it knows, so the root
+// cause from another library is more than its Error() string in logs:
+//
+//	logger.Error("query failed", "error", err, "cause", ctxerrors.CauseDetails(err))
+//
+// It knows *os.PathError (op, path), *os.LinkError (op, old, new),
+// *os.SyscallError (syscall), *net.OpError (op, net, source, addr),
+// *net.DNSError (name, server, is_timeout, is_not_found), syscall.Errno
+// (errno, name) and the errors of the lib/pq and pgx PostgreSQL drivers
+// (code, severity, detail, hint, table, constraint and so on, whichever are
+// set). Marshal puts the same details in the layers of those errors. It
+// returns nil if there's no such error.
+func CauseDetails(err error) map[string]any {
+	var details map[string]any
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if found := causeDetails(err); found != nil {
+			details = found
+		}
+	}
+
+	return details
+}
+
+// causeDetails returns the structured details of err itself if it's of a type
+// CauseDetails knows, or nil.
+func causeDetails(err error) map[string]any {
+	switch e := err.(type) { //nolint:errorlint
+	case *fs.PathError:
+		return map[string]any{"op": e.Op, "path": e.Path}
+	case *os.LinkError:
+		return map[string]any{"op": e.Op, "old": e.Old, "new": e.New}
+	case *os.SyscallError:
+		return map[string]any{"syscall": e.Syscall}
+	case *net.OpError:
+		return netOpDetails(e)
+	case *net.DNSError:
+		return map[string]any{
+			"name":         e.Name,
+			"server":       e.Server,
+			"is_timeout":   e.IsTimeout,
+			"is_not_found": e.IsNotFound,
+		}
+	case syscall.Errno:
+		return map[string]any{"errno": uint64(e), "name": e.Error()}
+	}
+
+	return postgresDetails(reflect.ValueOf(err))
+}
+
+// netOpDetails returns the details of a *net.OpError, leaving out the
+// addresses it doesn't have.
+func netOpDetails(e *net.OpError) map[string]any {
+	details := map[string]any{"op": e.Op, "net": e.Net}
+
+	if e.Source != nil {
+		details["source"] = e.Source.String()
+	}
+
+	if e.Addr != nil {
+		details["addr"] = e.Addr.String()
+	}
+
+	return details
+}
+
+// postgresDetails returns the set fields of a PostgreSQL driver error, or nil
+// if value isn't one.
+func postgresDetails(value reflect.Value) map[string]any {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	typ := value.Type()
+	if typ.Kind() != reflect.Struct || !postgresErrorTypes[path.Base(typ.PkgPath())+"."+typ.Name()] {
+		return nil
+	}
+
+	return structDetails(value, postgresFields)
+}
+
+// structDetails returns the non-zero string and integer fields of the struct
+// value named in keys, under the keys they map to.
+func structDetails(value reflect.Value, keys map[string]string) map[string]any {
+	details := map[string]any{}
+
+	for i := range value.NumField() {
+		key, ok := keys[value.Type().Field(i).Name]
+		if !ok {
+			continue
+		}
+
+		switch field := value.Field(i); field.Kind() { //nolint:exhaustive
+		case reflect.String:
+			if field.String() != "" {
+				details[key] = field.String()
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if field.Int() != 0 {
+				details[key] = field.Int()
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if field.Uint() != 0 {
+				details[key] = field.Uint()
+			}
+		}
+	}
+
+	return details
+}
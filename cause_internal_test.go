@@ -0,0 +1,149 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pgError has the fields of a pgconn.PgError that matter here.
+type pgError struct {
+	Code           string
+	Message        string
+	Detail         string
+	Hint           string
+	Position       int32
+	TableName      string
+	ConstraintName string
+	Line           int32
+	unexported     string
+}
+
+func (e *pgError) Error() string {
+	return e.Message
+}
+
+func TestCauseDetails(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "/etc/app.yaml", Err: syscall.ENOENT}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected map[string]any
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "unknown type",
+			err:      Wrap(io.EOF, "read header"),
+			expected: nil,
+		},
+		{
+			name:     "errno is the innermost",
+			err:      Wrap(pathErr, "load config"),
+			expected: map[string]any{"errno": uint64(syscall.ENOENT), "name": syscall.ENOENT.Error()},
+		},
+		{
+			name:     "path error",
+			err:      Wrap(&fs.PathError{Op: "open", Path: "/etc/app.yaml", Err: io.EOF}, "load config"),
+			expected: map[string]any{"op": "open", "path": "/etc/app.yaml"},
+		},
+		{
+			name:     "link error",
+			err:      &os.LinkError{Op: "rename", Old: "a", New: "b", Err: io.EOF},
+			expected: map[string]any{"op": "rename", "old": "a", "new": "b"},
+		},
+		{
+			name:     "syscall error",
+			err:      os.NewSyscallError("fsync", io.EOF),
+			expected: map[string]any{"syscall": "fsync"},
+		},
+		{
+			name: "net op error",
+			err: fmt.Errorf("call upstream: %w", &net.OpError{
+				Op:   "dial",
+				Net:  "tcp",
+				Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5432},
+				Err:  io.EOF,
+			}),
+			expected: map[string]any{"op": "dial", "net": "tcp", "addr": "10.0.0.1:5432"},
+		},
+		{
+			name: "dns error",
+			err:  &net.DNSError{Err: "no such host", Name: "db.internal", Server: "10.0.0.2:53", IsNotFound: true},
+			expected: map[string]any{
+				"name":         "db.internal",
+				"server":       "10.0.0.2:53",
+				"is_timeout":   false,
+				"is_not_found": true,
+			},
+		},
+		{
+			name:     "postgres lookalike from another package",
+			err:      &pgError{Code: "23505"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, CauseDetails(tc.err))
+		})
+	}
+}
+
+func TestStructDetails(t *testing.T) {
+	err := &pgError{
+		Code:           "23505",
+		Message:        "duplicate key value violates unique constraint",
+		Detail:         "Key (email)=(a@b.c) already exists.",
+		TableName:      "users",
+		ConstraintName: "users_email_key",
+		Line:           570,
+		unexported:     "ignored",
+	}
+
+	require.Equal(t, map[string]any{
+		"code":       "23505",
+		"message":    "duplicate key value violates unique constraint",
+		"detail":     "Key (email)=(a@b.c) already exists.",
+		"table":      "users",
+		"constraint": "users_email_key",
+		"line":       int64(570),
+	}, structDetails(reflect.ValueOf(err).Elem(), postgresFields))
+}
+
+func TestMarshalCauseDetails(t *testing.T) {
+	err := Wrap(&fs.PathError{Op: "open", Path: "/etc/app.yaml", Err: syscall.ENOENT}, "load config")
+
+	data, marshalErr := Marshal(err)
+	require.NoError(t, marshalErr)
+
+	decoded, unmarshalErr := Unmarshal(data)
+	require.NoError(t, unmarshalErr)
+
+	var remote *RemoteError
+
+	require.True(t, errors.As(decoded, &remote))
+	require.Equal(t, "*fs.PathError", remote.Type)
+	require.Equal(t, map[string]any{"op": "open", "path": "/etc/app.yaml"}, remote.Details)
+
+	var errno *RemoteError
+
+	require.True(t, errors.As(remote.Err, &errno))
+	require.Equal(t, "syscall.Errno", errno.Type)
+	require.Equal(t, float64(syscall.ENOENT), errno.Details["errno"])
+
+	require.Contains(t, ToYAML(err), "details:\n")
+}
@@ -0,0 +1,80 @@
+// Package cbor encodes ctxerrors chains as CBOR (RFC 8949), for systems
+// exchanging errors over CBOR-based protocols where JSON is too verbose.
+//
+// The encoded chain has the same structure and format version as the JSON
+// ctxerrors.Marshal writes, with the same guarantees about older versions, so
+// services can switch between the two without losing anything. It's its own
+// package so the JSON-only users don't carry it, and it needs nothing outside
+// the standard library.
+package cbor
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Marshal encodes err's chain as CBOR, with everything ctxerrors.Marshal
+// includes.
+func Marshal(err error) ([]byte, error) {
+	data, marshalErr := ctxerrors.Marshal(err)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+
+	if decodeErr := decoder.Decode(&value); decodeErr != nil {
+		return nil, ctxerrors.Wrap(decodeErr, "failed to decode error chain")
+	}
+
+	encoded, encodeErr := encode(nil, value)
+	if encodeErr != nil {
+		return nil, ctxerrors.Wrap(encodeErr, "failed to encode error chain as CBOR")
+	}
+
+	return encoded, nil
+}
+
+// Unmarshal rebuilds the chain Marshal encoded, like ctxerrors.Unmarshal does.
+func Unmarshal(data []byte) (error, error) { //nolint:revive
+	value, err := decode(data)
+	if err != nil {
+		return nil, ctxerrors.Wrap(err, "failed to decode CBOR error chain")
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, ctxerrors.Wrap(err, "failed to re-encode error chain")
+	}
+
+	return ctxerrors.Unmarshal(encoded)
+}
+
+// Error carries an error chain through CBOR libraries that look for
+// MarshalCBOR and UnmarshalCBOR methods, such as fxamacker/cbor, e.g. as a
+// struct field of a message.
+type Error struct {
+	Err error
+}
+
+// MarshalCBOR encodes e.Err like Marshal does.
+func (e Error) MarshalCBOR() ([]byte, error) {
+	return Marshal(e.Err)
+}
+
+// UnmarshalCBOR sets e.Err to the chain decoded from data like Unmarshal does.
+func (e *Error) UnmarshalCBOR(data []byte) error {
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	e.Err = decoded
+
+	return nil
+}
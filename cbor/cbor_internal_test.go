@@ -0,0 +1,206 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	root := ctxerrors.WithField(ctxerrors.New("connection refused"), "retries", 3)
+	original := ctxerrors.WithKind(
+		ctxerrors.Wrap(errors.Join(fmt.Errorf("dial db: %w", root), errors.New("plain")), "load user"), //nolint:err113
+		ctxerrors.KindUnavailable,
+	)
+
+	data, err := Marshal(original)
+	require.NoError(t, err)
+
+	jsonData, err := ctxerrors.Marshal(original)
+	require.NoError(t, err)
+	require.Less(t, len(data), len(jsonData))
+
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+
+	require.Equal(t, original.Error(), decoded.Error())
+	require.Equal(t, ctxerrors.KindUnavailable, ctxerrors.KindOf(decoded))
+
+	retries, ok := ctxerrors.FieldInt(decoded, "retries")
+	require.True(t, ok)
+	require.Equal(t, 3, retries)
+
+	// Deterministic encoding
+	again, err := Marshal(original)
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+}
+
+func TestError(t *testing.T) {
+	original := ctxerrors.New("boom")
+
+	data, err := Error{Err: original}.MarshalCBOR()
+	require.NoError(t, err)
+
+	var decoded Error
+
+	require.NoError(t, decoded.UnmarshalCBOR(data))
+	require.Equal(t, original.Error(), decoded.Err.Error())
+
+	require.Error(t, decoded.UnmarshalCBOR([]byte{0xff}))
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Run("unsupported version", func(t *testing.T) {
+		data, err := encode(nil, map[string]any{"v": json.Number("99")})
+		require.NoError(t, err)
+
+		_, err = Unmarshal(data)
+		require.ErrorIs(t, err, ctxerrors.ErrUnsupportedWireVersion)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := Unmarshal([]byte{0xa1, 0x61})
+		require.ErrorIs(t, err, errTruncated)
+	})
+}
+
+func TestEncode(t *testing.T) {
+	testCases := []struct {
+		value    any
+		expected string
+	}{
+		{json.Number("0"), "00"},
+		{json.Number("23"), "17"},
+		{json.Number("24"), "1818"},
+		{json.Number("1000"), "1903e8"},
+		{json.Number("1000000"), "1a000f4240"},
+		{json.Number("1000000000000"), "1b000000e8d4a51000"},
+		{json.Number("-1"), "20"},
+		{json.Number("-1000"), "3903e7"},
+		{json.Number("1.1"), "fb3ff199999999999a"},
+		{json.Number("1e300"), "fb7e37e43c8800759c"},
+		{nil, "f6"},
+		{true, "f5"},
+		{false, "f4"},
+		{"", "60"},
+		{"IETF", "6449455446"},
+		{"ü", "62c3bc"},
+		{[]any{}, "80"},
+		{[]any{json.Number("1"), []any{json.Number("2"), json.Number("3")}}, "8201820203"},
+		{map[string]any{}, "a0"},
+		{map[string]any{"b": json.Number("2"), "aa": json.Number("3"), "a": json.Number("1")}, "a3616101616202626161" + "03"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			data, err := encode(nil, tc.value)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, hex.EncodeToString(data))
+		})
+	}
+
+	_, err := encode(nil, struct{}{})
+	require.ErrorIs(t, err, errUnsupported)
+}
+
+func TestDecode(t *testing.T) {
+	// Examples from RFC 8949 Appendix A
+	testCases := []struct {
+		data     string
+		expected any
+	}{
+		{"00", int64(0)},
+		{"1819", int64(25)},
+		{"1bffffffffffffffff", uint64(math.MaxUint64)},
+		{"3863", int64(-100)},
+		{"3bffffffffffffffff", -18446744073709551616.0},
+		{"f90000", 0.0},
+		{"f93c00", 1.0},
+		{"f97bff", 65504.0},
+		{"f90001", 5.960464477539063e-8},
+		{"f9c400", -4.0},
+		{"f97c00", math.Inf(1)},
+		{"fa47c35000", 100000.0},
+		{"fb3ff199999999999a", 1.1},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"f7", nil},
+		{"c074323031332d30332d32315432303a30343a30305a", "2013-03-21T20:04:00Z"},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"6449455446", "IETF"},
+		{"83010203", []any{int64(1), int64(2), int64(3)}},
+		{"a26161016162820203", map[string]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9f018202039f0405ffff", []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}},
+		{"bf61610161629f0203ffff", map[string]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.data, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.data)
+			require.NoError(t, err)
+
+			value, err := decode(data)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, value)
+		})
+	}
+
+	t.Run("NaN", func(t *testing.T) {
+		value, err := decode([]byte{0xf9, 0x7e, 0x00})
+		require.NoError(t, err)
+		require.True(t, math.IsNaN(value.(float64))) //nolint:forcetypeassert
+	})
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected error
+	}{
+		{"empty", "", errTruncated},
+		{"truncated argument", "19", errTruncated},
+		{"truncated text", "6449", errTruncated},
+		{"huge array", "9bffffffffffffffff", errTruncated},
+		{"huge map", "bbffffffffffffffff", errTruncated},
+		{"reserved info", "1c", errMalformed},
+		{"invalid UTF-8", "62c328", errMalformed},
+		{"mixed chunks", "5f6161ff", errMalformed},
+		{"indefinite integer", "1f", errMalformed},
+		{"stray break", "ff", errUnsupported},
+		{"unassigned simple", "e0", errUnsupported},
+		{"integer map key", "a201020304", errUnsupported},
+		{"trailing", "0000", errTrailing},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.data)
+			require.NoError(t, err)
+
+			_, err = decode(data)
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+
+	t.Run("too deep", func(t *testing.T) {
+		data := make([]byte, maxDepth+1)
+		for i := range data {
+			data[i] = 0x81
+		}
+
+		_, err := decode(append(data, 0x00))
+		require.ErrorIs(t, err, errTooDeep)
+	})
+}
@@ -0,0 +1,519 @@
+package cbor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Major types.
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// Additional information values with a meaning of their own.
+const (
+	infoUint8      = 24
+	infoUint16     = 25
+	infoUint32     = 26
+	infoUint64     = 27
+	infoIndefinite = 31
+
+	simpleFalse     = 20
+	simpleTrue      = 21
+	simpleNull      = 22
+	simpleUndefined = 23
+	simpleHalf      = 25
+	simpleSingle    = 26
+	simpleDouble    = 27
+)
+
+// breakByte ends an indefinite-length item.
+const breakByte = 0xff
+
+// maxDepth is how deeply decode lets items nest.
+const maxDepth = 512
+
+var (
+	errTruncated   = errors.New("truncated data")
+	errTrailing    = errors.New("trailing data")
+	errMalformed   = errors.New("malformed data")
+	errTooDeep     = errors.New("nesting too deep")
+	errUnsupported = errors.New("unsupported value")
+)
+
+// encode appends the CBOR encoding of a value decoded from JSON with
+// UseNumber to buf. Map keys are sorted like RFC 8949 deterministic encoding
+// wants them so the same chain always encodes the same.
+func encode(buf []byte, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, majorSimple<<5|simpleNull), nil
+	case bool:
+		if v {
+			return append(buf, majorSimple<<5|simpleTrue), nil
+		}
+
+		return append(buf, majorSimple<<5|simpleFalse), nil
+	case json.Number:
+		return encodeNumber(buf, v)
+	case string:
+		buf = appendHead(buf, majorText, uint64(len(v)))
+
+		return append(buf, v...), nil
+	case []any:
+		buf = appendHead(buf, majorArray, uint64(len(v)))
+
+		for _, item := range v {
+			var err error
+			if buf, err = encode(buf, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return buf, nil
+	case map[string]any:
+		return encodeMap(buf, v)
+	default:
+		return nil, ctxerrors.Wrapf(errUnsupported, "%T", value)
+	}
+}
+
+// encodeNumber appends n as an integer if it is one that fits, or a double.
+func encodeNumber(buf []byte, n json.Number) ([]byte, error) {
+	if i, err := n.Int64(); err == nil {
+		if i >= 0 {
+			return appendHead(buf, majorUint, uint64(i)), nil
+		}
+
+		return appendHead(buf, majorNegInt, uint64(-1-i)), nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return nil, ctxerrors.Wrapf(errUnsupported, "number %s", n)
+	}
+
+	buf = append(buf, majorSimple<<5|simpleDouble)
+
+	return binary.BigEndian.AppendUint64(buf, math.Float64bits(f)), nil
+}
+
+// encodeMap appends m with its keys sorted shortest first, then bytewise.
+func encodeMap(buf []byte, m map[string]any) ([]byte, error) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(a) - len(b)
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	buf = appendHead(buf, majorMap, uint64(len(m)))
+
+	for _, key := range keys {
+		buf = appendHead(buf, majorText, uint64(len(key)))
+		buf = append(buf, key...)
+
+		var err error
+		if buf, err = encode(buf, m[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+// appendHead appends the initial byte and argument of an item in its
+// shortest form.
+func appendHead(buf []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < infoUint8:
+		return append(buf, major<<5|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(buf, major<<5|infoUint8, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major<<5|infoUint16), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major<<5|infoUint32), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major<<5|infoUint64), arg)
+	}
+}
+
+// decoder reads one CBOR data item into the values encoding/json works with:
+// nil, bool, uint64, int64, float64, string, []byte, []any and map[string]any.
+// Tags are skipped and undefined decodes to nil.
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// decode decodes data, which must hold exactly one item.
+func decode(data []byte) (any, error) {
+	d := &decoder{data: data}
+
+	value, err := d.item()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.pos != len(d.data) {
+		return nil, errTrailing
+	}
+
+	return value, nil
+}
+
+// item decodes the item at the current position.
+func (d *decoder) item() (any, error) {
+	if d.depth >= maxDepth {
+		return nil, errTooDeep
+	}
+
+	d.depth++
+	defer func() { d.depth-- }()
+
+	initial, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	major, info := initial>>5, initial&0x1f
+
+	if major == majorSimple {
+		return d.simple(info)
+	}
+
+	if info == infoIndefinite {
+		return d.indefinite(major)
+	}
+
+	arg, err := d.arg(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		if arg <= math.MaxInt64 {
+			return int64(arg), nil
+		}
+
+		return arg, nil
+	case majorNegInt:
+		if arg <= math.MaxInt64 {
+			return -1 - int64(arg), nil
+		}
+
+		return -1 - float64(arg), nil
+	case majorBytes:
+		return d.bytes(arg)
+	case majorText:
+		return d.text(arg)
+	case majorArray:
+		return d.array(arg)
+	case majorMap:
+		return d.object(arg)
+	default: // majorTag
+		return d.item()
+	}
+}
+
+// simple decodes a major type 7 item.
+func (d *decoder) simple(info byte) (any, error) {
+	switch info {
+	case simpleFalse:
+		return false, nil
+	case simpleTrue:
+		return true, nil
+	case simpleNull, simpleUndefined:
+		return nil, nil //nolint:nilnil
+	case simpleHalf:
+		bits, err := d.read(2) //nolint:mnd
+		if err != nil {
+			return nil, err
+		}
+
+		return halfToFloat(binary.BigEndian.Uint16(bits)), nil
+	case simpleSingle:
+		bits, err := d.read(4) //nolint:mnd
+		if err != nil {
+			return nil, err
+		}
+
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(bits))), nil
+	case simpleDouble:
+		bits, err := d.read(8) //nolint:mnd
+		if err != nil {
+			return nil, err
+		}
+
+		return math.Float64frombits(binary.BigEndian.Uint64(bits)), nil
+	default:
+		return nil, ctxerrors.Wrapf(errUnsupported, "simple value %d", info)
+	}
+}
+
+// indefinite decodes an indefinite-length item of major type major.
+func (d *decoder) indefinite(major byte) (any, error) {
+	switch major {
+	case majorBytes, majorText:
+		var chunks []byte
+
+		for !d.atBreak() {
+			initial, err := d.byte()
+			if err != nil {
+				return nil, err
+			}
+
+			// Chunks must be definite-length strings of the same type
+			if initial>>5 != major || initial&0x1f == infoIndefinite {
+				return nil, errMalformed
+			}
+
+			n, err := d.arg(initial & 0x1f)
+			if err != nil {
+				return nil, err
+			}
+
+			chunk, err := d.read(n)
+			if err != nil {
+				return nil, err
+			}
+
+			chunks = append(chunks, chunk...)
+		}
+
+		if major == majorBytes {
+			return chunks, nil
+		}
+
+		if !utf8.Valid(chunks) {
+			return nil, errMalformed
+		}
+
+		return string(chunks), nil
+	case majorArray:
+		array := []any{}
+
+		for !d.atBreak() {
+			value, err := d.item()
+			if err != nil {
+				return nil, err
+			}
+
+			array = append(array, value)
+		}
+
+		return array, nil
+	case majorMap:
+		object := map[string]any{}
+
+		for !d.atBreak() {
+			if err := d.entry(object); err != nil {
+				return nil, err
+			}
+		}
+
+		return object, nil
+	default:
+		return nil, errMalformed
+	}
+}
+
+// atBreak consumes the break ending an indefinite-length item if it's next.
+func (d *decoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == breakByte {
+		d.pos++
+
+		return true
+	}
+
+	return false
+}
+
+// bytes decodes a byte string of n bytes.
+func (d *decoder) bytes(n uint64) (any, error) {
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Clone(data), nil
+}
+
+// text decodes a text string of n bytes.
+func (d *decoder) text(n uint64) (any, error) {
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utf8.Valid(data) {
+		return nil, errMalformed
+	}
+
+	return string(data), nil
+}
+
+// array decodes an array of n items.
+func (d *decoder) array(n uint64) (any, error) {
+	// Every item takes at least a byte, so don't trust n beyond that
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errTruncated
+	}
+
+	array := make([]any, 0, n)
+
+	for range n {
+		value, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+
+		array = append(array, value)
+	}
+
+	return array, nil
+}
+
+// object decodes a map of n entries.
+func (d *decoder) object(n uint64) (any, error) {
+	if n > uint64(len(d.data)-d.pos)/2 {
+		return nil, errTruncated
+	}
+
+	object := make(map[string]any, n)
+
+	for range n {
+		if err := d.entry(object); err != nil {
+			return nil, err
+		}
+	}
+
+	return object, nil
+}
+
+// entry decodes a map entry into object. Keys must be text strings.
+func (d *decoder) entry(object map[string]any) error {
+	key, err := d.item()
+	if err != nil {
+		return err
+	}
+
+	text, ok := key.(string)
+	if !ok {
+		return ctxerrors.Wrapf(errUnsupported, "%T map key", key)
+	}
+
+	value, err := d.item()
+	if err != nil {
+		return err
+	}
+
+	object[text] = value
+
+	return nil
+}
+
+// arg reads the argument encoded by the additional information info.
+func (d *decoder) arg(info byte) (uint64, error) {
+	var size uint64
+
+	switch {
+	case info < infoUint8:
+		return uint64(info), nil
+	case info == infoUint8:
+		size = 1
+	case info == infoUint16:
+		size = 2
+	case info == infoUint32:
+		size = 4
+	case info == infoUint64:
+		size = 8
+	default:
+		return 0, errMalformed
+	}
+
+	data, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+
+	var arg uint64
+	for _, b := range data {
+		arg = arg<<8 | uint64(b)
+	}
+
+	return arg, nil
+}
+
+// byte reads a single byte.
+func (d *decoder) byte() (byte, error) {
+	data, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+
+	return data[0], nil
+}
+
+// read reads the next n bytes.
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errTruncated
+	}
+
+	data := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return data, nil
+}
+
+// halfToFloat converts an IEEE 754 half-precision float as RFC 8949 Appendix D
+// does.
+func halfToFloat(half uint16) float64 {
+	exponent := int(half>>10) & 0x1f
+	mantissa := float64(half & 0x3ff)
+
+	var value float64
+
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+
+	if half&0x8000 != 0 {
+		return -value
+	}
+
+	return value
+}
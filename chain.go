@@ -0,0 +1,127 @@
+package ctxerrors
+
+import "errors"
+
+// Messages returns the message of every layer in err's chain, outermost first,
+// without locations and without repeating the text of the layers below, e.g.
+// for an API "error trail". Empty messages are skipped. Errors joined with
+// errors.Join, hashicorp/go-multierror or uber-go/multierr contribute their
+// members' messages in order. For a foreign wrapper such as
+// fmt.Errorf("...: %w", err) the wrapped error's text is trimmed off its own
+// when it's a ": "-separated suffix. A cause Rewrite rewrote contributes its
+// rewritten text and nothing below it.
+func Messages(err error) []string {
+	var messages []string
+
+	for err != nil {
+		if layer, ok := asCTXError(err); ok {
+			if message := layer.msg(); message != "" {
+				messages = append(messages, message)
+			}
+
+			err = layer.err
+
+			continue
+		}
+
+		if joined, ok := members(err); ok {
+			for _, member := range joined {
+				messages = append(messages, Messages(member)...)
+			}
+
+			return messages
+		}
+
+		if rewritten, ok := err.(*rewrittenError); ok { //nolint:errorlint
+			return append(messages, rewritten.message)
+		}
+
+		if message := ownMessage(err); message != "" {
+			messages = append(messages, message)
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return messages
+}
+
+// FirstContext returns the outermost *CTXError in err's chain, where context
+// was last added to it, following Unwrap() error, or nil if there's none.
+func FirstContext(err error) *CTXError {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if layer, ok := asCTXError(err); ok {
+			return layer
+		}
+	}
+
+	return nil
+}
+
+// LastContext returns the innermost *CTXError in err's chain, usually where
+// the error originated, following Unwrap() error, or nil if there's none.
+func LastContext(err error) *CTXError {
+	var last *CTXError
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if layer, ok := asCTXError(err); ok {
+			last = layer
+		}
+	}
+
+	return last
+}
+
+// FromMultiError converts a hashicorp/go-multierror *multierror.Error, or
+// anything else with a WrappedErrors() []error method, to the errors.Join
+// equivalent the rest of the package and the standard library understand. Any
+// other error is returned as is.
+func FromMultiError(err error) error {
+	multi, ok := err.(interface{ WrappedErrors() []error }) //nolint:errorlint
+	if !ok {
+		return err
+	}
+
+	return errors.Join(multi.WrappedErrors()...)
+}
+
+// walk calls visit on err and everything below it, depth first, following
+// Unwrap() error and the members of joined errors.
+func walk(err error, visit func(err error)) {
+	for err != nil {
+		visit(err)
+
+		if joined, ok := members(err); ok {
+			for _, member := range joined {
+				walk(member, visit)
+			}
+
+			return
+		}
+
+		wrapper, ok := err.(interface{ Unwrap() error }) //nolint:errorlint
+		if !ok {
+			return
+		}
+
+		err = wrapper.Unwrap()
+	}
+}
+
+// members returns the errors err joins together, for errors.Join style
+// Unwrap() []error, for the WrappedErrors() []error of hashicorp/go-multierror,
+// whose Unwrap() error only exposes them one at a time through an internal
+// chain type, and for the Errors() []error of uber-go/multierr versions that
+// predate Unwrap() []error.
+func members(err error) ([]error, bool) {
+	switch multi := err.(type) { //nolint:errorlint
+	case interface{ WrappedErrors() []error }:
+		return multi.WrappedErrors(), true
+	case interface{ Unwrap() []error }:
+		return multi.Unwrap(), true
+	case interface{ Errors() []error }:
+		return multi.Errors(), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,211 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessages(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected []string
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "plain error",
+			err:      baseErr,
+			expected: []string{"base error"},
+		},
+		{
+			name:     "new error",
+			err:      New("config file missing"),
+			expected: []string{"config file missing"},
+		},
+		{
+			name:     "chain",
+			err:      Wrap(Wrapf(New("config file missing"), "failed to read %s", "config"), "init failed"),
+			expected: []string{"init failed", "failed to read config", "config file missing"},
+		},
+		{
+			name:     "wrapped plain error",
+			err:      Wrap(baseErr, "context"),
+			expected: []string{"context", "base error"},
+		},
+		{
+			name:     "empty messages are skipped",
+			err:      Wrap(Wrap(baseErr, ""), "outer"),
+			expected: []string{"outer", "base error"},
+		},
+		{
+			name:     "foreign wrapper",
+			err:      Wrap(fmt.Errorf("foreign layer: %w", New("inner")), "outer"),
+			expected: []string{"outer", "foreign layer", "inner"},
+		},
+		{
+			name:     "joined errors",
+			err:      Wrap(errors.Join(New("first"), Wrap(baseErr, "second")), "batch"),
+			expected: []string{"batch", "first", "second", "base error"},
+		},
+		{
+			name:     "multierror",
+			err:      Wrap(&multiError{errs: []error{New("first"), Wrap(baseErr, "second")}}, "batch"),
+			expected: []string{"batch", "first", "second", "base error"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Messages(tc.err))
+		})
+	}
+}
+
+// multiError mimics hashicorp/go-multierror's *multierror.Error, which exposes
+// its members through WrappedErrors() rather than Unwrap() []error.
+type multiError struct {
+	errs []error
+}
+
+func (e *multiError) Error() string {
+	return fmt.Sprintf("%d errors occurred", len(e.errs))
+}
+
+func (e *multiError) WrappedErrors() []error {
+	return e.errs
+}
+
+func (e *multiError) Unwrap() error {
+	if len(e.errs) == 0 {
+		return nil
+	}
+
+	return e.errs[0]
+}
+
+func TestFirstAndLastContext(t *testing.T) {
+	origin := New("connection refused")
+	outer := Wrap(Wrap(fmt.Errorf("dial: %w", origin), "query failed"), "load user")
+	batch := Wrap(errors.Join(origin), "batch")
+
+	testCases := []struct {
+		name          string
+		err           error
+		expectedFirst error
+		expectedLast  error
+	}{
+		{name: "nil error", err: nil, expectedFirst: nil, expectedLast: nil},
+		{name: "foreign error", err: io.EOF, expectedFirst: nil, expectedLast: nil},
+		{name: "single layer", err: origin, expectedFirst: origin, expectedLast: origin},
+		{name: "chain", err: outer, expectedFirst: outer, expectedLast: origin},
+		{name: "foreign outermost", err: fmt.Errorf("handler: %w", outer), expectedFirst: outer, expectedLast: origin},
+		{name: "joined errors aren't followed", err: batch, expectedFirst: batch, expectedLast: batch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requireSameLayer(t, tc.expectedFirst, FirstContext(tc.err))
+			requireSameLayer(t, tc.expectedLast, LastContext(tc.err))
+		})
+	}
+}
+
+// requireSameLayer asserts layer is expected, or nil if expected is.
+func requireSameLayer(t *testing.T, expected error, layer *CTXError) {
+	t.Helper()
+
+	if expected == nil {
+		require.Nil(t, layer)
+
+		return
+	}
+
+	require.Same(t, expected, layer)
+}
+
+func TestFromMultiError(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	t.Run("not a multierror", func(t *testing.T) {
+		require.Equal(t, baseErr, FromMultiError(baseErr))
+		require.NoError(t, FromMultiError(nil))
+	})
+
+	t.Run("empty multierror", func(t *testing.T) {
+		require.NoError(t, FromMultiError(&multiError{}))
+	})
+
+	t.Run("converts members", func(t *testing.T) {
+		second := Wrap(baseErr, "second")
+		actual := FromMultiError(&multiError{errs: []error{New("first"), second}})
+
+		joined, ok := actual.(interface{ Unwrap() []error }) //nolint:errorlint
+
+		require.True(t, ok)
+		require.Len(t, joined.Unwrap(), 2)
+		require.ErrorIs(t, actual, baseErr)
+		require.Equal(t, []string{"first", "second", "base error"}, Messages(actual))
+	})
+}
+
+func TestWalk(t *testing.T) {
+	first := New("first")
+	second := Wrap(errors.New("base error"), "second") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected []string
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "linear chain",
+			err:      Wrap(second, "outer"),
+			expected: []string{"outer", "second", "base error"},
+		},
+		{
+			name:     "joined errors",
+			err:      errors.Join(first, second),
+			expected: []string{"first", "second", "base error"},
+		},
+		{
+			name:     "multierror",
+			err:      &multiError{errs: []error{first, second}},
+			expected: []string{"first", "second", "base error"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+
+			walk(tc.err, func(current error) {
+				if layer, ok := asCTXError(current); ok {
+					actual = append(actual, layer.Message())
+
+					return
+				}
+
+				if _, ok := members(current); !ok {
+					actual = append(actual, current.Error())
+				}
+			})
+
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
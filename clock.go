@@ -0,0 +1,31 @@
+package ctxerrors
+
+import "time"
+
+// Clock returns the current time.
+type Clock func() time.Time
+
+// SetClock sets where timestamps in error metadata come from, such as the time
+// of ErrorEvents and of the events the reporting adapters build, so tests can
+// freeze time and simulation testing can control it. Pass nil to go back to
+// time.Now, which is the default.
+func SetClock(clock Clock) {
+	updateConfig(func(c *config) {
+		c.clock = clock
+	})
+}
+
+// Now returns the current time according to the SetClock clock, for adapters
+// stamping errors with it.
+func Now() time.Time {
+	return currentConfig().now()
+}
+
+// now returns the current time according to the clock of these settings.
+func (c *config) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+
+	return time.Now()
+}
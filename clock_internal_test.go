@@ -0,0 +1,27 @@
+package ctxerrors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetClock(t *testing.T) {
+	t.Cleanup(func() { SetClock(nil) })
+
+	frozen := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return frozen })
+
+	require.Equal(t, frozen, Now())
+
+	events, cancel := Subscribe()
+	t.Cleanup(cancel)
+
+	_ = New("frozen")
+	require.Equal(t, frozen, (<-events).Time)
+
+	SetClock(nil)
+
+	require.WithinDuration(t, time.Now(), Now(), time.Minute)
+}
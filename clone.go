@@ -0,0 +1,128 @@
+package ctxerrors
+
+import "maps"
+
+// Clone returns a deep copy of the CTXError chain in err so the copy can be
+// annotated without touching the original. Every *CTXError layer is copied,
+// fields and frame notes included; the first error in the chain that isn't a
+// *CTXError is shared with the original since there's no generic way to copy
+// it.
+func Clone(err error) error {
+	return copyChain(err, nil)
+}
+
+// Rewrite returns a copy of the CTXError chain in err with each layer's message
+// passed through rewrite, e.g. to mask tokens before the error leaves a trust
+// boundary. The chain is copied the same way Clone does it, and the text of the
+// first non-*CTXError error in the chain is passed through rewrite too: that
+// error is wrapped in one whose Error() returns the rewritten text and which
+// unwraps to it, so errors.Is and errors.As still find it. Messages and
+// Marshal stop at that error and report only its rewritten text.
+func Rewrite(err error, rewrite func(message string) string) error {
+	if rewrite == nil {
+		return Clone(err)
+	}
+
+	return rewriteChain(err, rewrite)
+}
+
+// rewriteChain copies every *CTXError layer at the top of err's chain the way
+// copyChain does with its message passed through rewrite, and has the
+// innermost one wrap a rewrittenError around what it wrapped. The chains a
+// layer keeps aside, the one Compact made it from and the one Barrier hides,
+// are rewritten too so Uncompacted and UnwrapBarrier don't hand back the
+// original text.
+func rewriteChain(err error, rewrite func(message string) string) error {
+	layer, ok := asCTXError(err)
+	if !ok {
+		if err == nil {
+			return nil
+		}
+
+		return &rewrittenError{err: err, message: rewrite(err.Error())}
+	}
+
+	clone := *layer
+	clone.err = rewriteChain(layer.err, rewrite)
+	clone.fields = maps.Clone(layer.fields)
+	clone.notes = maps.Clone(layer.notes)
+	clone.message = rewrite(layer.msg())
+	clone.lazy = nil
+	clone.compacted = rewriteChain(layer.compacted, rewrite)
+	clone.barrier = rewriteChain(layer.barrier, rewrite)
+
+	return &clone
+}
+
+// rewrittenError is a cause whose text Rewrite passed through its rewrite
+// function.
+type rewrittenError struct {
+	err     error
+	message string
+}
+
+// Error returns the rewritten text of the cause.
+func (e *rewrittenError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the original cause.
+func (e *rewrittenError) Unwrap() error {
+	return e.err
+}
+
+// WithCause returns a copy of the CTXError chain in err whose innermost layer
+// wraps newCause instead of what it wrapped, keeping every layer's message,
+// location and fields, e.g. to translate a driver error into a documented
+// public sentinel without losing the annotations added on the way up:
+//
+//	if errors.Is(err, sql.ErrNoRows) {
+//		return ctxerrors.WithCause(err, ErrUserNotFound)
+//	}
+//
+// Only the *CTXError layers at the top of the chain are copied, the first
+// error that isn't one is what gets replaced, along with everything below it.
+// If err isn't a *CTXError newCause is returned. It returns nil if err is nil.
+func WithCause(err, newCause error) error {
+	if isNilError(err) {
+		return nil
+	}
+
+	return replaceCause(err, newCause)
+}
+
+// replaceCause copies every *CTXError layer at the top of err's chain the way
+// copyChain does and has the innermost one wrap newCause.
+func replaceCause(err, newCause error) error {
+	layer, ok := asCTXError(err)
+	if !ok {
+		return newCause
+	}
+
+	clone := *layer
+	clone.err = replaceCause(layer.err, newCause)
+	clone.fields = maps.Clone(layer.fields)
+	clone.notes = maps.Clone(layer.notes)
+
+	return &clone
+}
+
+// copyChain copies every *CTXError layer at the top of err's chain, calling
+// modify (if not nil) on each copy.
+func copyChain(err error, modify func(layer *CTXError)) error {
+	layer, ok := asCTXError(err)
+	if !ok {
+		return err
+	}
+
+	clone := *layer
+	clone.err = copyChain(layer.err, modify)
+	clone.fields = maps.Clone(layer.fields)
+	clone.notes = maps.Clone(layer.notes)
+
+	if modify != nil {
+		modify(&clone)
+	}
+
+	return &clone
+}
@@ -0,0 +1,277 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name   string
+		err    error
+		layers int
+	}{
+		{
+			name:   "nil error",
+			err:    nil,
+			layers: 0,
+		},
+		{
+			name:   "plain error",
+			err:    baseErr,
+			layers: 0,
+		},
+		{
+			name:   "single layer",
+			err:    New("something went wrong"),
+			layers: 1,
+		},
+		{
+			name:   "multiple layers",
+			err:    Wrap(Wrap(baseErr, "first wrap"), "second wrap"),
+			layers: 2,
+		},
+		{
+			name:   "stops at foreign wrapper",
+			err:    Wrap(fmt.Errorf("foreign: %w", New("inner")), "outer"),
+			layers: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := Clone(tc.err)
+
+			if tc.layers == 0 {
+				require.Equal(t, tc.err, actual)
+
+				return
+			}
+
+			require.Equal(t, tc.err.Error(), actual.Error())
+
+			original, cloned := tc.err, actual
+			for range tc.layers {
+				originalLayer, ok := asCTXError(original)
+				require.True(t, ok)
+
+				clonedLayer, ok := asCTXError(cloned)
+				require.True(t, ok)
+
+				require.NotSame(t, originalLayer, clonedLayer)
+				require.Equal(t, originalLayer.message, clonedLayer.message)
+				require.Equal(t, originalLayer.file, clonedLayer.file)
+				require.Equal(t, originalLayer.line, clonedLayer.line)
+				require.Equal(t, originalLayer.funcName, clonedLayer.funcName)
+
+				original, cloned = originalLayer.err, clonedLayer.err
+			}
+
+			// Whatever is below the copied layers is shared
+			require.Equal(t, original, cloned)
+		})
+	}
+
+	t.Run("mutating the clone leaves the original alone", func(t *testing.T) {
+		original := Wrap(New("inner"), "outer")
+		clone := Clone(original)
+
+		var cloneErr *CTXError
+
+		require.True(t, errors.As(clone, &cloneErr))
+		cloneErr.message = "changed"
+
+		require.Contains(t, original.Error(), "outer")
+		require.NotContains(t, original.Error(), "changed")
+	})
+
+	t.Run("keeps errors.Is working", func(t *testing.T) {
+		clone := Clone(Wrap(baseErr, "wrapped"))
+		require.ErrorIs(t, clone, baseErr)
+	})
+}
+
+func TestRewrite(t *testing.T) {
+	baseErr := errors.New("base error token=abc123") //nolint:err113
+	tokenRegexp := regexp.MustCompile(`token=\S+`)
+	mask := func(message string) string {
+		return tokenRegexp.ReplaceAllString(message, "token=***")
+	}
+
+	testCases := []struct {
+		name     string
+		err      error
+		rewrite  func(string) string
+		expected []string
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			rewrite:  mask,
+			expected: nil,
+		},
+		{
+			name:     "plain error is rewritten",
+			err:      baseErr,
+			rewrite:  mask,
+			expected: []string{"base error token=***"},
+		},
+		{
+			name:     "cause of a chain is rewritten",
+			err:      Wrap(fmt.Errorf("driver: %w", baseErr), "query failed"),
+			rewrite:  mask,
+			expected: []string{"query failed", "driver: base error token=***"},
+		},
+		{
+			name:     "every layer is rewritten",
+			err:      Wrapf(Wrap(New("login token=s3cr3t"), "auth failed token=x"), "request %d", 1),
+			rewrite:  mask,
+			expected: []string{"request 1", "auth failed token=***", "login token=***"},
+		},
+		{
+			name:     "nil rewrite clones",
+			err:      Wrap(baseErr, "wrapped"),
+			rewrite:  nil,
+			expected: []string{"wrapped", "base error token=abc123"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := Rewrite(tc.err, tc.rewrite)
+
+			if tc.err == nil {
+				require.NoError(t, actual)
+
+				return
+			}
+
+			actualStr := actual.Error()
+			for _, expected := range tc.expected {
+				require.Contains(t, actualStr, expected)
+			}
+
+			if tc.rewrite != nil {
+				require.NotContains(t, actualStr, "abc123")
+				require.NotContains(t, actualStr, "s3cr3t")
+			}
+		})
+	}
+
+	t.Run("original is untouched", func(t *testing.T) {
+		original := Wrap(baseErr, "token=abc123")
+		rewritten := Rewrite(original, mask)
+
+		require.Contains(t, original.Error(), "token=abc123: ")
+		require.Contains(t, rewritten.Error(), "token=***: ")
+		require.ErrorIs(t, rewritten, baseErr)
+		require.NotContains(t, rewritten.Error(), "abc123")
+	})
+
+	t.Run("compacted and barrier chains are rewritten", func(t *testing.T) {
+		compacted := Rewrite(Compact(Wrap(Wrap(baseErr, "token=SECRET"), "outer")), mask)
+
+		require.NotContains(t, compacted.Error(), "SECRET")
+		require.NotContains(t, Uncompacted(compacted).Error(), "SECRET")
+		require.NotContains(t, Uncompacted(compacted).Error(), "abc123")
+
+		barrier := Rewrite(Barrier(Wrap(baseErr, "token=SECRET"), "failed"), mask)
+
+		require.NotContains(t, UnwrapBarrier(barrier).Error(), "SECRET")
+		require.NotContains(t, UnwrapBarrier(barrier).Error(), "abc123")
+	})
+
+	t.Run("cause is still found by errors.As", func(t *testing.T) {
+		pathErr := &os.PathError{Op: "open", Path: "/tmp/token=abc123", Err: os.ErrNotExist}
+		rewritten := Rewrite(Wrap(pathErr, "load"), mask)
+
+		var target *os.PathError
+		require.ErrorAs(t, rewritten, &target)
+		require.Same(t, pathErr, target)
+		require.ErrorIs(t, rewritten, os.ErrNotExist)
+		require.Contains(t, rewritten.Error(), "load: open /tmp/token=*** file does not exist")
+		require.Equal(t, []string{"load", "open /tmp/token=*** file does not exist"}, Messages(rewritten))
+
+		data, err := Marshal(rewritten)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "abc123")
+	})
+}
+
+func TestCloneCopiesFields(t *testing.T) {
+	original := &CTXError{
+		message: "with fields",
+		fields:  map[string]any{"user_id": 42},
+	}
+
+	clone, ok := asCTXError(Clone(original))
+	require.True(t, ok)
+	require.Equal(t, original.Fields(), clone.Fields())
+
+	clone.fields["user_id"] = 7
+
+	require.Equal(t, 42, original.fields["user_id"])
+}
+
+func TestWithCause(t *testing.T) {
+	driverErr := errors.New("sql: no rows in result set") //nolint:err113
+	errNotFound := errors.New("user not found")           //nolint:err113
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WithCause(nil, errNotFound))
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		require.Same(t, errNotFound, WithCause(driverErr, errNotFound))
+	})
+
+	t.Run("replaces the cause", func(t *testing.T) {
+		original := WithField(Wrap(Wrap(driverErr, "query users"), "load user"), "user_id", 42)
+		actual := WithCause(original, errNotFound)
+
+		require.ErrorIs(t, actual, errNotFound)
+		require.NotErrorIs(t, actual, driverErr)
+		require.Equal(t, []string{"load user", "query users", "user not found"}, Messages(actual))
+		require.Equal(t, 42, Fields(actual)["user_id"])
+		require.Equal(t, FirstContext(original).Line(), FirstContext(actual).Line())
+		require.Equal(t, LastContext(original).Line(), LastContext(actual).Line())
+
+		// The original is left alone
+		require.ErrorIs(t, original, driverErr)
+		require.NotErrorIs(t, original, errNotFound)
+	})
+
+	t.Run("annotating the copy leaves the original alone", func(t *testing.T) {
+		original := AnnotateFrame(WithField(Wrap(driverErr, "load user"), "user_id", 42), 0, "original note")
+
+		actual := WithCause(original, errNotFound)
+		actual = WithField(actual, "user_id", 7)
+		actual = AnnotateFrame(actual, 0, "copy note")
+
+		require.Equal(t, 7, Fields(actual)["user_id"])
+		require.Equal(t, "copy note", FirstContext(actual).FrameNote(0))
+
+		require.Equal(t, map[string]any{"user_id": 42}, Fields(original))
+		require.Equal(t, "original note", FirstContext(original).FrameNote(0))
+	})
+
+	t.Run("foreign wrapper goes too", func(t *testing.T) {
+		actual := WithCause(Wrap(fmt.Errorf("scan: %w", driverErr), "load user"), errNotFound)
+
+		require.Equal(t, []string{"load user", "user not found"}, Messages(actual))
+	})
+
+	t.Run("new error gets a cause", func(t *testing.T) {
+		actual := WithCause(New("lookup failed"), errNotFound)
+
+		require.ErrorIs(t, actual, errNotFound)
+		require.Equal(t, []string{"lookup failed", "user not found"}, Messages(actual))
+	})
+}
@@ -0,0 +1,170 @@
+// Command ctxerrors-gen generates package-local error helpers that record a
+// component name and a default kind on every error they create, so every
+// package of a large codebase wraps the same way without configuring each
+// call:
+//
+//	//go:generate go run github.com/psyb0t/ctxerrors/cmd/ctxerrors-gen -component billing -kind internal
+//
+// The generated file, ctxerrors_gen.go unless -o says otherwise, declares
+// newError, newErrorf, wrapError and wrapErrorf. They work like ctxerrors.New,
+// Newf, Wrap and Wrapf and record the location of their caller, or -skip
+// frames above it for helpers calling them in turn. The component goes in the
+// ctxerrors.FieldComponent field and the kind is set unless the chain already
+// has one. The package defaults to $GOPACKAGE, which go generate sets, and
+// the component to the package.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"text/template"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// ErrInvalidFlag is returned for flag values that can't make a valid file.
+var ErrInvalidFlag = errors.New("invalid flag")
+
+// defaultOutput is the file written to unless -o says otherwise.
+const defaultOutput = "ctxerrors_gen.go"
+
+func main() {
+	if err := run(os.Args[1:], os.Getenv("GOPACKAGE"), os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run is main without the process plumbing. goPackage is $GOPACKAGE.
+func run(args []string, goPackage string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("ctxerrors-gen", flag.ContinueOnError)
+	pkg := flags.String("package", goPackage, "package of the generated file")
+	component := flags.String("component", "", "component recorded on every error, the package if empty")
+	kind := flags.String("kind", "", "kind set on errors whose chain has none, empty for none")
+	skip := flags.Int("skip", 0, "extra frames to skip when recording the location")
+	output := flags.String("o", defaultOutput, "file to write to, - for stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return ctxerrors.Wrap(err, "failed to parse flags")
+	}
+
+	if *component == "" {
+		*component = *pkg
+	}
+
+	source, err := generate(helperOptions{
+		Package:   *pkg,
+		Component: *component,
+		Kind:      ctxerrors.Kind(*kind),
+		Skip:      *skip,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		if _, err := stdout.Write(source); err != nil {
+			return ctxerrors.Wrap(err, "failed to write helpers")
+		}
+
+		return nil
+	}
+
+	if err := os.WriteFile(*output, source, 0o644); err != nil { //nolint:gosec
+		return ctxerrors.Wrap(err, "failed to write helpers")
+	}
+
+	return nil
+}
+
+// helperOptions is what the generated helpers are configured with.
+type helperOptions struct {
+	Package   string
+	Component string
+	Kind      ctxerrors.Kind
+	Skip      int
+}
+
+// Frames is how many frames the helpers skip to record their caller's
+// location.
+func (o helperOptions) Frames() int {
+	return o.Skip + 1
+}
+
+// generate returns the gofmt-ed source of the helpers for opts.
+func generate(opts helperOptions) ([]byte, error) {
+	if !token.IsIdentifier(opts.Package) {
+		return nil, ctxerrors.Wrapf(ErrInvalidFlag, "package %q isn't an identifier, run under go generate or set -package", opts.Package)
+	}
+
+	if opts.Skip < 0 {
+		return nil, ctxerrors.Wrapf(ErrInvalidFlag, "skip %d is negative", opts.Skip)
+	}
+
+	var buf bytes.Buffer
+
+	if err := helpersTemplate.Execute(&buf, opts); err != nil {
+		return nil, ctxerrors.Wrap(err, "failed to render helpers")
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, ctxerrors.Wrap(err, "failed to format helpers")
+	}
+
+	return source, nil
+}
+
+// helpersTemplate renders the generated file.
+var helpersTemplate = template.Must(template.New("helpers").Parse(`// Code generated by ctxerrors-gen; DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/psyb0t/ctxerrors"
+
+// errorComponent is recorded on every error the helpers below create.
+const errorComponent = {{printf "%q" .Component}}
+
+// newError is ctxerrors.New recording errorComponent{{if .Kind}} and a default kind{{end}}.
+func newError(message string) error {
+	return annotateError(ctxerrors.NewSkip({{.Frames}}, message))
+}
+
+// newErrorf is ctxerrors.Newf recording errorComponent{{if .Kind}} and a default kind{{end}}.
+func newErrorf(format string, args ...any) error {
+	return annotateError(ctxerrors.NewfSkip({{.Frames}}, format, args...))
+}
+
+// wrapError is ctxerrors.Wrap recording errorComponent{{if .Kind}} and a default kind{{end}}.
+func wrapError(err error, message string) error {
+	return annotateError(ctxerrors.WrapSkip(err, {{.Frames}}, message))
+}
+
+// wrapErrorf is ctxerrors.Wrapf recording errorComponent{{if .Kind}} and a default kind{{end}}.
+func wrapErrorf(err error, format string, args ...any) error {
+	return annotateError(ctxerrors.WrapfSkip(err, {{.Frames}}, format, args...))
+}
+
+// annotateError records errorComponent on err{{if .Kind}} and sets its kind unless its chain has one{{end}}.
+func annotateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	err = ctxerrors.WithField(err, ctxerrors.FieldComponent, errorComponent)
+{{- if .Kind}}
+
+	if ctxerrors.KindOf(err) == ctxerrors.KindUnknown {
+		err = ctxerrors.WithKind(err, {{printf "%q" .Kind}})
+	}
+{{- end}}
+
+	return err
+}
+`)) //nolint:gochecknoglobals
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("stdout", func(t *testing.T) {
+		var stdout bytes.Buffer
+
+		require.NoError(t, run([]string{"-kind", "internal", "-skip", "1", "-o", "-"}, "billing", &stdout))
+
+		file, err := parser.ParseFile(token.NewFileSet(), defaultOutput, stdout.Bytes(), parser.ParseComments)
+		require.NoError(t, err)
+		require.Equal(t, "billing", file.Name.Name)
+
+		source := stdout.String()
+		require.Contains(t, source, "// Code generated by ctxerrors-gen; DO NOT EDIT.")
+		require.Contains(t, source, `const errorComponent = "billing"`)
+		require.Contains(t, source, "ctxerrors.NewSkip(2, message)")
+		require.Contains(t, source, "ctxerrors.WrapfSkip(err, 2, format, args...)")
+		require.Contains(t, source, `ctxerrors.WithKind(err, "internal")`)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), defaultOutput)
+
+		require.NoError(t, run([]string{"-package", "ledger", "-component", "payments", "-o", output}, "", &bytes.Buffer{}))
+
+		source, err := os.ReadFile(output) //nolint:gosec
+		require.NoError(t, err)
+		require.Contains(t, string(source), "package ledger")
+		require.Contains(t, string(source), `const errorComponent = "payments"`)
+		require.Contains(t, string(source), "ctxerrors.WrapSkip(err, 1, message)")
+		require.NotContains(t, string(source), "WithKind")
+	})
+
+	t.Run("invalid flags", func(t *testing.T) {
+		require.ErrorIs(t, run([]string{"-o", "-"}, "", &bytes.Buffer{}), ErrInvalidFlag)
+		require.ErrorIs(t, run([]string{"-skip", "-1", "-o", "-"}, "billing", &bytes.Buffer{}), ErrInvalidFlag)
+		require.Error(t, run([]string{"-nope"}, "billing", &bytes.Buffer{}))
+	})
+}
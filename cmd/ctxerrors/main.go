@@ -0,0 +1,168 @@
+// Command ctxerrors pretty-prints ctxerrors output found in log lines.
+//
+// It reads the given files, or stdin if there are none, and copies every line
+// to stdout. Lines containing a rendered error chain, either as plain text or
+// inside a JSON log record, are followed by that chain as an indented tree with
+// the locations lined up:
+//
+//	kubectl logs my-pod | ctxerrors
+//	ctxerrors -sep " -> " app.log
+//
+// Errors logged by binaries built with -trimpath can have their files resolved
+// back to the source tree through a symbol map generated at build time, in the
+// main module of the build:
+//
+//	go list -m -json all | ctxerrors -gen-symbols -goroot "$(go env GOROOT)" > symbols.json
+//	ctxerrors -symbols symbols.json app.log
+//
+// With -schema it prints the JSON Schema of the format ctxerrors.Marshal
+// writes instead, e.g. to keep a copy for other languages up to date:
+//
+//	//go:generate go run github.com/psyb0t/ctxerrors/cmd/ctxerrors -schema -o ctxerrors.schema.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/psyb0t/ctxerrors"
+	"github.com/psyb0t/ctxerrors/parser"
+	"github.com/psyb0t/ctxerrors/symbols"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run is main without the process plumbing.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("ctxerrors", flag.ContinueOnError)
+	separator := flags.String("sep", ctxerrors.DefaultSeparator, "separator between layers in the logged errors")
+	symbolsPath := flags.String("symbols", "", "symbol map to resolve -trimpath file names with")
+	genSymbols := flags.Bool("gen-symbols", false, "write a symbol map built from `go list -m -json all` on stdin")
+	goroot := flags.String("goroot", "", "GOROOT of the build, for -gen-symbols")
+	schema := flags.Bool("schema", false, "write the JSON Schema of the ctxerrors.Marshal format")
+	output := flags.String("o", "", "file to write to instead of stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return ctxerrors.Wrap(err, "failed to parse flags")
+	}
+
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return ctxerrors.Wrap(err, "failed to create output file")
+		}
+		defer file.Close() //nolint:errcheck
+
+		stdout = file
+	}
+
+	if *schema {
+		if _, err := stdout.Write(ctxerrors.WireSchema()); err != nil {
+			return ctxerrors.Wrap(err, "failed to write schema")
+		}
+
+		return nil
+	}
+
+	if *genSymbols {
+		return generateSymbols(stdin, stdout, *goroot)
+	}
+
+	opts := printOptions{separator: *separator}
+
+	if *symbolsPath != "" {
+		symbolMap, err := loadSymbols(*symbolsPath)
+		if err != nil {
+			return err
+		}
+
+		opts.symbols = symbolMap
+	}
+
+	if flags.NArg() == 0 {
+		return prettyPrint(stdin, stdout, opts)
+	}
+
+	for _, path := range flags.Args() {
+		if err := prettyPrintFile(path, stdout, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printOptions says how prettyPrint reads and prints chains.
+type printOptions struct {
+	separator string       // Between layers in the logged errors
+	symbols   *symbols.Map // Resolves file names, nil to print them as logged
+}
+
+// generateSymbols writes the symbol map for the `go list -m -json all` output
+// read from r.
+func generateSymbols(r io.Reader, w io.Writer, goroot string) error {
+	symbolMap, err := symbols.FromGoList(r, goroot)
+	if err != nil {
+		return ctxerrors.Wrap(err, "failed to build symbol map")
+	}
+
+	return symbolMap.Write(w)
+}
+
+// loadSymbols reads the symbol map at path.
+func loadSymbols(path string) (*symbols.Map, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, ctxerrors.Wrap(err, "failed to open symbol map")
+	}
+	defer file.Close() //nolint:errcheck
+
+	return symbols.Load(file)
+}
+
+// prettyPrintFile runs prettyPrint on the file at path.
+func prettyPrintFile(path string, stdout io.Writer, opts printOptions) error {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return ctxerrors.Wrap(err, "failed to open log file")
+	}
+	defer file.Close() //nolint:errcheck
+
+	return prettyPrint(file, stdout, opts)
+}
+
+// prettyPrint copies every line from r to w, following each line that holds
+// ctxerrors output with the pretty-printed chain.
+func prettyPrint(r io.Reader, w io.Writer, opts printOptions) error {
+	p := parser.Parser{Separator: opts.separator}
+
+	err := p.ParseStream(r, func(entry parser.Entry) error {
+		if _, err := fmt.Fprintln(w, entry.Text); err != nil {
+			return ctxerrors.Wrap(err, "failed to write line")
+		}
+
+		for _, chain := range entry.Chains {
+			if opts.symbols != nil {
+				chain = opts.symbols.Resymbolize(chain)
+			}
+
+			if err := printChain(w, chain); err != nil {
+				return ctxerrors.Wrap(err, "failed to print chain")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return ctxerrors.Wrap(err, "failed to pretty-print log lines")
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestRun(t *testing.T) {
+	input := "" +
+		"server started\n" +
+		"outer -> root [/src/a.go:1 in main.a] [/src/b.go:2 in main.b]\n"
+	expected := "" +
+		"server started\n" +
+		"outer -> root [/src/a.go:1 in main.a] [/src/b.go:2 in main.b]\n" +
+		"  outer   /src/b.go:2 in main.b\n" +
+		"    root  /src/a.go:1 in main.a\n"
+
+	t.Run("stdin", func(t *testing.T) {
+		var stdout bytes.Buffer
+
+		require.NoError(t, run([]string{"-sep", " -> "}, strings.NewReader(input), &stdout))
+		require.Equal(t, expected, stdout.String())
+	})
+
+	t.Run("files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		require.NoError(t, os.WriteFile(path, []byte(input), 0o600))
+
+		var stdout bytes.Buffer
+
+		require.NoError(t, run([]string{"-sep", " -> ", path, path}, strings.NewReader(""), &stdout))
+		require.Equal(t, expected+expected, stdout.String())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		var stdout bytes.Buffer
+
+		err := run([]string{filepath.Join(t.TempDir(), "nope.log")}, strings.NewReader(""), &stdout)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("bad flag", func(t *testing.T) {
+		var stdout bytes.Buffer
+
+		require.Error(t, run([]string{"-nope"}, strings.NewReader(""), &stdout))
+	})
+}
+
+func TestRunSymbols(t *testing.T) {
+	goList := `{"Path": "github.com/acme/app", "Main": true, "Dir": "/home/dev/app"}`
+
+	var generated bytes.Buffer
+
+	require.NoError(t, run([]string{"-gen-symbols", "-goroot", "/usr/local/go"}, strings.NewReader(goList), &generated))
+
+	path := filepath.Join(t.TempDir(), "symbols.json")
+	require.NoError(t, os.WriteFile(path, generated.Bytes(), 0o600))
+
+	input := "outer: root [github.com/acme/app/a.go:1 in main.a] [net/http/server.go:2 in http.serve]\n"
+	expected := input +
+		"  outer   /usr/local/go/src/net/http/server.go:2 in http.serve\n" +
+		"    root  /home/dev/app/a.go:1 in main.a\n"
+
+	var stdout bytes.Buffer
+
+	require.NoError(t, run([]string{"-symbols", path}, strings.NewReader(input), &stdout))
+	require.Equal(t, expected, stdout.String())
+
+	t.Run("missing symbol map", func(t *testing.T) {
+		err := run([]string{"-symbols", filepath.Join(t.TempDir(), "nope.json")}, strings.NewReader(input), &stdout)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("bad go list output", func(t *testing.T) {
+		require.Error(t, run([]string{"-gen-symbols"}, strings.NewReader("{nope"), &stdout))
+	})
+}
+
+func TestRunSchema(t *testing.T) {
+	var stdout bytes.Buffer
+
+	require.NoError(t, run([]string{"-schema"}, strings.NewReader(""), &stdout))
+	require.Equal(t, string(ctxerrors.WireSchema()), stdout.String())
+
+	t.Run("output file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ctxerrors.schema.json")
+
+		var stdout bytes.Buffer
+
+		require.NoError(t, run([]string{"-schema", "-o", path}, strings.NewReader(""), &stdout))
+		require.Zero(t, stdout.Len())
+
+		written, err := os.ReadFile(path) //nolint:gosec
+		require.NoError(t, err)
+		require.Equal(t, ctxerrors.WireSchema(), written)
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+	"github.com/psyb0t/ctxerrors/parser"
+)
+
+// indentWidth is how many spaces each level of the chain is indented by.
+const indentWidth = 2
+
+// printChain writes chain as an indented tree, one layer per line with the
+// locations aligned in a column after the longest message.
+func printChain(w io.Writer, chain parser.Chain) error {
+	rows := make([]string, 0, len(chain.Layers))
+	for i, layer := range chain.Layers {
+		rows = append(rows, strings.Repeat(" ", (i+1)*indentWidth)+layer.Message)
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	for i, layer := range chain.Layers {
+		if _, err := fmt.Fprintf(w, "%-*s  %s\n", width, rows[i], formatLocation(layer)); err != nil {
+			return ctxerrors.Wrap(err, "failed to write layer")
+		}
+	}
+
+	if chain.Cause != "" {
+		indent := strings.Repeat(" ", (len(chain.Layers)+1)*indentWidth)
+		if _, err := fmt.Fprintf(w, "%s%s\n", indent, chain.Cause); err != nil {
+			return ctxerrors.Wrap(err, "failed to write cause")
+		}
+	}
+
+	return nil
+}
+
+// formatLocation renders the location and ID of a layer.
+func formatLocation(layer parser.Layer) string {
+	var location string
+
+	switch {
+	case layer.File != "":
+		location = fmt.Sprintf("%s:%d in %s", layer.File, layer.Line, layer.FuncName)
+	case layer.FuncName != "":
+		location = "in " + layer.FuncName
+	}
+
+	if layer.ID != "" {
+		location = strings.TrimSpace(location + " id=" + layer.ID)
+	}
+
+	return location
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors/parser"
+)
+
+func TestPrintChain(t *testing.T) {
+	testCases := []struct {
+		name     string
+		chain    parser.Chain
+		expected string
+	}{
+		{
+			name: "aligned locations",
+			chain: parser.Chain{
+				Layers: []parser.Layer{
+					{Message: "init failed", File: "/src/main.go", Line: 24, FuncName: "main.main"},
+					{Message: "failed to read config", File: "/src/db.go", Line: 17, FuncName: "main.initDatabase"},
+				},
+			},
+			expected: "" +
+				"  init failed              /src/main.go:24 in main.main\n" +
+				"    failed to read config  /src/db.go:17 in main.initDatabase\n",
+		},
+		{
+			name: "cause, function only and ids",
+			chain: parser.Chain{
+				Layers: []parser.Layer{
+					{Message: "failed to open", FuncName: "main.open", ID: "ab-1"},
+					{Message: "hidden", ID: "ab-2"},
+				},
+				Cause: "no such file",
+			},
+			expected: "" +
+				"  failed to open  in main.open id=ab-1\n" +
+				"    hidden        id=ab-2\n" +
+				"      no such file\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			require.NoError(t, printChain(&buf, tc.chain))
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
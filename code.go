@@ -0,0 +1,23 @@
+package ctxerrors
+
+// FieldCode is the field key an error code set with WithCode is stored under.
+const FieldCode = "code"
+
+// WithCode returns err tagged with an application-defined error code, e.g. one
+// documented for API clients, without modifying err, the same way WithField
+// does it.
+func WithCode(err error, code string) error {
+	// Skip WithCode(), annotate() and wrap() to get user's caller
+	framesToSkip := 3
+
+	return annotate(err, map[string]any{FieldCode: code}, framesToSkip)
+}
+
+// CodeOf returns the error code set in err's chain, including layers inside
+// joined errors, by the outermost layer that has one unless SetFieldPrecedence
+// says otherwise, or an empty string if none does.
+func CodeOf(err error) string {
+	code, _ := lookupField[string](err, FieldCode)
+
+	return code
+}
@@ -0,0 +1,60 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeOf(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: "",
+		},
+		{
+			name:     "foreign error",
+			err:      baseErr,
+			expected: "",
+		},
+		{
+			name:     "no code",
+			err:      Wrap(baseErr, "wrapped"),
+			expected: "",
+		},
+		{
+			name:     "on foreign error",
+			err:      WithCode(baseErr, "E1001"),
+			expected: "E1001",
+		},
+		{
+			name:     "deep in chain",
+			err:      Wrap(WithCode(Wrap(baseErr, "inner"), "E1001"), "outer"),
+			expected: "E1001",
+		},
+		{
+			name:     "outermost wins",
+			err:      WithCode(Wrap(WithCode(Wrap(baseErr, "inner"), "E1001"), "outer"), "E2002"),
+			expected: "E2002",
+		},
+		{
+			name:     "inside joined errors",
+			err:      errors.Join(baseErr, WithCode(Wrap(baseErr, "inner"), "E1001")),
+			expected: "E1001",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, CodeOf(tc.err))
+		})
+	}
+}
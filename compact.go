@@ -0,0 +1,119 @@
+package ctxerrors
+
+import (
+	"maps"
+	"strings"
+)
+
+// Compact returns a copy of the CTXError chain in err with every run of
+// adjacent layers created in the same function of the same file merged into a
+// single layer, for UIs where a helper wrapping three times on its way out
+// shouldn't take three locations. A merged layer has the location, ID, callers
+// and timestamp of the outermost layer of its run, the non-empty messages of
+// the run joined with the separator, outermost first, and the fields of every
+// layer of the run, the outermost winning when they share a key. Only the
+// *CTXError layers at the top of the chain are considered, like Clone does.
+//
+// Uncompacted returns the chain err was for full-fidelity logging. If nothing
+// can be merged err itself is returned.
+func Compact(err error) error {
+	layer, ok := asCTXError(err)
+	if !ok || !compactable(layer) {
+		return err
+	}
+
+	compacted, _ := asCTXError(compactChain(layer, currentConfig().separator))
+	compacted.compacted = err
+
+	return compacted
+}
+
+// Uncompacted returns the chain Compact made err from, or err itself if it
+// isn't what Compact returned.
+func Uncompacted(err error) error {
+	if layer, ok := asCTXError(err); ok && layer.compacted != nil {
+		return layer.compacted
+	}
+
+	return err
+}
+
+// compactable reports whether any two adjacent layers at the top of the chain
+// starting at layer would be merged by Compact.
+func compactable(layer *CTXError) bool {
+	for {
+		next, ok := asCTXError(layer.err)
+		if !ok {
+			return false
+		}
+
+		if sameFunction(layer, next) {
+			return true
+		}
+
+		layer = next
+	}
+}
+
+// compactChain copies the chain starting at layer the way Compact describes.
+func compactChain(layer *CTXError, separator string) error {
+	merged := *layer
+	merged.fields = maps.Clone(layer.fields)
+	merged.notes = maps.Clone(layer.notes)
+	merged.compacted = nil
+
+	var messages []string
+	if message := layer.msg(); message != "" {
+		messages = append(messages, message)
+	}
+
+	last := layer
+
+	for next, ok := asCTXError(last.err); ok && sameFunction(last, next); next, ok = asCTXError(last.err) {
+		if message := next.msg(); message != "" {
+			messages = append(messages, message)
+		}
+
+		for key, value := range next.fields {
+			if _, ok := merged.fields[key]; !ok {
+				if merged.fields == nil {
+					merged.fields = make(map[string]any, len(next.fields))
+				}
+
+				merged.fields[key] = value
+			}
+		}
+
+		merged.refs = append(merged.refs[:len(merged.refs):len(merged.refs)], next.refs...)
+
+		if merged.origin == nil {
+			merged.origin = next.origin
+		}
+
+		if merged.ancestry == nil {
+			merged.ancestry = next.ancestry
+		}
+
+		last = next
+	}
+
+	if last != layer {
+		merged.message = strings.Join(messages, separator)
+		merged.lazy = nil
+	}
+
+	merged.err = last.err
+	merged.barrier = last.barrier
+
+	if inner, ok := asCTXError(last.err); ok {
+		merged.err = compactChain(inner, separator)
+	}
+
+	return &merged
+}
+
+// sameFunction reports whether the layers a and b were created in the same
+// function of the same file.
+func sameFunction(a, b *CTXError) bool {
+	return a.funcName != "" && a.funcName == b.funcName && a.file == b.file
+}
@@ -0,0 +1,65 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func compactQuery(err error) error {
+	err = WithField(Wrap(err, "scan row"), "row", 3)
+	err = Wrap(err, "")
+
+	return WithField(Wrap(err, "query users"), "table", "users")
+}
+
+//go:noinline
+func compactLoad(err error) error {
+	return Wrap(compactQuery(err), "load user")
+}
+
+func TestCompact(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+	original := compactLoad(baseErr)
+
+	compacted := Compact(original)
+
+	require.Len(t, ctxLayers(original), 4)
+	require.Len(t, ctxLayers(compacted), 2)
+	require.Equal(t, Short(original), Short(compacted))
+	require.ErrorIs(t, compacted, baseErr)
+	require.Same(t, original, Uncompacted(compacted))
+
+	layers := ctxLayers(compacted)
+	outer, _ := asCTXError(original)
+	query, _ := asCTXError(outer.err)
+
+	require.Equal(t, "load user", layers[0].Message())
+	require.Equal(t, outer.Line(), layers[0].Line())
+
+	require.Equal(t, "query users: scan row", layers[1].Message())
+	require.Equal(t, query.Line(), layers[1].Line())
+	require.Equal(t, query.FuncName(), layers[1].FuncName())
+	require.Equal(t, map[string]any{"row": 3, "table": "users"}, layers[1].Fields())
+	require.Nil(t, Uncompacted(layers[1]).(*CTXError).compacted) //nolint:errorlint,forcetypeassert
+
+	t.Run("outermost field wins", func(t *testing.T) {
+		err := WithField(Wrap(WithField(New("inner"), "key", "inner"), "outer"), "key", "outer")
+
+		require.Equal(t, "outer", Fields(Compact(err))["key"])
+	})
+
+	t.Run("nothing to merge", func(t *testing.T) {
+		err := Wrap(baseErr, "load user")
+
+		require.Same(t, err, Compact(err))
+		require.Same(t, err, Uncompacted(err))
+	})
+
+	t.Run("not a ctxerror", func(t *testing.T) {
+		require.Same(t, baseErr, Compact(baseErr))
+		require.NoError(t, Compact(nil))
+	})
+}
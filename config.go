@@ -0,0 +1,209 @@
+package ctxerrors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// CaptureMode controls how much caller information is resolved when an error
+// is created.
+type CaptureMode int
+
+const (
+	// CaptureFull records file, line and function name. This is the default.
+	CaptureFull CaptureMode = iota
+	// CaptureFuncOnly records only the function name, skipping file and line
+	// resolution for less overhead and shorter output.
+	CaptureFuncOnly
+)
+
+// SourceMapper maps a captured file and line to the location that should be
+// reported instead, e.g. from generated code back to the source it was
+// generated from.
+type SourceMapper func(file string, line int) (string, int)
+
+// config holds the package-wide settings. A config that's been published with
+// updateConfig is never modified again, so the hot path can read it without
+// locking.
+type config struct {
+	hideLocation    bool                  // Leave file/line/func out of Error()
+	captureMode     CaptureMode           // What gets resolved at creation time
+	normalizePaths  bool                  // Use forward slashes in captured file paths
+	sourceMapper    SourceMapper          // Remaps captured locations, nil means as is
+	instanceIDs     bool                  // Give every created error a unique ID
+	enrichHooks     []enrichHookEntry     // Run on every created error
+	separator       string                // Goes between layers in Error()
+	queryArgsPolicy QueryArgsPolicy       // What WrapQuery records of query arguments
+	callerDepth     int                   // Extra frames captured above the caller
+	stackIf         StackPredicate        // Picks errors that get callerDepth frames
+	debugDump       DumpPredicate         // Picks errors that get a goroutine dump
+	redactedFields  []string              // Lowercased key patterns Fields redacts
+	fieldPrecedence FieldPrecedence       // Which layer wins for a repeated key
+	fieldProviders  []fieldProviderEntry  // Consulted by NewCtx and WrapCtx
+	deterministic   bool                  // Stable placeholders, see SetDeterministic
+	sentinelKinds   []SentinelKind        // Classify's fallback, in order
+	anonymizePaths  bool                  // Hash the directory of captured file paths
+	maxLayerMessage int                   // Bytes of each message Error() renders, 0 for all
+	maxTotalMessage int                   // Bytes of all messages Error() renders, 0 for all
+	countActivity   bool                  // Keep the counters PublishExpvar publishes
+	subscribers     []*subscriber         // Get an ErrorEvent for every created error
+	clock           Clock                 // Where timestamps come from, nil means time.Now
+	idGenerator     IDGenerator           // Makes instance IDs, nil means the built-in one
+	resolver        Resolver              // Resolves captured PCs, nil means RuntimeResolver
+	fieldMarshalers []fieldMarshalerEntry // Render field values handed out by Fields
+	vet             TestingT              // Told about wraps adding no context, see SetVet
+	vetMode         VetMode               // What vet does about them
+	timestamps      bool                  // Record when every created error was created
+	collapseRepeats bool                  // Render runs of identical messages once in Error()
+	funcNameStyle   FuncNameStyle         // How output renders function names
+	warningHook     WarningHook           // Told about discouraged wraps, see SetWarningHook
+	warnDepth       int                   // Chain depth past which wraps get a warning, 0 for none
+	formatCheck     FormatCheck           // What happens to mismatched format arguments
+	inferOps        bool                  // Derive missing ops from function names, see SetInferOps
+	injections      []Injection           // Failures forced on created errors, see SetInjections
+	packagePolicies []packagePolicyEntry  // Defaults by package, least specific first
+	ancestry        bool                  // Record the go statements behind errors, see SetGoroutineAncestry
+}
+
+var (
+	configMu     sync.Mutex                         //nolint:gochecknoglobals // Serializes updateConfig
+	activeConfig = newActiveConfig(defaultConfig()) //nolint:gochecknoglobals
+)
+
+// newActiveConfig returns an atomic pointer holding initial.
+func newActiveConfig(initial config) *atomic.Pointer[config] {
+	var active atomic.Pointer[config]
+
+	active.Store(&initial)
+
+	return &active
+}
+
+// defaultConfig returns the settings the package starts with.
+func defaultConfig() config {
+	return config{
+		normalizePaths: true,
+		separator:      DefaultSeparator,
+		sentinelKinds:  DefaultSentinelKinds(),
+	}
+}
+
+// textOptions returns the settings Error() renders with.
+func (c config) textOptions() textOptions {
+	return textOptions{
+		separator:       c.separator,
+		hideLocation:    c.hideLocation,
+		maxLayerMessage: c.maxLayerMessage,
+		maxTotalMessage: c.maxTotalMessage,
+		collapseRepeats: c.collapseRepeats,
+		funcNameStyle:   c.funcNameStyle,
+	}
+}
+
+// mapLocation applies the source mapper, path normalization and then either
+// deterministic mode or anonymization to a captured file and line.
+func (c *config) mapLocation(file string, line int) (string, int) {
+	if c.sourceMapper != nil {
+		file, line = c.sourceMapper(file, line)
+	}
+
+	if c.normalizePaths || c.deterministic || c.anonymizePaths {
+		file = normalizePath(file)
+	}
+
+	switch {
+	case c.deterministic:
+		file, line = deterministicLocation(file, line)
+	case c.anonymizePaths:
+		file = anonymizePath(file)
+	}
+
+	return file, line
+}
+
+// SetHideLocation controls whether Error() includes the file, line and function
+// of each layer. Hiding them keeps source layout out of user-visible strings
+// while the location stays available through the File, Line and FuncName
+// accessors.
+func SetHideLocation(hide bool) {
+	updateConfig(func(c *config) {
+		c.hideLocation = hide
+	})
+}
+
+// SetCaptureMode sets how much caller information is captured for errors
+// created from now on.
+func SetCaptureMode(mode CaptureMode) {
+	updateConfig(func(c *config) {
+		c.captureMode = mode
+	})
+}
+
+// SetNormalizePaths controls whether captured file paths are rewritten to use
+// forward slashes regardless of GOOS. It's on by default.
+func SetNormalizePaths(normalize bool) {
+	updateConfig(func(c *config) {
+		c.normalizePaths = normalize
+	})
+}
+
+// SetSourceMapper sets the function used to remap every captured file and line,
+// for pointing errors raised in generated code (protoc, templ, mockgen) at the
+// authoring source. Pass nil to report locations as is. //line directives are
+// already honored by the runtime before the mapper is called.
+func SetSourceMapper(mapper SourceMapper) {
+	updateConfig(func(c *config) {
+		c.sourceMapper = mapper
+	})
+}
+
+// SetInstanceIDs controls whether every error created from now on gets a short
+// unique ID, rendered in Error() and available through ID(), so an error string
+// reported by a user can be matched to the exact server-side log record.
+func SetInstanceIDs(enabled bool) {
+	updateConfig(func(c *config) {
+		c.instanceIDs = enabled
+	})
+}
+
+// SetTimestamps controls whether every error created from now on records when
+// it was created, by the SetClock clock, available through Time() and
+// Records. It's off by default since reading the clock costs something on
+// every error. SetDeterministic swaps the clock for placeholder times.
+func SetTimestamps(enabled bool) {
+	updateConfig(func(c *config) {
+		c.timestamps = enabled
+	})
+}
+
+// SetSeparator sets the separator Error() puts between the layers of a chain,
+// e.g. " -> " or " | " to match existing alerting regexes. An empty separator
+// restores DefaultSeparator.
+func SetSeparator(separator string) {
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+
+	updateConfig(func(c *config) {
+		c.separator = separator
+	})
+}
+
+// currentConfig returns the current settings snapshot without locking. It
+// must not be modified, use updateConfig for that.
+func currentConfig() *config {
+	return activeConfig.Load()
+}
+
+// updateConfig publishes a copy of the current settings with update applied.
+// Readers see either the old snapshot or the new one, never a mix. Slices in
+// the copy are still shared with the old snapshot, so update has to replace
+// them rather than modify them in place.
+func updateConfig(update func(c *config)) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	next := *activeConfig.Load()
+	update(&next)
+	activeConfig.Store(&next)
+}
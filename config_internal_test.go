@@ -0,0 +1,221 @@
+package ctxerrors
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetHideLocation(t *testing.T) {
+	t.Cleanup(func() { SetHideLocation(false) })
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "new error",
+			err:      New("something went wrong"),
+			expected: "something went wrong",
+		},
+		{
+			name:     "wrapped error",
+			err:      Wrap(baseErr, "additional context"),
+			expected: "additional context: base error",
+		},
+		{
+			name:     "chain",
+			err:      Wrap(Wrap(New("root"), "middle"), "outer"),
+			expected: "outer: middle: root",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetHideLocation(true)
+			require.Equal(t, tc.expected, tc.err.Error())
+
+			// Location is still there for whoever asks for it
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(tc.err, &ctxErr))
+			require.Contains(t, ctxErr.File(), goFileExtension)
+			require.NotZero(t, ctxErr.Line())
+			require.Contains(t, ctxErr.FuncName(), "TestSetHideLocation")
+
+			SetHideLocation(false)
+			require.Contains(t, tc.err.Error(), goFileExtension)
+		})
+	}
+}
+
+func TestSetCaptureMode(t *testing.T) {
+	t.Cleanup(func() { SetCaptureMode(CaptureFull) })
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name string
+		err  func() error
+	}{
+		{
+			name: "new",
+			err:  func() error { return New("something went wrong") },
+		},
+		{
+			name: "wrap",
+			err:  func() error { return Wrap(baseErr, "additional context") },
+		},
+		{
+			name: "wrapf",
+			err:  func() error { return Wrapf(baseErr, "code=%d", 42) },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetCaptureMode(CaptureFuncOnly)
+
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(tc.err(), &ctxErr))
+			require.Empty(t, ctxErr.File())
+			require.Zero(t, ctxErr.Line())
+			require.Contains(t, ctxErr.FuncName(), "TestSetCaptureMode")
+			require.Contains(t, ctxErr.Error(), "[in ")
+
+			funcOnlyName := ctxErr.FuncName()
+			require.NotContains(t, ctxErr.Error(), goFileExtension)
+
+			SetCaptureMode(CaptureFull)
+
+			require.True(t, errors.As(tc.err(), &ctxErr))
+			require.Contains(t, ctxErr.File(), goFileExtension)
+			require.NotZero(t, ctxErr.Line())
+			require.Equal(t, funcOnlyName, ctxErr.FuncName())
+		})
+	}
+}
+
+func TestSetNormalizePaths(t *testing.T) {
+	t.Cleanup(func() { SetNormalizePaths(true) })
+
+	require.True(t, currentConfig().normalizePaths)
+
+	var ctxErr *CTXError
+
+	require.True(t, errors.As(New("normalized"), &ctxErr))
+	require.NotContains(t, ctxErr.File(), `\`)
+
+	SetNormalizePaths(false)
+	require.False(t, currentConfig().normalizePaths)
+
+	_, expectedFile, _, ok := runtime.Caller(0)
+	require.True(t, ok)
+
+	require.True(t, errors.As(New("as reported by the runtime"), &ctxErr))
+	require.Equal(t, expectedFile, ctxErr.File())
+}
+
+func TestSetSourceMapper(t *testing.T) {
+	t.Cleanup(func() { SetSourceMapper(nil) })
+
+	t.Run("line directives are honored", func(t *testing.T) {
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(newFromGenerated(), &ctxErr))
+		require.Equal(t, "user.tmpl", filepath.Base(ctxErr.File()))
+		require.Equal(t, 7, ctxErr.Line())
+		require.Contains(t, ctxErr.FuncName(), "newFromGenerated")
+	})
+
+	t.Run("mapper remaps locations", func(t *testing.T) {
+		SetSourceMapper(func(file string, line int) (string, int) {
+			if filepath.Base(file) != "user.tmpl" {
+				return file, line
+			}
+
+			return `templates\user.templ`, line + 100
+		})
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(newFromGenerated(), &ctxErr))
+		require.Equal(t, "templates/user.templ", ctxErr.File())
+		require.Equal(t, 107, ctxErr.Line())
+
+		// Locations the mapper doesn't care about are left alone
+		require.True(t, errors.As(New("not generated"), &ctxErr))
+		require.Contains(t, ctxErr.File(), "config_internal_test.go")
+	})
+
+	t.Run("nil mapper reports locations as is", func(t *testing.T) {
+		SetSourceMapper(nil)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(newFromGenerated(), &ctxErr))
+		require.Equal(t, "user.tmpl", filepath.Base(ctxErr.File()))
+		require.Equal(t, 7, ctxErr.Line())
+	})
+}
+
+func TestConcurrentConfig(t *testing.T) {
+	t.Cleanup(func() {
+		SetHideLocation(false)
+		SetSeparator(DefaultSeparator)
+	})
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	var wg sync.WaitGroup
+
+	for i := range 8 {
+		wg.Add(2) //nolint:mnd
+
+		go func() {
+			defer wg.Done()
+
+			for j := range 100 {
+				SetHideLocation(j%2 == 0)
+				SetSeparator([]string{": ", " -> "}[i%2])
+
+				unregister := RegisterEnrichHook(EnrichHookFunc(func(*CTXError) map[string]any { return nil }))
+				unregister()
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for range 100 {
+				err := Wrap(New("inner"), "outer")
+				require.Contains(t, Wrap(err, "top").Error(), "inner")
+				require.ErrorIs(t, Wrap(baseErr, "wrapped"), baseErr)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestUpdateConfig(t *testing.T) {
+	t.Cleanup(func() { SetSeparator(DefaultSeparator) })
+
+	before := currentConfig()
+
+	SetSeparator(" | ")
+
+	after := currentConfig()
+
+	require.NotSame(t, before, after)
+	require.Equal(t, DefaultSeparator, before.separator)
+	require.Equal(t, " | ", after.separator)
+}
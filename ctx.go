@@ -0,0 +1,120 @@
+package ctxerrors
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync/atomic"
+)
+
+// FieldProvider returns fields to attach to an error created under ctx by
+// NewCtx or WrapCtx, e.g. the request ID a middleware stored in it.
+type FieldProvider func(ctx context.Context) map[string]any
+
+// fieldProviderEntry pairs a registered provider with the ID used to
+// unregister it.
+type fieldProviderEntry struct {
+	id       uint64
+	provider FieldProvider
+}
+
+var fieldProviderIDs atomic.Uint64 //nolint:gochecknoglobals
+
+// NewCtx is like New but also attaches the fields every registered
+// FieldProvider returns for ctx, those of the operation StartOp started in it
+// and, if ctx has a deadline, how long it had left as FieldDeadlineRemaining.
+func NewCtx(ctx context.Context, message string) error {
+	// Skip NewCtx() and newLayer() to get user's caller
+	framesToSkip := 2
+
+	layer := newLayer(nil, message, framesToSkip)
+
+	return finishLayer(layer, providedFields(ctx))
+}
+
+// WrapCtx is like Wrap but also attaches the fields every registered
+// FieldProvider returns for ctx, those of the operation StartOp started in it
+// and, if ctx has a deadline, how long it had left as FieldDeadlineRemaining.
+func WrapCtx(ctx context.Context, err error, message string) error {
+	// Skip WrapCtx() and wrap() to get user's caller
+	framesToSkip := 2
+
+	return wrap(err, message, framesToSkip, providedFields(ctx))
+}
+
+// RegisterFieldProvider adds provider to the providers consulted by NewCtx and
+// WrapCtx, in registration order, so infrastructure like request middleware
+// or job runners can make sure every error created under their contexts
+// carries the same standard metadata. A later provider wins when two return
+// the same field key, and provided fields win over enrich hook fields. The
+// returned function unregisters the provider.
+func RegisterFieldProvider(provider FieldProvider) func() {
+	id := fieldProviderIDs.Add(1)
+
+	updateConfig(func(c *config) {
+		// Never append in place, older snapshots may share the slice
+		c.fieldProviders = append(slices.Clip(c.fieldProviders), fieldProviderEntry{id: id, provider: provider})
+	})
+
+	return func() {
+		updateConfig(func(c *config) {
+			c.fieldProviders = slices.DeleteFunc(slices.Clone(c.fieldProviders), func(entry fieldProviderEntry) bool {
+				return entry.id == id
+			})
+		})
+	}
+}
+
+// providedFields merges the fields of the operation StartOp started in ctx,
+// how long ctx has left until its deadline and the fields every registered
+// FieldProvider returns for ctx. A panicking provider is logged and skipped
+// like a panicking enrich hook.
+func providedFields(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields map[string]any
+
+	if timer, ok := OpTimer(ctx); ok {
+		fields = timer.fields()
+	}
+
+	if remaining, ok := deadlineRemaining(ctx); ok {
+		if fields == nil {
+			fields = make(map[string]any, 1)
+		}
+
+		fields[FieldDeadlineRemaining] = remaining
+	}
+
+	for _, entry := range currentConfig().fieldProviders {
+		provided := callFieldProvider(ctx, entry.provider)
+		if len(provided) == 0 {
+			continue
+		}
+
+		if fields == nil {
+			fields = make(map[string]any, len(provided))
+		}
+
+		maps.Copy(fields, provided)
+	}
+
+	return fields
+}
+
+// callFieldProvider calls provider, recovering from any panic in it.
+func callFieldProvider(ctx context.Context, provider FieldProvider) (fields map[string]any) { //nolint:nonamedreturns
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Field provider panicked", "panic", r)
+			countActivity(&activity.hookFailures)
+
+			fields = nil
+		}
+	}()
+
+	return provider(ctx)
+}
@@ -0,0 +1,82 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type requestIDKey struct{}
+
+func TestRegisterFieldProvider(t *testing.T) { //nolint:funlen
+	baseErr := errors.New("base error") //nolint:err113
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-123")
+
+	unregisterHook := RegisterEnrichHook(EnrichHookFunc(func(*CTXError) map[string]any {
+		return map[string]any{"source": "hook", "hook": true}
+	}))
+	t.Cleanup(unregisterHook)
+
+	unregisterFirst := RegisterFieldProvider(func(ctx context.Context) map[string]any {
+		requestID, _ := ctx.Value(requestIDKey{}).(string)
+
+		return map[string]any{"request_id": requestID, "source": "first"}
+	})
+	t.Cleanup(unregisterFirst)
+
+	unregisterSecond := RegisterFieldProvider(func(context.Context) map[string]any {
+		return map[string]any{"source": "second"}
+	})
+	t.Cleanup(unregisterSecond)
+
+	unregisterPanicking := RegisterFieldProvider(func(context.Context) map[string]any {
+		panic("provider exploded")
+	})
+	t.Cleanup(unregisterPanicking)
+
+	expectedFields := map[string]any{"request_id": "req-123", "source": "second", "hook": true}
+
+	t.Run("NewCtx", func(t *testing.T) {
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(NewCtx(ctx, "failed"), &ctxErr))
+		require.Equal(t, "failed", ctxErr.Message())
+		require.Contains(t, ctxErr.FuncName(), "TestRegisterFieldProvider")
+		require.Equal(t, expectedFields, ctxErr.Fields())
+	})
+
+	t.Run("WrapCtx", func(t *testing.T) {
+		actual := WrapCtx(ctx, baseErr, "failed")
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(actual, &ctxErr))
+		require.Contains(t, ctxErr.FuncName(), "TestRegisterFieldProvider")
+		require.Equal(t, expectedFields, ctxErr.Fields())
+		require.ErrorIs(t, actual, baseErr)
+	})
+
+	t.Run("WrapCtx nil error", func(t *testing.T) {
+		require.NoError(t, WrapCtx(ctx, nil, "failed"))
+	})
+
+	t.Run("plain constructors skip providers", func(t *testing.T) {
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(Wrap(baseErr, "failed"), &ctxErr))
+		require.Equal(t, map[string]any{"source": "hook", "hook": true}, ctxErr.Fields())
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		unregisterFirst()
+		unregisterSecond()
+		unregisterPanicking()
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(NewCtx(ctx, "failed"), &ctxErr))
+		require.Equal(t, map[string]any{"source": "hook", "hook": true}, ctxErr.Fields())
+	})
+}
@@ -1,18 +1,40 @@
 package ctxerrors
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // CTXError holds the wrapped error and additional context.
 type CTXError struct {
-	err      error  // Original error
-	message  string // Additional context message
-	file     string // File where error occurred
-	line     int    // Line where error occurred
-	funcName string // Function where error occurred
+	err       error                  // Original error
+	message   string                 // Additional context message
+	file      string                 // File where error occurred
+	line      int                    // Line where error occurred
+	funcName  string                 // Function where error occurred
+	id        string                 // Unique instance ID, empty unless enabled
+	fields    map[string]any         // Structured fields attached to this layer
+	refs      []error                // Errors referenced with %w in the message
+	callers   []uintptr              // Extra frames above the caller, see SetCallerDepth
+	frames    []Frame                // Resolved callers of a layer decoded by Unmarshal
+	dump      []byte                 // Stacks of all goroutines, see SetDebugDump
+	barrier   error                  // Cause hidden by Barrier, see UnwrapBarrier
+	created   time.Time              // When the layer was created, see SetTimestamps
+	notes     map[int]string         // Notes on frames by index, see AnnotateFrame
+	origin    []Frame                // Where the work failing was submitted, see LinkOrigin
+	ancestry  *goroutineAncestry     // Go statements behind it, see SetGoroutineAncestry
+	lazy      *lazyMessage           // Unformatted message, see WrapLazyf
+	compacted error                  // Chain Compact made this one from, see Uncompacted
+	boundary  bool                   // Layers below are library internals, see WrapStackBoundary
+	oncePC    uintptr                // Call site of the WrapOnce call that made it
+	callerPCs [inlineCallers]uintptr // Backs callers when they fit
 }
 
 // New creates a new error with context but without wrapping another error.
@@ -20,61 +42,123 @@ func New(message string) error {
 	// Skip New() to get user's caller
 	framesToSkip := 1
 
-	file, line, funcName := getCallerInfo(framesToSkip)
+	return NewSkip(framesToSkip, message)
+}
 
-	return &CTXError{
-		message:  message,
-		file:     file,
-		line:     line,
-		funcName: funcName,
-	}
+// Newf creates a new error with context and a printf-style message. Errors
+// referenced with %w are matched by errors.Is and errors.As, like with
+// fmt.Errorf.
+func Newf(format string, args ...any) error {
+	// Skip Newf() to get user's caller
+	framesToSkip := 1
+
+	return NewfSkip(framesToSkip, format, args...)
 }
 
 // Wrap wraps an error with context information (file, line, and function name).
 func Wrap(err error, message string) error {
-	// Skip Wrap() and wrap() to get user's caller
-	framesToSkip := 2
+	// Skip Wrap() to get user's caller
+	framesToSkip := 1
 
-	return wrap(err, message, framesToSkip)
+	return WrapSkip(err, framesToSkip, message)
 }
 
 // Wrapf wraps an error with context information (file, line, and function name).
+// Errors referenced with %w in the message are matched by errors.Is and
+// errors.As along with err, like with fmt.Errorf.
 func Wrapf(err error, format string, args ...any) error {
-	// Skip Wrapf() and wrap() to get user's caller
-	framesToSkip := 2
+	// Skip Wrapf() to get user's caller
+	framesToSkip := 1
 
-	return wrap(err, fmt.Sprintf(format, args...), framesToSkip)
+	return WrapfSkip(err, framesToSkip, format, args...)
 }
 
-// wrap is a private function that both Wrap and Wrapf use to create errors with context
-func wrap(err error, message string, skip int) error {
-	if err == nil {
-		// For nil error debug logging, get stack trace at different levels
-		debugFrame1 := 2
-		debugFrame2 := 3
-		debugFrame3 := 4
+// formatMessage renders format the way fmt.Errorf does, returning the non-nil
+// errors referenced with %w along with the message.
+func formatMessage(format string, args ...any) (string, []error) {
+	formatted := fmt.Errorf(format, args...) //nolint:err113
 
-		pc, file, line, _ := runtime.Caller(debugFrame1)
-		funcName := runtime.FuncForPC(pc).Name()
+	var refs []error
 
-		pc2, file2, line2, _ := runtime.Caller(debugFrame2)
-		funcName2 := runtime.FuncForPC(pc2).Name()
+	switch wrapper := formatted.(type) { //nolint:errorlint
+	case interface{ Unwrap() []error }:
+		refs = wrapper.Unwrap()
+	case interface{ Unwrap() error }:
+		refs = []error{wrapper.Unwrap()}
+	}
+
+	refs = slices.DeleteFunc(refs, func(ref error) bool { return ref == nil })
+	if len(refs) == 0 {
+		refs = nil
+	}
 
-		pc3, file3, line3, _ := runtime.Caller(debugFrame3)
-		funcName3 := runtime.FuncForPC(pc3).Name()
+	return formatted.Error(), refs
+}
 
-		slog.Error("Trying to wrap a nil error",
-			"sourceFile1", fmt.Sprintf("%s:%d", file, line),
-			"sourceFunc1", funcName,
-			"sourceFile2", fmt.Sprintf("%s:%d", file2, line2),
-			"sourceFunc2", funcName2,
-			"sourceFile3", fmt.Sprintf("%s:%d", file3, line3),
-			"sourceFunc3", funcName3,
-		)
+// wrap creates a layer wrapping err with message and fields, located skip
+// frames up the stack from it, for the constructors that set nothing else on
+// it. A nil err is logged and nil returned.
+func wrap(err error, message string, skip int, fields map[string]any) error {
+	if err == nil {
+		// Skip wrap() too
+		logNilWrap(skip + 1)
 
 		return nil
 	}
 
+	// Skip wrap() too
+	layer := newLayer(err, message, skip+1)
+
+	return finishLayer(layer, fields)
+}
+
+// logNilWrap logs an attempt to wrap a nil error with the three frames
+// starting skip frames up the stack from it.
+func logNilWrap(skip int) {
+	pc, file, line, _ := runtime.Caller(skip)
+	funcName := runtime.FuncForPC(pc).Name()
+
+	pc2, file2, line2, _ := runtime.Caller(skip + 1)
+	funcName2 := runtime.FuncForPC(pc2).Name()
+
+	pc3, file3, line3, _ := runtime.Caller(skip + 2) //nolint:mnd
+	funcName3 := runtime.FuncForPC(pc3).Name()
+
+	slog.Error("Trying to wrap a nil error",
+		"sourceFile1", fmt.Sprintf("%s:%d", file, line),
+		"sourceFunc1", funcName,
+		"sourceFile2", fmt.Sprintf("%s:%d", file2, line2),
+		"sourceFunc2", funcName2,
+		"sourceFile3", fmt.Sprintf("%s:%d", file3, line3),
+		"sourceFunc3", funcName3,
+	)
+}
+
+// newLayer creates a layer wrapping err, which may be nil, located skip frames
+// up the stack from it with the callers SetCallerDepth asks for. Every
+// constructor capturing its own location starts here, sets whatever else its
+// layer holds and hands it to finishLayer.
+func newLayer(err error, message string, skip int) *CTXError {
+	// Skip newLayer() too
+	layer := locatedLayer(err, message, skip+1)
+	layer.captureCallers(skip)
+
+	return layer
+}
+
+// newLayerDepth is newLayer capturing depth callers whatever SetCallerDepth
+// says.
+func newLayerDepth(err error, message string, skip, depth int) *CTXError {
+	// Skip newLayerDepth() too
+	layer := locatedLayer(err, message, skip+1)
+	layer.captureCallersDepth(skip, depth)
+
+	return layer
+}
+
+// locatedLayer creates a layer wrapping err with message, located skip frames
+// up the stack from it.
+func locatedLayer(err error, message string, skip int) *CTXError {
 	file, line, funcName := getCallerInfo(skip)
 
 	return &CTXError{
@@ -83,6 +167,7 @@ func wrap(err error, message string, skip int) error {
 		file:     file,
 		line:     line,
 		funcName: funcName,
+		id:       newInstanceID(),
 	}
 }
 
@@ -95,33 +180,413 @@ func (e *CTXError) Unwrap() error {
 	return e.err
 }
 
-// Error returns the formatted error message, including file and function details.
+// Is reports whether any error referenced with %w in this layer's message
+// matches target. The wrapped error itself is taken care of by errors.Is.
+func (e *CTXError) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+
+	for _, ref := range e.refs {
+		if errors.Is(ref, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error referenced with %w in this layer's message that
+// matches target. The wrapped error itself is taken care of by errors.As.
+func (e *CTXError) As(target any) bool {
+	if e == nil {
+		return false
+	}
+
+	for _, ref := range e.refs {
+		if errors.As(ref, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Error returns the formatted error message, including file and function details
+// unless they've been turned off with SetHideLocation, followed by the instance
+// ID if there is one. Control characters, bidirectional text controls and
+// invalid UTF-8 in messages come out escaped, e.g. "\n" and "\x1b".
 func (e *CTXError) Error() string {
 	if e == nil {
 		return ""
 	}
 
-	if e.err != nil {
-		return fmt.Sprintf(
-			"%s: %s [%s:%d in %s]",
-			e.message, e.err, e.file, e.line, e.funcName,
-		)
+	return e.text(currentConfig().textOptions())
+}
+
+// text renders this layer and everything below it as Error() does, with opts
+// in place of the global settings. The run of *CTXError layers on top of the
+// chain is sized up front and written into a single builder, messages top down
+// then locations bottom up, so deep chains don't pay for a string per layer.
+func (e *CTXError) text(opts textOptions) string {
+	var stack [textStackLayers]*CTXError
+
+	layers := stack[:0]
+	if depth := e.depth(); depth > len(stack) {
+		layers = make([]*CTXError, 0, depth)
 	}
 
-	return fmt.Sprintf(
-		"%s [%s:%d in %s]",
-		e.message, e.file, e.line, e.funcName,
+	var (
+		cause    error
+		internal = -1 // Layers below the outermost WrapStackBoundary one
 	)
+
+	for layer := e; ; {
+		if internal >= 0 {
+			internal++
+		} else if layer.boundary {
+			internal = 0
+		}
+
+		layers = append(layers, layer)
+
+		if layer.err == nil {
+			break
+		}
+
+		next, ok := asCTXError(layer.err)
+		if !ok {
+			cause = layer.err
+
+			break
+		}
+
+		layer = next
+	}
+
+	var causeMessage string
+	if cause != nil {
+		causeMessage = cause.Error()
+	}
+
+	var repeats []int
+	if opts.collapseRepeats {
+		layers, repeats = collapseRepeats(layers)
+	}
+
+	var builder strings.Builder
+
+	builder.Grow(textSize(layers, cause != nil, causeMessage, opts))
+
+	messages := newMessageWriter(&builder, opts)
+
+	for i, layer := range layers {
+		if i > 0 {
+			messages.write(opts.separator)
+		}
+
+		messages.writeMessage(layer.msg())
+
+		if repeats != nil && repeats[i] > 1 {
+			messages.write(repeatMarker(repeats[i]))
+		}
+	}
+
+	if cause != nil {
+		messages.write(opts.separator)
+		messages.writeMessage(causeMessage)
+	}
+
+	messages.close()
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+
+		// A collapsed run is internal if every layer it stands for is
+		run := 1
+		if repeats != nil {
+			run = repeats[i]
+		}
+
+		hidden := internal >= run
+		internal -= run
+
+		if !opts.hideLocation && !hidden {
+			builder.WriteString(" ")
+			layer.writeLocation(&builder, opts.funcNameStyle)
+		}
+
+		if layer.id != "" {
+			builder.WriteString(" [id=")
+			builder.WriteString(layer.id)
+			builder.WriteString("]")
+		}
+	}
+
+	return builder.String()
+}
+
+// depth returns how many *CTXError layers are stacked directly on each other
+// starting at this one.
+func (e *CTXError) depth() int {
+	depth := 1
+
+	for layer, ok := asCTXError(e.err); ok; layer, ok = asCTXError(layer.err) {
+		depth++
+	}
+
+	return depth
+}
+
+// textStackLayers is how many layers text keeps track of without allocating.
+const textStackLayers = 16
+
+// textSize returns how many bytes text needs for layers followed by the cause
+// message, if there's a cause. Line numbers are counted at their widest.
+func textSize(layers []*CTXError, hasCause bool, causeMessage string, opts textOptions) int {
+	const (
+		locationOverhead = len(" [: in ]") + 20 // 20 is the widest int64
+		idOverhead       = len(" [id=]")
+	)
+
+	size := len(opts.separator) * (len(layers) - 1)
+	if hasCause {
+		size += len(opts.separator) + len(causeMessage)
+	}
+
+	for _, layer := range layers {
+		size += len(layer.msg())
+
+		if !opts.hideLocation {
+			size += locationOverhead + len(layer.file) + len(layer.funcName)
+		}
+
+		if layer.id != "" {
+			size += idOverhead + len(layer.id)
+		}
+	}
+
+	return size
+}
+
+// causeText renders err with opts if it's a *CTXError, or with its own Error()
+// otherwise.
+func causeText(err error, opts textOptions) string {
+	if layer, ok := asCTXError(err); ok {
+		return layer.text(opts)
+	}
+
+	return err.Error()
+}
+
+// writeLocation writes the bracketed location suffix used by Error() to
+// builder, with the function name in style.
+func (e *CTXError) writeLocation(builder *strings.Builder, style FuncNameStyle) {
+	builder.WriteString("[")
+
+	if e.file != "" {
+		var digits [20]byte
+
+		builder.WriteString(e.file)
+		builder.WriteString(":")
+		builder.Write(strconv.AppendInt(digits[:0], int64(e.line), 10)) //nolint:mnd
+		builder.WriteString(" ")
+	}
+
+	// Function-only capture mode leaves just this
+	builder.WriteString("in ")
+	builder.WriteString(formatFuncName(e.funcName, style))
+	builder.WriteString("]")
+}
+
+// Message returns the context message of this layer only.
+func (e *CTXError) Message() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.msg()
+}
+
+// File returns the file where the error was created.
+func (e *CTXError) File() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.file
+}
+
+// Line returns the line where the error was created.
+func (e *CTXError) Line() int {
+	if e == nil {
+		return 0
+	}
+
+	return e.line
+}
+
+// FuncName returns the fully qualified name of the function where the error was created.
+func (e *CTXError) FuncName() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.funcName
+}
+
+// ID returns the unique instance ID of this layer, or an empty string if
+// instance IDs were off when it was created.
+func (e *CTXError) ID() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.id
+}
+
+// Time returns when this layer was created, or the zero time if SetTimestamps
+// was off then.
+func (e *CTXError) Time() time.Time {
+	if e == nil {
+		return time.Time{}
+	}
+
+	return e.created
+}
+
+// Fields returns a copy of the structured fields attached to this layer only,
+// with values rendered by RegisterFieldMarshaler marshalers and the values of
+// keys matching SetRedactedFields patterns redacted. WithDebugField fields are
+// left out.
+func (e *CTXError) Fields() map[string]any {
+	return e.renderedFields(false)
+}
+
+// renderedFields returns the fields of this layer like Fields describes,
+// including the WithDebugField ones with their values computed if debug is
+// set.
+func (e *CTXError) renderedFields(debug bool) map[string]any {
+	if e == nil || len(e.fields) == 0 {
+		return nil
+	}
+
+	cfg := currentConfig()
+	fields := maps.Clone(e.fields)
+
+	for key, value := range fields {
+		if lazy, ok := value.(*debugValue); ok {
+			if !debug {
+				delete(fields, key)
+
+				continue
+			}
+
+			fields[key] = lazy.get()
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if len(cfg.fieldMarshalers) > 0 {
+		for key, value := range fields {
+			fields[key] = cfg.marshalField(value)
+		}
+	}
+
+	redactFields(fields, cfg.redactedFields)
+
+	return fields
 }
 
 // getCallerInfo retrieves file, line, and function name where the error was created.
+// In CaptureFuncOnly mode only the function name is resolved.
 func getCallerInfo(skip int) (string, int, string) {
-	pc, file, line, ok := runtime.Caller(skip + 1)
-	if !ok {
+	cfg := currentConfig()
+
+	if cfg.captureMode == CaptureFuncOnly && cfg.resolver == nil {
+		// Skip runtime.Callers, getCallerInfo and getFuncName
+		return "", 0, getFuncName(skip + 3) //nolint:mnd
+	}
+
+	// runtime.Caller allocates on every call, this doesn't
+	var pcs [1]uintptr
+
+	// Skip runtime.Callers and getCallerInfo
+	if runtime.Callers(skip+2, pcs[:]) == 0 { //nolint:mnd
 		return "", 0, ""
 	}
 
-	funcName := runtime.FuncForPC(pc).Name()
+	// The PC is a return address, step back into the call instruction
+	frame := cfg.resolve(pcs[0] - 1)
+	if frame == (Frame{}) {
+		return "", 0, ""
+	}
+
+	if cfg.captureMode == CaptureFuncOnly {
+		return "", 0, frame.FuncName
+	}
+
+	file, line := cfg.mapLocation(frame.File, frame.Line)
+
+	return file, line, frame.FuncName
+}
+
+// resolve returns the frame of pc as the configured Resolver sees it.
+func (c *config) resolve(pc uintptr) Frame {
+	if c.resolver != nil {
+		return c.resolver.Resolve(pc)
+	}
+
+	return RuntimeResolver{}.Resolve(pc)
+}
+
+// normalizePath turns every backslash in path into a forward slash, whatever
+// the GOOS, since that's what log tooling downstream expects.
+func normalizePath(path string) string {
+	return strings.ReplaceAll(path, `\`, "/")
+}
+
+// getFuncName resolves only the function name of the frame at skip as counted
+// by runtime.Callers, without looking up its file and line.
+func getFuncName(skip int) string {
+	var pcs [1]uintptr
+	if runtime.Callers(skip, pcs[:]) == 0 {
+		return ""
+	}
+
+	// The PC is a return address, step back into the call instruction
+	fn := runtime.FuncForPC(pcs[0] - 1)
+	if fn == nil {
+		return ""
+	}
+
+	return fn.Name()
+}
+
+// withFields attaches fields to layer, one still being created, keeping
+// whatever it already has for keys not in fields.
+func withFields(layer *CTXError, fields map[string]any) {
+	if len(fields) == 0 {
+		return
+	}
+
+	if layer.fields == nil {
+		layer.fields = make(map[string]any, len(fields))
+	}
+
+	maps.Copy(layer.fields, fields)
+}
+
+// asCTXError reports whether err itself (not something further down its chain)
+// is a non-nil *CTXError.
+func asCTXError(err error) (*CTXError, bool) {
+	ctxErr, ok := err.(*CTXError) //nolint:errorlint
+	if !ok || ctxErr == nil {
+		return nil, false
+	}
 
-	return file, line, funcName
+	return ctxErr, true
 }
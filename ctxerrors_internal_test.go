@@ -312,3 +312,232 @@ func TestWrapf(t *testing.T) { //nolint:funlen
 		})
 	}
 }
+
+func TestAccessors(t *testing.T) {
+	t.Run("populated error", func(t *testing.T) {
+		err := &CTXError{
+			message:  "context message",
+			file:     "test.go",
+			line:     42,
+			funcName: "TestFunc",
+		}
+
+		require.Equal(t, "context message", err.Message())
+		require.Equal(t, "test.go", err.File())
+		require.Equal(t, 42, err.Line())
+		require.Equal(t, "TestFunc", err.FuncName())
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		var err *CTXError
+
+		require.Empty(t, err.Message())
+		require.Empty(t, err.File())
+		require.Zero(t, err.Line())
+		require.Empty(t, err.FuncName())
+	})
+}
+
+func TestNormalizePath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "already slashed",
+			path:     "/home/user/project/main.go",
+			expected: "/home/user/project/main.go",
+		},
+		{
+			name:     "windows path",
+			path:     `C:\Users\user\project\main.go`,
+			expected: "C:/Users/user/project/main.go",
+		},
+		{
+			name:     "mixed separators",
+			path:     `C:/Users\user/project\main.go`,
+			expected: "C:/Users/user/project/main.go",
+		},
+		{
+			name:     "empty path",
+			path:     "",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, normalizePath(tc.path))
+		})
+	}
+}
+
+func TestNewf(t *testing.T) {
+	refErr := errors.New("referenced error") //nolint:err113
+
+	testCases := []struct {
+		name            string
+		format          string
+		args            []any
+		expectedMessage string
+		expectedIs      []error
+	}{
+		{
+			name:            "plain formatting",
+			format:          "user %d not found",
+			args:            []any{42},
+			expectedMessage: "user 42 not found",
+		},
+		{
+			name:            "wrap verb",
+			format:          "lookup failed: %w",
+			args:            []any{refErr},
+			expectedMessage: "lookup failed: referenced error",
+			expectedIs:      []error{refErr},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := Newf(tc.format, tc.args...)
+
+			var actualErr *CTXError
+
+			require.True(t, errors.As(actual, &actualErr))
+			require.Equal(t, tc.expectedMessage, actualErr.Message())
+			require.Contains(t, actualErr.FuncName(), "TestNewf")
+			require.Nil(t, errors.Unwrap(actual))
+
+			for _, target := range tc.expectedIs {
+				require.ErrorIs(t, actual, target)
+			}
+		})
+	}
+}
+
+type refError struct{ code int }
+
+func (e *refError) Error() string { return fmt.Sprintf("ref error %d", e.code) }
+
+func TestWrapfWrapVerb(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+	firstRef := errors.New("first ref") //nolint:err113
+	secondRef := &refError{code: 7}
+	unrelated := errors.New("unrelated") //nolint:err113
+
+	actual := Wrapf(baseErr, "while handling %w and %w", firstRef, secondRef)
+
+	var actualErr *CTXError
+
+	require.True(t, errors.As(actual, &actualErr))
+	require.Equal(t, "while handling first ref and ref error 7", actualErr.Message())
+	require.Equal(t, baseErr, errors.Unwrap(actual))
+
+	require.ErrorIs(t, actual, baseErr)
+	require.ErrorIs(t, actual, firstRef)
+	require.ErrorIs(t, actual, secondRef)
+	require.NotErrorIs(t, actual, unrelated)
+
+	var target *refError
+
+	require.ErrorAs(t, Wrap(actual, "outer"), &target)
+	require.Equal(t, 7, target.code)
+
+	t.Run("nil reference", func(t *testing.T) {
+		actual := Wrapf(baseErr, "with %w", nil)
+
+		require.Contains(t, actual.Error(), "with %!w(<nil>)")
+		require.ErrorIs(t, actual, baseErr)
+	})
+}
+
+func TestWrapAllocations(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	// The CTXError itself is the only allocation allowed on the common path
+	testCases := []struct {
+		name string
+		fn   func()
+	}{
+		{
+			name: "new",
+			fn:   func() { _ = New("something went wrong") },
+		},
+		{
+			name: "wrap",
+			fn:   func() { _ = Wrap(baseErr, "additional context") },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.LessOrEqual(t, testing.AllocsPerRun(100, tc.fn), 1.0)
+		})
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = New("something went wrong")
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = Wrap(baseErr, "additional context")
+	}
+}
+
+func BenchmarkWrapf(b *testing.B) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = Wrapf(baseErr, "additional context %d", 42)
+	}
+}
+
+// deepChain wraps a plain error in depth layers.
+func deepChain(depth int) error {
+	err := errors.New("base error") //nolint:err113
+	for i := range depth {
+		err = Wrapf(err, "layer %d", i)
+	}
+
+	return err
+}
+
+func TestErrorAllocations(t *testing.T) {
+	for _, depth := range []int{1, 10, 50} {
+		t.Run(fmt.Sprintf("depth %d", depth), func(t *testing.T) {
+			err := deepChain(depth)
+
+			// The builder's buffer, sized once and handed over as the string,
+			// plus the layer list past what fits on the stack
+			expected := 1.0
+			if depth > textStackLayers {
+				expected = 2.0
+			}
+
+			require.LessOrEqual(t, testing.AllocsPerRun(100, func() { _ = err.Error() }), expected)
+		})
+	}
+}
+
+func BenchmarkErrorDeepChain(b *testing.B) {
+	err := deepChain(20)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = err.Error()
+	}
+}
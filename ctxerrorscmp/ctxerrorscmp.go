@@ -0,0 +1,100 @@
+// Package ctxerrorscmp provides google/go-cmp options for comparing values
+// that contain ctxerrors chains, so tests using cmp.Diff don't each need their
+// own transformer for *CTXError and its unexported fields.
+//
+// Every option here applies to values of any type implementing error and they
+// all decide how errors are compared, so pass only one of them to a single
+// cmp.Equal or cmp.Diff call.
+package ctxerrorscmp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Layer is one error of a chain as TransformChain presents it to cmp.
+type Layer struct {
+	Type     string         // Go type of the error, e.g. *ctxerrors.CTXError
+	Message  string         // Own message, without the layers below
+	File     string         // *CTXError only
+	Line     int            // *CTXError only
+	FuncName string         // *CTXError only
+	Fields   map[string]any // *CTXError only, redacted like CTXError.Fields
+	Joined   [][]Layer      // Chains of the members of a joined error
+}
+
+// TransformChain returns an option that turns every error into its chain of
+// Layers, outermost first, so cmp.Diff reports differences field by field and
+// layer by layer instead of giving up on unexported fields.
+func TransformChain() cmp.Option {
+	return cmp.Transformer("ctxerrors.Chain", Chain)
+}
+
+// IgnoreLocations returns an option that considers two errors equal if their
+// chains only differ in where their *CTXError layers were created, as
+// ctxerrors.Differ with IgnoreLocations sees it.
+func IgnoreLocations() cmp.Option {
+	return cmp.Comparer(func(x, y error) bool {
+		return ctxerrors.Differ{IgnoreLocations: true}.Diff(x, y) == ""
+	})
+}
+
+// CompareByMessage returns an option that considers two errors equal if their
+// chains have the same messages as ctxerrors.Messages returns them, ignoring
+// locations, fields and types.
+func CompareByMessage() cmp.Option {
+	return cmp.Comparer(func(x, y error) bool {
+		return slices.Equal(ctxerrors.Messages(x), ctxerrors.Messages(y))
+	})
+}
+
+// Chain returns the Layers of err's chain, outermost first, following Unwrap()
+// error and the members of errors joined with Unwrap() []error. It returns nil
+// if err is nil.
+func Chain(err error) []Layer {
+	var layers []Layer
+
+	for err != nil {
+		layer := Layer{Type: fmt.Sprintf("%T", err)}
+
+		if joined, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint
+			for _, member := range joined.Unwrap() {
+				layer.Joined = append(layer.Joined, Chain(member))
+			}
+
+			return append(layers, layer)
+		}
+
+		if ctxErr, ok := err.(*ctxerrors.CTXError); ok { //nolint:errorlint
+			layer.Message = ctxErr.Message()
+			layer.File = ctxErr.File()
+			layer.Line = ctxErr.Line()
+			layer.FuncName = ctxErr.FuncName()
+			layer.Fields = ctxErr.Fields()
+		} else {
+			layer.Message = ownMessage(err)
+		}
+
+		layers = append(layers, layer)
+		err = errors.Unwrap(err)
+	}
+
+	return layers
+}
+
+// ownMessage returns the text err adds on top of the error it wraps.
+func ownMessage(err error) string {
+	message := err.Error()
+
+	if inner := errors.Unwrap(err); inner != nil {
+		message = strings.TrimSuffix(message, ": "+inner.Error())
+	}
+
+	return message
+}
@@ -0,0 +1,92 @@
+package ctxerrorscmp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// result is a value containing an error, like tests usually compare.
+type result struct {
+	ID  int
+	Err error
+}
+
+func TestChain(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	t.Run("nil error", func(t *testing.T) {
+		require.Nil(t, Chain(nil))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		inner := ctxerrors.WithField(ctxerrors.Wrap(baseErr, "connect"), "host", "db")
+		err := ctxerrors.Wrap(fmt.Errorf("dial: %w", inner), "load user")
+
+		layers := Chain(err)
+
+		require.Len(t, layers, 4)
+		require.Equal(t, "*ctxerrors.CTXError", layers[0].Type)
+		require.Equal(t, "load user", layers[0].Message)
+		require.Equal(t, "github.com/psyb0t/ctxerrors/ctxerrorscmp.TestChain.func2", layers[0].FuncName)
+		require.NotZero(t, layers[0].Line)
+		require.Equal(t, Layer{Type: "*fmt.wrapError", Message: "dial"}, layers[1])
+		require.Equal(t, map[string]any{"host": "db"}, layers[2].Fields)
+		require.Equal(t, Layer{Type: "*errors.errorString", Message: "connection refused"}, layers[3])
+	})
+
+	t.Run("joined errors", func(t *testing.T) {
+		layers := Chain(errors.Join(baseErr, fmt.Errorf("dial: %w", baseErr)))
+
+		require.Len(t, layers, 1)
+		require.Equal(t, [][]Layer{
+			{{Type: "*errors.errorString", Message: "connection refused"}},
+			{{Type: "*fmt.wrapError", Message: "dial"}, {Type: "*errors.errorString", Message: "connection refused"}},
+		}, layers[0].Joined)
+	})
+}
+
+func TestOptions(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	first := result{ID: 1, Err: ctxerrors.Wrap(baseErr, "load user")}
+	second := result{ID: 1, Err: ctxerrors.Wrap(baseErr, "load user")}
+	otherMessage := result{ID: 1, Err: ctxerrors.Wrap(baseErr, "load users")}
+	otherField := result{ID: 1, Err: ctxerrors.WithKind(second.Err, ctxerrors.KindUnavailable)}
+
+	testCases := []struct {
+		name     string
+		option   cmp.Option
+		x, y     result
+		expected bool
+	}{
+		{name: "transform, same error", option: TransformChain(), x: first, y: first, expected: true},
+		{name: "transform, other location", option: TransformChain(), x: first, y: second, expected: false},
+		{name: "ignore locations, other location", option: IgnoreLocations(), x: first, y: second, expected: true},
+		{name: "ignore locations, other message", option: IgnoreLocations(), x: first, y: otherMessage, expected: false},
+		{name: "ignore locations, other field", option: IgnoreLocations(), x: first, y: otherField, expected: false},
+		{name: "by message, other field", option: CompareByMessage(), x: first, y: otherField, expected: true},
+		{name: "by message, other message", option: CompareByMessage(), x: first, y: otherMessage, expected: false},
+		{name: "by message, nil errors", option: CompareByMessage(), x: result{}, y: result{}, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, cmp.Equal(tc.x, tc.y, tc.option))
+		})
+	}
+
+	t.Run("transform reports the differing field", func(t *testing.T) {
+		diff := cmp.Diff(first, otherMessage, TransformChain())
+
+		// cmp's output isn't stable enough to compare in full
+		require.Contains(t, diff, "ctxerrors.Chain")
+		require.Contains(t, diff, `"load user"`)
+		require.Contains(t, diff, `"load users"`)
+	})
+}
@@ -0,0 +1,103 @@
+// Package ctxerrorstest builds ctxerrors chains with controlled locations,
+// IDs and fields for testing code that consumes them, such as log formatters
+// and reporters, without the expected output depending on where the test
+// happens to create its errors.
+package ctxerrorstest
+
+import "github.com/psyb0t/ctxerrors"
+
+// Location used by Fake unless At says otherwise.
+const (
+	DefaultFile     = "fake.go"
+	DefaultLine     = 1
+	DefaultFuncName = "fake.Func"
+)
+
+// fake is what an error built by Fake is made of.
+type fake struct {
+	err      error
+	file     string
+	line     int
+	funcName string
+	id       string
+	fields   map[string]any
+}
+
+// Option customizes an error built by Fake.
+type Option func(f *fake)
+
+// At sets the location of the error.
+func At(file string, line int, funcName string) Option {
+	return func(f *fake) {
+		f.file = file
+		f.line = line
+		f.funcName = funcName
+	}
+}
+
+// Wrapping makes the error wrap err.
+func Wrapping(err error) Option {
+	return func(f *fake) {
+		f.err = err
+	}
+}
+
+// WithID sets the instance ID of the error.
+func WithID(id string) Option {
+	return func(f *fake) {
+		f.id = id
+	}
+}
+
+// WithField sets the field key of the error to value.
+func WithField(key string, value any) Option {
+	return func(f *fake) {
+		if f.fields == nil {
+			f.fields = make(map[string]any)
+		}
+
+		f.fields[key] = value
+	}
+}
+
+// WithKind classifies the error as kind.
+func WithKind(kind ctxerrors.Kind) Option {
+	return WithField(ctxerrors.FieldKind, kind)
+}
+
+// Fake returns a *ctxerrors.CTXError with message, located at DefaultFile,
+// DefaultLine and DefaultFuncName unless At says otherwise. It's built with
+// ctxerrors.NewAt or ctxerrors.WrapAt, so no callers are captured but the
+// location goes through source mapping and enrich hooks run like they do for
+// any other error.
+func Fake(message string, opts ...Option) error {
+	f := fake{
+		err:      nil,
+		file:     DefaultFile,
+		line:     DefaultLine,
+		funcName: DefaultFuncName,
+		id:       "",
+		fields:   nil,
+	}
+
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	var err error
+	if f.err != nil {
+		err = ctxerrors.WrapAt(f.err, message, f.file, f.line, f.funcName)
+	} else {
+		err = ctxerrors.NewAt(message, f.file, f.line, f.funcName)
+	}
+
+	for key, value := range f.fields {
+		err = ctxerrors.WithField(err, key, value)
+	}
+
+	if f.id != "" {
+		err = ctxerrors.WithInstanceID(err, f.id)
+	}
+
+	return err
+}
@@ -0,0 +1,51 @@
+package ctxerrorstest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestFake(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		err := Fake("something went wrong")
+
+		require.EqualError(t, err, "something went wrong [fake.go:1 in fake.Func]")
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		baseErr := errors.New("connection refused") //nolint:err113
+
+		err := Fake("create user",
+			At("svc/user.go", 42, "svc.Create"),
+			WithID("abc-1"),
+			WithKind(ctxerrors.KindUnavailable),
+			WithField("user_id", 7),
+			Wrapping(Fake("connect", At("db/conn.go", 12, "db.Connect"), Wrapping(baseErr))),
+		)
+
+		require.EqualError(t, err,
+			"create user: connect: connection refused [db/conn.go:12 in db.Connect] [svc/user.go:42 in svc.Create] [id=abc-1]")
+		require.ErrorIs(t, err, baseErr)
+		require.Equal(t, ctxerrors.KindUnavailable, ctxerrors.KindOf(err))
+		require.Equal(t, []string{"create user", "connect", "connection refused"}, ctxerrors.Messages(err))
+
+		var layer *ctxerrors.CTXError
+
+		require.ErrorAs(t, err, &layer)
+		require.Equal(t, "abc-1", layer.ID())
+		require.Equal(t, map[string]any{ctxerrors.FieldKind: ctxerrors.KindUnavailable, "user_id": 7}, layer.Fields())
+	})
+
+	t.Run("runs enrich hooks like NewAt", func(t *testing.T) {
+		unregister := ctxerrors.RegisterEnrichHook(ctxerrors.EnrichHookFunc(func(*ctxerrors.CTXError) map[string]any {
+			return map[string]any{"hooked": true}
+		}))
+		t.Cleanup(unregister)
+
+		require.Equal(t, map[string]any{"hooked": true, "user_id": 7}, ctxerrors.Fields(Fake("hooked", WithField("user_id", 7))))
+	})
+}
@@ -0,0 +1,95 @@
+// Package datadog maps ctxerrors chains to Datadog's standard error attributes
+// so logs and traces shipped to Datadog get picked up by Error Tracking.
+package datadog
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Datadog's standard error attribute names.
+const (
+	AttributeMessage = "error.message"
+	AttributeKind    = "error.kind"
+	AttributeStack   = "error.stack"
+)
+
+// Attributes returns the standard Datadog error attributes for err:
+// error.message is err.Error(), error.kind is the Go type of the root cause,
+// since the type of the outer wrapper would lump every error together, and
+// error.stack lists the location of every *CTXError layer, origin first, in
+// the same layout as a Go stack trace. It returns nil for a nil error.
+func Attributes(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+
+	return map[string]string{
+		AttributeMessage: err.Error(),
+		AttributeKind:    kind(err),
+		AttributeStack:   stack(err),
+	}
+}
+
+// LogAttr returns the same attributes as an "error" group for slog, which
+// Datadog reads as error.message, error.kind and error.stack from JSON logs.
+func LogAttr(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+
+	return slog.Group("error",
+		slog.String("message", err.Error()),
+		slog.String("kind", kind(err)),
+		slog.String("stack", stack(err)),
+	)
+}
+
+// kind returns the Go type of the innermost error in err's chain.
+func kind(err error) string {
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			return fmt.Sprintf("%T", err)
+		}
+
+		err = inner
+	}
+}
+
+// stack renders the locations of the *CTXError layers in err's chain,
+// innermost first.
+func stack(err error) string {
+	var frames []string
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		layer, ok := err.(*ctxerrors.CTXError) //nolint:errorlint
+		if !ok || layer == nil {
+			continue
+		}
+
+		frame := layer.FuncName()
+		if layer.File() != "" {
+			frame += fmt.Sprintf("\n\t%s:%d", layer.File(), layer.Line())
+		}
+
+		frames = append(frames, frame)
+	}
+
+	// Outermost layers were collected first, a stack trace starts at the origin
+	var b strings.Builder
+
+	for i := len(frames) - 1; i >= 0; i-- {
+		b.WriteString(frames[i])
+
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+	}
+
+	return b.String()
+}
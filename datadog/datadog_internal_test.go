@@ -0,0 +1,65 @@
+package datadog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestAttributes(t *testing.T) {
+	t.Run("nil error", func(t *testing.T) {
+		require.Nil(t, Attributes(nil))
+		require.Equal(t, slog.Attr{}, LogAttr(nil))
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		attrs := Attributes(errors.New("boom")) //nolint:err113
+
+		require.Equal(t, "boom", attrs[AttributeMessage])
+		require.Equal(t, "*errors.errorString", attrs[AttributeKind])
+		require.Empty(t, attrs[AttributeStack])
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		baseErr := errors.New("connection refused") //nolint:err113
+		inner := ctxerrors.Wrap(baseErr, "dial")
+		err := ctxerrors.Wrap(fmt.Errorf("foreign: %w", inner), "load user")
+
+		attrs := Attributes(err)
+		require.Equal(t, err.Error(), attrs[AttributeMessage])
+		require.Equal(t, "*errors.errorString", attrs[AttributeKind])
+
+		stackRegexp := regexp.MustCompile(
+			`^github\.com/psyb0t/ctxerrors/datadog\.TestAttributes\.func3\n\t.+/datadog_internal_test\.go:\d+\n` +
+				`github\.com/psyb0t/ctxerrors/datadog\.TestAttributes\.func3\n\t.+/datadog_internal_test\.go:\d+$`,
+		)
+		require.Regexp(t, stackRegexp, attrs[AttributeStack])
+	})
+}
+
+func TestLogAttr(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	err := ctxerrors.New("boom")
+
+	logger.Error("request failed", LogAttr(err))
+
+	var record map[string]any
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	group, ok := record["error"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, err.Error(), group["message"])
+	require.Equal(t, "*ctxerrors.CTXError", group["kind"])
+	require.Contains(t, group["stack"], "TestLogAttr")
+}
@@ -0,0 +1,31 @@
+package ctxerrors
+
+import (
+	"context"
+	"time"
+)
+
+// FieldDeadlineRemaining is the field key NewCtx and WrapCtx record how long
+// the context had left until its deadline under.
+const FieldDeadlineRemaining = "deadline_remaining"
+
+// deadlineRemaining returns how long ctx has left until its deadline, by the
+// SetClock clock, negative if it already passed, and false if it has none.
+func deadlineRemaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+
+	return deadline.Sub(Now()), true
+}
+
+// DeadlineRemaining returns how long the context err was created under had
+// left until its deadline, negative for how long ago it passed, as recorded
+// by the outermost layer with FieldDeadlineRemaining in its chain (see
+// SetFieldPrecedence). Failing with 2ms left and failing 30s after the
+// deadline call for very different fixes; with StartOp's Elapsed next to it,
+// it also says how big the budget was.
+func DeadlineRemaining(err error) (time.Duration, bool) {
+	return lookupField[time.Duration](err, FieldDeadlineRemaining)
+}
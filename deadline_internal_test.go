@@ -0,0 +1,66 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlineRemaining(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	SetClock(func() time.Time { return now })
+	t.Cleanup(func() { SetClock(nil) })
+
+	baseErr := errors.New("connection reset") //nolint:err113
+
+	withDeadline := func(offset time.Duration) context.Context {
+		ctx, cancel := context.WithDeadline(context.Background(), now.Add(offset))
+		t.Cleanup(cancel)
+
+		return ctx
+	}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected time.Duration
+		ok       bool
+	}{
+		{
+			name:     "time left",
+			err:      WrapCtx(withDeadline(2*time.Millisecond), baseErr, "query failed"),
+			expected: 2 * time.Millisecond,
+			ok:       true,
+		},
+		{
+			name:     "deadline passed",
+			err:      NewCtx(withDeadline(-30*time.Second), "query failed"),
+			expected: -30 * time.Second,
+			ok:       true,
+		},
+		{
+			name:     "outermost wins",
+			err:      WrapCtx(withDeadline(time.Second), WrapCtx(withDeadline(time.Minute), baseErr, "a"), "b"),
+			expected: time.Second,
+			ok:       true,
+		},
+		{
+			name:     "no deadline",
+			err:      WrapCtx(context.Background(), baseErr, "query failed"),
+			expected: 0,
+			ok:       false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			remaining, ok := DeadlineRemaining(tc.err)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, remaining)
+		})
+	}
+}
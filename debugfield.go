@@ -0,0 +1,66 @@
+package ctxerrors
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// debugValue is the value of a field WithDebugField attached, computed the
+// first time it's asked for.
+type debugValue struct {
+	once    sync.Once
+	compute func() any
+	value   any
+}
+
+// get returns the value, computing it on the first call.
+func (v *debugValue) get() any {
+	v.once.Do(func() {
+		v.value = callDebugValue(v.compute)
+	})
+
+	return v.value
+}
+
+// callDebugValue calls compute, recovering from any panic in it like a
+// panicking field marshaler, in which case the value is nil.
+func callDebugValue(compute func() any) (value any) { //nolint:nonamedreturns
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Debug field panicked", "panic", r)
+			countActivity(&activity.hookFailures)
+
+			value = nil
+		}
+	}()
+
+	return compute()
+}
+
+// WithDebugField returns err with key set to what compute returns, like
+// WithField does, for payloads too expensive to build for every error, e.g.
+// request dumps. compute is only called, once, when the fields are asked for
+// at debug verbosity with DebugFields, FieldsFor or a Marshaler with Debug
+// set. Fields, Marshal and the formatters leave the field out.
+func WithDebugField(err error, key string, compute func() any) error {
+	// Skip WithDebugField(), annotate() and wrap() to get user's caller
+	framesToSkip := 3
+
+	return annotate(err, map[string]any{key: &debugValue{compute: compute}}, framesToSkip)
+}
+
+// DebugFields is like Fields but includes the fields WithDebugField attached,
+// computing them if they haven't been yet.
+func DebugFields(err error) map[string]any {
+	return mergedFields(err, true)
+}
+
+// FieldsFor returns the DebugFields of err if handler is enabled for
+// slog.LevelDebug under ctx and its Fields otherwise, so debug payloads are
+// only built when they end up in the log:
+//
+//	logger.ErrorContext(ctx, "request failed", "fields", ctxerrors.FieldsFor(ctx, err, logger.Handler()))
+func FieldsFor(ctx context.Context, err error, handler slog.Handler) map[string]any {
+	return mergedFields(err, handler.Enabled(ctx, slog.LevelDebug))
+}
@@ -0,0 +1,87 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDebugField(t *testing.T) {
+	calls := 0
+	dump := func() any {
+		calls++
+
+		return "GET /users/42 HTTP/1.1"
+	}
+
+	err := WithField(WithDebugField(Wrap(io.EOF, "read body"), "request_dump", dump), "user_id", 42)
+
+	require.Equal(t, map[string]any{"user_id": 42}, Fields(err))
+	require.Empty(t, AllValues(err, "request_dump"))
+
+	var layer *CTXError
+
+	require.True(t, errors.As(err, &layer))
+	require.Equal(t, map[string]any{"user_id": 42}, layer.Fields())
+
+	data, marshalErr := Marshal(err)
+	require.NoError(t, marshalErr)
+	require.NotContains(t, string(data), "request_dump")
+	require.Zero(t, calls)
+
+	expected := map[string]any{"user_id": 42, "request_dump": "GET /users/42 HTTP/1.1"}
+
+	require.Equal(t, expected, DebugFields(err))
+	require.Equal(t, expected, DebugFields(err))
+	require.Equal(t, 1, calls)
+
+	t.Run("only field", func(t *testing.T) {
+		var layer *CTXError
+
+		require.True(t, errors.As(WithDebugField(New("boom"), "dump", dump), &layer))
+		require.Nil(t, layer.Fields())
+	})
+
+	t.Run("panicking", func(t *testing.T) {
+		err := WithDebugField(New("boom"), "dump", func() any { panic("dump blew up") })
+
+		require.Equal(t, map[string]any{"dump": nil}, DebugFields(err))
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WithDebugField(nil, "dump", dump))
+	})
+}
+
+func TestFieldsFor(t *testing.T) {
+	err := WithDebugField(New("boom"), "dump", func() any { return "payload" })
+
+	testCases := []struct {
+		name     string
+		level    slog.Level
+		expected map[string]any
+	}{
+		{
+			name:     "debug",
+			level:    slog.LevelDebug,
+			expected: map[string]any{"dump": "payload"},
+		},
+		{
+			name:     "info",
+			level:    slog.LevelInfo,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: tc.level})
+
+			require.Equal(t, tc.expected, FieldsFor(context.Background(), err, handler))
+		})
+	}
+}
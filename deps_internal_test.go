@@ -0,0 +1,76 @@
+package ctxerrors
+
+import (
+	"errors"
+	"go/build"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const modulePath = "github.com/psyb0t/ctxerrors"
+
+// thirdPartyImporters are the packages of this module allowed to import other
+// modules, each of them only the ones listed.
+var thirdPartyImporters = map[string][]string{ //nolint:gochecknoglobals
+	"ctxerrorscmp": {"github.com/google/go-cmp/"},
+}
+
+// TestStdlibOnly keeps the packages of this module free of third-party imports
+// but for thirdPartyImporters, so importing the core never builds anything
+// else. Anything needing more than that goes in a module of its own like
+// tools.
+func TestStdlibOnly(t *testing.T) {
+	err := filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if d.Name() == "testdata" || strings.HasPrefix(d.Name(), ".") && path != "." {
+			return filepath.SkipDir
+		}
+
+		if path != "." {
+			if _, statErr := os.Stat(filepath.Join(path, "go.mod")); statErr == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		pkg, importErr := build.ImportDir(path, 0)
+		if importErr != nil {
+			var noGo *build.NoGoError
+			if errors.As(importErr, &noGo) {
+				return nil
+			}
+
+			return importErr
+		}
+
+		for _, imp := range pkg.Imports {
+			if imp == modulePath || strings.HasPrefix(imp, modulePath+"/") {
+				continue
+			}
+
+			if slices.ContainsFunc(thirdPartyImporters[filepath.ToSlash(path)], func(prefix string) bool {
+				return strings.HasPrefix(imp, prefix)
+			}) {
+				continue
+			}
+
+			first, _, _ := strings.Cut(imp, "/")
+			require.NotContains(t, first, ".", "%s imports %s", path, imp)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+}
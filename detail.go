@@ -0,0 +1,133 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format implements fmt.Formatter. %s and %v print Error(), %q prints it
+// quoted and %+v prints the chain in the detail layout of golang.org/x/xerrors,
+// every layer's message followed by its function and location, and by the
+// frames above it if SetCallerDepth asked for any, with their AnnotateFrame
+// notes, the Origin LinkOrigin attached and the go statements
+// SetGoroutineAncestry recorded, if any, and a line marking a
+// WrapStackBoundary layer:
+//
+//	failed to load config:
+//	    main.loadConfig
+//	        /app/config.go:42 (while reloading on SIGHUP)
+//	  - open /etc/app.yaml: no such file or directory
+//
+// Errors in the chain that aren't *CTXError are printed with %+v too, so
+// whatever detail they carry themselves shows up as well.
+func (e *CTXError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = io.WriteString(s, e.detail())
+	case verb == 'q':
+		_, _ = io.WriteString(s, strconv.Quote(e.Error()))
+	default:
+		_, _ = io.WriteString(s, e.Error())
+	}
+}
+
+// detail renders the %+v layout of this layer and everything below it.
+func (e *CTXError) detail() string {
+	if e == nil {
+		return "<nil>"
+	}
+
+	style := currentConfig().funcNameStyle
+
+	var builder strings.Builder
+
+	for layer := e; ; {
+		builder.WriteString(escapeMessage(layer.msg()))
+		builder.WriteString(":\n    ")
+		builder.WriteString(formatFuncName(layer.funcName, style))
+
+		if layer.file != "" {
+			writeFileLine(&builder, layer.file, layer.line)
+		}
+
+		writeFrameNote(&builder, layer.notes[0])
+
+		for i, frame := range layer.Callers() {
+			builder.WriteString("\n    ")
+			builder.WriteString(formatFuncName(frame.FuncName, style))
+			writeFileLine(&builder, frame.File, frame.Line)
+			writeFrameNote(&builder, layer.notes[i+1])
+		}
+
+		if len(layer.origin) > 0 {
+			builder.WriteString("\n    submitted from:")
+
+			for _, frame := range layer.origin {
+				builder.WriteString("\n    ")
+				builder.WriteString(formatFuncName(frame.FuncName, style))
+				writeFileLine(&builder, frame.File, frame.Line)
+			}
+		}
+
+		if layer.ancestry != nil {
+			builder.WriteString("\n    started by:")
+
+			for _, spawn := range layer.ancestry.spawns {
+				builder.WriteString("\n    ")
+				builder.WriteString(formatFuncName(spawn.FuncName, style))
+
+				if spawn.Goroutine != 0 {
+					builder.WriteString(" in goroutine ")
+					builder.WriteString(strconv.FormatUint(spawn.Goroutine, 10))
+				}
+
+				if spawn.File != "" {
+					writeFileLine(&builder, spawn.File, spawn.Line)
+				}
+			}
+		}
+
+		if layer.err == nil {
+			break
+		}
+
+		if layer.boundary {
+			builder.WriteString("\n    (library internals below)")
+		}
+
+		builder.WriteString("\n  - ")
+
+		next, ok := asCTXError(layer.err)
+		if !ok {
+			builder.WriteString(fmt.Sprintf("%+v", layer.err))
+
+			break
+		}
+
+		layer = next
+	}
+
+	return builder.String()
+}
+
+// writeFileLine writes the indented file:line line of a frame in the %+v layout.
+func writeFileLine(builder *strings.Builder, file string, line int) {
+	builder.WriteString("\n        ")
+	builder.WriteString(file)
+	builder.WriteString(":")
+	builder.WriteString(strconv.Itoa(line))
+}
+
+// writeFrameNote writes note after the file:line of a frame in the %+v layout,
+// if there is one.
+func writeFrameNote(builder *strings.Builder, note string) {
+	if note == "" {
+		return
+	}
+
+	builder.WriteString(" (")
+	builder.WriteString(escapeMessage(note))
+	builder.WriteString(")")
+}
@@ -0,0 +1,95 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) { //nolint:funlen
+	baseErr := errors.New("no such file") //nolint:err113
+
+	inner := &CTXError{
+		err:      baseErr,
+		message:  "open config",
+		file:     "/app/config.go",
+		line:     12,
+		funcName: "main.openConfig",
+	}
+
+	outer := &CTXError{
+		err:      inner,
+		message:  "failed to load config",
+		file:     "/app/main.go",
+		line:     42,
+		funcName: "main.loadConfig",
+	}
+
+	testCases := []struct {
+		name     string
+		format   string
+		err      error
+		expected string
+	}{
+		{
+			name:     "v",
+			format:   "%v",
+			err:      outer,
+			expected: outer.Error(),
+		},
+		{
+			name:     "s",
+			format:   "%s",
+			err:      outer,
+			expected: outer.Error(),
+		},
+		{
+			name:     "q",
+			format:   "%q",
+			err:      inner,
+			expected: `"open config: no such file [/app/config.go:12 in main.openConfig]"`,
+		},
+		{
+			name:   "detail",
+			format: "%+v",
+			err:    outer,
+			expected: "failed to load config:\n" +
+				"    main.loadConfig\n" +
+				"        /app/main.go:42\n" +
+				"  - open config:\n" +
+				"    main.openConfig\n" +
+				"        /app/config.go:12\n" +
+				"  - no such file",
+		},
+		{
+			name:   "detail without file",
+			format: "%+v",
+			err:    &CTXError{message: "standalone", funcName: "main.run"},
+			expected: "standalone:\n" +
+				"    main.run",
+		},
+		{
+			name:     "detail of nil layer",
+			format:   "%+v",
+			err:      (*CTXError)(nil),
+			expected: "<nil>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, fmt.Sprintf(tc.format, tc.err))
+		})
+	}
+
+	t.Run("detail of captured error", func(t *testing.T) {
+		actual := fmt.Sprintf("%+v", Wrap(New("root"), "outer"))
+
+		require.Contains(t, actual, "outer:\n    ")
+		require.Contains(t, actual, "TestFormat")
+		require.Contains(t, actual, "\n  - root:\n    ")
+		require.Contains(t, actual, goFileExtension)
+	})
+}
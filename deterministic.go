@@ -0,0 +1,83 @@
+package ctxerrors
+
+import (
+	"go/build"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Placeholders used in deterministic mode, see SetDeterministic.
+const (
+	DeterministicIDPrefix = "00000000"
+	DeterministicDump     = "[goroutine dump]\n"
+	DeterministicGOROOT   = "$GOROOT"
+)
+
+// deterministicIDs counts the IDs handed out since SetDeterministic.
+var deterministicIDs atomic.Uint64 //nolint:gochecknoglobals
+
+// deterministicTimes counts the creation times stamped since SetDeterministic.
+var deterministicTimes atomic.Int64 //nolint:gochecknoglobals
+
+// TestingT is the part of testing.TB the test helpers use.
+type TestingT interface {
+	Helper()
+	Cleanup(f func())
+	Errorf(format string, args ...any)
+}
+
+// SetDeterministic makes the rest of t produce errors that render the same on
+// every machine and Go version, so assertions on Error() and %+v output and
+// golden files don't flake:
+//
+//   - captured files are reduced to their base name, and files of the standard
+//     library become $GOROOT/src/... with line 0
+//   - instance IDs, if turned on, become DeterministicIDPrefix followed by a
+//     counter starting over at 1
+//   - goroutine dumps become DeterministicDump
+//   - creation times, if SetTimestamps turned them on, become the Unix epoch in
+//     UTC plus one second per error created, so they still come in order
+//
+// The previous behavior is restored when t finishes. Like the other settings
+// it's package-wide, so don't combine it with t.Parallel.
+func SetDeterministic(t TestingT) {
+	t.Helper()
+
+	deterministicIDs.Store(0)
+	deterministicTimes.Store(0)
+
+	previous := currentConfig().deterministic
+
+	updateConfig(func(c *config) {
+		c.deterministic = true
+	})
+
+	t.Cleanup(func() {
+		updateConfig(func(c *config) {
+			c.deterministic = previous
+		})
+	})
+}
+
+// deterministicTime returns the next placeholder creation time in
+// deterministic mode.
+func deterministicTime() time.Time {
+	return time.Unix(deterministicTimes.Add(1), 0).UTC()
+}
+
+// deterministicLocation returns the placeholder location for file and line in
+// deterministic mode. file has forward slashes.
+func deterministicLocation(file string, line int) (string, int) {
+	if file == "" {
+		return "", line
+	}
+
+	goroot := strings.TrimSuffix(normalizePath(build.Default.GOROOT), "/") + "/"
+	if build.Default.GOROOT != "" && strings.HasPrefix(file, goroot) {
+		return DeterministicGOROOT + "/" + strings.TrimPrefix(file, goroot), 0
+	}
+
+	return path.Base(file), line
+}
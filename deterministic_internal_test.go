@@ -0,0 +1,132 @@
+package ctxerrors
+
+import (
+	"errors"
+	"go/build"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDeterministic(t *testing.T) {
+	t.Run("locations", func(t *testing.T) {
+		SetDeterministic(t)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(NewDepth(2, "something went wrong"), &ctxErr))
+		require.Equal(t, "deterministic_internal_test.go", ctxErr.File())
+		require.NotZero(t, ctxErr.Line())
+
+		callers := ctxErr.Callers()
+		require.Len(t, callers, 2)
+		require.Equal(t, Frame{File: "$GOROOT/src/testing/testing.go", Line: 0, FuncName: "testing.tRunner"}, callers[0])
+	})
+
+	t.Run("instance IDs", func(t *testing.T) {
+		SetInstanceIDs(true)
+		t.Cleanup(func() { SetInstanceIDs(false) })
+
+		SetDeterministic(t)
+
+		require.Equal(t, DeterministicIDPrefix+"-1", New("first").(*CTXError).ID())  //nolint:errorlint,forcetypeassert
+		require.Equal(t, DeterministicIDPrefix+"-2", New("second").(*CTXError).ID()) //nolint:errorlint,forcetypeassert
+
+		SetDeterministic(t)
+
+		require.Equal(t, DeterministicIDPrefix+"-1", New("again").(*CTXError).ID()) //nolint:errorlint,forcetypeassert
+	})
+
+	t.Run("timestamps", func(t *testing.T) {
+		SetTimestamps(true)
+		t.Cleanup(func() { SetTimestamps(false) })
+
+		SetDeterministic(t)
+
+		first := New("first")
+		second := Wrap(first, "second")
+
+		require.Equal(t, time.Unix(1, 0).UTC(), first.(*CTXError).Time())  //nolint:errorlint,forcetypeassert
+		require.Equal(t, time.Unix(2, 0).UTC(), second.(*CTXError).Time()) //nolint:errorlint,forcetypeassert
+		require.Equal(t, time.Unix(1, 0).UTC(), Records(second)[1].Time)
+	})
+
+	t.Run("debug dumps", func(t *testing.T) {
+		SetDebugDump(func(*CTXError) bool { return true })
+		t.Cleanup(func() { SetDebugDump(nil) })
+
+		SetDeterministic(t)
+
+		require.Equal(t, DeterministicDump, DebugDump(New("dumped")))
+		require.Equal(t, DeterministicDump, DebugDump(WrapDebugDump(errors.New("base error"), "dumped"))) //nolint:err113
+	})
+
+	t.Run("restored on cleanup", func(t *testing.T) {
+		t.Run("deterministic", func(t *testing.T) {
+			SetDeterministic(t)
+			require.True(t, currentConfig().deterministic)
+		})
+
+		require.False(t, currentConfig().deterministic)
+
+		_, expectedFile, _, ok := runtime.Caller(0)
+		require.True(t, ok)
+
+		require.Equal(t, filepath.ToSlash(expectedFile), New("as captured").(*CTXError).File()) //nolint:errorlint,forcetypeassert
+	})
+
+	t.Run("nested calls keep the outer one", func(t *testing.T) {
+		SetDeterministic(t)
+
+		t.Run("inner", func(t *testing.T) {
+			SetDeterministic(t)
+		})
+
+		require.True(t, currentConfig().deterministic)
+	})
+}
+
+func TestDeterministicLocation(t *testing.T) {
+	goroot := filepath.ToSlash(build.Default.GOROOT)
+
+	testCases := []struct {
+		name         string
+		file         string
+		line         int
+		expectedFile string
+		expectedLine int
+	}{
+		{
+			name:         "empty",
+			file:         "",
+			line:         0,
+			expectedFile: "",
+			expectedLine: 0,
+		},
+		{
+			name:         "user file",
+			file:         "/home/someone/src/app/main.go",
+			line:         42,
+			expectedFile: "main.go",
+			expectedLine: 42,
+		},
+		{
+			name:         "standard library",
+			file:         goroot + "/src/net/http/server.go",
+			line:         2294,
+			expectedFile: "$GOROOT/src/net/http/server.go",
+			expectedLine: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, line := deterministicLocation(tc.file, tc.line)
+			require.Equal(t, tc.expectedFile, file)
+			require.Equal(t, tc.expectedLine, line)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Differ compares error chains like Diff does but with its own settings.
+type Differ struct {
+	// IgnoreLocations leaves file, line and function out of the comparison,
+	// for expected errors built somewhere other than where the actual ones
+	// are created.
+	IgnoreLocations bool
+}
+
+// Diff returns a human-readable list of the differences between the chains of
+// expected and actual, or an empty string if there are none, to make failing
+// error assertions say what differed instead of dumping two long strings.
+// The chains are compared layer by layer, including layers inside joined
+// errors: the message, the fields (kinds included) and the location of every
+// *CTXError, and the type and own message of every other error. Field values,
+// WithDebugField ones computed and included, are compared with
+// reflect.DeepEqual and redacted like Fields does.
+func Diff(expected, actual error) string {
+	return Differ{}.Diff(expected, actual)
+}
+
+// Diff compares expected and actual like the package-level Diff does.
+func (d Differ) Diff(expected, actual error) string {
+	expectedLayers := diffLayers(expected)
+	actualLayers := diffLayers(actual)
+
+	var builder strings.Builder
+
+	for i := range max(len(expectedLayers), len(actualLayers)) {
+		prefix := "layer " + strconv.Itoa(i)
+
+		switch {
+		case i >= len(actualLayers):
+			fmt.Fprintf(&builder, "%s: missing, expected %s\n", prefix, expectedLayers[i].summary())
+		case i >= len(expectedLayers):
+			fmt.Fprintf(&builder, "%s: unexpected %s\n", prefix, actualLayers[i].summary())
+		default:
+			d.diffLayer(&builder, prefix, expectedLayers[i], actualLayers[i])
+		}
+	}
+
+	return builder.String()
+}
+
+// diffLayer writes the differences between expected and actual to builder.
+func (d Differ) diffLayer(builder *strings.Builder, prefix string, expected, actual diffLayer) {
+	if expected.typ != actual.typ {
+		fmt.Fprintf(builder, "%s type: expected %s, got %s\n", prefix, expected.typ, actual.typ)
+
+		return
+	}
+
+	if expected.message != actual.message {
+		fmt.Fprintf(builder, "%s message: expected %q, got %q\n", prefix, expected.message, actual.message)
+	}
+
+	if !d.IgnoreLocations && expected.location != actual.location {
+		fmt.Fprintf(builder, "%s location: expected %s, got %s\n", prefix, expected.location, actual.location)
+	}
+
+	keys := slices.Sorted(maps.Keys(expected.fields))
+	for key := range actual.fields {
+		if _, ok := expected.fields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		expectedValue, inExpected := expected.fields[key]
+		actualValue, inActual := actual.fields[key]
+
+		switch {
+		case !inActual:
+			fmt.Fprintf(builder, "%s field %q: expected %#v, got nothing\n", prefix, key, expectedValue)
+		case !inExpected:
+			fmt.Fprintf(builder, "%s field %q: expected nothing, got %#v\n", prefix, key, actualValue)
+		case reflect.TypeOf(expectedValue) != reflect.TypeOf(actualValue):
+			fmt.Fprintf(builder, "%s field %q: expected %#v (%T), got %#v (%T)\n",
+				prefix, key, expectedValue, expectedValue, actualValue, actualValue)
+		case !reflect.DeepEqual(expectedValue, actualValue):
+			fmt.Fprintf(builder, "%s field %q: expected %#v, got %#v\n", prefix, key, expectedValue, actualValue)
+		}
+	}
+}
+
+// diffLayer is what Diff compares of one error in a chain.
+type diffLayer struct {
+	typ      string         // Go type, e.g. *ctxerrors.CTXError
+	message  string         // Own message, without the layers below
+	location string         // file:line in func, *CTXError only
+	fields   map[string]any // Redacted fields, debug ones too, *CTXError only
+}
+
+// summary describes the layer for when the other chain doesn't have it.
+func (l diffLayer) summary() string {
+	return fmt.Sprintf("%s %q", l.typ, l.message)
+}
+
+// diffLayers returns the layers of err's chain in the order walk visits them.
+func diffLayers(err error) []diffLayer {
+	var layers []diffLayer
+
+	walk(err, func(current error) {
+		layer := diffLayer{typ: fmt.Sprintf("%T", current)}
+
+		if ctxErr, ok := asCTXError(current); ok {
+			layer.message = ctxErr.msg()
+			layer.location = fmt.Sprintf("%s:%d in %s", ctxErr.file, ctxErr.line, ctxErr.funcName)
+			layer.fields = ctxErr.renderedFields(true)
+		} else if _, ok := members(current); !ok {
+			layer.message = ownMessage(current)
+		}
+
+		layers = append(layers, layer)
+	})
+
+	return layers
+}
+
+// ownMessage returns the text err adds on top of the error it wraps, trimming
+// the wrapped error's text off its own when it's a ": "-separated suffix as
+// fmt.Errorf("...: %w", err) leaves it.
+func ownMessage(err error) string {
+	message := err.Error()
+
+	if wrapper, ok := err.(interface{ Unwrap() error }); ok { //nolint:errorlint
+		if inner := wrapper.Unwrap(); inner != nil {
+			message = strings.TrimSuffix(message, ": "+inner.Error())
+		}
+	}
+
+	return message
+}
@@ -0,0 +1,123 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	located := func(message string, file string, line int) error {
+		return &CTXError{message: message, file: file, line: line, funcName: "main.run"}
+	}
+
+	testCases := []struct {
+		name     string
+		differ   Differ
+		expected error
+		actual   error
+		diff     string
+	}{
+		{
+			name:     "both nil",
+			expected: nil,
+			actual:   nil,
+			diff:     "",
+		},
+		{
+			name:     "same error",
+			expected: located("save user", "/app/user.go", 10),
+			actual:   located("save user", "/app/user.go", 10),
+			diff:     "",
+		},
+		{
+			name:     "message",
+			expected: located("save user", "/app/user.go", 10),
+			actual:   located("save users", "/app/user.go", 10),
+			diff:     "layer 0 message: expected \"save user\", got \"save users\"\n",
+		},
+		{
+			name:     "location",
+			expected: located("save user", "/app/user.go", 10),
+			actual:   located("save user", "/app/user.go", 12),
+			diff:     "layer 0 location: expected /app/user.go:10 in main.run, got /app/user.go:12 in main.run\n",
+		},
+		{
+			name:     "ignored location",
+			differ:   Differ{IgnoreLocations: true},
+			expected: located("save user", "/app/user.go", 10),
+			actual:   located("save user", "/app/user.go", 12),
+			diff:     "",
+		},
+		{
+			name:     "kind and fields",
+			differ:   Differ{IgnoreLocations: true},
+			expected: annotate(WithKind(Wrap(baseErr, "save user"), KindInternal), map[string]any{"id": 42, "gone": true}, 0),
+			actual:   annotate(WithKind(Wrap(baseErr, "save user"), KindNotFound), map[string]any{"id": int64(42), "new": "x"}, 0),
+			diff: "layer 0 field \"gone\": expected true, got nothing\n" +
+				"layer 0 field \"id\": expected 42 (int), got 42 (int64)\n" +
+				"layer 0 field \"kind\": expected \"internal\", got \"not_found\"\n" +
+				"layer 0 field \"new\": expected nothing, got \"x\"\n",
+		},
+		{
+			name:     "equal debug fields",
+			differ:   Differ{IgnoreLocations: true},
+			expected: WithDebugField(New("save user"), "dump", func() any { return "state" }),
+			actual:   WithDebugField(New("save user"), "dump", func() any { return "state" }),
+			diff:     "",
+		},
+		{
+			name:     "debug fields",
+			differ:   Differ{IgnoreLocations: true},
+			expected: WithDebugField(New("save user"), "dump", func() any { return "before" }),
+			actual:   WithDebugField(New("save user"), "dump", func() any { return "after" }),
+			diff:     "layer 0 field \"dump\": expected \"before\", got \"after\"\n",
+		},
+		{
+			name:     "missing layer",
+			differ:   Differ{IgnoreLocations: true},
+			expected: Wrap(baseErr, "save user"),
+			actual:   New("save user"),
+			diff:     "layer 1: missing, expected *errors.errorString \"base error\"\n",
+		},
+		{
+			name:     "unexpected layer",
+			differ:   Differ{IgnoreLocations: true},
+			expected: New("save user"),
+			actual:   Wrap(fmt.Errorf("tx: %w", baseErr), "save user"),
+			diff: "layer 1: unexpected *fmt.wrapError \"tx\"\n" +
+				"layer 2: unexpected *errors.errorString \"base error\"\n",
+		},
+		{
+			name:     "type",
+			differ:   Differ{IgnoreLocations: true},
+			expected: Wrap(baseErr, "save user"),
+			actual:   Wrap(New("base error"), "save user"),
+			diff:     "layer 1 type: expected *errors.errorString, got *ctxerrors.CTXError\n",
+		},
+		{
+			name:     "inside joined errors",
+			differ:   Differ{IgnoreLocations: true},
+			expected: errors.Join(New("first"), New("second")),
+			actual:   errors.Join(New("first"), New("third")),
+			diff:     "layer 2 message: expected \"second\", got \"third\"\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.diff, tc.differ.Diff(tc.expected, tc.actual))
+		})
+	}
+
+	t.Run("package level compares locations", func(t *testing.T) {
+		first := New("same")
+		second := New("same")
+
+		require.Contains(t, Diff(first, second), "layer 0 location: ")
+	})
+}
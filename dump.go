@@ -0,0 +1,94 @@
+package ctxerrors
+
+import "runtime"
+
+// initialDumpSize is the buffer size a goroutine dump starts out with.
+const initialDumpSize = 64 * 1024
+
+// DumpPredicate decides whether a newly created error gets a goroutine dump.
+type DumpPredicate func(err *CTXError) bool
+
+// SetDebugDump sets the predicate run on every error created from now on,
+// after the enrich hooks, to decide whether it gets a dump of every goroutine's
+// stack attached, for rare failures where the state of other goroutines is the
+// only clue. Dumps stop the world and can run into megabytes, so keep the
+// predicate narrow. Pass nil to turn it off, which is the default.
+func SetDebugDump(predicate DumpPredicate) {
+	updateConfig(func(c *config) {
+		c.debugDump = predicate
+	})
+}
+
+// WrapDebugDump is like Wrap but always attaches a dump of every goroutine's
+// stack, whatever SetDebugDump says.
+func WrapDebugDump(err error, message string) error {
+	// Skip WrapDebugDump() and newLayer() to get user's caller
+	framesToSkip := 2
+
+	if err == nil {
+		// Skip WrapDebugDump() and logNilWrap() instead
+		logNilWrap(framesToSkip)
+
+		return nil
+	}
+
+	layer := newLayer(err, message, framesToSkip)
+	layer.dump = currentConfig().goroutineDump()
+
+	return finishLayer(layer, nil)
+}
+
+// DebugDump returns the goroutine dump attached to the outermost layer in err's
+// chain that has one, including layers inside joined errors, or an empty
+// string if there's none.
+func DebugDump(err error) string {
+	var dump []byte
+
+	walk(err, func(current error) {
+		if dump != nil {
+			return
+		}
+
+		if layer, ok := asCTXError(current); ok {
+			dump = layer.dump
+		}
+	})
+
+	return string(dump)
+}
+
+// attachDebugDump attaches a goroutine dump to err if the SetDebugDump
+// predicate asks for one.
+func attachDebugDump(err *CTXError) {
+	cfg := currentConfig()
+	if cfg.debugDump == nil || !cfg.debugDump(err) {
+		return
+	}
+
+	err.dump = cfg.goroutineDump()
+}
+
+// goroutineDump returns the dump attached to errors with these settings, which
+// is DeterministicDump in deterministic mode.
+func (c *config) goroutineDump() []byte {
+	if c.deterministic {
+		return []byte(DeterministicDump)
+	}
+
+	return goroutineDump()
+}
+
+// goroutineDump returns the stacks of all goroutines as runtime.Stack formats
+// them.
+func goroutineDump() []byte {
+	buf := make([]byte, initialDumpSize)
+
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+
+		buf = make([]byte, 2*len(buf)) //nolint:mnd
+	}
+}
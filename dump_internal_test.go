@@ -0,0 +1,76 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDebugDump(t *testing.T) {
+	t.Cleanup(func() { SetDebugDump(nil) })
+
+	baseErr := errors.New("deadlock-ish") //nolint:err113
+
+	t.Run("off by default", func(t *testing.T) {
+		require.Empty(t, DebugDump(Wrap(baseErr, "wrapped")))
+	})
+
+	t.Run("predicate picks errors", func(t *testing.T) {
+		SetDebugDump(func(err *CTXError) bool {
+			return err.Message() == "stuck"
+		})
+
+		require.Empty(t, DebugDump(Wrap(baseErr, "fine")))
+
+		dump := DebugDump(Wrap(Wrap(baseErr, "stuck"), "outer"))
+		require.Contains(t, dump, "goroutine ")
+		require.Contains(t, dump, "TestSetDebugDump")
+	})
+
+	t.Run("predicate sees enrich hook fields", func(t *testing.T) {
+		unregister := RegisterEnrichHook(EnrichHookFunc(func(*CTXError) map[string]any {
+			return map[string]any{"component": "scheduler"}
+		}))
+		t.Cleanup(unregister)
+
+		SetDebugDump(func(err *CTXError) bool {
+			return err.Fields()["component"] == "scheduler"
+		})
+
+		require.NotEmpty(t, DebugDump(New("stuck")))
+	})
+}
+
+func TestWrapDebugDump(t *testing.T) {
+	baseErr := errors.New("deadlock-ish") //nolint:err113
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WrapDebugDump(nil, "wrapped"))
+	})
+
+	t.Run("always dumps", func(t *testing.T) {
+		actual := WrapDebugDump(baseErr, "wrapped")
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(actual, &ctxErr))
+		require.Equal(t, "wrapped", ctxErr.Message())
+		require.Contains(t, ctxErr.FuncName(), "TestWrapDebugDump")
+		require.Contains(t, DebugDump(actual), "goroutine ")
+		require.ErrorIs(t, actual, baseErr)
+	})
+
+	t.Run("inside joined errors", func(t *testing.T) {
+		actual := errors.Join(baseErr, WrapDebugDump(baseErr, "wrapped"))
+
+		require.NotEmpty(t, DebugDump(actual))
+	})
+}
+
+func TestGoroutineDump(t *testing.T) {
+	dump := string(goroutineDump())
+
+	require.Contains(t, dump, "goroutine ")
+	require.Contains(t, dump, "TestGoroutineDump")
+}
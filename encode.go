@@ -0,0 +1,136 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+// FieldSink receives the structured representation of an error chain from
+// Encode. It's small on purpose, so any logging or telemetry library can take
+// error chains by implementing it in a few lines, e.g. on top of a zap
+// ObjectEncoder or by collecting slog.Attrs.
+type FieldSink interface {
+	AddString(key, value string)
+	AddInt(key string, value int64)
+	// AddGroup adds a nested group of fields under key, which encode adds to
+	// the sink it's given.
+	AddGroup(key string, encode func(sink FieldSink))
+}
+
+// Encode sends err's chain to sink: its whole text as error, then the outermost
+// level of the chain, with every level below it nested in a cause group, like
+// LogfmtFormatter lays it out. A *CTXError level has msg, file, line, func, id
+// and a fields group, whichever it has, a level of another error type its type
+// and the msg it adds to what it wraps, and a joined error its whole text as
+// msg since its members don't fit on one level. Locations are always sent,
+// SetHideLocation only applies to Error(), and function names follow
+// SetFuncNameStyle. Fields are handed out like
+// Fields does, so marshalers and redaction apply: integers go to AddInt,
+// strings and everything else to AddString as fmt.Sprint renders it. Nothing
+// is sent for a nil error.
+func Encode(err error, sink FieldSink) {
+	if err == nil {
+		return
+	}
+
+	sink.AddString("error", err.Error())
+	encodeLevel(err, sink, currentConfig())
+}
+
+// encodeLevel sends one level of a chain and, nested, the ones below it.
+func encodeLevel(err error, sink FieldSink, cfg *config) {
+	if _, ok := members(err); ok {
+		sink.AddString("msg", err.Error())
+
+		return
+	}
+
+	layer, ok := asCTXError(err)
+	if !ok {
+		sink.AddString("type", fmt.Sprintf("%T", err))
+		sink.AddString("msg", ownMessage(err))
+		encodeCause(errors.Unwrap(err), sink, cfg)
+
+		return
+	}
+
+	sink.AddString("msg", layer.msg())
+
+	if layer.file != "" {
+		sink.AddString("file", layer.file)
+		sink.AddInt("line", int64(layer.line))
+	}
+
+	sink.AddString("func", formatFuncName(layer.funcName, cfg.funcNameStyle))
+
+	if layer.id != "" {
+		sink.AddString("id", layer.id)
+	}
+
+	if fields := layer.Fields(); len(fields) > 0 {
+		sink.AddGroup("fields", func(group FieldSink) {
+			for _, key := range slices.Sorted(maps.Keys(fields)) {
+				encodeValue(group, key, fields[key])
+			}
+		})
+	}
+
+	encodeCause(layer.err, sink, cfg)
+}
+
+// encodeCause sends cause as the cause group, if there is one.
+func encodeCause(cause error, sink FieldSink, cfg *config) {
+	if cause == nil {
+		return
+	}
+
+	sink.AddGroup("cause", func(group FieldSink) {
+		encodeLevel(cause, group, cfg)
+	})
+}
+
+// encodeValue sends a field value, integers that fit in an int64 as such and
+// everything else as a string.
+func encodeValue(sink FieldSink, key string, value any) {
+	switch v := value.(type) {
+	case string:
+		sink.AddString(key, v)
+	case int:
+		sink.AddInt(key, int64(v))
+	case int8:
+		sink.AddInt(key, int64(v))
+	case int16:
+		sink.AddInt(key, int64(v))
+	case int32:
+		sink.AddInt(key, int64(v))
+	case int64:
+		sink.AddInt(key, v)
+	case uint8:
+		sink.AddInt(key, int64(v))
+	case uint16:
+		sink.AddInt(key, int64(v))
+	case uint32:
+		sink.AddInt(key, int64(v))
+	case uint:
+		encodeUint(sink, key, uint64(v))
+	case uint64:
+		encodeUint(sink, key, v)
+	default:
+		sink.AddString(key, fmt.Sprint(value))
+	}
+}
+
+// encodeUint sends v as an integer if it fits in an int64 and as a string
+// otherwise.
+func encodeUint(sink FieldSink, key string, v uint64) {
+	if v > math.MaxInt64 {
+		sink.AddString(key, fmt.Sprint(v))
+
+		return
+	}
+
+	sink.AddInt(key, int64(v))
+}
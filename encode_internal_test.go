@@ -0,0 +1,120 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mapSink collects what Encode sends into nested maps.
+type mapSink map[string]any
+
+func (s mapSink) AddString(key, value string) {
+	s[key] = value
+}
+
+func (s mapSink) AddInt(key string, value int64) {
+	s[key] = value
+}
+
+func (s mapSink) AddGroup(key string, encode func(sink FieldSink)) {
+	group := mapSink{}
+	encode(group)
+	s[key] = group
+}
+
+func TestEncode(t *testing.T) {
+	t.Cleanup(func() { SetFuncNameStyle(FuncNameDefault) })
+
+	SetFuncNameStyle(FuncNameBare)
+
+	root := WithField(New("connection refused"), "retries", 3)
+	err := WithField(Wrap(fmt.Errorf("dial db: %w", root), "load user"), "elapsed", 2*time.Second)
+
+	sink := mapSink{}
+	Encode(err, sink)
+
+	var outer, inner *CTXError
+
+	require.True(t, errors.As(err, &outer))
+	require.True(t, errors.As(root, &inner))
+
+	require.Equal(t, mapSink{
+		"error": err.Error(),
+		"msg":   "load user",
+		"file":  outer.File(),
+		"line":  int64(outer.Line()),
+		"func":  "TestEncode",
+		"fields": mapSink{
+			"elapsed": "2s",
+		},
+		"cause": mapSink{
+			"type": "*fmt.wrapError",
+			"msg":  "dial db",
+			"cause": mapSink{
+				"msg":  "connection refused",
+				"file": inner.File(),
+				"line": int64(inner.Line()),
+				"func": "TestEncode",
+				"fields": mapSink{
+					"retries": int64(3),
+				},
+			},
+		},
+	}, sink)
+
+	t.Run("joined", func(t *testing.T) {
+		joined := errors.Join(io.EOF, io.ErrUnexpectedEOF)
+
+		sink := mapSink{}
+		Encode(Wrap(joined, "read"), sink)
+
+		require.Equal(t, mapSink{"msg": joined.Error()}, sink["cause"])
+	})
+
+	t.Run("hidden locations only leave Error()", func(t *testing.T) {
+		SetHideLocation(true)
+		t.Cleanup(func() { SetHideLocation(false) })
+
+		sink := mapSink{}
+		Encode(&CTXError{message: "boom", file: "/src/app/db.go", line: 12, funcName: "app.dial"}, sink)
+
+		require.Equal(t, mapSink{
+			"error": "boom",
+			"msg":   "boom",
+			"file":  "/src/app/db.go",
+			"line":  int64(12),
+			"func":  "dial",
+		}, sink)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		sink := mapSink{}
+		Encode(nil, sink)
+
+		require.Empty(t, sink)
+	})
+}
+
+func TestEncodeValue(t *testing.T) {
+	sink := mapSink{}
+
+	encodeValue(sink, "int8", int8(-8))
+	encodeValue(sink, "uint", uint(7))
+	encodeValue(sink, "huge", uint64(math.MaxUint64))
+	encodeValue(sink, "bool", true)
+	encodeValue(sink, "string", "text")
+
+	require.Equal(t, mapSink{
+		"int8":   int64(-8),
+		"uint":   int64(7),
+		"huge":   "18446744073709551615",
+		"bool":   "true",
+		"string": "text",
+	}, sink)
+}
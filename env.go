@@ -0,0 +1,57 @@
+package ctxerrors
+
+import (
+	"os"
+	"slices"
+)
+
+// EnvFieldPrefix goes in front of the names of the environment variables an
+// EnvSnapshot puts on errors, to keep them apart from other fields.
+const EnvFieldPrefix = "env."
+
+// EnvSnapshot is an EnrichHook putting the values of an allowlist of
+// environment variables on errors, for fleets running the same code under
+// different deployment configurations where a failure only shows up under
+// some of them:
+//
+//	defer ctxerrors.RegisterEnrichHook(ctxerrors.NewEnvSnapshot("REGION", "DEPLOY_ID"))()
+//
+// Only the listed variables are read, so secrets in the environment stay out
+// of error reports. Values are read when the error is created, and only onto
+// the innermost *CTXError layer of a chain since they're the same for the
+// layers wrapped around it. Unset variables are left out.
+type EnvSnapshot struct {
+	names []string
+}
+
+// NewEnvSnapshot returns an EnvSnapshot for the environment variables names,
+// whose values go in fields named EnvFieldPrefix plus the variable name, e.g.
+// env.REGION.
+func NewEnvSnapshot(names ...string) *EnvSnapshot {
+	return &EnvSnapshot{names: slices.Clone(names)}
+}
+
+// Enrich returns the set variables as fields if err is the innermost layer of
+// its chain.
+func (s *EnvSnapshot) Enrich(err *CTXError) map[string]any {
+	if innermostLayer(err) != err {
+		return nil
+	}
+
+	var fields map[string]any
+
+	for _, name := range s.names {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if fields == nil {
+			fields = make(map[string]any, len(s.names))
+		}
+
+		fields[EnvFieldPrefix+name] = value
+	}
+
+	return fields
+}
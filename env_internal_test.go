@@ -0,0 +1,39 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvSnapshot(t *testing.T) {
+	t.Setenv("CTXERRORS_TEST_REGION", "eu-west-1")
+	t.Setenv("CTXERRORS_TEST_DEPLOY_ID", "")
+	t.Setenv("CTXERRORS_TEST_SECRET", "hunter2")
+
+	unregister := RegisterEnrichHook(NewEnvSnapshot("CTXERRORS_TEST_REGION", "CTXERRORS_TEST_DEPLOY_ID", "CTXERRORS_TEST_UNSET"))
+	t.Cleanup(unregister)
+
+	inner := New("connection refused")
+	outer := Wrap(inner, "load user")
+
+	expected := map[string]any{
+		"env.CTXERRORS_TEST_REGION":    "eu-west-1",
+		"env.CTXERRORS_TEST_DEPLOY_ID": "",
+	}
+
+	require.Equal(t, expected, inner.(*CTXError).Fields()) //nolint:errorlint,forcetypeassert
+	require.Empty(t, outer.(*CTXError).Fields())           //nolint:errorlint,forcetypeassert
+	require.Equal(t, expected, Fields(outer))
+
+	t.Run("foreign cause", func(t *testing.T) {
+		err := Wrap(errors.New("boom"), "wrapped") //nolint:err113
+
+		require.Equal(t, "eu-west-1", Fields(err)["env.CTXERRORS_TEST_REGION"])
+	})
+
+	t.Run("nothing set", func(t *testing.T) {
+		require.Nil(t, NewEnvSnapshot("CTXERRORS_TEST_UNSET").Enrich(&CTXError{}))
+	})
+}
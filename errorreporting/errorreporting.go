@@ -0,0 +1,113 @@
+// Package errorreporting formats ctxerrors chains as the structured log
+// payload Google Cloud Error Reporting picks up from Cloud Logging, so errors
+// written to stdout on GKE or Cloud Run get grouped automatically.
+package errorreporting
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// PayloadType is the @type that marks a log entry as a reported error event.
+const PayloadType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+
+// ServiceContext identifies the service the error came from.
+type ServiceContext struct {
+	Service string `json:"service"`
+	Version string `json:"version,omitempty"`
+}
+
+// Payload is a Cloud Logging JSON entry Error Reporting understands.
+type Payload struct {
+	Type           string         `json:"@type"` //nolint:tagliatelle
+	Severity       string         `json:"severity"`
+	EventTime      time.Time      `json:"eventTime"`
+	ServiceContext ServiceContext `json:"serviceContext"`
+	Message        string         `json:"message"`
+	Context        *Context       `json:"context,omitempty"`
+}
+
+// Context holds where the error was reported from.
+type Context struct {
+	ReportLocation ReportLocation `json:"reportLocation"`
+}
+
+// ReportLocation is the source location the error was created at.
+type ReportLocation struct {
+	FilePath     string `json:"filePath"`
+	LineNumber   int    `json:"lineNumber"`
+	FunctionName string `json:"functionName"`
+}
+
+// ToPayload builds the Error Reporting payload for err. The message is
+// err.Error() followed by the locations of the chain's *CTXError layers in the
+// layout of runtime.Stack, origin first, which is what Error Reporting parses
+// Go stack traces from. The report location is the origin layer's, so errors
+// group even when the message has no usable trace. It returns nil for a nil
+// error.
+func ToPayload(err error, service ServiceContext) *Payload {
+	if err == nil {
+		return nil
+	}
+
+	layers := ctxLayers(err)
+
+	payload := &Payload{
+		Type:           PayloadType,
+		Severity:       "ERROR",
+		EventTime:      ctxerrors.Now().UTC(),
+		ServiceContext: service,
+		Message:        err.Error(),
+		Context:        nil,
+	}
+
+	if len(layers) == 0 {
+		return payload
+	}
+
+	payload.Message += "\n\n" + goroutineStack(layers)
+
+	origin := layers[0]
+	payload.Context = &Context{
+		ReportLocation: ReportLocation{
+			FilePath:     origin.File(),
+			LineNumber:   origin.Line(),
+			FunctionName: origin.FuncName(),
+		},
+	}
+
+	return payload
+}
+
+// ctxLayers returns the *CTXError layers of err's chain, innermost first.
+func ctxLayers(err error) []*ctxerrors.CTXError {
+	var layers []*ctxerrors.CTXError
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if layer, ok := err.(*ctxerrors.CTXError); ok && layer != nil { //nolint:errorlint
+			layers = append(layers, layer)
+		}
+	}
+
+	slices.Reverse(layers)
+
+	return layers
+}
+
+// goroutineStack renders layers the way runtime.Stack renders frames.
+func goroutineStack(layers []*ctxerrors.CTXError) string {
+	var b strings.Builder
+
+	b.WriteString("goroutine 1 [running]:")
+
+	for _, layer := range layers {
+		fmt.Fprintf(&b, "\n%s(...)\n\t%s:%d", layer.FuncName(), layer.File(), layer.Line())
+	}
+
+	return b.String()
+}
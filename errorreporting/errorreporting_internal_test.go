@@ -0,0 +1,67 @@
+package errorreporting
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestToPayload(t *testing.T) {
+	service := ServiceContext{Service: "api", Version: "1.2.3"}
+
+	t.Run("nil error", func(t *testing.T) {
+		require.Nil(t, ToPayload(nil, service))
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		payload := ToPayload(errors.New("boom"), service) //nolint:err113
+
+		require.Equal(t, PayloadType, payload.Type)
+		require.Equal(t, "ERROR", payload.Severity)
+		require.Equal(t, "boom", payload.Message)
+		require.Nil(t, payload.Context)
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		origin := ctxerrors.New("config file missing")
+		err := ctxerrors.Wrap(origin, "init failed")
+
+		payload := ToPayload(err, service)
+		require.Equal(t, service, payload.ServiceContext)
+		require.NotZero(t, payload.EventTime)
+
+		stackRegexp := regexp.MustCompile(
+			`^` + regexp.QuoteMeta(err.Error()) + `\n\ngoroutine 1 \[running\]:\n` +
+				`github\.com/psyb0t/ctxerrors/errorreporting\.TestToPayload\.func3\(\.\.\.\)\n\t.+_test\.go:\d+\n` +
+				`github\.com/psyb0t/ctxerrors/errorreporting\.TestToPayload\.func3\(\.\.\.\)\n\t.+_test\.go:\d+$`,
+		)
+		require.Regexp(t, stackRegexp, payload.Message)
+
+		var originErr *ctxerrors.CTXError
+
+		require.True(t, errors.As(origin, &originErr))
+		require.Equal(t, ReportLocation{
+			FilePath:     originErr.File(),
+			LineNumber:   originErr.Line(),
+			FunctionName: originErr.FuncName(),
+		}, payload.Context.ReportLocation)
+	})
+
+	t.Run("json layout", func(t *testing.T) {
+		data, err := json.Marshal(ToPayload(ctxerrors.New("boom"), ServiceContext{Service: "api"}))
+		require.NoError(t, err)
+
+		var entry map[string]any
+
+		require.NoError(t, json.Unmarshal(data, &entry))
+		require.Equal(t, PayloadType, entry["@type"])
+		require.Equal(t, map[string]any{"service": "api"}, entry["serviceContext"])
+		require.Contains(t, entry, "eventTime")
+		require.Contains(t, entry["context"], "reportLocation")
+	})
+}
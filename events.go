@@ -0,0 +1,116 @@
+package ctxerrors
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SubscriptionBuffer is how many events a subscription holds before the
+// oldest ones start getting dropped.
+const SubscriptionBuffer = 256
+
+// ErrorEvent describes a created error to subscribers, as a snapshot taken
+// once the constructor has built it. The error itself isn't handed out since
+// its creator may still be adding to it.
+type ErrorEvent struct {
+	Time     time.Time
+	Message  string
+	File     string
+	Line     int
+	FuncName string
+	ID       string
+	Fields   map[string]any // Set by enrich hooks and the constructor, redacted like Fields()
+	Cause    error          // Error wrapped by the created one, nil for New
+}
+
+// subscriber is the receiving end of a Subscribe call.
+type subscriber struct {
+	mu     sync.Mutex // Guards sends against close
+	events chan ErrorEvent
+	closed bool
+}
+
+// Subscribe returns a channel receiving an ErrorEvent for every error created
+// from now on, for in-process observers such as live debugging UIs or anomaly
+// detectors that shouldn't have to scrape logs, and a function that ends the
+// subscription and closes the channel. Creating errors never waits for a slow
+// subscriber: once SubscriptionBuffer events are pending, the oldest ones are
+// dropped to make room. Errors a SetInjections injection replaces before their
+// creator gets them aren't published.
+func Subscribe() (<-chan ErrorEvent, func()) {
+	sub := &subscriber{events: make(chan ErrorEvent, SubscriptionBuffer)}
+
+	updateConfig(func(c *config) {
+		// Never append in place, older snapshots may share the slice
+		c.subscribers = append(slices.Clip(c.subscribers), sub)
+	})
+
+	var once sync.Once
+
+	cancel := func() {
+		once.Do(func() {
+			updateConfig(func(c *config) {
+				c.subscribers = slices.DeleteFunc(slices.Clone(c.subscribers), func(other *subscriber) bool {
+					return other == sub
+				})
+			})
+
+			sub.mu.Lock()
+			defer sub.mu.Unlock()
+
+			sub.closed = true
+			close(sub.events)
+		})
+	}
+
+	return sub.events, cancel
+}
+
+// publishEvent sends an ErrorEvent for err to every subscriber.
+func publishEvent(err *CTXError, subscribers []*subscriber) {
+	event := ErrorEvent{
+		Time:     Now(),
+		Message:  err.msg(),
+		File:     err.file,
+		Line:     err.line,
+		FuncName: err.funcName,
+		ID:       err.id,
+		Fields:   err.Fields(),
+		Cause:    err.err,
+	}
+
+	for i, sub := range subscribers {
+		if i > 0 && event.Fields != nil {
+			// Every subscriber gets a map of its own to mess with
+			event.Fields = maps.Clone(event.Fields)
+		}
+
+		sub.send(event)
+	}
+}
+
+// send delivers event, dropping the oldest pending event if the buffer is
+// full.
+func (s *subscriber) send(event ErrorEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	for {
+		select {
+		case s.events <- event:
+			return
+		default:
+		}
+
+		select {
+		case <-s.events:
+		default:
+		}
+	}
+}
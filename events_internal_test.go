@@ -0,0 +1,153 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	unregister := RegisterEnrichHook(EnrichHookFunc(func(*CTXError) map[string]any {
+		return map[string]any{"request_id": "abc", "password": "hunter2"}
+	}))
+	t.Cleanup(unregister)
+	require.NoError(t, SetRedactedFields("password"))
+	t.Cleanup(func() { require.NoError(t, SetRedactedFields()) })
+
+	events, cancel := Subscribe()
+	t.Cleanup(cancel)
+
+	base := errors.New("base error") //nolint:err113
+	err := New("first")
+	_ = Wrap(base, "second")
+
+	first := <-events
+	require.Equal(t, "first", first.Message)
+	require.Equal(t, "github.com/psyb0t/ctxerrors.TestSubscribe", first.FuncName)
+	require.NotZero(t, first.Line)
+	require.NotZero(t, first.Time)
+	require.Nil(t, first.Cause)
+	require.Equal(t, map[string]any{"request_id": "abc", "password": RedactedValue}, first.Fields)
+
+	second := <-events
+	require.Equal(t, "second", second.Message)
+	require.Equal(t, base, second.Cause)
+
+	// The event is a snapshot, changing it leaves the error alone
+	first.Fields["request_id"] = "changed"
+	require.Equal(t, "abc", Fields(err)["request_id"])
+
+	cancel()
+
+	_, ok := <-events
+	require.False(t, ok)
+
+	// Cancelling twice is harmless and nothing gets published anymore
+	cancel()
+	require.Empty(t, currentConfig().subscribers)
+	_ = New("unobserved")
+}
+
+func TestSubscribeSeesFinishedLayer(t *testing.T) {
+	SetFormatCheck(FormatCheckField)
+	t.Cleanup(func() { SetFormatCheck(FormatCheckOff) })
+	t.Cleanup(RegisterFieldProvider(func(context.Context) map[string]any {
+		return map[string]any{"tenant": "acme"}
+	}))
+
+	events, cancel := Subscribe()
+	t.Cleanup(cancel)
+
+	base := errors.New("base error") //nolint:err113
+	format := "load user %d"
+	args := []any{"alice"}
+
+	_ = Wrapf(base, format, args...)
+	_ = WrapCtx(context.Background(), base, "load")
+	_ = WrapOp(base, "open", "/etc/app.yaml")
+
+	require.Equal(t, map[string]any{FieldFormatError: format}, (<-events).Fields)
+	require.Equal(t, map[string]any{"tenant": "acme"}, (<-events).Fields)
+	require.Equal(t, map[string]any{FieldOp: "open", FieldPath: "/etc/app.yaml"}, (<-events).Fields)
+}
+
+func TestSubscribeSkipsInjectedLayers(t *testing.T) {
+	require.NoError(t, SetInjections(t, Injection{Func: "ctxerrors.injectTargetNew", Err: errInjected}))
+
+	events, cancel := Subscribe()
+	t.Cleanup(cancel)
+
+	require.ErrorIs(t, injectTargetNew(), errInjected)
+	_ = New("delivered")
+
+	require.Equal(t, "delivered", (<-events).Message)
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %q", event.Message)
+	default:
+	}
+}
+
+func TestSubscribeDropsOldest(t *testing.T) {
+	events, cancel := Subscribe()
+	t.Cleanup(cancel)
+
+	for range SubscriptionBuffer + 2 {
+		_ = Wrapf(errors.New("base error"), "overflow") //nolint:err113
+	}
+
+	require.Len(t, events, SubscriptionBuffer)
+
+	cancel()
+
+	count := 0
+	for range events {
+		count++
+	}
+
+	require.Equal(t, SubscriptionBuffer, count)
+}
+
+func TestSubscribeMultiple(t *testing.T) {
+	first, cancelFirst := Subscribe()
+	t.Cleanup(cancelFirst)
+
+	second, cancelSecond := Subscribe()
+	t.Cleanup(cancelSecond)
+
+	_ = New("shared")
+
+	require.Equal(t, "shared", (<-first).Message)
+	require.Equal(t, "shared", (<-second).Message)
+
+	cancelFirst()
+	_ = New("second only")
+
+	require.Equal(t, "second only", (<-second).Message)
+}
+
+func TestSubscribeConcurrentCancel(t *testing.T) {
+	events, cancel := Subscribe()
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Go(func() {
+			for range 100 {
+				_ = New("concurrent")
+			}
+		})
+	}
+
+	go func() {
+		for range events { //nolint:revive
+		}
+	}()
+
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,162 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Expectation describes the structure an error chain is expected to have, built
+// with Expect and checked against an actual error with Check, e.g.
+//
+//	ctxerrors.Expect().
+//		Msg("save user").Kind(ctxerrors.KindInternal).
+//		Msg("commit").
+//		CausedBy(sql.ErrTxDone).
+//		Check(t, err)
+//
+// Every Msg matches the next *CTXError layer of the chain, outermost first,
+// following Unwrap() error, and Kind and Field apply to the layer of the Msg
+// before them. Layers past the last Msg aren't checked.
+type Expectation struct {
+	layers      []expectedLayer
+	chainFields map[string]any
+	causes      []error
+}
+
+// expectedLayer is what's expected of a single *CTXError layer.
+type expectedLayer struct {
+	message string
+	fields  map[string]any
+}
+
+// Expect starts an Expectation.
+func Expect() *Expectation {
+	return &Expectation{}
+}
+
+// Msg expects the next *CTXError layer of the chain to have message.
+func (x *Expectation) Msg(message string) *Expectation {
+	x.layers = append(x.layers, expectedLayer{message: message})
+
+	return x
+}
+
+// Kind expects the layer of the previous Msg to be classified as kind, or the
+// chain as a whole, as KindOf sees it, if there's no Msg before it.
+func (x *Expectation) Kind(kind Kind) *Expectation {
+	return x.Field(FieldKind, kind)
+}
+
+// Field expects the layer of the previous Msg to have key set to value, or the
+// chain as a whole, as FieldAs sees it, if there's no Msg before it. Values are
+// compared with reflect.DeepEqual.
+func (x *Expectation) Field(key string, value any) *Expectation {
+	fields := &x.chainFields
+	if len(x.layers) > 0 {
+		fields = &x.layers[len(x.layers)-1].fields
+	}
+
+	if *fields == nil {
+		*fields = make(map[string]any)
+	}
+
+	(*fields)[key] = value
+
+	return x
+}
+
+// CausedBy expects errors.Is(err, target) to be true for the checked error.
+func (x *Expectation) CausedBy(target error) *Expectation {
+	x.causes = append(x.causes, target)
+
+	return x
+}
+
+// Check reports every way err doesn't meet the expectation through t.Errorf
+// and returns whether it met all of them.
+func (x *Expectation) Check(t TestingT, err error) bool {
+	t.Helper()
+
+	failures := x.failures(err)
+	for _, failure := range failures {
+		t.Errorf("%s", failure)
+	}
+
+	return len(failures) == 0
+}
+
+// failures returns a description of every way err doesn't meet x.
+func (x *Expectation) failures(err error) []string {
+	if err == nil {
+		return []string{"expected an error, got nil"}
+	}
+
+	failures := fieldFailures("chain", x.chainFields, func(key string) (any, bool) {
+		return lookupField[any](err, key)
+	})
+
+	layers := ctxLayers(err)
+
+	for i, expected := range x.layers {
+		if i >= len(layers) {
+			failures = append(failures, fmt.Sprintf("layer %d: expected message %q, but the chain has only %d *CTXError layers",
+				i, expected.message, len(layers)))
+
+			continue
+		}
+
+		if actual := layers[i].msg(); actual != expected.message {
+			failures = append(failures, fmt.Sprintf("layer %d: expected message %q, got %q", i, expected.message, actual))
+		}
+
+		failures = append(failures, fieldFailures(fmt.Sprintf("layer %d", i), expected.fields, func(key string) (any, bool) {
+			value, ok := layers[i].fields[key]
+
+			return value, ok
+		})...)
+	}
+
+	for _, cause := range x.causes {
+		if !errors.Is(err, cause) {
+			failures = append(failures, fmt.Sprintf("expected the chain to be caused by %q", cause))
+		}
+	}
+
+	return failures
+}
+
+// fieldFailures compares the expected fields, in key order, with the values
+// lookup returns, describing mismatches as found on where.
+func fieldFailures(where string, expected map[string]any, lookup func(key string) (any, bool)) []string {
+	var failures []string
+
+	for _, key := range slices.Sorted(maps.Keys(expected)) {
+		actual, ok := lookup(key)
+
+		switch {
+		case !ok:
+			failures = append(failures, fmt.Sprintf("%s: expected field %q to be %#v, got nothing", where, key, expected[key]))
+		case !reflect.DeepEqual(expected[key], actual):
+			failures = append(failures, fmt.Sprintf("%s: expected field %q to be %#v, got %#v", where, key, expected[key], actual))
+		}
+	}
+
+	return failures
+}
+
+// ctxLayers returns the *CTXError layers of err's chain, outermost first,
+// following Unwrap() error.
+func ctxLayers(err error) []*CTXError {
+	var layers []*CTXError
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if layer, ok := asCTXError(err); ok {
+			layers = append(layers, layer)
+		}
+	}
+
+	return layers
+}
@@ -0,0 +1,119 @@
+package ctxerrors
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT records what's reported through it instead of failing the test.
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Cleanup(func()) {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestExpectation(t *testing.T) {
+	err := Wrap(
+		WithKind(Wrap(fmt.Errorf("tx: %w", sql.ErrTxDone), "commit"), KindInternal),
+		"save user",
+	)
+	err = WithField(err, "user_id", 42)
+
+	testCases := []struct {
+		name        string
+		expectation *Expectation
+		err         error
+		failures    []string
+	}{
+		{
+			name:        "matches",
+			expectation: Expect().Msg("save user").Field("user_id", 42).Msg("commit").Kind(KindInternal).CausedBy(sql.ErrTxDone),
+			err:         err,
+			failures:    nil,
+		},
+		{
+			name:        "chain-wide kind",
+			expectation: Expect().Kind(KindInternal).Msg("save user"),
+			err:         err,
+			failures:    nil,
+		},
+		{
+			name:        "nil error",
+			expectation: Expect().Msg("save user"),
+			err:         nil,
+			failures:    []string{"expected an error, got nil"},
+		},
+		{
+			name:        "wrong message",
+			expectation: Expect().Msg("save users"),
+			err:         err,
+			failures:    []string{`layer 0: expected message "save users", got "save user"`},
+		},
+		{
+			name:        "wrong kind",
+			expectation: Expect().Msg("save user").Msg("commit").Kind(KindNotFound),
+			err:         err,
+			failures:    []string{`layer 1: expected field "kind" to be "not_found", got "internal"`},
+		},
+		{
+			name:        "missing field",
+			expectation: Expect().Msg("save user").Kind(KindInternal),
+			err:         err,
+			failures:    []string{`layer 0: expected field "kind" to be "internal", got nothing`},
+		},
+		{
+			name:        "wrong chain-wide field",
+			expectation: Expect().Field("user_id", 43),
+			err:         err,
+			failures:    []string{`chain: expected field "user_id" to be 43, got 42`},
+		},
+		{
+			name:        "too few layers",
+			expectation: Expect().Msg("save user").Msg("commit").Msg("begin"),
+			err:         err,
+			failures:    []string{`layer 2: expected message "begin", but the chain has only 2 *CTXError layers`},
+		},
+		{
+			name:        "wrong cause",
+			expectation: Expect().CausedBy(sql.ErrNoRows),
+			err:         err,
+			failures:    []string{`expected the chain to be caused by "sql: no rows in result set"`},
+		},
+		{
+			name:        "every failure",
+			expectation: Expect().Msg("save users").Msg("rollback").CausedBy(sql.ErrNoRows),
+			err:         err,
+			failures: []string{
+				`layer 0: expected message "save users", got "save user"`,
+				`layer 1: expected message "rollback", got "commit"`,
+				`expected the chain to be caused by "sql: no rows in result set"`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &recordingT{}
+
+			require.Equal(t, len(tc.failures) == 0, tc.expectation.Check(recorder, tc.err))
+			require.Equal(t, tc.failures, recorder.errors)
+		})
+	}
+
+	t.Run("skips foreign layers", func(t *testing.T) {
+		err := Wrap(fmt.Errorf("foreign: %w", New("inner")), "outer")
+
+		require.True(t, Expect().Msg("outer").Msg("inner").Check(t, err))
+		require.True(t, Expect().CausedBy(errors.Unwrap(errors.Unwrap(err))).Check(t, err))
+	})
+}
@@ -0,0 +1,60 @@
+package ctxerrors
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+// ExpvarName is the expvar variable PublishExpvar publishes the counters as.
+const ExpvarName = "ctxerrors"
+
+// Counter names in the ExpvarName variable.
+const (
+	ExpvarErrorsCreated  = "errors_created"
+	ExpvarErrorsWrapped  = "errors_wrapped"
+	ExpvarStacksCaptured = "stacks_captured"
+	ExpvarHookFailures   = "hook_failures"
+)
+
+// activity counts what the package does once PublishExpvar turns counting on.
+var activity struct { //nolint:gochecknoglobals
+	created      atomic.Uint64
+	wrapped      atomic.Uint64
+	stacks       atomic.Uint64
+	hookFailures atomic.Uint64
+}
+
+var publishExpvarOnce sync.Once //nolint:gochecknoglobals
+
+// PublishExpvar starts counting errors created without a cause
+// (ExpvarErrorsCreated), errors created wrapping another one
+// (ExpvarErrorsWrapped), layers that captured caller frames
+// (ExpvarStacksCaptured) and panicking enrich hooks and field providers
+// (ExpvarHookFailures), and publishes the counts as the ExpvarName expvar
+// variable, so they show up on /debug/vars without wiring a metrics library.
+// Counting is off until it's called, since every count is a write to memory
+// shared by every goroutine creating errors. Calling it again does nothing.
+func PublishExpvar() {
+	publishExpvarOnce.Do(func() {
+		expvar.Publish(ExpvarName, expvar.Func(func() any {
+			return map[string]uint64{
+				ExpvarErrorsCreated:  activity.created.Load(),
+				ExpvarErrorsWrapped:  activity.wrapped.Load(),
+				ExpvarStacksCaptured: activity.stacks.Load(),
+				ExpvarHookFailures:   activity.hookFailures.Load(),
+			}
+		}))
+
+		updateConfig(func(c *config) {
+			c.countActivity = true
+		})
+	})
+}
+
+// countActivity adds one to counter if PublishExpvar turned counting on.
+func countActivity(counter *atomic.Uint64) {
+	if currentConfig().countActivity {
+		counter.Add(1)
+	}
+}
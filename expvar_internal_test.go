@@ -0,0 +1,57 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// expvarCounts reads the published counters back the way /debug/vars does.
+func expvarCounts(t *testing.T) map[string]uint64 {
+	t.Helper()
+
+	published := expvar.Get(ExpvarName)
+	require.NotNil(t, published)
+
+	var counts map[string]uint64
+
+	require.NoError(t, json.Unmarshal([]byte(published.String()), &counts))
+
+	return counts
+}
+
+func TestPublishExpvar(t *testing.T) {
+	t.Cleanup(func() { SetCallerDepth(0) })
+
+	PublishExpvar()
+	PublishExpvar()
+
+	before := expvarCounts(t)
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	_ = New("created")
+	_ = Newf("created %d", 2)
+	_ = Wrap(baseErr, "wrapped")
+
+	SetCallerDepth(1)
+
+	_ = Wrap(baseErr, "wrapped with a stack")
+
+	unregister := RegisterEnrichHook(EnrichHookFunc(func(*CTXError) map[string]any {
+		panic("hook blew up")
+	}))
+	_ = New("created with a broken hook")
+
+	unregister()
+
+	after := expvarCounts(t)
+
+	require.Equal(t, uint64(3), after[ExpvarErrorsCreated]-before[ExpvarErrorsCreated])
+	require.Equal(t, uint64(2), after[ExpvarErrorsWrapped]-before[ExpvarErrorsWrapped])
+	require.Equal(t, uint64(2), after[ExpvarStacksCaptured]-before[ExpvarStacksCaptured])
+	require.Equal(t, uint64(1), after[ExpvarHookFailures]-before[ExpvarHookFailures])
+}
@@ -0,0 +1,263 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FieldPrecedence decides which value wins when the same field key is set on
+// more than one layer of a chain.
+type FieldPrecedence int
+
+const (
+	// OutermostWins picks the value closest to the top of the chain, i.e. the
+	// context added last. This is the default.
+	OutermostWins FieldPrecedence = iota
+	// InnermostWins picks the value closest to where the error originated.
+	InnermostWins
+	// CollectAll keeps every value where a merged view has room for them and
+	// picks the outermost one where only one value fits.
+	CollectAll
+)
+
+// SetFieldPrecedence sets which value wins when a field key is set on more than
+// one layer of a chain. It applies to every lookup that reads a single value
+// off a chain, such as KindOf, RetryAfter, Op and Path. AllValues returns every
+// value regardless.
+func SetFieldPrecedence(precedence FieldPrecedence) {
+	updateConfig(func(c *config) {
+		c.fieldPrecedence = precedence
+	})
+}
+
+// Fields returns the fields of every layer in err's chain merged into a single
+// map, including layers inside joined errors, for loggers and reporters that
+// want them all at once. Keys set on more than one layer are resolved as
+// SetFieldPrecedence says, with CollectAll turning them into a []any of every
+// value, outermost first. Values are rendered by RegisterFieldMarshaler
+// marshalers and those of keys matching SetRedactedFields patterns come out
+// redacted. It returns nil if there are no fields.
+func Fields(err error) map[string]any {
+	return mergedFields(err, false)
+}
+
+// mergedFields merges the fields of err's chain like Fields describes,
+// including the WithDebugField ones if debug is set.
+func mergedFields(err error, debug bool) map[string]any {
+	cfg := currentConfig()
+
+	collected := map[string][]any{}
+
+	walk(err, func(current error) {
+		if layer, ok := asCTXError(current); ok {
+			for key, value := range layer.fields {
+				if lazy, ok := value.(*debugValue); ok {
+					if !debug {
+						continue
+					}
+
+					value = lazy.get()
+				}
+
+				collected[key] = append(collected[key], cfg.marshalField(value))
+			}
+		}
+	})
+
+	if len(collected) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]any, len(collected))
+
+	for key, values := range collected {
+		switch {
+		case len(values) == 1:
+			merged[key] = values[0]
+		case cfg.fieldPrecedence == CollectAll:
+			merged[key] = values
+		case cfg.fieldPrecedence == InnermostWins:
+			merged[key] = values[len(values)-1]
+		default:
+			merged[key] = values[0]
+		}
+	}
+
+	redactFields(merged, cfg.redactedFields)
+
+	return merged
+}
+
+// AllValues returns every value of the field key in err's chain, outermost
+// first, including those set on layers inside joined errors but not
+// WithDebugField ones. Values of keys matching SetRedactedFields patterns come
+// out redacted.
+func AllValues(err error, key string) []any {
+	var values []any
+
+	walk(err, func(current error) {
+		if layer, ok := asCTXError(current); ok {
+			if value, ok := layer.fields[key]; ok {
+				if _, debug := value.(*debugValue); !debug {
+					values = append(values, value)
+				}
+			}
+		}
+	})
+
+	if len(values) > 0 && isRedacted(key, currentConfig().redactedFields) {
+		for i := range values {
+			values[i] = RedactedValue
+		}
+	}
+
+	return values
+}
+
+// lookupField returns the value of the field key of type T in err's chain that
+// wins under the current FieldPrecedence. Values of any other type and
+// WithDebugField ones are ignored. Redaction isn't applied, the package reads
+// its own fields through it.
+func lookupField[T any](err error, key string) (T, bool) {
+	var (
+		values []T
+		zero   T
+	)
+
+	walk(err, func(current error) {
+		if layer, ok := asCTXError(current); ok {
+			value, ok := layer.fields[key]
+			if _, debug := value.(*debugValue); !ok || debug {
+				return
+			}
+
+			if typed, ok := value.(T); ok {
+				values = append(values, typed)
+			}
+		}
+	})
+
+	if len(values) == 0 {
+		return zero, false
+	}
+
+	if currentConfig().fieldPrecedence == InnermostWins {
+		return values[len(values)-1], true
+	}
+
+	return values[0], true
+}
+
+// FieldAs returns the value of the field key in err's chain, as picked by
+// SetFieldPrecedence among the values of type T. Values of any other type and
+// WithDebugField ones are ignored. If key matches a SetRedactedFields pattern
+// the value comes out as RedactedValue, or isn't found if T can't hold it.
+func FieldAs[T any](err error, key string) (T, bool) {
+	return visibleField[T](err, key)
+}
+
+// visibleField is lookupField with the value of a redacted key replaced by
+// RedactedValue, for the exported getters.
+func visibleField[T any](err error, key string) (T, bool) {
+	if !isRedacted(key, currentConfig().redactedFields) {
+		return lookupField[T](err, key)
+	}
+
+	var zero T
+
+	if _, ok := lookupField[any](err, key); !ok {
+		return zero, false
+	}
+
+	redacted, ok := any(RedactedValue).(T)
+
+	return redacted, ok
+}
+
+// FieldString returns the value of the field key in err's chain as picked by
+// SetFieldPrecedence if it's a string or a fmt.Stringer, RedactedValue if key
+// is redacted.
+func FieldString(err error, key string) (string, bool) {
+	value, ok := visibleField[any](err, key)
+	if !ok {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case fmt.Stringer:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// FieldInt returns the value of the field key in err's chain as picked by
+// SetFieldPrecedence if it's any integer type, or a float64 holding a whole
+// number as decoded JSON does, that fits in an int. A redacted key isn't
+// found.
+func FieldInt(err error, key string) (int, bool) {
+	value, ok := visibleField[any](err, key)
+	if !ok {
+		return 0, false
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return intFrom(v, v >= math.MinInt && v <= math.MaxInt)
+	case uint:
+		return intFrom(v, v <= math.MaxInt)
+	case uint8:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		return intFrom(v, uint64(v) <= math.MaxInt)
+	case uint64:
+		return intFrom(v, v <= math.MaxInt)
+	case float64:
+		return intFrom(v, v == math.Trunc(v) && v >= math.MinInt && v < math.MaxInt)
+	default:
+		return 0, false
+	}
+}
+
+// FieldTime returns the value of the field key in err's chain as picked by
+// SetFieldPrecedence if it's a time.Time or an RFC 3339 string. A redacted key
+// isn't found.
+func FieldTime(err error, key string) (time.Time, bool) {
+	value, ok := visibleField[any](err, key)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		parsed, parseErr := time.Parse(time.RFC3339Nano, v)
+
+		return parsed, parseErr == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// intFrom converts v to an int if it fits.
+func intFrom[T int64 | uint | uint32 | uint64 | float64](v T, fits bool) (int, bool) {
+	if !fits {
+		return 0, false
+	}
+
+	return int(v), true
+}
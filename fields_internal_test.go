@@ -0,0 +1,306 @@
+package ctxerrors
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFieldPrecedence(t *testing.T) {
+	t.Cleanup(func() { SetFieldPrecedence(OutermostWins) })
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	inner := annotate(Wrap(baseErr, "inner"), map[string]any{FieldKind: KindNotFound, FieldOp: "open"}, 0)
+	middle := annotate(Wrap(inner, "middle"), map[string]any{FieldKind: "not a kind"}, 0)
+	outer := annotate(Wrap(middle, "outer"), map[string]any{FieldKind: KindInternal, FieldOp: "read"}, 0)
+
+	testCases := []struct {
+		name         string
+		precedence   FieldPrecedence
+		expectedKind Kind
+		expectedOp   string
+	}{
+		{
+			name:         "outermost wins",
+			precedence:   OutermostWins,
+			expectedKind: KindInternal,
+			expectedOp:   "read",
+		},
+		{
+			name:         "innermost wins",
+			precedence:   InnermostWins,
+			expectedKind: KindNotFound,
+			expectedOp:   "open",
+		},
+		{
+			name:         "collect all picks outermost",
+			precedence:   CollectAll,
+			expectedKind: KindInternal,
+			expectedOp:   "read",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetFieldPrecedence(tc.precedence)
+
+			require.Equal(t, tc.expectedKind, KindOf(outer))
+			require.Equal(t, tc.expectedOp, Op(outer))
+		})
+	}
+}
+
+func TestAllValues(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetRedactedFields()) })
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	inner := annotate(Wrap(baseErr, "inner"), map[string]any{"user": "alice", "token": "abc"}, 0)
+	outer := annotate(Wrap(inner, "outer"), map[string]any{"user": 42, "token": "def"}, 0)
+	joined := errors.Join(outer, annotate(New("other"), map[string]any{"user": "bob"}, 0))
+
+	testCases := []struct {
+		name     string
+		err      error
+		key      string
+		expected []any
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			key:      "user",
+			expected: nil,
+		},
+		{
+			name:     "missing key",
+			err:      outer,
+			key:      "nope",
+			expected: nil,
+		},
+		{
+			name:     "outermost first",
+			err:      outer,
+			key:      "user",
+			expected: []any{42, "alice"},
+		},
+		{
+			name:     "inside joined errors",
+			err:      joined,
+			key:      "user",
+			expected: []any{42, "alice", "bob"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, AllValues(tc.err, tc.key))
+		})
+	}
+
+	t.Run("redacted", func(t *testing.T) {
+		require.NoError(t, SetRedactedFields("token"))
+		require.Equal(t, []any{RedactedValue, RedactedValue}, AllValues(outer, "token"))
+	})
+}
+
+type stringerValue struct{}
+
+func (stringerValue) String() string { return "stringer" }
+
+func TestTypedFieldGetters(t *testing.T) { //nolint:funlen
+	when := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	err := Wrap(annotate(New("inner"), map[string]any{
+		"name":      "alice",
+		"stringer":  stringerValue{},
+		"int":       42,
+		"int64":     int64(7),
+		"uint64":    uint64(math.MaxUint64),
+		"float":     float64(12),
+		"fraction":  1.5,
+		"time":      when,
+		"time_text": "2024-03-01T12:30:00Z",
+		"bad_time":  "yesterday",
+	}, 0), "outer")
+
+	t.Run("FieldString", func(t *testing.T) {
+		testCases := []struct {
+			key           string
+			expected      string
+			expectedFound bool
+		}{
+			{key: "name", expected: "alice", expectedFound: true},
+			{key: "stringer", expected: "stringer", expectedFound: true},
+			{key: "int", expected: "", expectedFound: false},
+			{key: "missing", expected: "", expectedFound: false},
+		}
+
+		for _, tc := range testCases {
+			actual, found := FieldString(err, tc.key)
+
+			require.Equal(t, tc.expectedFound, found, tc.key)
+			require.Equal(t, tc.expected, actual, tc.key)
+		}
+	})
+
+	t.Run("FieldInt", func(t *testing.T) {
+		testCases := []struct {
+			key           string
+			expected      int
+			expectedFound bool
+		}{
+			{key: "int", expected: 42, expectedFound: true},
+			{key: "int64", expected: 7, expectedFound: true},
+			{key: "float", expected: 12, expectedFound: true},
+			{key: "uint64", expected: 0, expectedFound: false},
+			{key: "fraction", expected: 0, expectedFound: false},
+			{key: "name", expected: 0, expectedFound: false},
+			{key: "missing", expected: 0, expectedFound: false},
+		}
+
+		for _, tc := range testCases {
+			actual, found := FieldInt(err, tc.key)
+
+			require.Equal(t, tc.expectedFound, found, tc.key)
+			require.Equal(t, tc.expected, actual, tc.key)
+		}
+	})
+
+	t.Run("FieldTime", func(t *testing.T) {
+		testCases := []struct {
+			key           string
+			expected      time.Time
+			expectedFound bool
+		}{
+			{key: "time", expected: when, expectedFound: true},
+			{key: "time_text", expected: when, expectedFound: true},
+			{key: "bad_time", expected: time.Time{}, expectedFound: false},
+			{key: "int", expected: time.Time{}, expectedFound: false},
+			{key: "missing", expected: time.Time{}, expectedFound: false},
+		}
+
+		for _, tc := range testCases {
+			actual, found := FieldTime(err, tc.key)
+
+			require.Equal(t, tc.expectedFound, found, tc.key)
+			require.True(t, tc.expected.Equal(actual), tc.key)
+		}
+	})
+
+	t.Run("FieldAs", func(t *testing.T) {
+		actual, found := FieldAs[time.Time](err, "time")
+		require.True(t, found)
+		require.Equal(t, when, actual)
+
+		_, found = FieldAs[time.Time](err, "time_text")
+		require.False(t, found)
+	})
+
+	t.Run("redacted keys", func(t *testing.T) {
+		require.NoError(t, SetRedactedFields("name", "int", "time"))
+		t.Cleanup(func() { require.NoError(t, SetRedactedFields()) })
+
+		name, found := FieldString(err, "name")
+		require.True(t, found)
+		require.Equal(t, RedactedValue, name)
+
+		anyName, found := FieldAs[any](err, "name")
+		require.True(t, found)
+		require.Equal(t, RedactedValue, anyName)
+
+		_, found = FieldInt(err, "int")
+		require.False(t, found)
+
+		_, found = FieldTime(err, "time")
+		require.False(t, found)
+
+		_, found = FieldAs[time.Time](err, "time")
+		require.False(t, found)
+	})
+
+	t.Run("debug fields", func(t *testing.T) {
+		debugErr := WithDebugField(New("boom"), "dump", func() any { return "state" })
+
+		_, found := FieldAs[any](debugErr, "dump")
+		require.False(t, found)
+
+		_, found = FieldString(debugErr, "dump")
+		require.False(t, found)
+	})
+}
+
+func TestFields(t *testing.T) {
+	t.Cleanup(func() {
+		SetFieldPrecedence(OutermostWins)
+		require.NoError(t, SetRedactedFields())
+	})
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	inner := annotate(Wrap(baseErr, "inner"), map[string]any{"user": "alice", "query": "SELECT 1"}, 0)
+	outer := annotate(Wrap(inner, "outer"), map[string]any{"user": "bob", "token": "abc"}, 0)
+	joined := Wrap(errors.Join(outer, annotate(New("other"), map[string]any{"shard": 3}, 0)), "batch")
+
+	testCases := []struct {
+		name       string
+		precedence FieldPrecedence
+		err        error
+		expected   map[string]any
+	}{
+		{
+			name:       "nil error",
+			precedence: OutermostWins,
+			err:        nil,
+			expected:   nil,
+		},
+		{
+			name:       "no fields",
+			precedence: OutermostWins,
+			err:        Wrap(baseErr, "plain"),
+			expected:   nil,
+		},
+		{
+			name:       "outermost wins",
+			precedence: OutermostWins,
+			err:        outer,
+			expected:   map[string]any{"user": "bob", "query": "SELECT 1", "token": "abc"},
+		},
+		{
+			name:       "innermost wins",
+			precedence: InnermostWins,
+			err:        outer,
+			expected:   map[string]any{"user": "alice", "query": "SELECT 1", "token": "abc"},
+		},
+		{
+			name:       "collect all",
+			precedence: CollectAll,
+			err:        outer,
+			expected:   map[string]any{"user": []any{"bob", "alice"}, "query": "SELECT 1", "token": "abc"},
+		},
+		{
+			name:       "inside joined errors",
+			precedence: OutermostWins,
+			err:        joined,
+			expected:   map[string]any{"user": "bob", "query": "SELECT 1", "token": "abc", "shard": 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetFieldPrecedence(tc.precedence)
+
+			require.Equal(t, tc.expected, Fields(tc.err))
+		})
+	}
+
+	t.Run("redacted", func(t *testing.T) {
+		SetFieldPrecedence(OutermostWins)
+		require.NoError(t, SetRedactedFields("token"))
+
+		require.Equal(t, map[string]any{"user": "bob", "query": "SELECT 1", "token": RedactedValue}, Fields(outer))
+	})
+}
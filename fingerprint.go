@@ -0,0 +1,37 @@
+package ctxerrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// fingerprintBytes is how many bytes of the hash go into a fingerprint,
+// rendered as twice as many hex digits.
+const fingerprintBytes = 8
+
+// Fingerprint returns a short hash grouping err with the errors that took the
+// same code path: it covers the functions that created the *CTXError layers in
+// err's chain and the Go types of the other errors, including inside joined
+// errors, but not messages, fields or line numbers, so a user ID in a message
+// or a line added above the call doesn't split a group. It's stable across
+// processes and machines running the same build and empty for a nil error.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	hash := sha256.New()
+
+	walk(err, func(current error) {
+		if layer, ok := asCTXError(current); ok {
+			fmt.Fprintf(hash, "%s\n", layer.funcName)
+
+			return
+		}
+
+		fmt.Fprintf(hash, "%T\n", current)
+	})
+
+	return hex.EncodeToString(hash.Sum(nil)[:fingerprintBytes])
+}
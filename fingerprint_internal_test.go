@@ -0,0 +1,66 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func fingerprintOrigin(id int) error {
+	return Newf("user %d not found", id)
+}
+
+//go:noinline
+func fingerprintOther(id int) error {
+	return Newf("user %d not found", id)
+}
+
+func TestFingerprint(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	require.Empty(t, Fingerprint(nil))
+
+	fingerprint := Fingerprint(Wrap(fingerprintOrigin(1), "load user"))
+	require.Len(t, fingerprint, 2*fingerprintBytes)
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "different message",
+			err:      Wrap(fingerprintOrigin(2), "load user again"),
+			expected: true,
+		},
+		{
+			name:     "different function",
+			err:      Wrap(fingerprintOther(1), "load user"),
+			expected: false,
+		},
+		{
+			name:     "missing layer",
+			err:      fingerprintOrigin(1),
+			expected: false,
+		},
+		{
+			name:     "foreign layer",
+			err:      Wrap(fmt.Errorf("lookup: %w", fingerprintOrigin(1)), "load user"),
+			expected: false,
+		},
+		{
+			name:     "foreign cause",
+			err:      Wrap(baseErr, "load user"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Fingerprint(tc.err) == fingerprint)
+		})
+	}
+}
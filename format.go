@@ -0,0 +1,42 @@
+package ctxerrors
+
+// DefaultSeparator is what goes between the layers of a chain unless
+// SetSeparator or a TextFormatter says otherwise.
+const DefaultSeparator = ": "
+
+// textOptions controls how a chain is rendered as text.
+type textOptions struct {
+	separator       string
+	hideLocation    bool
+	maxLayerMessage int // Zero means no limit
+	maxTotalMessage int // Zero means no limit
+	collapseRepeats bool
+	funcNameStyle   FuncNameStyle
+}
+
+// TextFormatter renders error chains like Error() does but with its own
+// settings, for outputs that need to look different from the global default.
+type TextFormatter struct {
+	// Separator goes between layers. Empty means the global separator.
+	Separator string
+	// FuncNames is how function names are rendered. FuncNameDefault means
+	// the global style.
+	FuncNames FuncNameStyle
+}
+
+// Format renders err. Errors in the chain that aren't *CTXError are rendered
+// with their own Error(), including whatever they wrap.
+func (f TextFormatter) Format(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	opts := currentConfig().textOptions()
+	if f.Separator != "" {
+		opts.separator = f.Separator
+	}
+
+	opts.funcNameStyle = funcNameStyleOr(f.FuncNames, opts.funcNameStyle)
+
+	return causeText(err, opts)
+}
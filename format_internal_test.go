@@ -0,0 +1,103 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextFormatter(t *testing.T) {
+	t.Cleanup(func() { SetSeparator(DefaultSeparator) })
+
+	baseErr := errors.New("base error") //nolint:err113
+	chain := Wrap(Wrap(New("root"), "middle"), "outer")
+
+	testCases := []struct {
+		name            string
+		globalSeparator string
+		formatter       TextFormatter
+		err             error
+		expectedPrefix  string
+	}{
+		{
+			name:            "nil error",
+			globalSeparator: DefaultSeparator,
+			formatter:       TextFormatter{Separator: " | "},
+			err:             nil,
+			expectedPrefix:  "",
+		},
+		{
+			name:            "default separator",
+			globalSeparator: DefaultSeparator,
+			formatter:       TextFormatter{},
+			err:             chain,
+			expectedPrefix:  "outer: middle: root [",
+		},
+		{
+			name:            "formatter separator",
+			globalSeparator: DefaultSeparator,
+			formatter:       TextFormatter{Separator: " | "},
+			err:             chain,
+			expectedPrefix:  "outer | middle | root [",
+		},
+		{
+			name:            "global separator",
+			globalSeparator: " -> ",
+			formatter:       TextFormatter{},
+			err:             chain,
+			expectedPrefix:  "outer -> middle -> root [",
+		},
+		{
+			name:            "formatter overrides global",
+			globalSeparator: " -> ",
+			formatter:       TextFormatter{Separator: " | "},
+			err:             chain,
+			expectedPrefix:  "outer | middle | root [",
+		},
+		{
+			name:            "plain error",
+			globalSeparator: DefaultSeparator,
+			formatter:       TextFormatter{Separator: " | "},
+			err:             baseErr,
+			expectedPrefix:  "base error",
+		},
+		{
+			name:            "foreign wrapper keeps its own text",
+			globalSeparator: DefaultSeparator,
+			formatter:       TextFormatter{Separator: " | "},
+			err:             Wrap(fmt.Errorf("foreign: %w", baseErr), "outer"),
+			expectedPrefix:  "outer | foreign: base error [",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetSeparator(tc.globalSeparator)
+
+			actual := tc.formatter.Format(tc.err)
+			if tc.err == nil {
+				require.Empty(t, actual)
+
+				return
+			}
+
+			require.True(t, strings.HasPrefix(actual, tc.expectedPrefix), actual)
+		})
+	}
+}
+
+func TestSetSeparator(t *testing.T) {
+	t.Cleanup(func() { SetSeparator(DefaultSeparator) })
+
+	chain := Wrap(New("root"), "outer")
+
+	SetSeparator(" -> ")
+	require.Regexp(t, `^outer -> root \[`, chain.Error())
+
+	SetSeparator("")
+	require.Equal(t, DefaultSeparator, currentConfig().separator)
+	require.Regexp(t, `^outer: root \[`, chain.Error())
+}
@@ -0,0 +1,67 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldFormatError is the field key FormatCheckField records the format
+// string of a badly formatted message under.
+const FieldFormatError = "format_error"
+
+// FormatCheck says what happens when the format string and arguments of a
+// formatted message don't match.
+type FormatCheck int
+
+const (
+	// FormatCheckOff leaves mismatches alone, so the message just carries
+	// fmt's %!d(string=...) markers. This is the default.
+	FormatCheckOff FormatCheck = iota
+	// FormatCheckWarn reports mismatches to the SetWarningHook hook as
+	// WarnBadFormat warnings.
+	FormatCheckWarn
+	// FormatCheckField records the format string of mismatches in the
+	// FieldFormatError field, so they can be found in logs and fixed later.
+	FormatCheckField
+)
+
+// WarnBadFormat is a formatted message whose format string and arguments
+// don't match, see SetFormatCheck.
+const WarnBadFormat WarningKind = "bad_format"
+
+// SetFormatCheck makes Newf, Wrapf, NewfSkip and WrapfSkip check their
+// formatted messages for the markers fmt leaves on bad verbs, wrong argument
+// types and missing or extra arguments, and handle them as check says,
+// instead of letting %!d(string=...) garbage slip into messages unnoticed.
+// An argument whose own text holds "%!" counts as a mismatch too. WrapLazyf
+// isn't checked since it formats nothing up front.
+func SetFormatCheck(check FormatCheck) {
+	updateConfig(func(c *config) {
+		c.formatCheck = check
+	})
+}
+
+// checkFormat handles layer, just created with a message formatted from
+// format, as SetFormatCheck says if the message shows format and its
+// arguments didn't match.
+func checkFormat(layer *CTXError, format string) {
+	cfg := currentConfig()
+	if cfg.formatCheck == FormatCheckOff || !strings.Contains(layer.message, "%!") {
+		return
+	}
+
+	switch cfg.formatCheck {
+	case FormatCheckWarn:
+		if cfg.warningHook != nil {
+			callWarningHook(cfg.warningHook, Warning{
+				Kind: WarnBadFormat,
+				Message: fmt.Sprintf("ctxerrors: message at %s:%d in %s doesn't match its arguments: %s",
+					layer.file, layer.line, layer.funcName, layer.message),
+				Err: layer,
+			})
+		}
+	case FormatCheckField:
+		withFields(layer, map[string]any{FieldFormatError: format})
+	case FormatCheckOff:
+	}
+}
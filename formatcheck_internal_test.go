@@ -0,0 +1,90 @@
+package ctxerrors
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFormatCheck(t *testing.T) {
+	var warnings []Warning
+
+	SetWarningHook(func(warning Warning) {
+		warnings = append(warnings, warning)
+	})
+	t.Cleanup(func() {
+		SetWarningHook(nil)
+		SetFormatCheck(FormatCheckOff)
+	})
+
+	testCases := []struct {
+		name   string
+		format string
+		create func(format string, args ...any) error
+		args   []any
+		bad    bool
+	}{
+		{
+			name:   "good wrapf",
+			format: "load user %d",
+			create: func(format string, args ...any) error { return Wrapf(io.EOF, format, args...) },
+			args:   []any{42},
+		},
+		{
+			name:   "wrong type",
+			format: "load user %d",
+			create: func(format string, args ...any) error { return Wrapf(io.EOF, format, args...) },
+			args:   []any{"alice"},
+			bad:    true,
+		},
+		{
+			name:   "missing argument",
+			format: "load user %s in %s",
+			create: func(format string, args ...any) error { return Newf(format, args...) },
+			args:   []any{"alice"},
+			bad:    true,
+		},
+		{
+			name:   "extra argument",
+			format: "load user",
+			create: func(format string, args ...any) error { return NewfSkip(0, format, args...) },
+			args:   []any{"alice"},
+			bad:    true,
+		},
+		{
+			name:   "wrapping a non error",
+			format: "load user: %w",
+			create: func(format string, args ...any) error { return WrapfSkip(io.EOF, 0, format, args...) },
+			args:   []any{"alice"},
+			bad:    true,
+		},
+	}
+
+	for _, check := range []FormatCheck{FormatCheckOff, FormatCheckWarn, FormatCheckField} {
+		SetFormatCheck(check)
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				warnings = nil
+
+				err := tc.create(tc.format, tc.args...)
+				layer, _ := asCTXError(err)
+
+				if check == FormatCheckWarn && tc.bad {
+					require.Len(t, warnings, 1)
+					require.Equal(t, WarnBadFormat, warnings[0].Kind)
+					require.Same(t, layer, warnings[0].Err)
+				} else {
+					require.Empty(t, warnings)
+				}
+
+				if check == FormatCheckField && tc.bad {
+					require.Equal(t, tc.format, layer.fields[FieldFormatError])
+				} else {
+					require.NotContains(t, layer.fields, FieldFormatError)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package ctxerrors
+
+import "maps"
+
+// AnnotateFrame returns err with note attached to one frame of its outermost
+// *CTXError layer, shown after that frame's location in %+v output, to explain
+// what generic infrastructure a stack passes through was doing:
+//
+//	err = ctxerrors.AnnotateFrame(err, 1, "inside retry loop")
+//
+// Frame 0 is the layer's own location and frame i its i-th caller as Callers
+// returns them. A later note on the same frame replaces the earlier one. err
+// itself is never modified, like with WithField. If err isn't a *CTXError it's
+// wrapped in a new layer with an empty message created at the caller first. It
+// returns err as is if the layer has no such frame, and nil if err is nil.
+func AnnotateFrame(err error, frame int, note string) error {
+	if isNilError(err) {
+		return nil
+	}
+
+	layer, ok := asCTXError(err)
+	if !ok {
+		// Skip AnnotateFrame() and newLayer() to get user's caller
+		framesToSkip := 2
+
+		layer = newLayer(err, "", framesToSkip)
+		if frame < 0 || frame > len(layer.Callers()) {
+			return err
+		}
+
+		layer.notes = map[int]string{frame: note}
+
+		return finishLayer(layer, nil)
+	}
+
+	if frame < 0 || frame > len(layer.Callers()) {
+		return err
+	}
+
+	annotated := *layer
+	annotated.notes = make(map[int]string, len(layer.notes)+1)
+
+	maps.Copy(annotated.notes, layer.notes)
+	annotated.notes[frame] = note
+
+	return &annotated
+}
+
+// FrameNote returns the note AnnotateFrame attached to frame of this layer, or
+// an empty string if there's none.
+func (e *CTXError) FrameNote(frame int) string {
+	if e == nil {
+		return ""
+	}
+
+	return e.notes[frame]
+}
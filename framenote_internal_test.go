@@ -0,0 +1,70 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func frameNoteRetry() error {
+	return Wrap(errors.New("connection refused"), "dial") //nolint:err113
+}
+
+func TestAnnotateFrame(t *testing.T) {
+	t.Cleanup(func() { SetCallerDepth(0) })
+
+	SetCallerDepth(1)
+
+	baseErr := errors.New("base error") //nolint:err113
+	original := frameNoteRetry()
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, AnnotateFrame(nil, 0, "note"))
+	})
+
+	t.Run("notes frames", func(t *testing.T) {
+		annotated := AnnotateFrame(AnnotateFrame(original, 0, "first try"), 1, "inside retry loop")
+		annotated = AnnotateFrame(annotated, 0, "second try")
+
+		layer := FirstContext(annotated)
+		require.Equal(t, "second try", layer.FrameNote(0))
+		require.Equal(t, "inside retry loop", layer.FrameNote(1))
+		require.Empty(t, layer.FrameNote(2))
+		require.Empty(t, FirstContext(original).FrameNote(0))
+
+		detail := fmt.Sprintf("%+v", annotated)
+		require.Regexp(t, `frameNoteRetry\n        \S+\.go:\d+ \(second try\)\n`, detail)
+		require.Regexp(t, `TestAnnotateFrame\S*\n        \S+\.go:\d+ \(inside retry loop\)\n`, detail)
+		require.NotContains(t, fmt.Sprintf("%+v", original), "(")
+		require.Equal(t, original.Error(), annotated.Error())
+	})
+
+	t.Run("no such frame", func(t *testing.T) {
+		require.Same(t, original, AnnotateFrame(original, 2, "note"))
+		require.Same(t, original, AnnotateFrame(original, -1, "note"))
+	})
+
+	t.Run("foreign error", func(t *testing.T) {
+		annotated := AnnotateFrame(baseErr, 0, "note")
+
+		layer := FirstContext(annotated)
+		require.Equal(t, "note", layer.FrameNote(0))
+		require.Contains(t, layer.FuncName(), "TestAnnotateFrame")
+		require.ErrorIs(t, annotated, baseErr)
+	})
+
+	t.Run("clone keeps notes", func(t *testing.T) {
+		annotated := AnnotateFrame(original, 0, "note")
+
+		require.Equal(t, "note", FirstContext(Clone(annotated)).FrameNote(0))
+	})
+
+	t.Run("nil layer", func(t *testing.T) {
+		var layer *CTXError
+
+		require.Empty(t, layer.FrameNote(0))
+	})
+}
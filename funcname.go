@@ -0,0 +1,71 @@
+package ctxerrors
+
+import "strings"
+
+// FuncNameStyle controls how function names are rendered in error output.
+type FuncNameStyle int
+
+const (
+	// FuncNameDefault renders function names as SetFuncNameStyle says, which
+	// is FuncNameFull unless changed. For the global setting it's the same as
+	// FuncNameFull.
+	FuncNameDefault FuncNameStyle = iota
+	// FuncNameFull renders the name the runtime reports, with the full import
+	// path and receiver: github.com/acme/app/user.(*Store).Load.
+	FuncNameFull
+	// FuncNameShort renders the package name, receiver type and function:
+	// user.Store.Load.
+	FuncNameShort
+	// FuncNameBare renders the receiver type and function without the
+	// package: Store.Load.
+	FuncNameBare
+)
+
+// SetFuncNameStyle controls how Error(), %+v, FormatTable and the formatters
+// without a style of their own render the function names of locations and
+// captured callers, for log systems that want less than the full import path.
+// FuncName and Callers still return the full names.
+func SetFuncNameStyle(style FuncNameStyle) {
+	updateConfig(func(c *config) {
+		c.funcNameStyle = style
+	})
+}
+
+// funcNameStyleOr returns style unless it's FuncNameDefault, in which case it
+// returns fallback.
+func funcNameStyleOr(style, fallback FuncNameStyle) FuncNameStyle {
+	if style == FuncNameDefault {
+		return fallback
+	}
+
+	return style
+}
+
+// formatFuncName renders the runtime function name name in style.
+func formatFuncName(name string, style FuncNameStyle) string {
+	if style != FuncNameShort && style != FuncNameBare {
+		return name
+	}
+
+	// Only look for the package path before type parameters, they can hold
+	// slashes of their own
+	head := name
+	if bracket := strings.IndexByte(head, '['); bracket >= 0 {
+		head = head[:bracket]
+	}
+
+	name = name[strings.LastIndexByte(head, '/')+1:]
+
+	if style == FuncNameBare {
+		if dot := strings.IndexByte(name, '.'); dot >= 0 {
+			name = name[dot+1:]
+		}
+	}
+
+	// (*Store).Load reads as Store.Load
+	if strings.Contains(name, "(*") {
+		name = strings.NewReplacer("(*", "", ").", ".").Replace(name)
+	}
+
+	return name
+}
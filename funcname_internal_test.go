@@ -0,0 +1,112 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type funcNameStore struct{}
+
+//go:noinline
+func (*funcNameStore) load() error {
+	return New("load failed")
+}
+
+func TestFormatFuncName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		funcName string
+		expected map[FuncNameStyle]string
+	}{
+		{
+			name:     "pointer receiver",
+			funcName: "github.com/acme/app/user.(*Store).Load",
+			expected: map[FuncNameStyle]string{
+				FuncNameDefault: "github.com/acme/app/user.(*Store).Load",
+				FuncNameFull:    "github.com/acme/app/user.(*Store).Load",
+				FuncNameShort:   "user.Store.Load",
+				FuncNameBare:    "Store.Load",
+			},
+		},
+		{
+			name:     "value receiver closure",
+			funcName: "github.com/acme/app/user.Store.Load.func1",
+			expected: map[FuncNameStyle]string{
+				FuncNameShort: "user.Store.Load.func1",
+				FuncNameBare:  "Store.Load.func1",
+			},
+		},
+		{
+			name:     "main package",
+			funcName: "main.main",
+			expected: map[FuncNameStyle]string{
+				FuncNameShort: "main.main",
+				FuncNameBare:  "main",
+			},
+		},
+		{
+			name:     "type parameters",
+			funcName: "github.com/acme/app/list.(*List[...]).Push",
+			expected: map[FuncNameStyle]string{
+				FuncNameShort: "list.List[...].Push",
+				FuncNameBare:  "List[...].Push",
+			},
+		},
+		{
+			name:     "escaped dot in package",
+			funcName: "gopkg.in/yaml%2ev3.Marshal",
+			expected: map[FuncNameStyle]string{
+				FuncNameShort: "yaml%2ev3.Marshal",
+				FuncNameBare:  "Marshal",
+			},
+		},
+		{
+			name:     "empty",
+			funcName: "",
+			expected: map[FuncNameStyle]string{
+				FuncNameShort: "",
+				FuncNameBare:  "",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for style, expected := range tc.expected {
+				require.Equal(t, expected, formatFuncName(tc.funcName, style), style)
+			}
+		})
+	}
+}
+
+func TestSetFuncNameStyle(t *testing.T) {
+	t.Cleanup(func() { SetFuncNameStyle(FuncNameDefault) })
+
+	err := (&funcNameStore{}).load()
+
+	const (
+		full  = "github.com/psyb0t/ctxerrors.(*funcNameStore).load"
+		short = "ctxerrors.funcNameStore.load"
+		bare  = " funcNameStore.load"
+	)
+
+	require.Contains(t, err.Error(), "in "+full+"]")
+	require.Contains(t, LogfmtFormatter{FuncNames: FuncNameShort}.Format(err), "func="+short)
+	require.Contains(t, TextFormatter{FuncNames: FuncNameBare}.Format(err), "in"+bare+"]")
+
+	SetFuncNameStyle(FuncNameShort)
+
+	require.Contains(t, err.Error(), "in "+short+"]")
+	require.Contains(t, fmt.Sprintf("%+v", err), "\n    "+short+"\n")
+	require.Contains(t, FormatTable(err), short)
+	require.Contains(t, LogfmtFormatter{}.Format(err), "func="+short)
+	require.Contains(t, LogfmtFormatter{FuncNames: FuncNameFull}.Format(err), "func="+full)
+	require.Contains(t, TextFormatter{FuncNames: FuncNameBare}.Format(err), "in"+bare+"]")
+
+	var ctxErr *CTXError
+
+	require.ErrorAs(t, err, &ctxErr)
+	require.Equal(t, full, ctxErr.FuncName())
+}
@@ -0,0 +1,191 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FieldPanic is the field key the value recovered from a panic is stored
+// under by Go and Supervisor.
+const FieldPanic = "panic"
+
+// ErrGoexit is what Go delivers when the function it runs calls
+// runtime.Goexit instead of returning, e.g. through t.FailNow.
+var ErrGoexit = errors.New("goroutine exited without returning")
+
+// panicCallers is how many frames above the panic site a recovered panic
+// captures at least, whatever SetCallerDepth says.
+const panicCallers = 32
+
+// Go runs fn in a new goroutine and returns a channel that receives what it
+// returns, then gets closed. A panic in fn is recovered and delivered as an
+// error located where fn panicked, with the stack above it as its callers and
+// the recovered value as the FieldPanic field, wrapping the value if it's an
+// error, so nothing has to hand-roll a recover block around go func(). If fn
+// calls runtime.Goexit the channel receives ErrGoexit wrapped at where fn
+// starts instead of a nil that would pass for success.
+func Go(fn func() error) <-chan error {
+	return goBlaming(fn, fn)
+}
+
+// goBlaming is Go blaming a runtime.Goexit in fn on origin, the function fn
+// calls if it's a closure around one.
+func goBlaming(fn func() error, origin any) <-chan error {
+	result := make(chan error, 1)
+
+	go func() {
+		returned := false
+
+		defer func() {
+			if !returned {
+				result <- WrapFrom(ErrGoexit, origin, "function called runtime.Goexit")
+			}
+
+			close(result)
+		}()
+
+		result <- callRecovered(fn)
+		returned = true
+	}()
+
+	return result
+}
+
+// Supervisor runs functions in goroutines like Go but delivers their failures
+// to a callback, and can restart them. The zero Supervisor runs a function
+// once and drops its error.
+type Supervisor struct {
+	// OnError is called with every error the function returns, every panic in
+	// it and every runtime.Goexit it calls, converted like Go does, from the
+	// goroutine supervising it.
+	OnError func(err error)
+	// Restart runs the function again after it fails, until it returns nil or
+	// its context is done.
+	Restart bool
+	// RestartDelay is how long to wait before running a failed function again.
+	RestartDelay time.Duration
+}
+
+// Go runs fn in a new goroutine under ctx and returns a channel that's closed
+// once fn has returned for good. Every run gets a goroutine of its own, so a
+// runtime.Goexit in fn, e.g. through t.FailNow, counts as a failure like Go
+// reports it instead of silently ending the supervision.
+func (s Supervisor) Go(ctx context.Context, fn func(ctx context.Context) error) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for {
+			err := <-goBlaming(func() error { return fn(ctx) }, fn)
+			if err == nil {
+				return
+			}
+
+			if s.OnError != nil {
+				s.OnError(err)
+			}
+
+			if !s.Restart || ctx.Err() != nil {
+				return
+			}
+
+			timer := time.NewTimer(s.RestartDelay)
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return done
+}
+
+// callRecovered calls fn, converting a panic in it to an error.
+func callRecovered(fn func() error) (err error) { //nolint:nonamedreturns
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicError(r)
+		}
+	}()
+
+	return fn()
+}
+
+// panicError converts the value recovered from a panic to an error located at
+// the panic site. It must be called from the deferred function that recovered.
+func panicError(recovered any) error {
+	cfg := currentConfig()
+	depth := max(cfg.callerDepth, panicCallers)
+
+	// Leave room for the frames of the recovery and the panic machinery
+	pcs := make([]uintptr, panicCallers+depth)
+	// Skip runtime.Callers and panicError
+	pcs = pcs[:runtime.Callers(2, pcs)] //nolint:mnd
+
+	site := panicSite(pcs)
+
+	ctxErr := &CTXError{
+		message: fmt.Sprintf("panic: %v", recovered),
+		id:      newInstanceID(),
+	}
+
+	if cause, ok := recovered.(error); ok {
+		ctxErr.err = cause
+		ctxErr.message = "panic"
+	}
+
+	if site < len(pcs) {
+		// The panic site's PC is a return address too, unless a signal
+		// interrupted it, and either way stepping back stays in its call
+		frame := cfg.resolve(pcs[site] - 1)
+		ctxErr.funcName = frame.FuncName
+
+		if cfg.captureMode != CaptureFuncOnly {
+			ctxErr.file, ctxErr.line = cfg.mapLocation(frame.File, frame.Line)
+		}
+
+		callers := pcs[site+1:]
+		ctxErr.callers = slices.Clip(callers[:min(len(callers), depth)])
+
+		if len(ctxErr.callers) > 0 {
+			countActivity(&activity.stacks)
+		}
+	}
+
+	return finishLayer(ctxErr, map[string]any{FieldPanic: recovered})
+}
+
+// panicSite returns the index of the frame that panicked in pcs: the first one
+// outside the runtime after the runtime's panic machinery, or len(pcs) if
+// there's none.
+func panicSite(pcs []uintptr) int {
+	inPanic := false
+
+	for i, pc := range pcs {
+		fn := runtime.FuncForPC(pc - 1)
+		if fn == nil {
+			continue
+		}
+
+		name := fn.Name()
+
+		switch {
+		case name == "runtime.gopanic" || name == "runtime.sigpanic":
+			inPanic = true
+		case inPanic && !strings.HasPrefix(name, "runtime."):
+			return i
+		}
+	}
+
+	return len(pcs)
+}
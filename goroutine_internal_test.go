@@ -0,0 +1,201 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGo(t *testing.T) {
+	t.Run("result", func(t *testing.T) {
+		require.NoError(t, <-Go(func() error { return nil }))
+		require.ErrorIs(t, <-Go(func() error { return io.EOF }), io.EOF)
+	})
+
+	t.Run("channel closed", func(t *testing.T) {
+		result := Go(func() error { return nil })
+		<-result
+
+		_, open := <-result
+		require.False(t, open)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		_, _, panicLine, _ := runtime.Caller(0)
+
+		err := <-Go(func() error {
+			panic("boom") // panicLine + 3
+		})
+
+		var layer *CTXError
+		require.ErrorAs(t, err, &layer)
+		require.Equal(t, "panic: boom", layer.Message())
+		require.Equal(t, panicLine+3, layer.Line())
+		require.True(t, strings.HasSuffix(layer.File(), "goroutine_internal_test.go"))
+		require.Equal(t, "github.com/psyb0t/ctxerrors.TestGo.func3.1", layer.FuncName())
+		require.Equal(t, "boom", layer.Fields()[FieldPanic])
+		require.NotEmpty(t, layer.Callers())
+		require.Nil(t, layer.Unwrap())
+	})
+
+	t.Run("panic with error", func(t *testing.T) {
+		err := <-Go(func() error {
+			panic(io.ErrUnexpectedEOF)
+		})
+
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		require.Equal(t, []string{"panic", io.ErrUnexpectedEOF.Error()}, Messages(err))
+	})
+
+	t.Run("runtime panic", func(t *testing.T) {
+		err := <-Go(func() error {
+			var values []int
+
+			_ = values[len(values)] //nolint:gosec
+
+			return nil
+		})
+
+		var runtimeErr runtime.Error
+		require.ErrorAs(t, err, &runtimeErr)
+
+		var layer *CTXError
+		require.ErrorAs(t, err, &layer)
+		require.Equal(t, "github.com/psyb0t/ctxerrors.TestGo.func5.1", layer.FuncName())
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		err := <-Go(func() error {
+			var layer *CTXError
+
+			return layer.err
+		})
+
+		var layer *CTXError
+		require.ErrorAs(t, err, &layer)
+		require.Equal(t, "github.com/psyb0t/ctxerrors.TestGo.func6.1", layer.FuncName())
+	})
+
+	t.Run("goexit", func(t *testing.T) {
+		result := Go(func() error {
+			runtime.Goexit()
+
+			return nil
+		})
+
+		err, received := <-result
+		require.True(t, received)
+		require.ErrorIs(t, err, ErrGoexit)
+		require.Equal(t, "github.com/psyb0t/ctxerrors.TestGo.func7.1", FirstContext(err).FuncName())
+
+		_, open := <-result
+		require.False(t, open)
+	})
+}
+
+func TestSupervisor(t *testing.T) {
+	t.Run("runs once", func(t *testing.T) {
+		var errs []error
+
+		supervisor := Supervisor{OnError: func(err error) { errs = append(errs, err) }}
+
+		<-supervisor.Go(context.Background(), func(context.Context) error { return io.EOF })
+
+		require.Equal(t, []error{io.EOF}, errs)
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		<-Supervisor{}.Go(context.Background(), func(context.Context) error { panic("boom") })
+	})
+
+	t.Run("restarts until success", func(t *testing.T) {
+		var (
+			mu   sync.Mutex
+			errs []error
+		)
+
+		supervisor := Supervisor{
+			OnError: func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				errs = append(errs, err)
+			},
+			Restart:      true,
+			RestartDelay: time.Millisecond,
+		}
+
+		runs := 0
+
+		<-supervisor.Go(context.Background(), func(context.Context) error {
+			runs++
+
+			switch runs {
+			case 1:
+				return io.EOF
+			case 2: //nolint:mnd
+				panic("boom")
+			default:
+				return nil
+			}
+		})
+
+		require.Equal(t, 3, runs)
+		require.Len(t, errs, 2)
+		require.ErrorIs(t, errs[0], io.EOF)
+		require.Equal(t, "boom", Fields(errs[1])[FieldPanic])
+	})
+
+	t.Run("reports and restarts after goexit", func(t *testing.T) {
+		var errs []error
+
+		supervisor := Supervisor{
+			OnError:      func(err error) { errs = append(errs, err) },
+			Restart:      true,
+			RestartDelay: time.Millisecond,
+		}
+
+		runs := 0
+
+		<-supervisor.Go(context.Background(), func(context.Context) error {
+			runs++
+
+			if runs == 1 {
+				runtime.Goexit()
+			}
+
+			return nil
+		})
+
+		require.Equal(t, 2, runs)
+		require.Len(t, errs, 1)
+		require.ErrorIs(t, errs[0], ErrGoexit)
+	})
+
+	t.Run("stops when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		supervisor := Supervisor{
+			OnError:      func(error) { cancel() },
+			Restart:      true,
+			RestartDelay: time.Hour,
+		}
+
+		done := supervisor.Go(ctx, func(ctx context.Context) error {
+			return errors.New("failed") //nolint:err113
+		})
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("supervisor didn't stop")
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// graphNode is one error of a chain as ToDOT and ToMermaid draw it.
+type graphNode struct {
+	lines    []string // Message, then function and location if there are any
+	children []int    // Indexes of the errors it wraps or joins
+}
+
+// ToDOT renders err's wrap and join structure as a Graphviz digraph, one box
+// per error with its message, function and location, and an edge from every
+// error to each one it wraps or joins, for design docs and postmortems about
+// fan-out failures:
+//
+//	digraph chain {
+//		node [shape=box];
+//		n0 [label="load users\napp.loadAll\n/src/app/user.go:42"];
+//		n1 [label="(2 joined errors)"];
+//		n0 -> n1;
+//		...
+//	}
+//
+// Errors that aren't *CTXError only show the text they add to what they wrap,
+// like in FormatTable, and messages are escaped like Error() escapes them.
+// It returns an empty string for a nil error.
+func ToDOT(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	nodes := chainGraph(err)
+
+	var builder strings.Builder
+
+	builder.WriteString("digraph chain {\n\tnode [shape=box];\n")
+
+	for i, node := range nodes {
+		label := make([]string, len(node.lines))
+		for j, line := range node.lines {
+			label[j] = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(line)
+		}
+
+		fmt.Fprintf(&builder, "\tn%d [label=\"%s\"];\n", i, strings.Join(label, `\n`))
+	}
+
+	for i, node := range nodes {
+		for _, child := range node.children {
+			fmt.Fprintf(&builder, "\tn%d -> n%d;\n", i, child)
+		}
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+// ToMermaid renders the same graph as ToDOT as a Mermaid flowchart, for
+// Markdown that renders those, like GitHub's:
+//
+//	flowchart TD
+//		n0["load users<br/>app.loadAll<br/>/src/app/user.go:42"]
+//		n1["(2 joined errors)"]
+//		n0 --> n1
+//		...
+//
+// It returns an empty string for a nil error.
+func ToMermaid(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	nodes := chainGraph(err)
+
+	var builder strings.Builder
+
+	builder.WriteString("flowchart TD\n")
+
+	for i, node := range nodes {
+		label := make([]string, len(node.lines))
+		for j, line := range node.lines {
+			label[j] = strings.NewReplacer("#", "#35;", `"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(line)
+		}
+
+		fmt.Fprintf(&builder, "\tn%d[\"%s\"]\n", i, strings.Join(label, "<br/>"))
+	}
+
+	for i, node := range nodes {
+		for _, child := range node.children {
+			fmt.Fprintf(&builder, "\tn%d --> n%d\n", i, child)
+		}
+	}
+
+	return builder.String()
+}
+
+// chainGraph returns the nodes of err's wrap and join structure, err first and
+// the rest in the order walk visits them.
+func chainGraph(err error) []graphNode {
+	style := currentConfig().funcNameStyle
+
+	var (
+		nodes []graphNode
+		add   func(err error) int
+	)
+
+	add = func(err error) int {
+		index := len(nodes)
+		nodes = append(nodes, graphNode{lines: graphLines(err, style), children: nil})
+
+		var below []error
+
+		if joined, ok := members(err); ok {
+			below = joined
+		} else if wrapper, ok := err.(interface{ Unwrap() error }); ok { //nolint:errorlint
+			below = []error{wrapper.Unwrap()}
+		}
+
+		for _, inner := range below {
+			if inner != nil {
+				child := add(inner)
+				nodes[index].children = append(nodes[index].children, child)
+			}
+		}
+
+		return index
+	}
+
+	add(err)
+
+	return nodes
+}
+
+// graphLines returns what the node of err says, as FormatTable shows it.
+func graphLines(err error, style FuncNameStyle) []string {
+	layer, ok := asCTXError(err)
+	if !ok {
+		if joined, ok := members(err); ok {
+			return []string{fmt.Sprintf("(%d joined errors)", len(joined))}
+		}
+
+		return []string{escapeMessage(ownMessage(err))}
+	}
+
+	lines := []string{escapeMessage(layer.msg())}
+
+	if layer.funcName != "" {
+		lines = append(lines, formatFuncName(layer.funcName, style))
+	}
+
+	if layer.file != "" {
+		lines = append(lines, layer.file+":"+strconv.Itoa(layer.line))
+	}
+
+	return lines
+}
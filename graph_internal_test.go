@@ -0,0 +1,73 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToDOTAndToMermaid(t *testing.T) {
+	dial := &CTXError{
+		err:      errors.New(`refused "db"`), //nolint:err113
+		message:  "dial <primary>",
+		file:     "/src/app/db.go",
+		line:     12,
+		funcName: "github.com/acme/app.dial",
+	}
+	cache := fmt.Errorf("cache: %w", errors.New("miss #1")) //nolint:err113
+	top := &CTXError{
+		err:      errors.Join(dial, cache),
+		message:  "load users",
+		file:     "/src/app/user.go",
+		line:     42,
+		funcName: "github.com/acme/app.(*Users).loadAll",
+	}
+
+	testCases := []struct {
+		name            string
+		err             error
+		expectedDOT     string
+		expectedMermaid string
+	}{
+		{name: "nil error", err: nil, expectedDOT: "", expectedMermaid: ""},
+		{
+			name: "fan-out",
+			err:  top,
+			expectedDOT: "digraph chain {\n" +
+				"\tnode [shape=box];\n" +
+				"\tn0 [label=\"load users\\ngithub.com/acme/app.(*Users).loadAll\\n/src/app/user.go:42\"];\n" +
+				"\tn1 [label=\"(2 joined errors)\"];\n" +
+				"\tn2 [label=\"dial <primary>\\ngithub.com/acme/app.dial\\n/src/app/db.go:12\"];\n" +
+				"\tn3 [label=\"refused \\\"db\\\"\"];\n" +
+				"\tn4 [label=\"cache\"];\n" +
+				"\tn5 [label=\"miss #1\"];\n" +
+				"\tn0 -> n1;\n" +
+				"\tn1 -> n2;\n" +
+				"\tn1 -> n4;\n" +
+				"\tn2 -> n3;\n" +
+				"\tn4 -> n5;\n" +
+				"}\n",
+			expectedMermaid: "flowchart TD\n" +
+				"\tn0[\"load users<br/>github.com/acme/app.(*Users).loadAll<br/>/src/app/user.go:42\"]\n" +
+				"\tn1[\"(2 joined errors)\"]\n" +
+				"\tn2[\"dial #lt;primary#gt;<br/>github.com/acme/app.dial<br/>/src/app/db.go:12\"]\n" +
+				"\tn3[\"refused #quot;db#quot;\"]\n" +
+				"\tn4[\"cache\"]\n" +
+				"\tn5[\"miss #35;1\"]\n" +
+				"\tn0 --> n1\n" +
+				"\tn1 --> n2\n" +
+				"\tn1 --> n4\n" +
+				"\tn2 --> n3\n" +
+				"\tn4 --> n5\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedDOT, ToDOT(tc.err))
+			require.Equal(t, tc.expectedMermaid, ToMermaid(tc.err))
+		})
+	}
+}
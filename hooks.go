@@ -0,0 +1,157 @@
+package ctxerrors
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"sync/atomic"
+)
+
+// EnrichHook is called for every error created by New, Wrap and Wrapf, before
+// it's returned to the caller. The fields it returns are attached to the new
+// layer, which is the sanctioned way to inject things like correlation IDs
+// pulled from ambient state. The error must be treated as read-only.
+type EnrichHook interface {
+	Enrich(err *CTXError) map[string]any
+}
+
+// EnrichHookFunc adapts an ordinary function to the EnrichHook interface.
+type EnrichHookFunc func(err *CTXError) map[string]any
+
+// Enrich calls f(err).
+func (f EnrichHookFunc) Enrich(err *CTXError) map[string]any {
+	return f(err)
+}
+
+// observerHook is an EnrichHook that only looks at layers and needs them to
+// carry their constructor's own fields, like QuotaTracker. finishLayer runs it
+// once those are attached and ignores the fields it returns.
+type observerHook interface {
+	EnrichHook
+	observer()
+}
+
+// enrichHookEntry pairs a registered hook with the ID used to unregister it.
+type enrichHookEntry struct {
+	id   uint64
+	hook EnrichHook
+}
+
+var enrichHookIDs atomic.Uint64 //nolint:gochecknoglobals
+
+// RegisterEnrichHook adds hook to the hooks run on every created error, in
+// registration order. A later hook wins when two return the same field key.
+// The returned function unregisters the hook.
+func RegisterEnrichHook(hook EnrichHook) func() {
+	id := enrichHookIDs.Add(1)
+
+	updateConfig(func(c *config) {
+		// Never append in place, older snapshots may share the slice
+		c.enrichHooks = append(slices.Clip(c.enrichHooks), enrichHookEntry{id: id, hook: hook})
+	})
+
+	return func() {
+		updateConfig(func(c *config) {
+			c.enrichHooks = slices.DeleteFunc(slices.Clone(c.enrichHooks), func(entry enrichHookEntry) bool {
+				return entry.id == id
+			})
+		})
+	}
+}
+
+// finishLayer completes layer once its constructor has set everything else
+// on it. It stamps the creation time and the go statements behind its
+// goroutine if SetTimestamps and SetGoroutineAncestry ask for them, applies
+// the package policies covering it and runs every registered EnrichHook on
+// it. fields, the constructor's own, are attached after the hooks' and win,
+// and the observer hooks run after that. The SetDebugDump dump comes next
+// unless layer has one already, then wraps get vetted as SetVet and
+// SetWarningHook say. It returns layer, or the error a SetInjections
+// injection replaces it with, and publishes layer to the Subscribe
+// subscribers only in the former case. A panicking hook is logged and skipped
+// so a broken hook can't take down the code that's just trying to return an
+// error.
+func finishLayer(layer *CTXError, fields map[string]any) error {
+	// Every constructor comes through here, so count them here too
+	if layer.err == nil {
+		countActivity(&activity.created)
+	} else {
+		countActivity(&activity.wrapped)
+	}
+
+	cfg := currentConfig()
+
+	switch {
+	case cfg.timestamps && cfg.deterministic:
+		layer.created = deterministicTime()
+	case cfg.timestamps:
+		layer.created = cfg.now()
+	}
+
+	if cfg.ancestry {
+		captureAncestry(cfg, layer)
+	}
+
+	applyPackagePolicies(cfg.packagePolicies, layer)
+
+	for _, entry := range cfg.enrichHooks {
+		if _, ok := entry.hook.(observerHook); ok {
+			continue
+		}
+
+		hookFields := callEnrichHook(entry.hook, layer)
+		if len(hookFields) == 0 {
+			continue
+		}
+
+		if layer.fields == nil {
+			layer.fields = make(map[string]any, len(hookFields))
+		}
+
+		maps.Copy(layer.fields, hookFields)
+	}
+
+	withFields(layer, fields)
+
+	for _, entry := range cfg.enrichHooks {
+		if _, ok := entry.hook.(observerHook); ok {
+			callEnrichHook(entry.hook, layer)
+		}
+	}
+
+	if layer.dump == nil {
+		attachDebugDump(layer)
+	}
+
+	if layer.err != nil && (cfg.vet != nil || cfg.warningHook != nil) {
+		vetLayer(cfg, layer)
+	}
+
+	var result error = layer
+
+	if len(cfg.injections) > 0 {
+		result = injectFailure(cfg.injections, layer)
+	}
+
+	// Last, so subscribers see the layer as its creator gets it, and not at
+	// all if an injection replaced it
+	if len(cfg.subscribers) > 0 && result == error(layer) {
+		publishEvent(layer, cfg.subscribers)
+	}
+
+	return result
+}
+
+// callEnrichHook calls hook, recovering from any panic in it.
+func callEnrichHook(hook EnrichHook, err *CTXError) (fields map[string]any) { //nolint:nonamedreturns
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Enrich hook panicked", "panic", r)
+			countActivity(&activity.hookFailures)
+
+			fields = nil
+		}
+	}()
+
+	return hook.Enrich(err)
+}
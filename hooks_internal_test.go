@@ -0,0 +1,109 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterEnrichHook(t *testing.T) { //nolint:funlen
+	baseErr := errors.New("base error") //nolint:err113
+
+	t.Run("fields are attached to every created layer", func(t *testing.T) {
+		unregister := RegisterEnrichHook(EnrichHookFunc(func(_ *CTXError) map[string]any {
+			return map[string]any{"correlation_id": "abc-123"}
+		}))
+		t.Cleanup(unregister)
+
+		for _, err := range []error{
+			New("new"),
+			Wrap(baseErr, "wrap"),
+			Wrapf(baseErr, "wrapf %d", 1),
+		} {
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(err, &ctxErr))
+			require.Equal(t, map[string]any{"correlation_id": "abc-123"}, ctxErr.Fields())
+		}
+	})
+
+	t.Run("hooks see the error being created", func(t *testing.T) {
+		var seen *CTXError
+
+		unregister := RegisterEnrichHook(EnrichHookFunc(func(err *CTXError) map[string]any {
+			seen = err
+
+			return nil
+		}))
+		t.Cleanup(unregister)
+
+		err := Wrap(baseErr, "context")
+
+		require.Equal(t, err, seen)
+		require.Equal(t, "context", seen.Message())
+		require.Contains(t, seen.FuncName(), "TestRegisterEnrichHook")
+		require.Nil(t, seen.Fields())
+	})
+
+	t.Run("later hooks win", func(t *testing.T) {
+		first := RegisterEnrichHook(EnrichHookFunc(func(_ *CTXError) map[string]any {
+			return map[string]any{"source": "first", "first": true}
+		}))
+		t.Cleanup(first)
+
+		second := RegisterEnrichHook(EnrichHookFunc(func(_ *CTXError) map[string]any {
+			return map[string]any{"source": "second"}
+		}))
+		t.Cleanup(second)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(New("layered"), &ctxErr))
+		require.Equal(t, map[string]any{"source": "second", "first": true}, ctxErr.Fields())
+	})
+
+	t.Run("unregister removes only that hook", func(t *testing.T) {
+		keep := RegisterEnrichHook(EnrichHookFunc(func(_ *CTXError) map[string]any {
+			return map[string]any{"kept": true}
+		}))
+		t.Cleanup(keep)
+
+		remove := RegisterEnrichHook(EnrichHookFunc(func(_ *CTXError) map[string]any {
+			return map[string]any{"removed": true}
+		}))
+		remove()
+		remove() // Unregistering twice is harmless
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(New("after unregister"), &ctxErr))
+		require.Equal(t, map[string]any{"kept": true}, ctxErr.Fields())
+	})
+
+	t.Run("panicking hook is skipped", func(t *testing.T) {
+		broken := RegisterEnrichHook(EnrichHookFunc(func(_ *CTXError) map[string]any {
+			panic("hook blew up")
+		}))
+		t.Cleanup(broken)
+
+		working := RegisterEnrichHook(EnrichHookFunc(func(_ *CTXError) map[string]any {
+			return map[string]any{"ok": true}
+		}))
+		t.Cleanup(working)
+
+		var ctxErr *CTXError
+
+		require.NotPanics(t, func() {
+			require.True(t, errors.As(New("survives"), &ctxErr))
+		})
+		require.Equal(t, map[string]any{"ok": true}, ctxErr.Fields())
+	})
+
+	t.Run("no hooks means no fields", func(t *testing.T) {
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(New("bare"), &ctxErr))
+		require.Nil(t, ctxErr.Fields())
+	})
+}
@@ -0,0 +1,88 @@
+package ctxerrors
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	idPrefixBytes = 4  // Random bytes making up the per-process ID prefix
+	idCounterBase = 36 // Base the counter part of an ID is rendered in
+)
+
+// IDGenerator returns a new unique instance ID.
+type IDGenerator func() string
+
+var (
+	idCounter atomic.Uint64                 //nolint:gochecknoglobals
+	idPrefix  = sync.OnceValue(newIDPrefix) //nolint:gochecknoglobals
+)
+
+// SetIDGenerator sets what makes the instance IDs SetInstanceIDs turns on, so
+// tests and simulation testing can control them. It takes precedence over the
+// placeholders of SetDeterministic. Pass nil to go back to the built-in
+// generator, which is the default.
+func SetIDGenerator(generator IDGenerator) {
+	updateConfig(func(c *config) {
+		c.idGenerator = generator
+	})
+}
+
+// WithInstanceID returns err with the instance ID of its outermost layer set
+// to id, for IDs that come from elsewhere, e.g. errors replayed from another
+// process' logs or test fixtures. Like WithField it never modifies err and
+// wraps anything that isn't a *CTXError in a new layer created at the caller.
+// It returns nil if err is nil.
+func WithInstanceID(err error, id string) error {
+	if isNilError(err) {
+		return nil
+	}
+
+	layer, ok := asCTXError(err)
+	if !ok {
+		// Skip WithInstanceID() and newLayer() to get user's caller
+		framesToSkip := 2
+
+		layer = newLayer(err, "", framesToSkip)
+		layer.id = id
+
+		return finishLayer(layer, nil)
+	}
+
+	annotated := *layer
+	annotated.id = id
+
+	return &annotated
+}
+
+// newInstanceID returns a new unique instance ID, or an empty string if
+// instance IDs are off. Unless SetIDGenerator says otherwise, IDs are a random
+// per-process prefix followed by a base36 counter, e.g. "9f86d081-2s".
+func newInstanceID() string {
+	cfg := currentConfig()
+	if !cfg.instanceIDs {
+		return ""
+	}
+
+	if cfg.idGenerator != nil {
+		return cfg.idGenerator()
+	}
+
+	if cfg.deterministic {
+		return DeterministicIDPrefix + "-" + strconv.FormatUint(deterministicIDs.Add(1), idCounterBase)
+	}
+
+	return idPrefix() + "-" + strconv.FormatUint(idCounter.Add(1), idCounterBase)
+}
+
+// newIDPrefix returns a random hex string telling this process' IDs apart
+// from those of other processes and restarts.
+func newIDPrefix() string {
+	buf := make([]byte, idPrefixBytes)
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,159 @@
+package ctxerrors
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceIDs(t *testing.T) {
+	t.Cleanup(func() {
+		SetInstanceIDs(false)
+		SetHideLocation(false)
+	})
+
+	idRegexp := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-z]+$`)
+	baseErr := errors.New("base error") //nolint:err113
+
+	t.Run("off by default", func(t *testing.T) {
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(New("no id"), &ctxErr))
+		require.Empty(t, ctxErr.ID())
+		require.NotContains(t, ctxErr.Error(), "[id=")
+	})
+
+	t.Run("every layer gets its own id", func(t *testing.T) {
+		SetInstanceIDs(true)
+
+		inner := New("inner")
+		outer := Wrap(inner, "outer")
+		formatted := Wrapf(baseErr, "code=%d", 42)
+
+		seen := map[string]bool{}
+
+		for _, err := range []error{inner, outer, formatted} {
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(err, &ctxErr))
+			require.Regexp(t, idRegexp, ctxErr.ID())
+			require.Contains(t, ctxErr.Error(), "[id="+ctxErr.ID()+"]")
+			require.False(t, seen[ctxErr.ID()])
+
+			seen[ctxErr.ID()] = true
+		}
+	})
+
+	t.Run("rendered even with hidden locations", func(t *testing.T) {
+		SetInstanceIDs(true)
+		SetHideLocation(true)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(New("user facing"), &ctxErr))
+		require.Equal(t, "user facing [id="+ctxErr.ID()+"]", ctxErr.Error())
+	})
+
+	t.Run("unique across goroutines", func(t *testing.T) {
+		SetInstanceIDs(true)
+
+		const goroutines, perGoroutine = 8, 100
+
+		ids := make(chan string, goroutines*perGoroutine)
+
+		var wg sync.WaitGroup
+
+		for range goroutines {
+			wg.Go(func() {
+				for range perGoroutine {
+					var ctxErr *CTXError
+					if errors.As(New("concurrent"), &ctxErr) {
+						ids <- ctxErr.ID()
+					}
+				}
+			})
+		}
+
+		wg.Wait()
+		close(ids)
+
+		seen := map[string]bool{}
+		for id := range ids {
+			require.False(t, seen[id])
+
+			seen[id] = true
+		}
+
+		require.Len(t, seen, goroutines*perGoroutine)
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		var ctxErr *CTXError
+
+		require.Empty(t, ctxErr.ID())
+	})
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	t.Cleanup(func() {
+		SetInstanceIDs(false)
+		SetIDGenerator(nil)
+	})
+
+	next := 0
+	SetIDGenerator(func() string {
+		next++
+
+		return "sim-" + strconv.Itoa(next)
+	})
+
+	var ctxErr *CTXError
+
+	require.True(t, errors.As(New("ids off"), &ctxErr))
+	require.Empty(t, ctxErr.ID())
+	require.Zero(t, next)
+
+	SetInstanceIDs(true)
+
+	require.True(t, errors.As(New("first"), &ctxErr))
+	require.Equal(t, "sim-1", ctxErr.ID())
+
+	// Wins over the deterministic placeholders
+	SetDeterministic(t)
+
+	require.True(t, errors.As(Wrap(ctxErr, "second"), &ctxErr))
+	require.Equal(t, "sim-2", ctxErr.ID())
+
+	SetIDGenerator(nil)
+
+	require.True(t, errors.As(New("built-in"), &ctxErr))
+	require.Equal(t, DeterministicIDPrefix+"-1", ctxErr.ID())
+}
+
+func TestWithInstanceID(t *testing.T) {
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WithInstanceID(nil, "abc-1"))
+	})
+
+	t.Run("ctx error", func(t *testing.T) {
+		original := New("boom")
+		actual := WithInstanceID(original, "abc-1")
+
+		require.Equal(t, "abc-1", FirstContext(actual).ID())
+		require.Empty(t, FirstContext(original).ID())
+		require.Equal(t, []string{"boom"}, Messages(actual))
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		baseErr := errors.New("boom") //nolint:err113
+		actual := WithInstanceID(baseErr, "abc-1")
+
+		require.ErrorIs(t, actual, baseErr)
+		require.Equal(t, "abc-1", FirstContext(actual).ID())
+		require.Equal(t, "github.com/psyb0t/ctxerrors.TestWithInstanceID.func3", FirstContext(actual).FuncName())
+	})
+}
@@ -0,0 +1,89 @@
+package ctxerrors
+
+import (
+	"path"
+	"slices"
+	"time"
+)
+
+// Injection is a failure SetInjections forces on the errors created in
+// matching functions.
+type Injection struct {
+	// Func is a path.Match pattern for the function creating the error,
+	// matched against both its full name and its FuncNameShort form, e.g.
+	// "store.DB.*" or "github.com/acme/app/store.(*DB).Get".
+	Func  string
+	Delay time.Duration // Slept before the error is returned
+	Err   error         // Returned instead of the error created, nil keeps it
+}
+
+// SetInjections makes every constructor, New, Wrap and all their variants,
+// apply the first of injections matching the function it's called from for
+// the rest of t, so tests can find out how the code handling those errors
+// copes with slow failures or with different errors, chaos style, without
+// touching production code:
+//
+//	ctxerrors.SetInjections(t, ctxerrors.Injection{
+//		Func:  "store.DB.Get",
+//		Delay: 2 * time.Second,
+//		Err:   ctxerrors.WithKind(ctxerrors.New("injected"), ctxerrors.KindUnavailable),
+//	})
+//
+// Only errors that happen anyway are affected, a call site that doesn't fail
+// never gets an injected error. The previous injections are restored when t
+// finishes. Like the other settings it's package-wide, so don't combine it
+// with t.Parallel.
+func SetInjections(t TestingT, injections ...Injection) error {
+	t.Helper()
+
+	for _, injection := range injections {
+		if _, err := path.Match(injection.Func, ""); err != nil {
+			return Wrapf(err, "invalid injection pattern %q", injection.Func)
+		}
+	}
+
+	previous := currentConfig().injections
+
+	updateConfig(func(c *config) {
+		c.injections = slices.Clone(injections)
+	})
+
+	t.Cleanup(func() {
+		updateConfig(func(c *config) {
+			c.injections = previous
+		})
+	})
+
+	return nil
+}
+
+// injectFailure applies the first of injections matching the function layer
+// was created in, returning layer or the error replacing it.
+func injectFailure(injections []Injection, layer *CTXError) error {
+	if layer.funcName == "" {
+		return layer
+	}
+
+	short := formatFuncName(layer.funcName, FuncNameShort)
+
+	for _, injection := range injections {
+		full, _ := path.Match(injection.Func, layer.funcName)
+		shortened, _ := path.Match(injection.Func, short)
+
+		if !full && !shortened {
+			continue
+		}
+
+		if injection.Delay > 0 {
+			time.Sleep(injection.Delay)
+		}
+
+		if injection.Err != nil {
+			return injection.Err
+		}
+
+		return layer
+	}
+
+	return layer
+}
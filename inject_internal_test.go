@@ -0,0 +1,83 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errInjected = errors.New("injected") //nolint:gochecknoglobals
+
+//go:noinline
+func injectTargetNew() error {
+	return New("lookup failed")
+}
+
+//go:noinline
+func injectTargetWrap(err error) error {
+	return Wrap(err, "query failed")
+}
+
+//go:noinline
+func injectTargetVariants(err error) []error {
+	return []error{
+		Newf("lookup %d failed", 42),
+		Wrapf(err, "query %s failed", "users"),
+		NewSkip(0, "lookup failed"),
+		NewfSkip(0, "lookup %d failed", 42),
+		WrapSkip(err, 0, "query failed"),
+		WrapfSkip(err, 0, "query %s failed", "users"),
+		NewDepth(2, "lookup failed"),
+		WrapDepth(err, 2, "query failed"),
+		WithField(err, "table", "users"),
+	}
+}
+
+func TestSetInjections(t *testing.T) {
+	baseErr := errors.New("connection reset") //nolint:err113
+
+	t.Run("substitutes errors", func(t *testing.T) {
+		require.NoError(t, SetInjections(t,
+			Injection{Func: "ctxerrors.injectTargetNew", Err: errInjected},
+			Injection{Func: "github.com/psyb0t/ctxerrors.injectTarget*", Err: nil},
+		))
+
+		require.Equal(t, 2, CurrentConfig().Injections)
+		require.ErrorIs(t, injectTargetNew(), errInjected)
+
+		wrapped := injectTargetWrap(baseErr)
+		require.NotErrorIs(t, wrapped, errInjected)
+		require.ErrorIs(t, wrapped, baseErr)
+
+		require.NotErrorIs(t, New("untouched"), errInjected)
+	})
+
+	t.Run("covers every constructor", func(t *testing.T) {
+		require.NoError(t, SetInjections(t, Injection{Func: "ctxerrors.injectTargetVariants", Err: errInjected}))
+
+		for i, err := range injectTargetVariants(baseErr) {
+			require.ErrorIs(t, err, errInjected, "constructor %d", i)
+		}
+	})
+
+	t.Run("adds latency", func(t *testing.T) {
+		delay := 20 * time.Millisecond
+
+		require.NoError(t, SetInjections(t, Injection{Func: "ctxerrors.injectTargetWrap", Delay: delay, Err: nil}))
+
+		start := time.Now()
+		err := injectTargetWrap(baseErr)
+
+		require.GreaterOrEqual(t, time.Since(start), delay)
+		require.ErrorIs(t, err, baseErr)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		require.Error(t, SetInjections(t, Injection{Func: "[", Delay: 0, Err: errInjected}))
+	})
+
+	require.Zero(t, CurrentConfig().Injections)
+	require.NotErrorIs(t, injectTargetNew(), errInjected)
+}
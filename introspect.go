@@ -0,0 +1,113 @@
+package ctxerrors
+
+import "slices"
+
+// Config is a snapshot of the package-wide settings as CurrentConfig returns
+// it, for applications asserting their error handling setup at startup or
+// showing it on a diagnostics endpoint. Settings made of functions or
+// interfaces only say whether they're set, registrations how many there are.
+type Config struct {
+	HideLocation    bool            `json:"hide_location"`
+	CaptureMode     CaptureMode     `json:"capture_mode"`
+	NormalizePaths  bool            `json:"normalize_paths"`
+	SourceMapper    bool            `json:"source_mapper"`
+	InstanceIDs     bool            `json:"instance_ids"`
+	EnrichHooks     int             `json:"enrich_hooks"`
+	Separator       string          `json:"separator"`
+	QueryArgsPolicy QueryArgsPolicy `json:"query_args_policy"`
+	CallerDepth     int             `json:"caller_depth"`
+	StackIf         bool            `json:"stack_if"`
+	DebugDump       bool            `json:"debug_dump"`
+	RedactedFields  []string        `json:"redacted_fields,omitempty"` // Lowercased
+	FieldPrecedence FieldPrecedence `json:"field_precedence"`
+	FieldProviders  int             `json:"field_providers"`
+	Deterministic   bool            `json:"deterministic"`
+	SentinelKinds   []SentinelKind  `json:"-"` // Holds errors, which don't encode
+	AnonymizePaths  bool            `json:"anonymize_paths"`
+	MaxLayerMessage int             `json:"max_layer_message"`
+	MaxTotalMessage int             `json:"max_total_message"`
+	CountActivity   bool            `json:"count_activity"`
+	Subscribers     int             `json:"subscribers"`
+	Clock           bool            `json:"clock"`
+	IDGenerator     bool            `json:"id_generator"`
+	Resolver        bool            `json:"resolver"`
+	FieldMarshalers int             `json:"field_marshalers"`
+	Vet             bool            `json:"vet"`
+	VetMode         VetMode         `json:"vet_mode"`
+	Timestamps      bool            `json:"timestamps"`
+	CollapseRepeats bool            `json:"collapse_repeats"`
+	FuncNameStyle   FuncNameStyle   `json:"func_name_style"`
+	WarningHook     bool            `json:"warning_hook"`
+	WarnDepth       int             `json:"warn_depth"`
+	FormatCheck     FormatCheck     `json:"format_check"`
+	InferOps        bool            `json:"infer_ops"`
+	Injections      int             `json:"injections"`
+	PackagePolicies int             `json:"package_policies"`
+	Ancestry        bool            `json:"ancestry"`
+}
+
+// CurrentConfig returns a copy of the settings errors are created and
+// rendered with right now. Changing it changes nothing, use the Set and
+// Register functions for that.
+func CurrentConfig() Config {
+	return currentConfig().export()
+}
+
+// export returns the settings of cfg as a Config.
+func (cfg *config) export() Config {
+	return Config{
+		HideLocation:    cfg.hideLocation,
+		CaptureMode:     cfg.captureMode,
+		NormalizePaths:  cfg.normalizePaths,
+		SourceMapper:    cfg.sourceMapper != nil,
+		InstanceIDs:     cfg.instanceIDs,
+		EnrichHooks:     len(cfg.enrichHooks),
+		Separator:       cfg.separator,
+		QueryArgsPolicy: cfg.queryArgsPolicy,
+		CallerDepth:     cfg.callerDepth,
+		StackIf:         cfg.stackIf != nil,
+		DebugDump:       cfg.debugDump != nil,
+		RedactedFields:  slices.Clone(cfg.redactedFields),
+		FieldPrecedence: cfg.fieldPrecedence,
+		FieldProviders:  len(cfg.fieldProviders),
+		Deterministic:   cfg.deterministic,
+		SentinelKinds:   slices.Clone(cfg.sentinelKinds),
+		AnonymizePaths:  cfg.anonymizePaths,
+		MaxLayerMessage: cfg.maxLayerMessage,
+		MaxTotalMessage: cfg.maxTotalMessage,
+		CountActivity:   cfg.countActivity,
+		Subscribers:     len(cfg.subscribers),
+		Clock:           cfg.clock != nil,
+		IDGenerator:     cfg.idGenerator != nil,
+		Resolver:        cfg.resolver != nil,
+		FieldMarshalers: len(cfg.fieldMarshalers),
+		Vet:             cfg.vet != nil,
+		VetMode:         cfg.vetMode,
+		Timestamps:      cfg.timestamps,
+		CollapseRepeats: cfg.collapseRepeats,
+		FuncNameStyle:   cfg.funcNameStyle,
+		WarningHook:     cfg.warningHook != nil,
+		WarnDepth:       cfg.warnDepth,
+		FormatCheck:     cfg.formatCheck,
+		InferOps:        cfg.inferOps,
+		Injections:      len(cfg.injections),
+		PackagePolicies: len(cfg.packagePolicies),
+		Ancestry:        cfg.ancestry,
+	}
+}
+
+// ActiveHooks returns the registered EnrichHooks in the order they run.
+func ActiveHooks() []EnrichHook {
+	entries := currentConfig().enrichHooks
+	if len(entries) == 0 {
+		return nil
+	}
+
+	hooks := make([]EnrichHook, 0, len(entries))
+
+	for _, entry := range entries {
+		hooks = append(hooks, entry.hook)
+	}
+
+	return hooks
+}
@@ -0,0 +1,74 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentConfig(t *testing.T) {
+	defaults := CurrentConfig()
+	require.True(t, defaults.NormalizePaths)
+	require.Equal(t, DefaultSeparator, defaults.Separator)
+	require.Equal(t, DefaultSentinelKinds(), defaults.SentinelKinds)
+	require.Zero(t, defaults.EnrichHooks)
+	require.False(t, defaults.SourceMapper)
+
+	// Every setting has to be in the snapshot
+	require.Equal(t, reflect.TypeFor[config]().NumField(), reflect.TypeFor[Config]().NumField())
+
+	t.Cleanup(func() {
+		SetSeparator("")
+		SetCallerDepth(0)
+		SetSourceMapper(nil)
+		SetFuncNameStyle(FuncNameDefault)
+	})
+
+	SetSeparator(" -> ")
+	SetCallerDepth(3)
+	SetSourceMapper(func(file string, line int) (string, int) { return file, line })
+	SetFuncNameStyle(FuncNameShort)
+
+	unregister := RegisterEnrichHook(EnrichHookFunc(func(*CTXError) map[string]any { return nil }))
+	t.Cleanup(unregister)
+
+	cfg := CurrentConfig()
+	require.Equal(t, " -> ", cfg.Separator)
+	require.Equal(t, 3, cfg.CallerDepth)
+	require.True(t, cfg.SourceMapper)
+	require.Equal(t, FuncNameShort, cfg.FuncNameStyle)
+	require.Equal(t, 1, cfg.EnrichHooks)
+
+	cfg.SentinelKinds[0].Kind = KindInternal
+	require.Equal(t, DefaultSentinelKinds(), CurrentConfig().SentinelKinds)
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"caller_depth":3`)
+	require.NotContains(t, string(data), "SentinelKinds")
+}
+
+func TestActiveHooks(t *testing.T) {
+	require.Empty(t, ActiveHooks())
+
+	first := EnrichHookFunc(func(*CTXError) map[string]any { return map[string]any{"hook": 1} })
+	second := EnrichHookFunc(func(*CTXError) map[string]any { return map[string]any{"hook": 2} })
+
+	unregisterFirst := RegisterEnrichHook(first)
+	unregisterSecond := RegisterEnrichHook(second)
+
+	t.Cleanup(unregisterSecond)
+
+	hooks := ActiveHooks()
+	require.Len(t, hooks, 2)
+	require.Equal(t, map[string]any{"hook": 1}, hooks[0].Enrich(nil))
+	require.Equal(t, map[string]any{"hook": 2}, hooks[1].Enrich(nil))
+
+	unregisterFirst()
+
+	hooks = ActiveHooks()
+	require.Len(t, hooks, 1)
+	require.Equal(t, map[string]any{"hook": 2}, hooks[0].Enrich(nil))
+}
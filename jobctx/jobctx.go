@@ -0,0 +1,115 @@
+// Package jobctx makes the errors of worker pool jobs carry which job they
+// came from, without every handler annotating them by hand. The pool puts the
+// job in the context it hands the handler and runs it through Run:
+//
+//	defer ctxerrors.RegisterFieldProvider(jobctx.Provider())()
+//
+//	err := jobctx.Run(ctx, jobctx.Job{ID: msg.ID, Queue: "emails", EnqueuedAt: msg.Timestamp}, handleEmail)
+//
+// Errors created under the context with ctxerrors.NewCtx and WrapCtx get the
+// job's fields from the provider, and Run adds them to whatever the handler
+// returns if it doesn't carry them yet, so errors made with plain New and Wrap
+// don't go without.
+package jobctx
+
+import (
+	"context"
+	"time"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Field keys the job is recorded under.
+const (
+	FieldJobID      = "job_id"
+	FieldQueue      = "queue"
+	FieldEnqueuedAt = "enqueued_at"
+)
+
+// Job describes the job a worker is processing.
+type Job struct {
+	ID         string
+	Queue      string
+	EnqueuedAt time.Time
+}
+
+// Fields returns the fields recording j, leaving out the ones it doesn't
+// have. It returns nil for the zero Job.
+func (j Job) Fields() map[string]any {
+	var fields map[string]any
+
+	set := func(key string, value any) {
+		if fields == nil {
+			fields = make(map[string]any, 3) //nolint:mnd
+		}
+
+		fields[key] = value
+	}
+
+	if j.ID != "" {
+		set(FieldJobID, j.ID)
+	}
+
+	if j.Queue != "" {
+		set(FieldQueue, j.Queue)
+	}
+
+	if !j.EnqueuedAt.IsZero() {
+		set(FieldEnqueuedAt, j.EnqueuedAt)
+	}
+
+	return fields
+}
+
+// jobKey is the context key the current job is stored under.
+type jobKey struct{}
+
+// WithJob returns a copy of ctx carrying job as the one being processed.
+func WithJob(ctx context.Context, job Job) context.Context {
+	return context.WithValue(ctx, jobKey{}, job)
+}
+
+// FromContext returns the job WithJob put in ctx, if any.
+func FromContext(ctx context.Context) (Job, bool) {
+	if ctx == nil {
+		return Job{}, false
+	}
+
+	job, ok := ctx.Value(jobKey{}).(Job)
+
+	return job, ok
+}
+
+// Provider returns a ctxerrors.FieldProvider recording the job carried by the
+// context passed to NewCtx or WrapCtx. Register it with
+// ctxerrors.RegisterFieldProvider.
+func Provider() ctxerrors.FieldProvider {
+	return func(ctx context.Context) map[string]any {
+		job, ok := FromContext(ctx)
+		if !ok {
+			return nil
+		}
+
+		return job.Fields()
+	}
+}
+
+// Run calls handler with ctx carrying job and returns its error with the
+// fields of job added to its outermost layer, leaving out those the chain
+// already has. It returns nil if handler does.
+func Run(ctx context.Context, job Job, handler func(ctx context.Context) error) error {
+	err := handler(WithJob(ctx, job))
+	if err == nil {
+		return nil
+	}
+
+	present := ctxerrors.Fields(err)
+
+	for key, value := range job.Fields() {
+		if _, ok := present[key]; !ok {
+			err = ctxerrors.WithField(err, key, value)
+		}
+	}
+
+	return err
+}
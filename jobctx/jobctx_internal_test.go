@@ -0,0 +1,94 @@
+package jobctx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestRun(t *testing.T) {
+	enqueuedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	job := Job{ID: "job-42", Queue: "emails", EnqueuedAt: enqueuedAt}
+
+	unregister := ctxerrors.RegisterFieldProvider(Provider())
+	t.Cleanup(unregister)
+
+	baseErr := errors.New("smtp down") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		handler  func(ctx context.Context) error
+		expected map[string]any
+	}{
+		{
+			name:     "success",
+			handler:  func(context.Context) error { return nil },
+			expected: nil,
+		},
+		{
+			name: "plain wrap",
+			handler: func(context.Context) error {
+				return ctxerrors.Wrap(baseErr, "send email")
+			},
+			expected: map[string]any{FieldJobID: "job-42", FieldQueue: "emails", FieldEnqueuedAt: enqueuedAt},
+		},
+		{
+			name: "foreign error",
+			handler: func(context.Context) error {
+				return baseErr
+			},
+			expected: map[string]any{FieldJobID: "job-42", FieldQueue: "emails", FieldEnqueuedAt: enqueuedAt},
+		},
+		{
+			name: "wrap under the context",
+			handler: func(ctx context.Context) error {
+				return ctxerrors.Wrap(ctxerrors.WrapCtx(ctx, baseErr, "send email"), "process")
+			},
+			expected: map[string]any{FieldJobID: "job-42", FieldQueue: "emails", FieldEnqueuedAt: enqueuedAt},
+		},
+		{
+			name: "fields already set win",
+			handler: func(context.Context) error {
+				return ctxerrors.WithField(ctxerrors.Wrap(baseErr, "send email"), FieldQueue, "emails-retry")
+			},
+			expected: map[string]any{FieldJobID: "job-42", FieldQueue: "emails-retry", FieldEnqueuedAt: enqueuedAt},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Run(context.Background(), job, tc.handler)
+
+			if tc.expected == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, baseErr)
+			require.Equal(t, tc.expected, ctxerrors.Fields(err))
+		})
+	}
+}
+
+func TestProvider(t *testing.T) {
+	provider := Provider()
+
+	require.Nil(t, provider(context.Background()))
+	require.Equal(t, map[string]any{FieldJobID: "job-1"}, provider(WithJob(context.Background(), Job{ID: "job-1"})))
+	require.Nil(t, provider(WithJob(context.Background(), Job{})))
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	job, ok := FromContext(WithJob(context.Background(), Job{ID: "job-1", Queue: "emails"}))
+	require.True(t, ok)
+	require.Equal(t, Job{ID: "job-1", Queue: "emails"}, job)
+}
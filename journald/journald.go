@@ -0,0 +1,158 @@
+// Package journald sends ctxerrors chains to the systemd journal with their
+// locations and fields as structured journal fields, so daemons' errors can be
+// filtered with journalctl by where they came from, e.g.
+//
+//	journalctl CODE_FUNC=github.com/user/app.(*Store).Load
+//	journalctl CTXERR_KIND=not_found
+package journald
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// DefaultSocket is where journald listens for the native protocol.
+const DefaultSocket = "/run/systemd/journal/socket"
+
+// FieldPrefix goes in front of the journal field names of an error's fields.
+const FieldPrefix = "CTXERR_"
+
+// maxFieldName is the longest field name journald accepts.
+const maxFieldName = 64
+
+// Syslog priorities errors get sent with.
+const (
+	priorityError   = 3
+	priorityWarning = 4
+)
+
+// Journal sends errors to journald over its native protocol.
+type Journal struct {
+	// Socket is the journald socket. Empty means DefaultSocket.
+	Socket string
+	// Identifier is the SYSLOG_IDENTIFIER. Empty means the executable's name.
+	Identifier string
+}
+
+// Report sends err to the journal, so a Journal can be used as a
+// report.Reporter. A nil err isn't sent.
+func (j Journal) Report(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	socket := j.Socket
+	if socket == "" {
+		socket = DefaultSocket
+	}
+
+	identifier := j.Identifier
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	fields := Fields(err)
+	fields["SYSLOG_IDENTIFIER"] = identifier
+
+	var dialer net.Dialer
+
+	conn, dialErr := dialer.DialContext(ctx, "unixgram", socket)
+	if dialErr != nil {
+		return ctxerrors.Wrap(dialErr, "failed to connect to journald")
+	}
+	defer conn.Close() //nolint:errcheck
+
+	// Entries too big for a datagram need the file descriptor passing of the
+	// protocol, which isn't supported, so they fail here
+	if _, writeErr := conn.Write(Encode(fields)); writeErr != nil {
+		return ctxerrors.Wrap(writeErr, "failed to send journal entry")
+	}
+
+	return nil
+}
+
+// Fields returns the journal fields for err: MESSAGE is err.Error(), PRIORITY
+// is 3 (err), or 4 (warning) if the chain's Kind is canceled, and CODE_FILE,
+// CODE_LINE and CODE_FUNC are the location of the innermost *CTXError layer,
+// where the error started. CTXERR_ID holds that layer's instance ID and every
+// field from ctxerrors.Fields gets a CTXERR_ field of its own named by
+// FieldName, e.g. CTXERR_REQUEST_ID. It returns nil for a nil error.
+func Fields(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+
+	priority := priorityError
+	if ctxerrors.KindOf(err) == ctxerrors.KindCanceled {
+		priority = priorityWarning
+	}
+
+	fields := map[string]string{
+		"MESSAGE":  err.Error(),
+		"PRIORITY": strconv.Itoa(priority),
+	}
+
+	if origin := ctxerrors.LastContext(err); origin != nil {
+		fields["CODE_FILE"] = origin.File()
+		fields["CODE_LINE"] = strconv.Itoa(origin.Line())
+		fields["CODE_FUNC"] = origin.FuncName()
+
+		if id := origin.ID(); id != "" {
+			fields[FieldPrefix+"ID"] = id
+		}
+	}
+
+	for key, value := range ctxerrors.Fields(err) {
+		fields[FieldName(key)] = fmt.Sprint(value)
+	}
+
+	return fields
+}
+
+// FieldName returns the journal field name an error field's key is sent
+// under: FieldPrefix and the key upper cased, with anything journald doesn't
+// allow in names replaced by underscores, cut to the 64 bytes it accepts.
+func FieldName(key string) string {
+	name := []byte(FieldPrefix + strings.ToUpper(key))
+
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+
+	return string(name[:min(len(name), maxFieldName)])
+}
+
+// Encode serializes fields in journald's native protocol, sorted by name.
+// Values with newlines use the protocol's length-prefixed form.
+func Encode(fields map[string]string) []byte {
+	var buf bytes.Buffer
+
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		value := fields[name]
+
+		if !strings.Contains(value, "\n") {
+			buf.WriteString(name + "=" + value + "\n")
+
+			continue
+		}
+
+		buf.WriteString(name + "\n")
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value + "\n")
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,112 @@
+package journald
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestFields(t *testing.T) {
+	t.Run("nil error", func(t *testing.T) {
+		require.Nil(t, Fields(nil))
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		require.Equal(t, map[string]string{
+			"MESSAGE":  "boom",
+			"PRIORITY": "3",
+		}, Fields(errors.New("boom"))) //nolint:err113
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		ctxerrors.SetInstanceIDs(true)
+		t.Cleanup(func() { ctxerrors.SetInstanceIDs(false) })
+
+		origin := ctxerrors.New("config file missing")
+		err := ctxerrors.WithField(ctxerrors.Wrap(origin, "init failed"), "request-id", "abc")
+
+		layer := origin.(*ctxerrors.CTXError) //nolint:errorlint,forcetypeassert
+		require.NotEmpty(t, layer.ID())
+
+		require.Equal(t, map[string]string{
+			"MESSAGE":           err.Error(),
+			"PRIORITY":          "3",
+			"CODE_FILE":         layer.File(),
+			"CODE_LINE":         strconv.Itoa(layer.Line()),
+			"CODE_FUNC":         "github.com/psyb0t/ctxerrors/journald.TestFields.func3",
+			"CTXERR_ID":         layer.ID(),
+			"CTXERR_REQUEST_ID": "abc",
+		}, Fields(err))
+	})
+
+	t.Run("canceled is a warning", func(t *testing.T) {
+		fields := Fields(ctxerrors.WithKind(ctxerrors.New("stop"), ctxerrors.KindCanceled))
+
+		require.Equal(t, "4", fields["PRIORITY"])
+		require.Equal(t, "canceled", fields["CTXERR_KIND"])
+	})
+}
+
+func TestFieldName(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"user", "CTXERR_USER"},
+		{"request-id", "CTXERR_REQUEST_ID"},
+		{"http.status", "CTXERR_HTTP_STATUS"},
+		{"naïve", "CTXERR_NA__VE"},
+		{string(make([]byte, 100)), "CTXERR_" + strings.Repeat("_", 57)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			require.Equal(t, tt.expected, FieldName(tt.key))
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	size := make([]byte, 8) //nolint:mnd
+	binary.LittleEndian.PutUint64(size, 3)
+
+	require.Equal(t,
+		"A=1\nB\n"+string(size)+"x\ny\nC=\n",
+		string(Encode(map[string]string{"C": "", "B": "x\ny", "A": "1"})))
+}
+
+func TestJournalReport(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+
+	defer conn.Close() //nolint:errcheck
+
+	journal := Journal{Socket: socket, Identifier: "app"}
+
+	require.NoError(t, journal.Report(context.Background(), nil))
+	require.NoError(t, journal.Report(context.Background(), errors.New("boom"))) //nolint:err113
+
+	buf := make([]byte, 1024) //nolint:mnd
+
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "MESSAGE=boom\nPRIORITY=3\nSYSLOG_IDENTIFIER=app\n", string(buf[:n]))
+
+	t.Run("no journald", func(t *testing.T) {
+		journal := Journal{Socket: filepath.Join(t.TempDir(), "missing.sock")}
+
+		require.ErrorContains(t, journal.Report(context.Background(), errors.New("boom")), //nolint:err113
+			"failed to connect to journald")
+	})
+}
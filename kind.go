@@ -0,0 +1,100 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"slices"
+)
+
+// Kind classifies an error by what went wrong rather than where, so callers
+// can branch on it (e.g. to pick an HTTP status) without matching sentinels.
+type Kind string
+
+// Kinds recognized across the package. KindUnknown means no kind was set.
+const (
+	KindUnknown          Kind = ""
+	KindNotFound         Kind = "not_found"
+	KindInvalidArgument  Kind = "invalid_argument"
+	KindAlreadyExists    Kind = "already_exists"
+	KindPermissionDenied Kind = "permission_denied"
+	KindUnauthenticated  Kind = "unauthenticated"
+	KindUnavailable      Kind = "unavailable"
+	KindDeadlineExceeded Kind = "deadline_exceeded"
+	KindCanceled         Kind = "canceled"
+	KindInternal         Kind = "internal"
+	KindEndOfData        Kind = "end_of_data"
+)
+
+// FieldKind is the field key a layer's Kind is stored under.
+const FieldKind = "kind"
+
+// KindOf returns the Kind set in err's chain, including layers inside joined
+// errors, by the outermost layer that has one unless SetFieldPrecedence says
+// otherwise, or KindUnknown if none does.
+func KindOf(err error) Kind {
+	kind, _ := lookupField[Kind](err, FieldKind)
+
+	return kind
+}
+
+// WithKind returns err classified as kind, without modifying err, the same way
+// WithField does it.
+func WithKind(err error, kind Kind) error {
+	// Skip WithKind(), annotate() and wrap() to get user's caller
+	framesToSkip := 3
+
+	return annotate(err, map[string]any{FieldKind: kind}, framesToSkip)
+}
+
+// SentinelKind maps errors matching Err with errors.Is to Kind, see
+// SetSentinelKinds.
+type SentinelKind struct {
+	Err  error
+	Kind Kind
+}
+
+// DefaultSentinelKinds returns the table Classify starts with, mapping the
+// sentinels of the standard library to kinds, for extending with
+// SetSentinelKinds.
+func DefaultSentinelKinds() []SentinelKind {
+	return []SentinelKind{
+		{Err: os.ErrNotExist, Kind: KindNotFound},
+		{Err: os.ErrExist, Kind: KindAlreadyExists},
+		{Err: os.ErrPermission, Kind: KindPermissionDenied},
+		{Err: os.ErrDeadlineExceeded, Kind: KindDeadlineExceeded},
+		{Err: context.DeadlineExceeded, Kind: KindDeadlineExceeded},
+		{Err: context.Canceled, Kind: KindCanceled},
+		{Err: io.EOF, Kind: KindEndOfData},
+	}
+}
+
+// SetSentinelKinds replaces the table Classify falls back to, in order of
+// precedence, so reused sentinel errors flow into the Kind taxonomy without
+// every caller classifying them by hand. An empty table turns the fallback
+// off.
+func SetSentinelKinds(table []SentinelKind) {
+	table = slices.Clone(table)
+
+	updateConfig(func(c *config) {
+		c.sentinelKinds = table
+	})
+}
+
+// Classify returns the Kind of err: the one set in its chain as KindOf sees
+// it, or else the Kind of the first entry of the SetSentinelKinds table err
+// matches with errors.Is, or KindUnknown if neither applies.
+func Classify(err error) Kind {
+	if kind := KindOf(err); kind != KindUnknown {
+		return kind
+	}
+
+	for _, entry := range currentConfig().sentinelKinds {
+		if errors.Is(err, entry.Err) {
+			return entry.Kind
+		}
+	}
+
+	return KindUnknown
+}
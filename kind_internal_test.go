@@ -0,0 +1,130 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKindOf(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected Kind
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: KindUnknown,
+		},
+		{
+			name:     "foreign error",
+			err:      baseErr,
+			expected: KindUnknown,
+		},
+		{
+			name:     "no kind",
+			err:      Wrap(baseErr, "wrapped"),
+			expected: KindUnknown,
+		},
+		{
+			name:     "deep in chain",
+			err:      Wrap(WithKind(Wrap(baseErr, "inner"), KindNotFound), "outer"),
+			expected: KindNotFound,
+		},
+		{
+			name:     "outermost wins",
+			err:      WithKind(Wrap(WithKind(Wrap(baseErr, "inner"), KindNotFound), "outer"), KindInternal),
+			expected: KindInternal,
+		},
+		{
+			name:     "inside joined errors",
+			err:      errors.Join(baseErr, WithKind(Wrap(baseErr, "inner"), KindUnavailable)),
+			expected: KindUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, KindOf(tc.err))
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected Kind
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: KindUnknown,
+		},
+		{
+			name:     "unknown error",
+			err:      Wrap(baseErr, "wrapped"),
+			expected: KindUnknown,
+		},
+		{
+			name:     "not exist",
+			err:      Wrap(&fs.PathError{Op: "open", Path: "/etc/app.conf", Err: fs.ErrNotExist}, "load config"),
+			expected: KindNotFound,
+		},
+		{
+			name:     "deadline exceeded",
+			err:      Wrap(context.DeadlineExceeded, "call upstream"),
+			expected: KindDeadlineExceeded,
+		},
+		{
+			name:     "end of data",
+			err:      Wrap(io.EOF, "read record"),
+			expected: KindEndOfData,
+		},
+		{
+			name:     "explicit kind wins",
+			err:      WithKind(Wrap(io.EOF, "read header"), KindInvalidArgument),
+			expected: KindInvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Classify(tc.err))
+		})
+	}
+}
+
+func TestSetSentinelKinds(t *testing.T) {
+	t.Cleanup(func() { SetSentinelKinds(DefaultSentinelKinds()) })
+
+	errQuota := errors.New("quota exceeded") //nolint:err113
+
+	t.Run("extended table", func(t *testing.T) {
+		SetSentinelKinds(append(DefaultSentinelKinds(), SentinelKind{Err: errQuota, Kind: KindUnavailable}))
+
+		require.Equal(t, KindUnavailable, Classify(Wrap(errQuota, "send")))
+		require.Equal(t, KindEndOfData, Classify(Wrap(io.EOF, "read")))
+	})
+
+	t.Run("earlier entries win", func(t *testing.T) {
+		SetSentinelKinds([]SentinelKind{{Err: io.EOF, Kind: KindInternal}, {Err: io.EOF, Kind: KindEndOfData}})
+
+		require.Equal(t, KindInternal, Classify(Wrap(io.EOF, "read")))
+	})
+
+	t.Run("empty table", func(t *testing.T) {
+		SetSentinelKinds(nil)
+
+		require.Equal(t, KindUnknown, Classify(Wrap(io.EOF, "read")))
+	})
+}
@@ -0,0 +1,159 @@
+// Package kube renders ctxerrors chains as the Kubernetes Events and status
+// conditions controllers and operators surface errors as, with a Reason
+// derived from the error's code or kind and the message cut to what the API
+// takes. It doesn't depend on client-go or apimachinery, Event and Condition
+// map onto theirs field by field:
+//
+//	if event := kube.ToEvent(err); event != nil {
+//		recorder.Event(obj, event.Type, event.Reason, event.Message)
+//	}
+//
+//	if cond := kube.ToCondition(err, "Ready", obj.Generation); cond != nil {
+//		meta.SetStatusCondition(&obj.Status.Conditions, metav1.Condition{
+//			Type: cond.Type, Status: metav1.ConditionStatus(cond.Status),
+//			ObservedGeneration: cond.ObservedGeneration,
+//			LastTransitionTime: metav1.NewTime(cond.LastTransitionTime),
+//			Reason: cond.Reason, Message: cond.Message,
+//		})
+//	}
+package kube
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// EventTypeWarning is the type of the Events ToEvent makes.
+const EventTypeWarning = "Warning"
+
+// ConditionFalse is the status of the Conditions ToCondition makes.
+const ConditionFalse = "False"
+
+// DefaultReason is the Reason of errors without a code or kind.
+const DefaultReason = "Error"
+
+// Limits of the Kubernetes API on what ToEvent and ToCondition produce.
+const (
+	MaxEventMessage     = 1024
+	MaxConditionMessage = 32768
+	MaxReason           = 1024
+)
+
+// truncated ends messages cut to fit a limit.
+const truncated = "…"
+
+// Event is what an EventRecorder records.
+type Event struct {
+	Type    string
+	Reason  string
+	Message string
+}
+
+// Condition is a metav1.Condition.
+type Condition struct {
+	Type               string
+	Status             string
+	ObservedGeneration int64
+	LastTransitionTime time.Time
+	Reason             string
+	Message            string
+}
+
+// ToEvent renders err as a Warning Event with Reason(err) as its reason and
+// the messages of the chain, as ctxerrors.Short renders them, cut to
+// MaxEventMessage bytes. It returns nil for a nil error.
+func ToEvent(err error) *Event {
+	if err == nil {
+		return nil
+	}
+
+	return &Event{
+		Type:    EventTypeWarning,
+		Reason:  Reason(err),
+		Message: truncate(ctxerrors.Short(err), MaxEventMessage),
+	}
+}
+
+// ToCondition renders err as a False condition of conditionType observed at
+// generation, with Reason(err) as its reason and the messages of the chain,
+// as ctxerrors.Short renders them, cut to MaxConditionMessage bytes. Its
+// transition time is ctxerrors.Now(), which meta.SetStatusCondition only
+// keeps if the status actually changed. It returns nil for a nil error.
+func ToCondition(err error, conditionType string, generation int64) *Condition {
+	if err == nil {
+		return nil
+	}
+
+	return &Condition{
+		Type:               conditionType,
+		Status:             ConditionFalse,
+		ObservedGeneration: generation,
+		LastTransitionTime: ctxerrors.Now(),
+		Reason:             Reason(err),
+		Message:            truncate(ctxerrors.Short(err), MaxConditionMessage),
+	}
+}
+
+// Reason returns err's code, or its kind as ctxerrors.Classify sees it if it
+// has none, in the CamelCase Kubernetes reasons use, e.g. "not_found" becomes
+// "NotFound". Characters a reason can't hold are dropped, one starting with a
+// digit gets DefaultReason in front and it's cut to MaxReason bytes. It's
+// DefaultReason if there's neither a code nor a kind.
+func Reason(err error) string {
+	name := ctxerrors.CodeOf(err)
+	if name == "" {
+		name = string(ctxerrors.Classify(err))
+	}
+
+	reason := camelCase(name)
+
+	switch {
+	case reason == "":
+		return DefaultReason
+	case !unicode.IsLetter(rune(reason[0])):
+		reason = DefaultReason + reason
+	}
+
+	return reason[:min(len(reason), MaxReason)]
+}
+
+// camelCase joins the ASCII letter and digit runs of name with their first
+// letters upper-cased.
+func camelCase(name string) string {
+	var builder strings.Builder
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !isASCIIAlnum(r)
+	})
+
+	for _, word := range words {
+		builder.WriteString(strings.ToUpper(word[:1]))
+		builder.WriteString(word[1:])
+	}
+
+	return builder.String()
+}
+
+// isASCIIAlnum reports whether r is an ASCII letter or digit.
+func isASCIIAlnum(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// truncate cuts message to at most limit bytes between runes, ending it with
+// an ellipsis if anything was cut.
+func truncate(message string, limit int) string {
+	if len(message) <= limit {
+		return message
+	}
+
+	cut := limit - len(truncated)
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+
+	return message[:cut] + truncated
+}
@@ -0,0 +1,102 @@
+package kube
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestReason(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "code",
+			err:      ctxerrors.WithCode(ctxerrors.WithKind(baseErr, ctxerrors.KindNotFound), "user_not_found"),
+			expected: "UserNotFound",
+		},
+		{
+			name:     "kind",
+			err:      ctxerrors.WithKind(ctxerrors.Wrap(baseErr, "get user"), ctxerrors.KindNotFound),
+			expected: "NotFound",
+		},
+		{
+			name:     "classified sentinel",
+			err:      ctxerrors.Wrap(os.ErrNotExist, "open config"),
+			expected: "NotFound",
+		},
+		{
+			name:     "invalid characters",
+			err:      ctxerrors.WithCode(baseErr, "quota.exceeded/ünits"),
+			expected: "QuotaExceededNits",
+		},
+		{
+			name:     "leading digit",
+			err:      ctxerrors.WithCode(baseErr, "404"),
+			expected: "Error404",
+		},
+		{
+			name:     "neither",
+			err:      ctxerrors.Wrap(baseErr, "dial"),
+			expected: DefaultReason,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Reason(tc.err))
+		})
+	}
+}
+
+func TestToEvent(t *testing.T) {
+	require.Nil(t, ToEvent(nil))
+
+	err := ctxerrors.WithKind(ctxerrors.Wrap(ctxerrors.New("no such user"), "reconcile failed"), ctxerrors.KindNotFound)
+
+	require.Equal(t, &Event{
+		Type:    EventTypeWarning,
+		Reason:  "NotFound",
+		Message: "reconcile failed: no such user",
+	}, ToEvent(err))
+
+	long := ToEvent(ctxerrors.New(strings.Repeat("é", MaxEventMessage)))
+
+	require.LessOrEqual(t, len(long.Message), MaxEventMessage)
+	require.True(t, strings.HasSuffix(long.Message, truncated))
+	require.True(t, strings.HasPrefix(long.Message, "éé"))
+}
+
+func TestToCondition(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	ctxerrors.SetClock(func() time.Time { return now })
+	t.Cleanup(func() { ctxerrors.SetClock(nil) })
+
+	require.Nil(t, ToCondition(nil, "Ready", 3))
+
+	err := ctxerrors.WithCode(ctxerrors.Wrap(ctxerrors.New("image pull failed"), "deploy failed"), "image_pull_backoff")
+
+	require.Equal(t, &Condition{
+		Type:               "Ready",
+		Status:             ConditionFalse,
+		ObservedGeneration: 3,
+		LastTransitionTime: now,
+		Reason:             "ImagePullBackoff",
+		Message:            "deploy failed: image pull failed",
+	}, ToCondition(err, "Ready", 3))
+
+	long := ToCondition(ctxerrors.New(strings.Repeat("x", MaxConditionMessage+1)), "Ready", 3)
+
+	require.Len(t, long.Message, MaxConditionMessage)
+}
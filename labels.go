@@ -0,0 +1,59 @@
+package ctxerrors
+
+import (
+	"errors"
+	"strings"
+)
+
+// Label keys returned by GroupLabels.
+const (
+	LabelPackage  = "package"
+	LabelFunction = "function"
+	LabelKind     = "kind"
+	LabelCode     = "code"
+)
+
+// GroupLabels returns low-cardinality labels that group err with others of its
+// sort, for Loki or Prometheus label sets where messages, IDs and line numbers
+// would blow up the series count: the package and function the error
+// originated in (its innermost *CTXError layer), its KindOf and its CodeOf.
+// Every key is present for a non-nil err, empty if unknown. It returns nil for
+// a nil err.
+func GroupLabels(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+
+	var origin *CTXError
+
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if layer, ok := asCTXError(current); ok {
+			origin = layer
+		}
+	}
+
+	pkg, function := SplitFuncName(origin.FuncName())
+
+	return map[string]string{
+		LabelPackage:  pkg,
+		LabelFunction: function,
+		LabelKind:     string(KindOf(err)),
+		LabelCode:     CodeOf(err),
+	}
+}
+
+// SplitFuncName splits a fully qualified function name such as
+// "github.com/user/repo/pkg.(*Type).Method" into its package path and the
+// rest.
+func SplitFuncName(funcName string) (string, string) {
+	lastSlash := strings.LastIndex(funcName, "/")
+
+	dot := strings.Index(funcName[lastSlash+1:], ".")
+	if dot < 0 {
+		return "", funcName
+	}
+
+	dot += lastSlash + 1
+
+	return funcName[:dot], funcName[dot+1:]
+}
@@ -0,0 +1,119 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func labelsOrigin() error {
+	return New("origin")
+}
+
+func TestGroupLabels(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected map[string]string
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name: "foreign error",
+			err:  baseErr,
+			expected: map[string]string{
+				LabelPackage:  "",
+				LabelFunction: "",
+				LabelKind:     "",
+				LabelCode:     "",
+			},
+		},
+		{
+			name: "origin through foreign wrapper",
+			err:  Wrap(fmt.Errorf("foreign: %w", labelsOrigin()), "outer"),
+			expected: map[string]string{
+				LabelPackage:  "github.com/psyb0t/ctxerrors",
+				LabelFunction: "labelsOrigin",
+				LabelKind:     "",
+				LabelCode:     "",
+			},
+		},
+		{
+			name: "with kind",
+			err:  annotate(Wrap(labelsOrigin(), "outer"), map[string]any{FieldKind: KindNotFound}, 0),
+			expected: map[string]string{
+				LabelPackage:  "github.com/psyb0t/ctxerrors",
+				LabelFunction: "labelsOrigin",
+				LabelKind:     string(KindNotFound),
+				LabelCode:     "",
+			},
+		},
+		{
+			name: "with code",
+			err:  WithCode(Wrap(labelsOrigin(), "outer"), "E_DB"),
+			expected: map[string]string{
+				LabelPackage:  "github.com/psyb0t/ctxerrors",
+				LabelFunction: "labelsOrigin",
+				LabelKind:     "",
+				LabelCode:     "E_DB",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, GroupLabels(tc.err))
+		})
+	}
+}
+
+func TestSplitFuncName(t *testing.T) {
+	testCases := []struct {
+		name             string
+		funcName         string
+		expectedPackage  string
+		expectedFunction string
+	}{
+		{
+			name:             "function",
+			funcName:         "github.com/user/repo/pkg.Do",
+			expectedPackage:  "github.com/user/repo/pkg",
+			expectedFunction: "Do",
+		},
+		{
+			name:             "method",
+			funcName:         "github.com/user/repo/pkg.(*Type).Method.func1",
+			expectedPackage:  "github.com/user/repo/pkg",
+			expectedFunction: "(*Type).Method.func1",
+		},
+		{
+			name:             "main",
+			funcName:         "main.main",
+			expectedPackage:  "main",
+			expectedFunction: "main",
+		},
+		{
+			name:             "no package",
+			funcName:         "weird",
+			expectedPackage:  "",
+			expectedFunction: "weird",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg, function := SplitFuncName(tc.funcName)
+
+			require.Equal(t, tc.expectedPackage, pkg)
+			require.Equal(t, tc.expectedFunction, function)
+		})
+	}
+}
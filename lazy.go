@@ -0,0 +1,56 @@
+package ctxerrors
+
+import "sync"
+
+// lazyMessage is a message whose formatting is put off until it's first read.
+type lazyMessage struct {
+	once    sync.Once
+	format  string
+	args    []any
+	message string
+}
+
+// WrapLazyf is like Wrapf but puts off formatting the message until something
+// reads it, for hot paths that wrap errors and mostly throw them away, such as
+// retried operations. The args are held on to until then, so they mustn't be
+// modified after the call. Errors referenced with %w are rendered the way
+// Wrapf renders them but not matched by errors.Is and errors.As since nothing
+// gets formatted up front, and enrich hooks see an empty message for the same
+// reason.
+func WrapLazyf(err error, format string, args ...any) error {
+	// Skip WrapLazyf() and newLayer() to get user's caller
+	framesToSkip := 2
+
+	if err == nil {
+		// Skip WrapLazyf() and logNilWrap() instead
+		logNilWrap(framesToSkip)
+
+		return nil
+	}
+
+	layer := newLayer(err, "", framesToSkip)
+	layer.lazy = &lazyMessage{format: format, args: args}
+
+	return finishLayer(layer, nil)
+}
+
+// text formats the message the first time it's called.
+func (m *lazyMessage) text() string {
+	m.once.Do(func() {
+		// Like Wrapf, so %w renders as the error's text, but too late for refs
+		m.message, _ = formatMessage(m.format, m.args...)
+		m.args = nil
+	})
+
+	return m.message
+}
+
+// msg returns the context message of this layer, formatting it first if it
+// was created by WrapLazyf.
+func (e *CTXError) msg() string {
+	if e.lazy != nil {
+		return e.lazy.text()
+	}
+
+	return e.message
+}
@@ -0,0 +1,71 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingStringer struct {
+	calls *int
+}
+
+func (s countingStringer) String() string {
+	*s.calls++
+
+	return "expensive"
+}
+
+func TestWrapLazyf(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WrapLazyf(nil, "attempt %d", 1))
+	})
+
+	t.Run("formats on first read only", func(t *testing.T) {
+		calls := 0
+		actual := WrapLazyf(baseErr, "computed %s value", countingStringer{calls: &calls})
+
+		require.Zero(t, calls)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(actual, &ctxErr))
+		require.Contains(t, ctxErr.FuncName(), "TestWrapLazyf")
+		require.Equal(t, "computed expensive value", ctxErr.Message())
+		require.Contains(t, actual.Error(), "computed expensive value: base error")
+		require.Equal(t, []string{"computed expensive value", "base error"}, Messages(actual))
+		require.Equal(t, 1, calls)
+		require.ErrorIs(t, actual, baseErr)
+	})
+
+	t.Run("renders %w like Wrapf", func(t *testing.T) {
+		refErr := errors.New("x") //nolint:err113
+		actual := WrapLazyf(baseErr, "item: %w", refErr)
+
+		require.Equal(t, "item: x", actual.(*CTXError).Message()) //nolint:errorlint,forcetypeassert
+		require.NotContains(t, actual.Error(), "%!w")
+	})
+
+	t.Run("detail output", func(t *testing.T) {
+		actual := fmt.Sprintf("%+v", WrapLazyf(baseErr, "item %d", 7))
+
+		require.Contains(t, actual, "item 7:\n")
+	})
+
+	t.Run("clone and rewrite", func(t *testing.T) {
+		original := WrapLazyf(baseErr, "user %s", "alice")
+
+		clone := Clone(original)
+		require.Equal(t, original.Error(), clone.Error())
+
+		rewritten := Rewrite(original, func(message string) string {
+			return message + "!"
+		})
+		require.Equal(t, []string{"user alice!", "base error!"}, Messages(rewritten))
+		require.Equal(t, []string{"user alice", "base error"}, Messages(original))
+	})
+}
@@ -0,0 +1,144 @@
+package ctxerrors
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SetMessageLimits caps how many bytes of message text Error() renders, for
+// errors that accidentally embed megabytes of payload. perLayer applies to the
+// message of every layer and to the text of the error at the bottom of the
+// chain, total to all of them together with their separators. Locations,
+// IDs and markers aren't counted and are never cut, and messages are only cut
+// between runes, never inside the escape of a control character. Cut text is
+// replaced with a marker saying how much was dropped, e.g.
+// "…(+1048576 bytes)". Zero or less means no limit, which is the default.
+// Message still returns the whole message.
+func SetMessageLimits(perLayer, total int) {
+	updateConfig(func(c *config) {
+		c.maxLayerMessage = max(perLayer, 0)
+		c.maxTotalMessage = max(total, 0)
+	})
+}
+
+// messageWriter writes the message part of Error() to a builder, cutting it
+// at a total budget.
+type messageWriter struct {
+	builder   *strings.Builder
+	perLayer  int // Zero means no limit
+	remaining int // Left of the total budget, negative means no limit
+	omitted   int // Bytes dropped once the budget ran out
+}
+
+// newMessageWriter returns a messageWriter writing to builder with the limits
+// in opts.
+func newMessageWriter(builder *strings.Builder, opts textOptions) messageWriter {
+	remaining := -1
+	if opts.maxTotalMessage > 0 {
+		remaining = opts.maxTotalMessage
+	}
+
+	return messageWriter{builder: builder, perLayer: opts.maxLayerMessage, remaining: remaining}
+}
+
+// writeMessage writes the message of a single layer escaped, cut at the
+// per-layer limit. Cuts are made in the message before escaping, so they
+// count its bytes and never split an escape.
+func (w *messageWriter) writeMessage(message string) {
+	escaped := escapeMessage(message)
+
+	kept := len(message)
+	if w.perLayer > 0 && len(escaped) > w.perLayer {
+		escaped, kept = escapedPrefix(message, w.perLayer)
+	}
+
+	w.writeEscaped(escaped, message[:kept])
+
+	if cut := len(message) - kept; cut > 0 {
+		if w.omitted > 0 {
+			// Counted in the marker close writes instead
+			w.omitted += cut
+		} else {
+			w.builder.WriteString(truncationMarker(cut))
+		}
+	}
+}
+
+// writeEscaped writes escaped, the escaped form of message, as far as the
+// total budget allows.
+func (w *messageWriter) writeEscaped(escaped, message string) {
+	if w.remaining < 0 || (w.omitted == 0 && len(escaped) <= w.remaining) {
+		w.write(escaped)
+
+		return
+	}
+
+	prefix, kept := escapedPrefix(message, w.remaining)
+
+	w.builder.WriteString(prefix)
+	w.omitted += len(message) - kept
+	w.remaining = 0
+}
+
+// write writes s as far as the total budget allows.
+func (w *messageWriter) write(s string) {
+	switch {
+	case w.remaining < 0:
+		w.builder.WriteString(s)
+	case w.omitted > 0 || len(s) > w.remaining:
+		kept := runeBoundary(s, w.remaining)
+
+		w.builder.WriteString(s[:kept])
+		w.omitted += len(s) - kept
+		w.remaining = 0
+	default:
+		w.builder.WriteString(s)
+		w.remaining -= len(s)
+	}
+}
+
+// close writes the marker for whatever the total budget dropped.
+func (w *messageWriter) close() {
+	if w.omitted > 0 {
+		w.builder.WriteString(truncationMarker(w.omitted))
+	}
+}
+
+// runeBoundary returns the largest n no greater than limit at which s can be
+// cut without splitting a UTF-8 sequence.
+func runeBoundary(s string, limit int) int {
+	n := limit
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return n
+}
+
+// escapedPrefix escapes as much of the start of message as fits in limit bytes
+// once escaped, without splitting a rune or an escape, and returns it with how
+// many bytes of message it covers.
+func escapedPrefix(message string, limit int) (string, int) {
+	var builder strings.Builder
+
+	i := 0
+
+	for i < len(message) {
+		escaped, size := escapeRune(message[i:])
+		if builder.Len()+len(escaped) > limit {
+			break
+		}
+
+		builder.WriteString(escaped)
+
+		i += size
+	}
+
+	return builder.String(), i
+}
+
+// truncationMarker returns the marker that replaces n cut bytes.
+func truncationMarker(n int) string {
+	return "…(+" + strconv.Itoa(n) + " bytes)"
+}
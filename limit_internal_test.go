@@ -0,0 +1,122 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetMessageLimits(t *testing.T) {
+	t.Cleanup(func() {
+		SetMessageLimits(0, 0)
+		SetHideLocation(false)
+	})
+
+	SetHideLocation(true)
+
+	payload := errors.New(strings.Repeat("x", 100)) //nolint:err113
+
+	testCases := []struct {
+		name     string
+		perLayer int
+		total    int
+		err      error
+		expected string
+	}{
+		{
+			name:     "no limits",
+			err:      Wrap(errors.New("short"), "outer"), //nolint:err113
+			expected: "outer: short",
+		},
+		{
+			name:     "per layer",
+			perLayer: 10,
+			err:      Wrap(payload, "outer message that is long"),
+			expected: "outer mess…(+16 bytes): xxxxxxxxxx…(+90 bytes)",
+		},
+		{
+			name:     "per layer under limit",
+			perLayer: 10,
+			err:      Wrap(errors.New("short"), "outer"), //nolint:err113
+			expected: "outer: short",
+		},
+		{
+			name:     "total",
+			total:    12,
+			err:      Wrap(payload, "outer"),
+			expected: "outer: xxxxx…(+95 bytes)",
+		},
+		{
+			name:     "total cuts separator and later layers",
+			total:    6,
+			err:      Wrap(Wrap(payload, "inner"), "outer"),
+			expected: "outer:…(+108 bytes)",
+		},
+		{
+			name:     "both",
+			perLayer: 10,
+			total:    20,
+			err:      Wrap(payload, "outer"),
+			expected: "outer: xxxxxxxxxx…(+90 bytes)",
+		},
+		{
+			name:     "both, total cuts first",
+			perLayer: 10,
+			total:    12,
+			err:      Wrap(payload, "outer"),
+			expected: "outer: xxxxx…(+95 bytes)",
+		},
+		{
+			name:     "per layer never splits runes",
+			perLayer: 4,
+			err:      New("añño"),
+			expected: "añ…(+3 bytes)",
+		},
+		{
+			name:     "total never splits runes",
+			total:    5,
+			err:      New("見つかりません"),
+			expected: "見…(+18 bytes)",
+		},
+		{
+			name:     "escaped before cutting",
+			perLayer: 8,
+			err:      New("a\nb\x1bcdef"),
+			expected: `a\nb\x1b…(+4 bytes)`,
+		},
+		{
+			name:     "per layer never splits escapes",
+			perLayer: 7,
+			err:      New("a\nb\x1bcdef"),
+			expected: `a\nb…(+5 bytes)`,
+		},
+		{
+			name:     "total never splits escapes",
+			total:    7,
+			err:      New("a\nb\x1bcdef"),
+			expected: `a\nb…(+5 bytes)`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetMessageLimits(tc.perLayer, tc.total)
+
+			require.Equal(t, tc.expected, tc.err.Error())
+		})
+	}
+
+	t.Run("locations and IDs are kept", func(t *testing.T) {
+		SetHideLocation(false)
+		SetMessageLimits(4, 0)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(Wrap(payload, "outer"), &ctxErr))
+		require.Equal(t, fmt.Sprintf("oute…(+1 bytes): xxxx…(+96 bytes) [%s:%d in %s]", ctxErr.File(), ctxErr.Line(), ctxErr.FuncName()), ctxErr.Error())
+		require.Equal(t, "outer", ctxErr.Message())
+	})
+}
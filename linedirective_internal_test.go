@@ -0,0 +1,9 @@
+package ctxerrors
+
+// newFromGenerated sits behind a //line directive the same way generated code
+// does, so it lives in its own file to keep the rest of the tests' positions
+// intact.
+func newFromGenerated() error {
+//line user.tmpl:7
+	return New("from template")
+}
@@ -0,0 +1,65 @@
+package ctxerrors
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// FieldLinkPrefix goes in front of the label of a link set with WithLink to
+// make the key of the field it's stored in, e.g. link.runbook.
+const FieldLinkPrefix = "link."
+
+// Link is a deep link an error carries, see WithLink.
+type Link struct {
+	Label string
+	URL   string
+}
+
+// WithLink returns err carrying a link to url under label, e.g. the trace in
+// the trace viewer, the runbook page or the dashboard of the failing
+// dependency, without modifying err, the same way WithField does it, so
+// whoever gets paged can click straight through:
+//
+//	return ctxerrors.WithLink(err, "runbook", "https://runbooks.example.com/db-failover")
+//
+// Links are fields under FieldLinkPrefix plus label, so reporters sending
+// fields send them too, and a later link with the same label replaces it.
+func WithLink(err error, label, url string) error {
+	// Skip WithLink(), annotate() and wrap() to get user's caller
+	framesToSkip := 3
+
+	return annotate(err, map[string]any{FieldLinkPrefix + label: url}, framesToSkip)
+}
+
+// Links returns the links set with WithLink in err's chain, including layers
+// inside joined errors, sorted by label. For a label set on more than one
+// layer the outermost wins. It returns nil if there are none.
+func Links(err error) []Link {
+	var links []Link
+
+	seen := map[string]bool{}
+
+	walk(err, func(current error) {
+		layer, ok := asCTXError(current)
+		if !ok {
+			return
+		}
+
+		for key, value := range layer.fields {
+			label, ok := strings.CutPrefix(key, FieldLinkPrefix)
+			url, isString := value.(string)
+
+			if ok && isString && !seen[label] {
+				seen[label] = true
+				links = append(links, Link{Label: label, URL: url})
+			}
+		}
+	})
+
+	slices.SortFunc(links, func(a, b Link) int {
+		return cmp.Compare(a.Label, b.Label)
+	})
+
+	return links
+}
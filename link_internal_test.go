@@ -0,0 +1,68 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinks(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected []Link
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "no links",
+			err:      WithField(Wrap(baseErr, "query"), "table", "users"),
+			expected: nil,
+		},
+		{
+			name: "links across layers",
+			err: WithLink(Wrap(WithLink(Wrap(baseErr, "query"), "trace", "https://traces.example.com/abc"), "load user"),
+				"runbook", "https://runbooks.example.com/db"),
+			expected: []Link{
+				{Label: "runbook", URL: "https://runbooks.example.com/db"},
+				{Label: "trace", URL: "https://traces.example.com/abc"},
+			},
+		},
+		{
+			name: "outermost wins",
+			err: WithLink(Wrap(WithLink(Wrap(baseErr, "query"), "runbook", "https://old.example.com"), "load user"),
+				"runbook", "https://new.example.com"),
+			expected: []Link{{Label: "runbook", URL: "https://new.example.com"}},
+		},
+		{
+			name:     "foreign error",
+			err:      WithLink(baseErr, "dashboard", "https://dash.example.com/db"),
+			expected: []Link{{Label: "dashboard", URL: "https://dash.example.com/db"}},
+		},
+		{
+			name: "inside join",
+			err: errors.Join(baseErr,
+				WithLink(New("replica down"), "dashboard", "https://dash.example.com/db")),
+			expected: []Link{{Label: "dashboard", URL: "https://dash.example.com/db"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Links(tc.err))
+		})
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		err := WithLink(baseErr, "runbook", "https://runbooks.example.com/db")
+
+		require.Equal(t, map[string]any{"link.runbook": "https://runbooks.example.com/db"}, Fields(err))
+		require.ErrorIs(t, err, baseErr)
+	})
+}
@@ -0,0 +1,96 @@
+package ctxerrors
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// logfmtCausePrefix is prepended to the keys once per level below the
+// outermost error.
+const logfmtCausePrefix = "cause."
+
+// LogfmtFormatter renders error chains as logfmt key=value pairs, for
+// pipelines built on logfmt that can't take the JSON output. Every level of
+// the chain gets msg, file, line and func keys, the outermost bare and every
+// cause below it prefixed with one more "cause.":
+//
+//	msg="load user" file=user.go line=42 func=app.load cause.msg="dial db" ...
+type LogfmtFormatter struct {
+	// Prefix goes in front of every key, e.g. "error." to keep them apart from
+	// the msg of the log line they end up in.
+	Prefix string
+	// FuncNames is how function names are rendered. FuncNameDefault means
+	// the global style.
+	FuncNames FuncNameStyle
+	// HideLocation leaves the file, line and func keys out. SetHideLocation
+	// only applies to Error() and doesn't change what the formatter renders.
+	HideLocation bool
+}
+
+// Format renders err, or returns an empty string for a nil error. Locations
+// are left out only when HideLocation says so. Errors that aren't *CTXError
+// only get a msg with the text they add to what they wrap, and joined errors
+// get one with their whole text since their members don't fit on one level.
+func (f LogfmtFormatter) Format(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	cfg := currentConfig()
+	style := funcNameStyleOr(f.FuncNames, cfg.funcNameStyle)
+
+	var builder strings.Builder
+
+	pair := func(key, value string) {
+		if builder.Len() > 0 {
+			builder.WriteByte(' ')
+		}
+
+		builder.WriteString(key + "=" + logfmtValue(value))
+	}
+
+	for prefix := f.Prefix; err != nil; prefix += logfmtCausePrefix {
+		if _, ok := members(err); ok {
+			pair(prefix+"msg", err.Error())
+
+			break
+		}
+
+		layer, ok := asCTXError(err)
+		if !ok {
+			pair(prefix+"msg", ownMessage(err))
+			err = errors.Unwrap(err)
+
+			continue
+		}
+
+		pair(prefix+"msg", layer.msg())
+
+		if !f.HideLocation {
+			pair(prefix+"file", layer.file)
+			pair(prefix+"line", strconv.Itoa(layer.line))
+			pair(prefix+"func", formatFuncName(layer.funcName, style))
+		}
+
+		err = layer.err
+	}
+
+	return builder.String()
+}
+
+// logfmtValue renders value bare if it needs no quoting, or double-quoted
+// with Go escapes otherwise.
+func logfmtValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f || r > '~' {
+			return strconv.Quote(value)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,89 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogfmtFormatter(t *testing.T) {
+	root := error(&CTXError{
+		message:  "connection refused",
+		file:     "/src/app/db.go",
+		line:     12,
+		funcName: "app.dial",
+	})
+
+	err := error(&CTXError{
+		err:      fmt.Errorf("dial db: %w", root),
+		message:  "load user",
+		file:     "/src/app/user.go",
+		line:     42,
+		funcName: "app.load",
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		require.Equal(t,
+			`msg="load user" file=/src/app/user.go line=42 func=app.load`+
+				` cause.msg="dial db"`+
+				` cause.cause.msg="connection refused" cause.cause.file=/src/app/db.go cause.cause.line=12`+
+				` cause.cause.func=app.dial`,
+			LogfmtFormatter{}.Format(err))
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		require.Equal(t,
+			`error.msg="connection refused" error.file=/src/app/db.go error.line=12 error.func=app.dial`,
+			LogfmtFormatter{Prefix: "error."}.Format(root))
+	})
+
+	t.Run("hidden location", func(t *testing.T) {
+		// fmt.Errorf bakes the text it wraps in, so build this chain afresh
+		hidden := error(&CTXError{err: fmt.Errorf("dial db: %w", root), message: "load user"})
+
+		require.Equal(t, `msg="load user" cause.msg="dial db" cause.cause.msg="connection refused"`,
+			LogfmtFormatter{HideLocation: true}.Format(hidden))
+	})
+
+	t.Run("SetHideLocation keeps locations", func(t *testing.T) {
+		SetHideLocation(true)
+		t.Cleanup(func() { SetHideLocation(false) })
+
+		require.Equal(t,
+			`msg="connection refused" file=/src/app/db.go line=12 func=app.dial`,
+			LogfmtFormatter{}.Format(root))
+	})
+
+	t.Run("joined", func(t *testing.T) {
+		joined := Wrap(errors.Join(errors.New("a"), errors.New("b")), "batch")
+
+		require.Contains(t, LogfmtFormatter{}.Format(joined), ` cause.msg="a\nb"`)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		require.Empty(t, LogfmtFormatter{}.Format(nil))
+	})
+}
+
+func TestLogfmtValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"", `""`},
+		{"two words", `"two words"`},
+		{"a=b", `"a=b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"line\nbreak", `"line\nbreak"`},
+		{"héllo", `"héllo"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			require.Equal(t, tt.expected, logfmtValue(tt.value))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package ctxerrors
+
+import (
+	"log/slog"
+	"slices"
+	"sync/atomic"
+)
+
+// fieldMarshalerEntry pairs a registered marshaler with the ID used to
+// unregister it. marshal reports false for values of other types.
+type fieldMarshalerEntry struct {
+	id      uint64
+	marshal func(value any) (any, bool)
+}
+
+var fieldMarshalerIDs atomic.Uint64 //nolint:gochecknoglobals
+
+// RegisterFieldMarshaler makes Fields hand out field values of type T as
+// marshal renders them, so rich values attached to errors come out of loggers
+// and reporters as something useful instead of "{}" or a giant dump, e.g. a
+// *http.Request as its method and URL or a proto message via protojson. T can
+// be an interface, matching every value implementing it. When several
+// marshalers match a value, the one registered last wins. The values stay
+// untouched inside the error. The returned function unregisters the marshaler.
+func RegisterFieldMarshaler[T any](marshal func(value T) any) func() {
+	id := fieldMarshalerIDs.Add(1)
+
+	entry := fieldMarshalerEntry{
+		id: id,
+		marshal: func(value any) (any, bool) {
+			typed, ok := value.(T)
+			if !ok {
+				return nil, false
+			}
+
+			return marshal(typed), true
+		},
+	}
+
+	updateConfig(func(c *config) {
+		// Never append in place, older snapshots may share the slice
+		c.fieldMarshalers = append(slices.Clip(c.fieldMarshalers), entry)
+	})
+
+	return func() {
+		updateConfig(func(c *config) {
+			c.fieldMarshalers = slices.DeleteFunc(slices.Clone(c.fieldMarshalers), func(other fieldMarshalerEntry) bool {
+				return other.id == id
+			})
+		})
+	}
+}
+
+// marshalField returns value as the last registered marshaler matching it
+// renders it, or value itself if none does.
+func (c *config) marshalField(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	for i := len(c.fieldMarshalers) - 1; i >= 0; i-- {
+		if marshaled, ok := callFieldMarshaler(c.fieldMarshalers[i], value); ok {
+			return marshaled
+		}
+	}
+
+	return value
+}
+
+// callFieldMarshaler calls entry's marshaler, recovering from any panic in it
+// like a panicking enrich hook, in which case the value counts as unmatched.
+func callFieldMarshaler(entry fieldMarshalerEntry, value any) (marshaled any, ok bool) { //nolint:nonamedreturns
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Field marshaler panicked", "panic", r)
+			countActivity(&activity.hookFailures)
+
+			marshaled, ok = nil, false
+		}
+	}()
+
+	return entry.marshal(value)
+}
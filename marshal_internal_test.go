@@ -0,0 +1,106 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// point is a field value type with no useful JSON rendering of its own.
+type point struct {
+	x, y int
+}
+
+func TestRegisterFieldMarshaler(t *testing.T) {
+	unregisterRequest := RegisterFieldMarshaler(func(r *http.Request) any {
+		return r.Method + " " + r.URL.String()
+	})
+	t.Cleanup(unregisterRequest)
+
+	unregisterPoint := RegisterFieldMarshaler(func(p point) any {
+		return fmt.Sprintf("(%d,%d)", p.x, p.y)
+	})
+	t.Cleanup(unregisterPoint)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42?page=2", nil)
+	inner := WithField(New("inner"), "at", point{1, 2})
+	err := WithField(WithField(inner, "request", req), "count", 3)
+
+	t.Run("Fields", func(t *testing.T) {
+		fields := Fields(err)
+		require.Equal(t, map[string]any{
+			"request": "GET /users/42?page=2",
+			"at":      "(1,2)",
+			"count":   3,
+		}, fields)
+
+		data, marshalErr := json.Marshal(fields)
+		require.NoError(t, marshalErr)
+		require.JSONEq(t, `{"request":"GET /users/42?page=2","at":"(1,2)","count":3}`, string(data))
+	})
+
+	t.Run("layer Fields", func(t *testing.T) {
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(inner, &ctxErr))
+		require.Equal(t, map[string]any{"at": "(1,2)"}, ctxErr.Fields())
+	})
+
+	t.Run("values stay untouched", func(t *testing.T) {
+		value, ok := FieldAs[*http.Request](err, "request")
+		require.True(t, ok)
+		require.Same(t, req, value)
+	})
+
+	t.Run("collect all", func(t *testing.T) {
+		SetFieldPrecedence(CollectAll)
+		t.Cleanup(func() { SetFieldPrecedence(OutermostWins) })
+
+		both := WithField(Wrap(inner, "outer"), "at", point{3, 4})
+		require.Equal(t, []any{"(3,4)", "(1,2)"}, Fields(both)["at"])
+	})
+
+	t.Run("redaction still wins", func(t *testing.T) {
+		require.NoError(t, SetRedactedFields("request"))
+		t.Cleanup(func() { require.NoError(t, SetRedactedFields()) })
+
+		require.Equal(t, RedactedValue, Fields(err)["request"])
+	})
+
+	t.Run("interfaces and the last registered win", func(t *testing.T) {
+		unregisterStringer := RegisterFieldMarshaler(func(s fmt.Stringer) any {
+			return "stringer: " + s.String()
+		})
+		t.Cleanup(unregisterStringer)
+
+		unregisterURL := RegisterFieldMarshaler(func(r *http.Request) any {
+			return r.URL.Path
+		})
+
+		require.Equal(t, "/users/42", Fields(err)["request"])
+		require.Equal(t, "stringer: 1s", Fields(WithField(New("timeout"), "after", time.Second))["after"])
+
+		unregisterURL()
+		require.Equal(t, "GET /users/42?page=2", Fields(err)["request"])
+	})
+
+	t.Run("panicking marshaler", func(t *testing.T) {
+		unregisterPanic := RegisterFieldMarshaler(func(int) any {
+			panic("marshaler went boom")
+		})
+		t.Cleanup(unregisterPanic)
+
+		require.Equal(t, 3, Fields(err)["count"])
+	})
+
+	t.Run("unregistered", func(t *testing.T) {
+		unregisterPoint()
+		require.Equal(t, point{1, 2}, Fields(err)["at"])
+	})
+}
@@ -0,0 +1,87 @@
+package ctxerrors
+
+import "reflect"
+
+// IsAny reports whether any of targets is in err's chain, so code handling a
+// few sentinels alike doesn't need a loop of errors.Is calls. A target made
+// with errors.Join, go-multierror or multierr stands for its members, any of
+// which will do. Matching goes like errors.Is, == for comparable targets and
+// Is(error) bool methods, but also through the members of joined errors
+// errors.Is doesn't know about, and not past a Barrier. nil targets are
+// ignored.
+func IsAny(err error, targets ...error) bool {
+	for _, target := range flattenTargets(targets) {
+		if matches(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchAll reports whether every one of targets is in err's chain, matched
+// like IsAny does, e.g. that a joined error holds both failures a test
+// expects. A target made with errors.Join, go-multierror or multierr needs
+// every one of its members. It returns false for a nil err and when there are
+// no targets other than nil ones.
+func MatchAll(err error, targets ...error) bool {
+	flat := flattenTargets(targets)
+	if err == nil || len(flat) == 0 {
+		return false
+	}
+
+	for _, target := range flat {
+		if !matches(err, target) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flattenTargets replaces joined errors in targets with their members,
+// recursively, and drops nil ones.
+func flattenTargets(targets []error) []error {
+	var flat []error
+
+	for _, target := range targets {
+		if target == nil {
+			continue
+		}
+
+		if joined, ok := members(target); ok {
+			flat = append(flat, flattenTargets(joined)...)
+
+			continue
+		}
+
+		flat = append(flat, target)
+	}
+
+	return flat
+}
+
+// matches reports whether target, which isn't nil, is in err's chain,
+// including inside joined errors, the way errors.Is compares them.
+func matches(err, target error) bool {
+	isComparable := reflect.TypeOf(target).Comparable()
+	found := false
+
+	walk(err, func(current error) {
+		if found {
+			return
+		}
+
+		if isComparable && current == target { //nolint:errorlint
+			found = true
+
+			return
+		}
+
+		if matcher, ok := current.(interface{ Is(target error) bool }); ok { //nolint:errorlint
+			found = matcher.Is(target)
+		}
+	})
+
+	return found
+}
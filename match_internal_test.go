@@ -0,0 +1,101 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAnyAndMatchAll(t *testing.T) {
+	errNotFound := errors.New("not found") //nolint:err113
+	errTimeout := errors.New("timeout")    //nolint:err113
+	_, statErr := os.Stat("/definitely/not/there")
+
+	joined := Wrap(errors.Join(Wrap(errNotFound, "user"), fmt.Errorf("order: %w", errTimeout)), "batch")
+	older := Wrap(&multierrError{errs: []error{errNotFound, errTimeout}}, "batch")
+
+	testCases := []struct {
+		name        string
+		err         error
+		targets     []error
+		expectedAny bool
+		expectedAll bool
+	}{
+		{
+			name:        "nil error",
+			err:         nil,
+			targets:     []error{errNotFound},
+			expectedAny: false,
+			expectedAll: false,
+		},
+		{
+			name:        "no targets",
+			err:         Wrap(errNotFound, "load"),
+			targets:     []error{nil},
+			expectedAny: false,
+			expectedAll: false,
+		},
+		{
+			name:        "wrapped",
+			err:         Wrap(Wrap(errNotFound, "query"), "load"),
+			targets:     []error{errTimeout, errNotFound},
+			expectedAny: true,
+			expectedAll: false,
+		},
+		{
+			name:        "joined",
+			err:         joined,
+			targets:     []error{errNotFound, errTimeout},
+			expectedAny: true,
+			expectedAll: true,
+		},
+		{
+			name:        "members errors.Is misses",
+			err:         older,
+			targets:     []error{errTimeout, errNotFound},
+			expectedAny: true,
+			expectedAll: true,
+		},
+		{
+			name:        "joined target",
+			err:         Wrap(errNotFound, "load"),
+			targets:     []error{errors.Join(errNotFound, errTimeout)},
+			expectedAny: true,
+			expectedAll: false,
+		},
+		{
+			name:        "is method",
+			err:         Wrap(statErr, "load"),
+			targets:     []error{fs.ErrNotExist},
+			expectedAny: true,
+			expectedAll: true,
+		},
+		{
+			name:        "barrier",
+			err:         Barrier(Wrap(errNotFound, "query"), "load failed"),
+			targets:     []error{errNotFound},
+			expectedAny: false,
+			expectedAll: false,
+		},
+		{
+			name:        "nothing matches",
+			err:         joined,
+			targets:     []error{io.EOF, context.Canceled},
+			expectedAny: false,
+			expectedAll: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedAny, IsAny(tc.err, tc.targets...))
+			require.Equal(t, tc.expectedAll, MatchAll(tc.err, tc.targets...))
+		})
+	}
+}
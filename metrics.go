@@ -0,0 +1,38 @@
+package ctxerrors
+
+import "errors"
+
+// ErrorMetrics describes a created error for metrics, with attributes of low
+// enough cardinality to label a counter or histogram with.
+type ErrorMetrics struct {
+	Package  string // Package the error was created in
+	Function string // Function the error was created in, without the package
+	Kind     Kind   // As Classify sees it at creation time
+	Depth    int    // Number of errors in the chain, counting this one
+	Wrapped  bool   // Whether the error wraps another one
+}
+
+// MetricsHook returns an EnrichHook that calls record with the ErrorMetrics
+// of every created error, for feeding a metrics library such as OpenTelemetry
+// without this module depending on it, e.g. a counter of errors by package,
+// function and kind and a histogram of chain depths. It attaches no fields.
+func MetricsHook(record func(metrics ErrorMetrics)) EnrichHook {
+	return EnrichHookFunc(func(err *CTXError) map[string]any {
+		pkg, function := SplitFuncName(err.funcName)
+
+		depth := 0
+		for current := error(err); current != nil; current = errors.Unwrap(current) {
+			depth++
+		}
+
+		record(ErrorMetrics{
+			Package:  pkg,
+			Function: function,
+			Kind:     Classify(err),
+			Depth:    depth,
+			Wrapped:  err.err != nil,
+		})
+
+		return nil
+	})
+}
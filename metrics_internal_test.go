@@ -0,0 +1,60 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func metricsOrigin() error {
+	return Wrap(fmt.Errorf("read: %w", context.DeadlineExceeded), "call upstream")
+}
+
+func TestMetricsHook(t *testing.T) {
+	var recorded []ErrorMetrics
+
+	unregister := RegisterEnrichHook(MetricsHook(func(metrics ErrorMetrics) {
+		recorded = append(recorded, metrics)
+	}))
+	t.Cleanup(unregister)
+
+	err := metricsOrigin()
+	_ = Wrap(err, "handle request")
+	_ = New("standalone")
+	_ = Wrap(errors.New("base error"), "plain") //nolint:err113
+
+	require.Equal(t, []ErrorMetrics{
+		{
+			Package:  "github.com/psyb0t/ctxerrors",
+			Function: "metricsOrigin",
+			Kind:     KindDeadlineExceeded,
+			Depth:    3,
+			Wrapped:  true,
+		},
+		{
+			Package:  "github.com/psyb0t/ctxerrors",
+			Function: "TestMetricsHook",
+			Kind:     KindDeadlineExceeded,
+			Depth:    4,
+			Wrapped:  true,
+		},
+		{
+			Package:  "github.com/psyb0t/ctxerrors",
+			Function: "TestMetricsHook",
+			Kind:     KindUnknown,
+			Depth:    1,
+			Wrapped:  false,
+		},
+		{
+			Package:  "github.com/psyb0t/ctxerrors",
+			Function: "TestMetricsHook",
+			Kind:     KindUnknown,
+			Depth:    2,
+			Wrapped:  true,
+		},
+	}, recorded)
+}
@@ -0,0 +1,122 @@
+// Package msgheaders carries ctxerrors chains in message headers, for NATS,
+// Kafka and other brokers, so a consumer that gives up on a message can send
+// it to a dead-letter queue with why it failed, and whatever drains that queue
+// gets the chain back:
+//
+//	// NATS
+//	_ = msgheaders.Inject(err, msg.Header.Set)
+//	failure, _ := msgheaders.Extract(msg.Header.Get)
+//
+//	// Kafka, e.g. segmentio/kafka-go
+//	_ = msgheaders.Inject(err, func(key, value string) {
+//		msg.Headers = append(msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+//	})
+//
+// It takes functions rather than the brokers' header types so it needs
+// nothing outside the standard library.
+package msgheaders
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Header keys Inject sets.
+const (
+	// HeaderMessage holds the error's text on one line, for people browsing
+	// a dead-letter queue.
+	HeaderMessage = "Ctxerrors-Message"
+	// HeaderChain holds the chain as ctxerrors.Marshal encodes it, in
+	// unpadded URL-safe base64.
+	HeaderChain = "Ctxerrors-Chain"
+)
+
+// DefaultMaxBytes is how big the package-level functions let each header
+// value get.
+const DefaultMaxBytes = 4096
+
+// Codec encodes error chains into message headers and back.
+type Codec struct {
+	// MaxBytes caps the size of each header value. The chain gives up parts
+	// as ctxerrors.Marshaler describes to fit, the message gets cut. Zero
+	// means no limit.
+	MaxBytes int
+}
+
+// Inject sets the headers carrying err through set with a Codec limited to
+// DefaultMaxBytes.
+func Inject(err error, set func(key, value string)) error {
+	return Codec{MaxBytes: DefaultMaxBytes}.Inject(err, set)
+}
+
+// Extract rebuilds the chain Inject set the headers for, getting them through
+// get.
+func Extract(get func(key string) string) (error, error) { //nolint:revive
+	return Codec{}.Extract(get)
+}
+
+// Inject sets HeaderMessage and HeaderChain for err through set, e.g. the Set
+// method of a nats.Header. A nil err sets nothing.
+func (c Codec) Inject(err error, set func(key, value string)) error {
+	if err == nil {
+		return nil
+	}
+
+	marshaler := ctxerrors.Marshaler{}
+	if c.MaxBytes > 0 {
+		marshaler.MaxBytes = base64.RawURLEncoding.DecodedLen(c.MaxBytes)
+	}
+
+	data, marshalErr := marshaler.Marshal(err)
+	if marshalErr != nil {
+		return ctxerrors.Wrap(marshalErr, "failed to marshal error chain")
+	}
+
+	set(HeaderMessage, c.message(err))
+	set(HeaderChain, base64.RawURLEncoding.EncodeToString(data))
+
+	return nil
+}
+
+// Extract rebuilds the chain from HeaderChain, getting it through get, e.g.
+// the Get method of a nats.Header, like ctxerrors.Unmarshal does. It returns
+// nil if the header isn't set.
+func (c Codec) Extract(get func(key string) string) (error, error) { //nolint:revive
+	encoded := get(HeaderChain)
+	if encoded == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ctxerrors.Wrap(err, "failed to decode error chain header")
+	}
+
+	return ctxerrors.Unmarshal(data)
+}
+
+// message returns err's text with line breaks turned into spaces, which
+// header values can't hold, cut to MaxBytes.
+func (c Codec) message(err error) string {
+	message := strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' {
+			return ' '
+		}
+
+		return r
+	}, err.Error())
+
+	if c.MaxBytes <= 0 || len(message) <= c.MaxBytes {
+		return message
+	}
+
+	cut := c.MaxBytes
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+
+	return message[:cut]
+}
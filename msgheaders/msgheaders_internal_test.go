@@ -0,0 +1,101 @@
+package msgheaders
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestInjectExtract(t *testing.T) {
+	original := ctxerrors.WithKind(
+		ctxerrors.Wrap(ctxerrors.WithField(ctxerrors.New("broker\nunreachable"), "partition", 7), "publish order"),
+		ctxerrors.KindUnavailable,
+	)
+
+	t.Run("round trip", func(t *testing.T) {
+		header := http.Header{}
+
+		require.NoError(t, Inject(original, header.Set))
+		require.NotContains(t, header.Get(HeaderMessage), "\n")
+		require.True(t, strings.HasPrefix(header.Get(HeaderMessage), `publish order: broker\nunreachable`))
+
+		decoded, err := Extract(header.Get)
+		require.NoError(t, err)
+		require.Equal(t, original.Error(), decoded.Error())
+		require.Equal(t, ctxerrors.KindUnavailable, ctxerrors.KindOf(decoded))
+
+		partition, ok := ctxerrors.FieldInt(decoded, "partition")
+		require.True(t, ok)
+		require.Equal(t, 7, partition)
+	})
+
+	t.Run("key value pairs", func(t *testing.T) {
+		type kafkaHeader struct {
+			Key   string
+			Value []byte
+		}
+
+		var headers []kafkaHeader
+
+		require.NoError(t, Inject(original, func(key, value string) {
+			headers = append(headers, kafkaHeader{Key: key, Value: []byte(value)})
+		}))
+		require.Len(t, headers, 2)
+
+		decoded, err := Extract(func(key string) string {
+			for _, header := range headers {
+				if header.Key == key {
+					return string(header.Value)
+				}
+			}
+
+			return ""
+		})
+		require.NoError(t, err)
+		require.Equal(t, original.Error(), decoded.Error())
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		header := http.Header{}
+		codec := Codec{MaxBytes: 700}
+
+		require.NoError(t, codec.Inject(ctxerrors.Wrap(original, strings.Repeat("é", 1000)), header.Set))
+		require.LessOrEqual(t, len(header.Get(HeaderMessage)), 700)
+		require.LessOrEqual(t, len(header.Get(HeaderChain)), 700)
+
+		decoded, err := codec.Extract(header.Get)
+		require.NoError(t, err)
+		require.Len(t, ctxerrors.Records(decoded), 3)
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		header := http.Header{}
+
+		require.NoError(t, Inject(nil, header.Set))
+		require.Empty(t, header)
+
+		decoded, err := Extract(header.Get)
+		require.NoError(t, err)
+		require.NoError(t, decoded)
+	})
+
+	t.Run("corrupt header", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(HeaderChain, "not base64!")
+
+		_, err := Extract(header.Get)
+		require.Error(t, err)
+	})
+
+	t.Run("chain over budget", func(t *testing.T) {
+		header := http.Header{}
+
+		err := Codec{MaxBytes: 8}.Inject(original, header.Set)
+		require.ErrorIs(t, err, ctxerrors.ErrOverBudget)
+		require.Empty(t, header)
+	})
+}
@@ -0,0 +1,394 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Formats, as the first byte of an item.
+const (
+	formatPositiveFixint = 0x00
+	formatFixmap         = 0x80
+	formatFixarray       = 0x90
+	formatFixstr         = 0xa0
+	formatNil            = 0xc0
+	formatNeverUsed      = 0xc1
+	formatFalse          = 0xc2
+	formatTrue           = 0xc3
+	formatBin8           = 0xc4
+	formatBin16          = 0xc5
+	formatBin32          = 0xc6
+	formatFloat32        = 0xca
+	formatFloat64        = 0xcb
+	formatUint8          = 0xcc
+	formatUint16         = 0xcd
+	formatUint32         = 0xce
+	formatUint64         = 0xcf
+	formatInt8           = 0xd0
+	formatInt16          = 0xd1
+	formatInt32          = 0xd2
+	formatInt64          = 0xd3
+	formatStr8           = 0xd9
+	formatStr16          = 0xda
+	formatStr32          = 0xdb
+	formatArray16        = 0xdc
+	formatArray32        = 0xdd
+	formatMap16          = 0xde
+	formatMap32          = 0xdf
+	formatNegativeFixint = 0xe0
+)
+
+// Limits of the fixed formats.
+const (
+	maxFixint    = 0x7f
+	minFixint    = -32
+	maxFixstr    = 31
+	maxFixlength = 15
+)
+
+// maxDepth is how deeply decode lets items nest.
+const maxDepth = 512
+
+var (
+	errTruncated   = errors.New("truncated data")
+	errTrailing    = errors.New("trailing data")
+	errMalformed   = errors.New("malformed data")
+	errTooDeep     = errors.New("nesting too deep")
+	errUnsupported = errors.New("unsupported value")
+)
+
+// encode appends the MessagePack encoding of a value decoded from JSON with
+// UseNumber to buf. Map keys are sorted so the same chain always encodes the
+// same.
+func encode(buf []byte, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, formatNil), nil
+	case bool:
+		if v {
+			return append(buf, formatTrue), nil
+		}
+
+		return append(buf, formatFalse), nil
+	case json.Number:
+		return encodeNumber(buf, v)
+	case string:
+		return append(appendLength(buf, len(v), formatFixstr, maxFixstr, formatStr8, formatStr16, formatStr32), v...), nil
+	case []any:
+		buf = appendLength(buf, len(v), formatFixarray, maxFixlength, 0, formatArray16, formatArray32)
+
+		for _, item := range v {
+			var err error
+			if buf, err = encode(buf, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return buf, nil
+	case map[string]any:
+		buf = appendLength(buf, len(v), formatFixmap, maxFixlength, 0, formatMap16, formatMap32)
+
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			var err error
+			if buf, err = encode(buf, key); err != nil {
+				return nil, err
+			}
+
+			if buf, err = encode(buf, v[key]); err != nil {
+				return nil, err
+			}
+		}
+
+		return buf, nil
+	default:
+		return nil, ctxerrors.Wrapf(errUnsupported, "%T", value)
+	}
+}
+
+// encodeNumber appends n as an integer in its shortest form if it is one that
+// fits, or a float64.
+func encodeNumber(buf []byte, n json.Number) ([]byte, error) {
+	i, err := n.Int64()
+	if err != nil {
+		f, floatErr := n.Float64()
+		if floatErr != nil {
+			return nil, ctxerrors.Wrapf(errUnsupported, "number %s", n)
+		}
+
+		return binary.BigEndian.AppendUint64(append(buf, formatFloat64), math.Float64bits(f)), nil
+	}
+
+	switch {
+	case i >= 0 && i <= maxFixint:
+		return append(buf, byte(i)), nil
+	case i >= minFixint && i < 0:
+		return append(buf, byte(int8(i))), nil
+	case i >= 0 && i <= math.MaxUint8:
+		return append(buf, formatUint8, byte(i)), nil
+	case i >= 0 && i <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, formatUint16), uint16(i)), nil
+	case i >= 0 && i <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, formatUint32), uint32(i)), nil
+	case i >= 0:
+		return binary.BigEndian.AppendUint64(append(buf, formatUint64), uint64(i)), nil
+	case i >= math.MinInt8:
+		return append(buf, formatInt8, byte(int8(i))), nil
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, formatInt16), uint16(int16(i))), nil
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, formatInt32), uint32(int32(i))), nil
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, formatInt64), uint64(i)), nil
+	}
+}
+
+// appendLength appends the header of a string, array or map of length n:
+// fixed if n is at most maxFixed, else the 8-bit (if the type has one), 16-bit
+// or 32-bit format.
+func appendLength(buf []byte, n int, fixed byte, maxFixed int, format8, format16, format32 byte) []byte {
+	switch {
+	case n <= maxFixed:
+		return append(buf, fixed|byte(n))
+	case format8 != 0 && n <= math.MaxUint8:
+		return append(buf, format8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, format16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, format32), uint32(n)) //nolint:gosec
+	}
+}
+
+// decoder reads one MessagePack item into the values encoding/json works
+// with: nil, bool, int64, uint64, float64, string, []byte, []any and
+// map[string]any. Extension types aren't supported.
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// decode decodes data, which must hold exactly one item.
+func decode(data []byte) (any, error) {
+	d := &decoder{data: data}
+
+	value, err := d.item()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.pos != len(d.data) {
+		return nil, errTrailing
+	}
+
+	return value, nil
+}
+
+// item decodes the item at the current position.
+func (d *decoder) item() (any, error) { //nolint:cyclop,funlen
+	if d.depth >= maxDepth {
+		return nil, errTooDeep
+	}
+
+	d.depth++
+	defer func() { d.depth-- }()
+
+	format, err := d.uint(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case format <= maxFixint:
+		return int64(format), nil
+	case format >= formatNegativeFixint:
+		return int64(int8(format)), nil //nolint:gosec
+	case format&0xf0 == formatFixmap:
+		return d.object(format & 0x0f)
+	case format&0xf0 == formatFixarray:
+		return d.array(format & 0x0f)
+	case format&0xe0 == formatFixstr:
+		return d.text(format & 0x1f)
+	}
+
+	switch format {
+	case formatNil:
+		return nil, nil //nolint:nilnil
+	case formatFalse:
+		return false, nil
+	case formatTrue:
+		return true, nil
+	case formatBin8, formatBin16, formatBin32:
+		n, err := d.uint(1 << (format - formatBin8))
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+
+		return slices.Clone(data), nil
+	case formatFloat32:
+		bits, err := d.uint(4) //nolint:mnd
+		if err != nil {
+			return nil, err
+		}
+
+		return float64(math.Float32frombits(uint32(bits))), nil
+	case formatFloat64:
+		bits, err := d.uint(8) //nolint:mnd
+		if err != nil {
+			return nil, err
+		}
+
+		return math.Float64frombits(bits), nil
+	case formatUint8, formatUint16, formatUint32, formatUint64:
+		value, err := d.uint(1 << (format - formatUint8))
+		if err != nil {
+			return nil, err
+		}
+
+		if value <= math.MaxInt64 {
+			return int64(value), nil
+		}
+
+		return value, nil
+	case formatInt8, formatInt16, formatInt32, formatInt64:
+		size := uint64(1) << (format - formatInt8)
+
+		value, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+
+		// Sign-extend from size bytes
+		shift := 64 - 8*size
+
+		return int64(value<<shift) >> shift, nil //nolint:gosec
+	case formatStr8, formatStr16, formatStr32:
+		n, err := d.uint(1 << (format - formatStr8))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.text(n)
+	case formatArray16, formatArray32:
+		n, err := d.uint(2 << (format - formatArray16))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.array(n)
+	case formatMap16, formatMap32:
+		n, err := d.uint(2 << (format - formatMap16))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.object(n)
+	case formatNeverUsed:
+		return nil, errMalformed
+	default:
+		return nil, ctxerrors.Wrapf(errUnsupported, "format 0x%02x", format)
+	}
+}
+
+// text decodes a string of n bytes.
+func (d *decoder) text(n uint64) (any, error) {
+	data, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utf8.Valid(data) {
+		return nil, errMalformed
+	}
+
+	return string(data), nil
+}
+
+// array decodes an array of n items.
+func (d *decoder) array(n uint64) (any, error) {
+	// Every item takes at least a byte, so don't trust n beyond that
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errTruncated
+	}
+
+	array := make([]any, 0, n)
+
+	for range n {
+		value, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+
+		array = append(array, value)
+	}
+
+	return array, nil
+}
+
+// object decodes a map of n entries. Keys must be strings.
+func (d *decoder) object(n uint64) (any, error) {
+	if n > uint64(len(d.data)-d.pos)/2 {
+		return nil, errTruncated
+	}
+
+	object := make(map[string]any, n)
+
+	for range n {
+		key, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+
+		text, ok := key.(string)
+		if !ok {
+			return nil, ctxerrors.Wrapf(errUnsupported, "%T map key", key)
+		}
+
+		value, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+
+		object[text] = value
+	}
+
+	return object, nil
+}
+
+// uint reads a big-endian unsigned integer of size bytes.
+func (d *decoder) uint(size uint64) (uint64, error) {
+	data, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+
+	return value, nil
+}
+
+// read reads the next n bytes.
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errTruncated
+	}
+
+	data := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return data, nil
+}
@@ -0,0 +1,80 @@
+// Package msgpack encodes ctxerrors chains as MessagePack, for RPC frameworks
+// and NATS-based systems that standardize on msgpack payloads.
+//
+// The encoded chain has the same structure and format version as the JSON
+// ctxerrors.Marshal writes, with the same guarantees about older versions. Like
+// the cbor package it's kept separate from the JSON-only core and needs nothing
+// outside the standard library.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Marshal encodes err's chain as MessagePack, with everything
+// ctxerrors.Marshal includes.
+func Marshal(err error) ([]byte, error) {
+	data, marshalErr := ctxerrors.Marshal(err)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+
+	if decodeErr := decoder.Decode(&value); decodeErr != nil {
+		return nil, ctxerrors.Wrap(decodeErr, "failed to decode error chain")
+	}
+
+	encoded, encodeErr := encode(nil, value)
+	if encodeErr != nil {
+		return nil, ctxerrors.Wrap(encodeErr, "failed to encode error chain as msgpack")
+	}
+
+	return encoded, nil
+}
+
+// Unmarshal rebuilds the chain Marshal encoded, like ctxerrors.Unmarshal does.
+func Unmarshal(data []byte) (error, error) { //nolint:revive
+	value, err := decode(data)
+	if err != nil {
+		return nil, ctxerrors.Wrap(err, "failed to decode msgpack error chain")
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, ctxerrors.Wrap(err, "failed to re-encode error chain")
+	}
+
+	return ctxerrors.Unmarshal(encoded)
+}
+
+// Error carries an error chain through msgpack libraries that look for
+// MarshalMsgpack and UnmarshalMsgpack methods, such as vmihailenco/msgpack,
+// e.g. as a struct field of a message.
+type Error struct {
+	Err error
+}
+
+// MarshalMsgpack encodes e.Err like Marshal does.
+func (e Error) MarshalMsgpack() ([]byte, error) {
+	return Marshal(e.Err)
+}
+
+// UnmarshalMsgpack sets e.Err to the chain decoded from data like Unmarshal
+// does.
+func (e *Error) UnmarshalMsgpack(data []byte) error {
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	e.Err = decoded
+
+	return nil
+}
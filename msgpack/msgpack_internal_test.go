@@ -0,0 +1,230 @@
+package msgpack
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	root := ctxerrors.WithField(ctxerrors.New("connection refused"), "retries", 3)
+	original := ctxerrors.WithKind(
+		ctxerrors.Wrap(errors.Join(fmt.Errorf("dial db: %w", root), errors.New("plain")), "load user"), //nolint:err113
+		ctxerrors.KindUnavailable,
+	)
+
+	data, err := Marshal(original)
+	require.NoError(t, err)
+
+	jsonData, err := ctxerrors.Marshal(original)
+	require.NoError(t, err)
+	require.Less(t, len(data), len(jsonData))
+
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+
+	require.Equal(t, original.Error(), decoded.Error())
+	require.Equal(t, ctxerrors.KindUnavailable, ctxerrors.KindOf(decoded))
+
+	retries, ok := ctxerrors.FieldInt(decoded, "retries")
+	require.True(t, ok)
+	require.Equal(t, 3, retries)
+
+	again, err := Marshal(original)
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+}
+
+func TestError(t *testing.T) {
+	original := ctxerrors.New("boom")
+
+	data, err := Error{Err: original}.MarshalMsgpack()
+	require.NoError(t, err)
+
+	var decoded Error
+
+	require.NoError(t, decoded.UnmarshalMsgpack(data))
+	require.Equal(t, original.Error(), decoded.Err.Error())
+
+	require.Error(t, decoded.UnmarshalMsgpack([]byte{formatNeverUsed}))
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	data, err := encode(nil, map[string]any{"v": json.Number("99")})
+	require.NoError(t, err)
+
+	_, err = Unmarshal(data)
+	require.ErrorIs(t, err, ctxerrors.ErrUnsupportedWireVersion)
+
+	_, err = Unmarshal([]byte{0x81, 0xa1})
+	require.ErrorIs(t, err, errTruncated)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	testCases := []struct {
+		value    any // As decoded, the encoded form being json.Number for numbers
+		expected string
+	}{
+		{int64(0), "00"},
+		{int64(127), "7f"},
+		{int64(128), "cc80"},
+		{int64(256), "cd0100"},
+		{int64(65536), "ce00010000"},
+		{int64(4294967296), "cf0000000100000000"},
+		{int64(-1), "ff"},
+		{int64(-32), "e0"},
+		{int64(-33), "d0df"},
+		{int64(-129), "d1ff7f"},
+		{int64(-32769), "d2ffff7fff"},
+		{int64(-2147483649), "d3ffffffff7fffffff"},
+		{1.5, "cb3ff8000000000000"},
+		{nil, "c0"},
+		{true, "c3"},
+		{false, "c2"},
+		{"", "a0"},
+		{"abc", "a3616263"},
+		{strings.Repeat("x", 32), "d920" + strings.Repeat("78", 32)},
+		{[]any{}, "90"},
+		{[]any{int64(1), []any{int64(2)}}, "92019102"},
+		{map[string]any{}, "80"},
+		{map[string]any{"b": int64(2), "a": int64(1)}, "82a16101a16202"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			data, err := encode(nil, asJSON(tc.value))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, hex.EncodeToString(data))
+
+			value, err := decode(data)
+			require.NoError(t, err)
+			require.Equal(t, tc.value, value)
+		})
+	}
+
+	t.Run("long", func(t *testing.T) {
+		for _, n := range []int{256, 70000} {
+			array := make([]any, n)
+			for i := range array {
+				array[i] = int64(i % 100)
+			}
+
+			object := map[string]any{strings.Repeat("k", n): strings.Repeat("v", n)}
+
+			for _, value := range []any{array, object, strings.Repeat("s", n)} {
+				data, err := encode(nil, asJSON(value))
+				require.NoError(t, err)
+
+				decoded, err := decode(data)
+				require.NoError(t, err)
+				require.Equal(t, value, decoded)
+			}
+		}
+	})
+
+	_, err := encode(nil, struct{}{})
+	require.ErrorIs(t, err, errUnsupported)
+}
+
+func TestDecode(t *testing.T) {
+	testCases := []struct {
+		data     string
+		expected any
+	}{
+		{"cfffffffffffffffff", uint64(math.MaxUint64)},
+		{"ca3fc00000", 1.5},
+		{"c403010203", []byte{1, 2, 3}},
+		{"c50003010203", []byte{1, 2, 3}},
+		{"c600000003010203", []byte{1, 2, 3}},
+		{"da0003616263", "abc"},
+		{"db00000003616263", "abc"},
+		{"dc000101", []any{int64(1)}},
+		{"dd0000000101", []any{int64(1)}},
+		{"de0001a16101", map[string]any{"a": int64(1)}},
+		{"df00000001a16101", map[string]any{"a": int64(1)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.data, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.data)
+			require.NoError(t, err)
+
+			value, err := decode(data)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected error
+	}{
+		{"empty", "", errTruncated},
+		{"truncated integer", "cd01", errTruncated},
+		{"truncated string", "a361", errTruncated},
+		{"huge array", "ddffffffff", errTruncated},
+		{"huge map", "dfffffffff", errTruncated},
+		{"never used", "c1", errMalformed},
+		{"invalid UTF-8", "a2c328", errMalformed},
+		{"extension", "d40100", errUnsupported},
+		{"integer map key", "810102", errUnsupported},
+		{"trailing", "0000", errTrailing},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.data)
+			require.NoError(t, err)
+
+			_, err = decode(data)
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+
+	t.Run("too deep", func(t *testing.T) {
+		data := make([]byte, maxDepth+1)
+		for i := range data {
+			data[i] = formatFixarray | 1
+		}
+
+		_, err := decode(append(data, 0x00))
+		require.ErrorIs(t, err, errTooDeep)
+	})
+}
+
+// asJSON turns a decoded value into what decoding JSON with UseNumber gives.
+func asJSON(value any) any {
+	switch v := value.(type) {
+	case int64:
+		return json.Number(fmt.Sprint(v))
+	case float64:
+		return json.Number(fmt.Sprint(v))
+	case []any:
+		converted := make([]any, len(v))
+		for i, item := range v {
+			converted[i] = asJSON(item)
+		}
+
+		return converted
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for key, item := range v {
+			converted[key] = asJSON(item)
+		}
+
+		return converted
+	default:
+		return value
+	}
+}
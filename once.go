@@ -0,0 +1,55 @@
+package ctxerrors
+
+import "runtime"
+
+// WrapOnce is like Wrap but returns err as it is if the chain already has a
+// layer made by this same WrapOnce call site, for errors that bounce through
+// a loop wrapping them on every pass and would otherwise pile up the same
+// context once per iteration:
+//
+//	for range maxAttempts {
+//		// Hands back the last failure when it has nothing newer
+//		if err = queue.Process(ctx, err); err == nil {
+//			return nil
+//		}
+//
+//		err = ctxerrors.WrapOnce(err, "processing failed")
+//	}
+//
+// Call sites are told apart by program counter, so two WrapOnce calls on the
+// same line are different sites and a plain Wrap never counts as one. It
+// returns nil if err is nil.
+func WrapOnce(err error, message string) error {
+	// Skip runtime.Callers and WrapOnce() to get user's call site
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) > 0 && wrappedAt(err, pcs[0]) { //nolint:mnd
+		return err
+	}
+
+	// Skip WrapOnce() and newLayer() to get user's caller
+	framesToSkip := 2
+
+	if err == nil {
+		// Skip WrapOnce() and logNilWrap() instead
+		logNilWrap(framesToSkip)
+
+		return nil
+	}
+
+	layer := newLayer(err, message, framesToSkip)
+	layer.oncePC = pcs[0]
+
+	return finishLayer(layer, nil)
+}
+
+// wrappedAt reports whether one of err's *CTXError layers was made by the
+// WrapOnce call at pc.
+func wrappedAt(err error, pc uintptr) bool {
+	for _, layer := range ctxLayers(err) {
+		if layer.oncePC == pc {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,50 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapOnce(t *testing.T) {
+	baseErr := errors.New("connection reset") //nolint:err113
+
+	t.Run("same call site", func(t *testing.T) {
+		err := error(baseErr)
+		for range 3 {
+			err = WrapOnce(err, "sync failed")
+		}
+
+		require.Len(t, ctxLayers(err), 1)
+		require.Equal(t, "sync failed", ctxLayers(err)[0].Message())
+		require.ErrorIs(t, err, baseErr)
+	})
+
+	t.Run("different call sites", func(t *testing.T) {
+		err := WrapOnce(baseErr, "sync failed")
+		err = WrapOnce(err, "sync failed")
+
+		require.Len(t, ctxLayers(err), 2)
+	})
+
+	t.Run("same site under other layers", func(t *testing.T) {
+		err := error(baseErr)
+		for range 3 {
+			err = WrapOnce(err, "attempt failed")
+			err = Wrap(err, "retrying")
+		}
+
+		require.Len(t, ctxLayers(err), 4)
+	})
+
+	t.Run("plain wrap doesn't count", func(t *testing.T) {
+		err := WrapOnce(Wrap(baseErr, "sync failed"), "sync failed")
+
+		require.Len(t, ctxLayers(err), 2)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		require.NoError(t, WrapOnce(nil, "sync failed"))
+	})
+}
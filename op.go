@@ -0,0 +1,171 @@
+package ctxerrors
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+// Field keys used by WrapOp and WithOp.
+const (
+	FieldOp   = "op"
+	FieldPath = "path"
+)
+
+// WrapOp wraps the error of a filesystem operation with the operation and the
+// path it was run on, e.g. "open /etc/app.yaml", also recorded as the FieldOp
+// and FieldPath fields, like an *fs.PathError that knows where it was created.
+func WrapOp(err error, op, path string) error {
+	// Skip WrapOp() and wrap() to get user's caller
+	framesToSkip := 2
+
+	fields := map[string]any{FieldOp: op, FieldPath: path}
+
+	return wrap(err, op+" "+path, framesToSkip, fields)
+}
+
+// Op returns the operation of the outermost WrapOp layer in err's chain (see
+// SetFieldPrecedence), or of the first *fs.PathError if there's none, or an
+// empty string.
+func Op(err error) string {
+	return opField(err, FieldOp, func(pathErr *fs.PathError) string { return pathErr.Op })
+}
+
+// Path returns the path of the outermost WrapOp layer in err's chain (see
+// SetFieldPrecedence), or of the first *fs.PathError if there's none, or an
+// empty string.
+func Path(err error) string {
+	return opField(err, FieldPath, func(pathErr *fs.PathError) string { return pathErr.Path })
+}
+
+// opField looks up the string field key of the WrapOp layer in err's chain
+// that wins under the current FieldPrecedence, falling back to fallback on the
+// first *fs.PathError.
+func opField(err error, key string, fallback func(pathErr *fs.PathError) string) string {
+	if value, ok := lookupField[string](err, key); ok {
+		return value
+	}
+
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return fallback(pathErr)
+	}
+
+	return ""
+}
+
+// WithOp records op, the logical operation err happened in such as
+// "userservice.Create", in the FieldOp field without modifying err, so the
+// path through the program's operations is recorded whatever the file layout
+// and Ops and FormatOps can render it. The op goes on err's outermost layer
+// the way WithField does it, unless that layer already has one, in which case
+// err is wrapped in a new layer with an empty message created at the caller.
+// It returns nil if err is nil.
+func WithOp(err error, op string) error {
+	// Skip WithOp(), annotate() and wrap() to get user's caller
+	framesToSkip := 3
+
+	if layer, ok := asCTXError(err); ok {
+		if _, ok := layer.fields[FieldOp]; ok {
+			// Skip WithOp() and wrap() only
+			return wrap(err, "", framesToSkip-1, map[string]any{FieldOp: op})
+		}
+	}
+
+	return annotate(err, map[string]any{FieldOp: op}, framesToSkip)
+}
+
+// SetInferOps makes Ops and FormatOps fall back to an operation derived from
+// the function that created a layer for layers without one recorded by
+// WithOp or WrapOp, so the path through the program renders without every
+// call site naming its op: a layer created in (*UserService).Create counts as
+// "UserService.Create". Closures count as the function they're in and a run
+// of layers inferring the same op counts once. The FieldOp field itself is
+// left alone.
+func SetInferOps(enabled bool) {
+	updateConfig(func(c *config) {
+		c.inferOps = enabled
+	})
+}
+
+// Ops returns the operations recorded with WithOp and WrapOp along err's
+// chain, outermost first, following Unwrap() error only, or nil if there are
+// none. See SetInferOps for layers without one.
+func Ops(err error) []string {
+	infer := currentConfig().inferOps
+
+	var ops []string
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		layer, ok := asCTXError(err)
+		if !ok {
+			continue
+		}
+
+		if op, ok := layer.fields[FieldOp].(string); ok {
+			ops = append(ops, op)
+
+			continue
+		}
+
+		if !infer {
+			continue
+		}
+
+		if op := inferOp(layer.funcName); op != "" && (len(ops) == 0 || ops[len(ops)-1] != op) {
+			ops = append(ops, op)
+		}
+	}
+
+	return ops
+}
+
+// inferOp derives an operation from the runtime function name funcName, the
+// way FuncNameBare renders it minus any closure suffixes, e.g.
+// "github.com/acme/app/user.(*UserService).Create.func1" becomes
+// "UserService.Create".
+func inferOp(funcName string) string {
+	op := formatFuncName(funcName, FuncNameBare)
+
+	for {
+		dot := strings.LastIndexByte(op, '.')
+		if dot < 0 || !isClosureSuffix(op[dot+1:]) {
+			return op
+		}
+
+		op = op[:dot]
+	}
+}
+
+// isClosureSuffix reports whether part is one of the name segments the
+// compiler gives closures, "func1" or the "2" of "func1.2".
+func isClosureSuffix(part string) bool {
+	digits := strings.TrimPrefix(part, "func")
+	if digits == "" {
+		return false
+	}
+
+	return strings.Trim(digits, "0123456789") == ""
+}
+
+// FormatOps renders err as its Ops followed by the message of the innermost
+// error in its chain, following Unwrap() error only, all separated by ": ",
+// e.g. "userservice.Create: store.Put: connection refused". It returns an
+// empty string for a nil error.
+func FormatOps(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	innermost := err
+	for inner := errors.Unwrap(innermost); inner != nil; inner = errors.Unwrap(innermost) {
+		innermost = inner
+	}
+
+	cause := innermost.Error()
+	if layer, ok := asCTXError(innermost); ok {
+		cause = layer.msg()
+	}
+
+	return strings.Join(append(Ops(err), escapeMessage(cause)), ": ")
+}
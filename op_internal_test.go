@@ -0,0 +1,209 @@
+package ctxerrors
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapOp(t *testing.T) {
+	baseErr := errors.New("disk on fire") //nolint:err113
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WrapOp(nil, "open", "/etc/app.yaml"))
+	})
+
+	t.Run("annotates error", func(t *testing.T) {
+		actual := WrapOp(baseErr, "open", "/etc/app.yaml")
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(actual, &ctxErr))
+		require.Equal(t, "open /etc/app.yaml", ctxErr.Message())
+		require.Equal(t, map[string]any{FieldOp: "open", FieldPath: "/etc/app.yaml"}, ctxErr.Fields())
+		require.Contains(t, ctxErr.FuncName(), "TestWrapOp")
+		require.ErrorIs(t, actual, baseErr)
+	})
+}
+
+func TestOpAndPath(t *testing.T) {
+	baseErr := errors.New("disk on fire") //nolint:err113
+	_, statErr := os.Stat("/definitely/not/there")
+
+	testCases := []struct {
+		name         string
+		err          error
+		expectedOp   string
+		expectedPath string
+	}{
+		{
+			name:         "nil error",
+			err:          nil,
+			expectedOp:   "",
+			expectedPath: "",
+		},
+		{
+			name:         "no op",
+			err:          Wrap(baseErr, "wrapped"),
+			expectedOp:   "",
+			expectedPath: "",
+		},
+		{
+			name:         "deep in chain",
+			err:          Wrap(WrapOp(baseErr, "write", "/tmp/out"), "outer"),
+			expectedOp:   "write",
+			expectedPath: "/tmp/out",
+		},
+		{
+			name:         "outermost wins",
+			err:          WrapOp(WrapOp(baseErr, "write", "/tmp/out"), "rename", "/tmp/final"),
+			expectedOp:   "rename",
+			expectedPath: "/tmp/final",
+		},
+		{
+			name:         "falls back to path error",
+			err:          Wrap(statErr, "wrapped"),
+			expectedOp:   "stat",
+			expectedPath: "/definitely/not/there",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOp, Op(tc.err))
+			require.Equal(t, tc.expectedPath, Path(tc.err))
+		})
+	}
+}
+
+func TestWithOp(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WithOp(nil, "store.Put"))
+	})
+
+	t.Run("annotates outermost layer", func(t *testing.T) {
+		wrapped := Wrap(baseErr, "failed to put")
+		actual := WithOp(wrapped, "store.Put")
+
+		require.Equal(t, wrapped.Error(), actual.Error())
+		require.Equal(t, "store.Put", Op(actual))
+		require.Empty(t, Op(wrapped))
+	})
+
+	t.Run("wraps foreign error", func(t *testing.T) {
+		actual := WithOp(baseErr, "store.Put")
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(actual, &ctxErr))
+		require.Empty(t, ctxErr.Message())
+		require.Contains(t, ctxErr.FuncName(), "TestWithOp")
+		require.ErrorIs(t, actual, baseErr)
+	})
+
+	t.Run("layer with op gets wrapped", func(t *testing.T) {
+		inner := WithOp(baseErr, "store.Put")
+		actual := WithOp(inner, "userservice.Create")
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(actual, &ctxErr))
+		require.Same(t, inner, ctxErr.Unwrap())
+		require.Contains(t, ctxErr.FuncName(), "TestWithOp")
+		require.Equal(t, []string{"userservice.Create", "store.Put"}, Ops(actual))
+	})
+}
+
+func TestOps(t *testing.T) {
+	joined := errors.Join(WithOp(New("a"), "x.A"), errors.New("b")) //nolint:err113
+
+	testCases := []struct {
+		name              string
+		err               error
+		expectedOps       []string
+		expectedFormatted string
+	}{
+		{name: "nil error", err: nil, expectedOps: nil, expectedFormatted: ""},
+		{
+			name:              "no ops",
+			err:               Wrap(New("connection refused"), "failed"),
+			expectedOps:       nil,
+			expectedFormatted: "connection refused",
+		},
+		{
+			name: "logical call path",
+			err: WithOp(Wrap(WithOp(Wrap(errors.New("connection refused"), "dial"), "store.Put"), //nolint:err113
+				"failed to create"), "userservice.Create"),
+			expectedOps:       []string{"userservice.Create", "store.Put"},
+			expectedFormatted: "userservice.Create: store.Put: connection refused",
+		},
+		{
+			name:              "filesystem op",
+			err:               WithOp(WrapOp(New("denied"), "open", "/etc/app.yaml"), "config.Load"),
+			expectedOps:       []string{"config.Load", "open"},
+			expectedFormatted: "config.Load: open: denied",
+		},
+		{
+			name:              "joined errors stop the path",
+			err:               WithOp(joined, "batch.Run"),
+			expectedOps:       []string{"batch.Run"},
+			expectedFormatted: "batch.Run: " + escapeMessage(joined.Error()),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedOps, Ops(tc.err))
+			require.Equal(t, tc.expectedFormatted, FormatOps(tc.err))
+		})
+	}
+}
+
+type opTestService struct{}
+
+//go:noinline
+func (*opTestService) Create() error {
+	load := func() error {
+		return Wrap(errors.New("connection refused"), "failed to load") //nolint:err113
+	}
+
+	return Wrap(load(), "failed to create")
+}
+
+func TestSetInferOps(t *testing.T) {
+	t.Cleanup(func() { SetInferOps(false) })
+
+	err := WithOp(Wrap((&opTestService{}).Create(), "request failed"), "api.Handle")
+
+	require.Equal(t, []string{"api.Handle"}, Ops(err))
+
+	SetInferOps(true)
+
+	require.True(t, CurrentConfig().InferOps)
+	require.Equal(t, []string{"api.Handle", "opTestService.Create"}, Ops(err))
+	require.Equal(t, "api.Handle: opTestService.Create: connection refused", FormatOps(err))
+}
+
+func TestInferOp(t *testing.T) {
+	testCases := []struct {
+		funcName string
+		expected string
+	}{
+		{funcName: "github.com/acme/app/user.(*UserService).Create", expected: "UserService.Create"},
+		{funcName: "github.com/acme/app/user.UserService.Create.func1", expected: "UserService.Create"},
+		{funcName: "github.com/acme/app/user.(*UserService).Create.func1.2", expected: "UserService.Create"},
+		{funcName: "github.com/acme/app/user.load", expected: "load"},
+		{funcName: "github.com/acme/app/user.funcs", expected: "funcs"},
+		{funcName: "", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.funcName, func(t *testing.T) {
+			require.Equal(t, tc.expected, inferOp(tc.funcName))
+		})
+	}
+}
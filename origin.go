@@ -0,0 +1,90 @@
+package ctxerrors
+
+import (
+	"context"
+	"runtime"
+	"slices"
+)
+
+// originCallers is how many frames above the submitting call site
+// CaptureOrigin captures.
+const originCallers = 8
+
+// originKey is the context key CaptureOrigin stores the Origin under.
+type originKey struct{}
+
+// Origin is where work handed to another goroutine or a queue was submitted
+// from, for errors the work fails with later to point back at.
+type Origin struct {
+	Frames []Frame // The submitting call site first, then its callers
+}
+
+// CaptureOrigin returns ctx carrying the Origin of its caller, to pass along
+// with work run asynchronously, so the errors it fails with can be linked back
+// to where it was submitted with LinkOrigin:
+//
+//	ctx = ctxerrors.CaptureOrigin(ctx)
+//	go func() {
+//		if err := process(ctx, job); err != nil {
+//			report(ctxerrors.LinkOrigin(err, ctxerrors.OriginFrom(ctx)))
+//		}
+//	}()
+//
+// Origin is plain data, so work going through a queue can carry it in its
+// message instead.
+func CaptureOrigin(ctx context.Context) context.Context {
+	pcs := make([]uintptr, originCallers+1)
+	// Skip runtime.Callers and CaptureOrigin
+	pcs = pcs[:runtime.Callers(2, pcs)] //nolint:mnd
+
+	return context.WithValue(ctx, originKey{}, Origin{Frames: resolveCallers(pcs)})
+}
+
+// OriginFrom returns the Origin CaptureOrigin stored in ctx, or the zero
+// Origin if there's none.
+func OriginFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+
+	return origin
+}
+
+// LinkOrigin returns err with origin attached to its outermost *CTXError
+// layer, printed by %+v after the layer's frames. err itself is never
+// modified, like with WithField. If err isn't a *CTXError it's wrapped in a
+// new layer with an empty message created at the caller first. It returns err
+// as is if origin is empty, and nil if err is nil.
+func LinkOrigin(err error, origin Origin) error {
+	if isNilError(err) {
+		return nil
+	}
+
+	if len(origin.Frames) == 0 {
+		return err
+	}
+
+	layer, ok := asCTXError(err)
+	if !ok {
+		// Skip LinkOrigin() and newLayer() to get user's caller
+		framesToSkip := 2
+
+		layer = newLayer(err, "", framesToSkip)
+		layer.origin = slices.Clone(origin.Frames)
+
+		return finishLayer(layer, nil)
+	}
+
+	linked := *layer
+	linked.origin = slices.Clone(origin.Frames)
+
+	return &linked
+}
+
+// Origin returns the Origin LinkOrigin attached to this layer, or the zero
+// Origin if there's none.
+func (e *CTXError) Origin() Origin {
+	if e == nil || len(e.origin) == 0 {
+		return Origin{}
+	}
+
+	return Origin{Frames: slices.Clone(e.origin)}
+}
@@ -0,0 +1,64 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func originSubmit(ctx context.Context) <-chan error {
+	ctx = CaptureOrigin(ctx)
+
+	return Go(func() error {
+		return LinkOrigin(Wrap(io.EOF, "process job"), OriginFrom(ctx))
+	})
+}
+
+func TestLinkOrigin(t *testing.T) {
+	err := <-originSubmit(context.Background())
+
+	var layer *CTXError
+
+	require.True(t, errors.As(err, &layer))
+
+	origin := layer.Origin()
+	require.NotEmpty(t, origin.Frames)
+	require.Contains(t, origin.Frames[0].FuncName, "originSubmit")
+	require.Contains(t, origin.Frames[1].FuncName, "TestLinkOrigin")
+	require.LessOrEqual(t, len(origin.Frames), originCallers+1)
+
+	detail := fmt.Sprintf("%+v", err)
+	require.Contains(t, detail, "\n    submitted from:\n    github.com/psyb0t/ctxerrors.originSubmit\n")
+	require.ErrorIs(t, err, io.EOF)
+
+	t.Run("wraps foreign errors", func(t *testing.T) {
+		linked := LinkOrigin(io.EOF, origin)
+
+		var layer *CTXError
+
+		require.True(t, errors.As(linked, &layer))
+		require.Equal(t, origin, layer.Origin())
+		require.Contains(t, layer.FuncName(), "TestLinkOrigin")
+		require.ErrorIs(t, linked, io.EOF)
+	})
+
+	t.Run("doesn't modify the error", func(t *testing.T) {
+		original := New("boom")
+
+		require.NotSame(t, original, LinkOrigin(original, origin))
+		require.Empty(t, original.(*CTXError).Origin().Frames) //nolint:errorlint,forcetypeassert
+	})
+
+	t.Run("no origin", func(t *testing.T) {
+		original := New("boom")
+
+		require.Same(t, original, LinkOrigin(original, OriginFrom(context.Background())))
+		require.NoError(t, LinkOrigin(nil, origin))
+		require.Empty(t, (*CTXError)(nil).Origin().Frames)
+	})
+}
@@ -0,0 +1,199 @@
+// Package otlp converts ctxerrors chains into OpenTelemetry log records, laid
+// out like the OTLP/JSON encoding, so errors can be exported over OTLP next to
+// traces without pulling in the OpenTelemetry SDK.
+package otlp
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// SeverityNumber is the OpenTelemetry log severity.
+type SeverityNumber int
+
+// Severities errors get logged with.
+const (
+	SeverityWarn  SeverityNumber = 13
+	SeverityError SeverityNumber = 17
+)
+
+// Semantic convention attribute keys set on every record.
+const (
+	AttributeExceptionType       = "exception.type"
+	AttributeExceptionMessage    = "exception.message"
+	AttributeExceptionStacktrace = "exception.stacktrace"
+	AttributeErrorType           = "error.type"
+	AttributeCodeFilePath        = "code.file.path"
+	AttributeCodeLineNumber      = "code.line.number"
+	AttributeCodeFunctionName    = "code.function.name"
+)
+
+// LogRecord is an OTLP log record. TraceID and SpanID are left for the caller
+// to fill in from the active span, as hex strings.
+type LogRecord struct {
+	TimeUnixNano         uint64         `json:"timeUnixNano,string"`
+	ObservedTimeUnixNano uint64         `json:"observedTimeUnixNano,string"`
+	SeverityNumber       SeverityNumber `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 AnyValue       `json:"body"`
+	Attributes           []KeyValue     `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+// KeyValue is a single attribute.
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// AnyValue holds exactly one of its fields.
+type AnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *int64   `json:"intValue,string,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// ToLogRecord builds the log record for err, timestamped with ctxerrors.Now.
+// The body is err.Error(). The exception attributes describe the whole chain:
+// the type of its innermost error that isn't a *CTXError, or *CTXError if
+// there's none, the full text, and MergedStack rendered the way runtime.Stack
+// renders frames. error.type is the chain's Kind when it has one and the
+// exception type otherwise, the code attributes hold the outermost layer's
+// location, and every field from ctxerrors.Fields becomes an attribute of its
+// own, sorted by key. Errors whose Kind is canceled are logged as warnings. It
+// returns nil for a nil error.
+func ToLogRecord(err error) *LogRecord {
+	if err == nil {
+		return nil
+	}
+
+	now := uint64(ctxerrors.Now().UnixNano()) //nolint:gosec
+
+	record := &LogRecord{
+		TimeUnixNano:         now,
+		ObservedTimeUnixNano: now,
+		SeverityNumber:       SeverityError,
+		SeverityText:         "ERROR",
+		Body:                 stringValue(err.Error()),
+	}
+
+	kind := ctxerrors.KindOf(err)
+	if kind == ctxerrors.KindCanceled {
+		record.SeverityNumber, record.SeverityText = SeverityWarn, "WARN"
+	}
+
+	exceptionType := exceptionType(err)
+
+	errorType := exceptionType
+	if kind != ctxerrors.KindUnknown {
+		errorType = string(kind)
+	}
+
+	record.Attributes = append(record.Attributes,
+		KeyValue{Key: AttributeExceptionType, Value: stringValue(exceptionType)},
+		KeyValue{Key: AttributeExceptionMessage, Value: stringValue(err.Error())},
+	)
+
+	if stack := ctxerrors.MergedStack(err); len(stack) > 0 {
+		record.Attributes = append(record.Attributes,
+			KeyValue{Key: AttributeExceptionStacktrace, Value: stringValue(goroutineStack(stack))})
+	}
+
+	record.Attributes = append(record.Attributes, KeyValue{Key: AttributeErrorType, Value: stringValue(errorType)})
+
+	var layer *ctxerrors.CTXError
+	if errors.As(err, &layer) && layer != nil {
+		record.Attributes = append(record.Attributes,
+			KeyValue{Key: AttributeCodeFilePath, Value: stringValue(layer.File())},
+			KeyValue{Key: AttributeCodeLineNumber, Value: intValue(int64(layer.Line()))},
+			KeyValue{Key: AttributeCodeFunctionName, Value: stringValue(layer.FuncName())},
+		)
+	}
+
+	fields := ctxerrors.Fields(err)
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		record.Attributes = append(record.Attributes, KeyValue{Key: key, Value: fieldValue(fields[key])})
+	}
+
+	return record
+}
+
+// exceptionType returns the type of the innermost error in err's chain that
+// isn't a *CTXError, or of the *CTXError layers if they're all there is.
+func exceptionType(err error) string {
+	exceptionType := ""
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if _, ok := err.(*ctxerrors.CTXError); !ok || exceptionType == "" { //nolint:errorlint
+			exceptionType = fmt.Sprintf("%T", err)
+		}
+	}
+
+	return exceptionType
+}
+
+// goroutineStack renders frames the way runtime.Stack does, which is what
+// OpenTelemetry expects of Go stack traces.
+func goroutineStack(frames []ctxerrors.Frame) string {
+	var b strings.Builder
+
+	b.WriteString("goroutine 1 [running]:")
+
+	for _, frame := range frames {
+		fmt.Fprintf(&b, "\n%s(...)\n\t%s:%d", frame.FuncName, frame.File, frame.Line)
+	}
+
+	return b.String()
+}
+
+// fieldValue maps a field value to the AnyValue of its type, or to its
+// fmt.Sprint text if it has none.
+func fieldValue(value any) AnyValue {
+	switch v := value.(type) {
+	case string:
+		return stringValue(v)
+	case bool:
+		return AnyValue{BoolValue: &v}
+	case int:
+		return intValue(int64(v))
+	case int8:
+		return intValue(int64(v))
+	case int16:
+		return intValue(int64(v))
+	case int32:
+		return intValue(int64(v))
+	case int64:
+		return intValue(v)
+	case uint8:
+		return intValue(int64(v))
+	case uint16:
+		return intValue(int64(v))
+	case uint32:
+		return intValue(int64(v))
+	case float32:
+		f := float64(v)
+
+		return AnyValue{DoubleValue: &f}
+	case float64:
+		return AnyValue{DoubleValue: &v}
+	default:
+		return stringValue(fmt.Sprint(value))
+	}
+}
+
+// stringValue returns s as an AnyValue.
+func stringValue(s string) AnyValue {
+	return AnyValue{StringValue: &s}
+}
+
+// intValue returns n as an AnyValue.
+func intValue(n int64) AnyValue {
+	return AnyValue{IntValue: &n}
+}
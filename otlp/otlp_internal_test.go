@@ -0,0 +1,115 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestToLogRecord(t *testing.T) {
+	t.Run("nil error", func(t *testing.T) {
+		require.Nil(t, ToLogRecord(nil))
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		record := ToLogRecord(errors.New("boom")) //nolint:err113
+
+		require.Equal(t, SeverityError, record.SeverityNumber)
+		require.Equal(t, "ERROR", record.SeverityText)
+		require.Equal(t, "boom", *record.Body.StringValue)
+		require.Equal(t, map[string]any{
+			AttributeExceptionType:    "*errors.errorString",
+			AttributeExceptionMessage: "boom",
+			AttributeErrorType:        "*errors.errorString",
+		}, attributes(record))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		clock := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		ctxerrors.SetClock(func() time.Time { return clock })
+		t.Cleanup(func() { ctxerrors.SetClock(nil) })
+
+		origin := ctxerrors.Wrap(fmt.Errorf("read: %w", context.DeadlineExceeded), "config file missing")
+		err := ctxerrors.Wrap(origin, "init failed")
+		err = ctxerrors.WithField(err, "attempts", 3)
+		err = ctxerrors.WithField(err, "ratio", 0.5)
+		err = ctxerrors.WithField(err, "cached", false)
+		err = ctxerrors.WithField(err, "user", "bob")
+
+		record := ToLogRecord(err)
+		require.Equal(t, uint64(clock.UnixNano()), record.TimeUnixNano) //nolint:gosec
+		require.Equal(t, err.Error(), *record.Body.StringValue)
+
+		attrs := attributes(record)
+		require.Equal(t, "context.deadlineExceededError", attrs[AttributeExceptionType])
+		require.Equal(t, "context.deadlineExceededError", attrs[AttributeErrorType])
+		require.Equal(t, err.Error(), attrs[AttributeExceptionMessage])
+		require.Equal(t, "github.com/psyb0t/ctxerrors/otlp.TestToLogRecord.func3", attrs[AttributeCodeFunctionName])
+		require.Regexp(t, `otlp_internal_test\.go$`, attrs[AttributeCodeFilePath])
+		require.Positive(t, attrs[AttributeCodeLineNumber])
+		require.Equal(t, int64(3), attrs["attempts"])
+		require.InDelta(t, 0.5, attrs["ratio"], 0)
+		require.Equal(t, false, attrs["cached"])
+		require.Equal(t, "bob", attrs["user"])
+
+		stackRegexp := regexp.MustCompile(`^goroutine 1 \[running\]:\n` +
+			`github\.com/psyb0t/ctxerrors/otlp\.TestToLogRecord\.func3\(\.\.\.\)\n\t.+_test\.go:\d+\n` +
+			`github\.com/psyb0t/ctxerrors/otlp\.TestToLogRecord\.func3\(\.\.\.\)\n\t.+_test\.go:\d+$`)
+		require.Regexp(t, stackRegexp, attrs[AttributeExceptionStacktrace])
+	})
+
+	t.Run("kind", func(t *testing.T) {
+		record := ToLogRecord(ctxerrors.WithKind(ctxerrors.New("gone"), ctxerrors.KindNotFound))
+
+		attrs := attributes(record)
+		require.Equal(t, "*ctxerrors.CTXError", attrs[AttributeExceptionType])
+		require.Equal(t, "not_found", attrs[AttributeErrorType])
+		require.Equal(t, SeverityError, record.SeverityNumber)
+	})
+
+	t.Run("canceled is a warning", func(t *testing.T) {
+		record := ToLogRecord(ctxerrors.WithKind(ctxerrors.New("stop"), ctxerrors.KindCanceled))
+
+		require.Equal(t, SeverityWarn, record.SeverityNumber)
+		require.Equal(t, "WARN", record.SeverityText)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		record := ToLogRecord(ctxerrors.WithField(ctxerrors.New("boom"), "attempts", 3))
+
+		data, err := json.Marshal(record)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"timeUnixNano":"`)
+		require.Contains(t, string(data), `{"key":"attempts","value":{"intValue":"3"}}`)
+		require.Contains(t, string(data), `"body":{"stringValue":"boom`)
+		require.NotContains(t, string(data), "traceId")
+	})
+}
+
+// attributes flattens the record's attributes to their Go values.
+func attributes(record *LogRecord) map[string]any {
+	attrs := make(map[string]any, len(record.Attributes))
+
+	for _, attr := range record.Attributes {
+		switch {
+		case attr.Value.StringValue != nil:
+			attrs[attr.Key] = *attr.Value.StringValue
+		case attr.Value.BoolValue != nil:
+			attrs[attr.Key] = *attr.Value.BoolValue
+		case attr.Value.IntValue != nil:
+			attrs[attr.Key] = *attr.Value.IntValue
+		case attr.Value.DoubleValue != nil:
+			attrs[attr.Key] = *attr.Value.DoubleValue
+		}
+	}
+
+	return attrs
+}
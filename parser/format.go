@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// FormatVersion is the ctxerrors.TextFormatVersion Format writes and Parse
+// reads.
+const FormatVersion = ctxerrors.TextFormatVersion
+
+// ErrAmbiguous is returned by Format for chains whose text Parse would read
+// back as a different chain.
+var ErrAmbiguous = errors.New("chain can't be rendered unambiguously")
+
+// Format renders chain as CTXError.Error() would have, following the grammar
+// documented on ctxerrors.TextFormatVersion. Whatever it returns, Parse turns
+// back into the same chain; chains it can't promise that for, like messages
+// containing the separator or layers with neither a location nor an ID, get
+// ErrAmbiguous instead.
+func (p Parser) Format(chain Chain) (string, error) {
+	separator := p.Separator
+	if separator == "" {
+		separator = ctxerrors.DefaultSeparator
+	}
+
+	if err := checkChain(chain, separator); err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+
+	for i, layer := range chain.Layers {
+		if i > 0 {
+			builder.WriteString(separator)
+		}
+
+		builder.WriteString(layer.Message)
+	}
+
+	if chain.Cause != "" {
+		builder.WriteString(separator)
+		builder.WriteString(chain.Cause)
+	}
+
+	if endsWithRun(builder.String()) {
+		return "", fmt.Errorf("%w: messages end with a location", ErrAmbiguous)
+	}
+
+	for i := len(chain.Layers) - 1; i >= 0; i-- {
+		writeTokens(&builder, chain.Layers[i])
+	}
+
+	text := builder.String()
+
+	// Catch whatever the checks above missed rather than break the promise
+	if parsed, ok := p.Parse(text); !ok || !equalChains(parsed, chain) {
+		return "", ErrAmbiguous
+	}
+
+	return text, nil
+}
+
+// checkChain returns an ErrAmbiguous error naming the first thing in chain
+// that Parse couldn't read back.
+func checkChain(chain Chain, separator string) error {
+	if len(chain.Layers) == 0 {
+		return fmt.Errorf("%w: no layers", ErrAmbiguous)
+	}
+
+	for i, layer := range chain.Layers {
+		if strings.Contains(layer.Message, separator) {
+			return fmt.Errorf("%w: layer %d message contains the separator", ErrAmbiguous, i)
+		}
+
+		if reason := checkLayer(layer); reason != "" {
+			return fmt.Errorf("%w: layer %d %s", ErrAmbiguous, i, reason)
+		}
+
+		// Parse gives an ID right after a location without one to that location
+		if i+1 < len(chain.Layers) {
+			inner := chain.Layers[i+1]
+			if layer.FuncName == "" && layer.ID != "" && inner.FuncName != "" && inner.ID == "" {
+				return fmt.Errorf("%w: layer %d ID follows a location without one", ErrAmbiguous, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkLayer describes what keeps layer's tokens from being parsed back, or
+// returns an empty string if nothing does.
+func checkLayer(layer Layer) string {
+	switch {
+	case layer.FuncName == "" && layer.ID == "":
+		return "has neither a location nor an ID"
+	case layer.FuncName == "" && (layer.File != "" || layer.Line != 0):
+		return "has a file or line without a function"
+	case layer.File == "" && layer.Line != 0:
+		return "has a line without a file"
+	case layer.Line < 0:
+		return "has a negative line"
+	case strings.ContainsAny(layer.File, "[]"):
+		return "file contains brackets"
+	case strings.ContainsAny(layer.ID, "[]"):
+		return "ID contains brackets"
+	case strings.ContainsAny(layer.FuncName, "[]") || strings.IndexFunc(layer.FuncName, isSpace) >= 0:
+		return "function contains brackets or whitespace"
+	}
+
+	return ""
+}
+
+// writeTokens writes the location and ID tokens of layer the way Error() does.
+func writeTokens(builder *strings.Builder, layer Layer) {
+	if layer.FuncName != "" {
+		builder.WriteString(" [")
+
+		if layer.File != "" {
+			builder.WriteString(layer.File)
+			builder.WriteString(":")
+			builder.WriteString(strconv.Itoa(layer.Line))
+			builder.WriteString(" ")
+		}
+
+		builder.WriteString("in ")
+		builder.WriteString(layer.FuncName)
+		builder.WriteString("]")
+	}
+
+	if layer.ID != "" {
+		builder.WriteString(" [id=")
+		builder.WriteString(layer.ID)
+		builder.WriteString("]")
+	}
+}
+
+// equalChains reports whether a and b hold the same layers and cause.
+func equalChains(a, b Chain) bool {
+	return a.Cause == b.Cause && slices.Equal(a.Layers, b.Layers)
+}
+
+// isSpace reports whether r is whitespace as the \s regexp class sees it.
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\f' || r == '\r'
+}
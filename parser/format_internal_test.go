@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestFormat(t *testing.T) { //nolint:funlen
+	testCases := []struct {
+		name      string
+		chain     Chain
+		separator string
+		expected  string
+		ambiguous bool
+	}{
+		{
+			name: "layers and cause",
+			chain: Chain{
+				Layers: []Layer{
+					{Message: "init failed", File: "/src/main.go", Line: 24, FuncName: "main.main"},
+					{Message: "failed to open", File: "/src/db.go", Line: 17, FuncName: "main.open", ID: "abc"},
+				},
+				Cause: "open /x: no such file",
+			},
+			expected: "init failed: failed to open: open /x: no such file " +
+				"[/src/db.go:17 in main.open] [id=abc] [/src/main.go:24 in main.main]",
+		},
+		{
+			name: "function only and hidden locations",
+			chain: Chain{
+				Layers: []Layer{
+					{Message: "outer", FuncName: "main.main"},
+					{Message: "inner", ID: "abc"},
+				},
+			},
+			separator: " <- ",
+			expected:  "outer <- inner [id=abc] [in main.main]",
+		},
+		{
+			name: "empty messages",
+			chain: Chain{
+				Layers: []Layer{
+					{FuncName: "main.a"},
+					{FuncName: "main.b"},
+				},
+			},
+			expected: ":  [in main.b] [in main.a]",
+		},
+		{
+			name:      "no layers",
+			chain:     Chain{},
+			ambiguous: true,
+		},
+		{
+			name: "message contains separator",
+			chain: Chain{
+				Layers: []Layer{{Message: "a: b", FuncName: "main.a"}},
+			},
+			ambiguous: true,
+		},
+		{
+			name: "layer without location or ID",
+			chain: Chain{
+				Layers: []Layer{{Message: "a"}},
+			},
+			ambiguous: true,
+		},
+		{
+			name: "line without file",
+			chain: Chain{
+				Layers: []Layer{{Message: "a", Line: 3, FuncName: "main.a"}},
+			},
+			ambiguous: true,
+		},
+		{
+			name: "function with whitespace",
+			chain: Chain{
+				Layers: []Layer{{Message: "a", FuncName: "main a"}},
+			},
+			ambiguous: true,
+		},
+		{
+			name: "ID after location without one",
+			chain: Chain{
+				Layers: []Layer{
+					{Message: "outer", ID: "abc"},
+					{Message: "inner", FuncName: "main.inner"},
+				},
+			},
+			ambiguous: true,
+		},
+		{
+			name: "message ending in a location",
+			chain: Chain{
+				Layers: []Layer{{Message: "a [in main.fake]", FuncName: "main.a"}},
+			},
+			ambiguous: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := Parser{Separator: tc.separator}
+
+			text, err := p.Format(tc.chain)
+			if tc.ambiguous {
+				require.ErrorIs(t, err, ErrAmbiguous)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, text)
+
+			parsed, ok := p.Parse(text)
+			require.True(t, ok)
+			require.Equal(t, tc.chain, parsed)
+		})
+	}
+}
+
+func TestFormatMatchesError(t *testing.T) {
+	baseErr := errors.New("no such file") //nolint:err113
+
+	errs := map[string]error{
+		"wrapped": ctxerrors.Wrap(ctxerrors.New("config missing"), "init failed"),
+		"cause":   ctxerrors.Wrap(ctxerrors.Wrap(baseErr, "failed to open"), "failed to load"),
+		"boundary": ctxerrors.WrapStackBoundary(
+			ctxerrors.Wrap(ctxerrors.New("parse failed"), "decode failed"), "load failed",
+		),
+	}
+
+	for name, err := range errs {
+		t.Run(name, func(t *testing.T) {
+			text := err.Error()
+
+			chain, ok := Parser{}.Parse(text)
+			require.True(t, ok)
+
+			formatted, formatErr := Parser{}.Format(chain)
+			require.NoError(t, formatErr)
+			require.Equal(t, text, formatted)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Package parser extracts ctxerrors chains from raw log streams, for building
+// log analysis and error budget tooling on top of what services already log.
+//
+// It understands the text CTXError.Error() renders, whether it shows up as a
+// bare log line, after the standard log package's timestamp, as a logfmt value
+// or as a string anywhere inside a JSON log record.
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// maxLineSize is the longest log line ParseStream can read.
+const maxLineSize = 1024 * 1024
+
+// Layer is one CTXError layer recovered from its rendered text.
+type Layer struct {
+	Message  string // Context message of this layer
+	File     string // Empty if the error was captured in function-only mode
+	Line     int    // Zero if the error was captured in function-only mode
+	FuncName string // Empty if locations were hidden
+	ID       string // Instance ID, if there was one
+}
+
+// Chain is a rendered CTXError chain recovered from a log line.
+type Chain struct {
+	Layers []Layer // Outermost first
+	Cause  string  // Text of the innermost non-ctxerrors error, if any
+}
+
+// Entry is one line of a log stream along with the chains found in it.
+type Entry struct {
+	Number int     // Line number, starting at 1
+	Text   string  // The line as read
+	Chains []Chain // Empty if the line holds no ctxerrors output
+}
+
+// Parser parses ctxerrors output. The zero value is ready to use.
+type Parser struct {
+	// Separator is what the logging services put between layers. Empty means
+	// ctxerrors.DefaultSeparator.
+	Separator string
+}
+
+var (
+	// tokenRegexp matches one bracketed location or instance ID token
+	tokenRegexp = regexp.MustCompile( //nolint:gochecknoglobals
+		`\[(?:id=([^\[\]]*)|(?:([^\[\]]*):(\d+) )?in ([^\[\]\s]+))\]`,
+	)
+	// runRegexp matches the run of tokens that ends a rendered chain
+	runRegexp = regexp.MustCompile( //nolint:gochecknoglobals
+		`(?: \[(?:id=[^\[\]]*|(?:[^\[\]]*:\d+ )?in [^\[\]\s]+)\])+`,
+	)
+	// quotedValueRegexp matches quoted logfmt-style values
+	quotedValueRegexp = regexp.MustCompile(`=("(?:[^"\\]|\\.)*")`) //nolint:gochecknoglobals
+	// errKeyRegexp matches unquoted error keys in text logs
+	errKeyRegexp = regexp.MustCompile(`(?:^|\s)(?:err|error)=`) //nolint:gochecknoglobals
+	// logPrefixRegexp matches the timestamp the standard log package prepends
+	logPrefixRegexp = regexp.MustCompile( //nolint:gochecknoglobals
+		`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)? `,
+	)
+)
+
+// ParseStream reads r line by line and calls handle with every line, including
+// the ones without ctxerrors output, stopping at the first error handle returns.
+func (p Parser) ParseStream(r io.Reader, handle func(entry Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+
+	for number := 1; scanner.Scan(); number++ {
+		line := scanner.Text()
+
+		entry := Entry{
+			Number: number,
+			Text:   line,
+			Chains: p.ParseLine(line),
+		}
+
+		if err := handle(entry); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return ctxerrors.Wrap(err, "failed to read log stream")
+	}
+
+	return nil
+}
+
+// ParseLine extracts every chain it can find in a log line, which is either a
+// JSON object or plain text.
+func (p Parser) ParseLine(line string) []Chain {
+	trimmed := strings.TrimSpace(line)
+
+	if strings.HasPrefix(trimmed, "{") {
+		var object any
+		if err := json.Unmarshal([]byte(trimmed), &object); err == nil {
+			return p.parseJSONValue(object)
+		}
+	}
+
+	text, ok := extractText(line)
+	if !ok {
+		return nil
+	}
+
+	chain, ok := p.Parse(text)
+	if !ok {
+		return nil
+	}
+
+	return []Chain{chain}
+}
+
+// Parse parses exactly the text rendered by CTXError.Error(). Messages that
+// contain the separator can't be told apart from layer boundaries, so they
+// come out split differently than they went in.
+func (p Parser) Parse(text string) (Chain, bool) {
+	if !endsWithRun(text) {
+		return Chain{}, false
+	}
+
+	separator := p.Separator
+	if separator == "" {
+		separator = ctxerrors.DefaultSeparator
+	}
+
+	runs := runRegexp.FindAllStringIndex(text, -1)
+	runStart := runs[len(runs)-1][0]
+
+	layers := parseTokens(text[runStart:])
+	messages := strings.SplitN(text[:runStart], separator, len(layers))
+
+	for len(messages) < len(layers) {
+		messages = append(messages, "")
+	}
+
+	chain := Chain{Layers: layers, Cause: ""}
+
+	for i := range chain.Layers {
+		chain.Layers[i].Message = messages[i]
+	}
+
+	// Whatever follows the innermost message is most likely a foreign cause
+	innermost := &chain.Layers[len(chain.Layers)-1]
+	if message, cause, found := strings.Cut(innermost.Message, separator); found {
+		innermost.Message = message
+		chain.Cause = cause
+	}
+
+	return chain, true
+}
+
+// parseJSONValue looks for rendered chains in every string inside a decoded
+// JSON value.
+func (p Parser) parseJSONValue(value any) []Chain {
+	var chains []Chain
+
+	switch v := value.(type) {
+	case string:
+		if chain, ok := p.Parse(v); ok {
+			chains = append(chains, chain)
+		}
+	case []any:
+		for _, item := range v {
+			chains = append(chains, p.parseJSONValue(item)...)
+		}
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			chains = append(chains, p.parseJSONValue(v[key])...)
+		}
+	}
+
+	return chains
+}
+
+// extractText finds the rendered chain inside a plain text log line: a quoted
+// logfmt value, whatever follows an err= or error= key, or the whole line minus
+// the standard log package's timestamp.
+func extractText(line string) (string, bool) {
+	for _, match := range quotedValueRegexp.FindAllStringSubmatch(line, -1) {
+		value, err := strconv.Unquote(match[1])
+		if err == nil && endsWithRun(value) {
+			return value, true
+		}
+	}
+
+	runs := runRegexp.FindAllStringIndex(line, -1)
+	if len(runs) == 0 {
+		return "", false
+	}
+
+	end := runs[len(runs)-1][1]
+	text := line[:end]
+
+	if keys := errKeyRegexp.FindAllStringIndex(text, -1); len(keys) > 0 {
+		return text[keys[len(keys)-1][1]:], true
+	}
+
+	return logPrefixRegexp.ReplaceAllString(text, ""), true
+}
+
+// endsWithRun reports whether text ends with a run of location tokens.
+func endsWithRun(text string) bool {
+	runs := runRegexp.FindAllStringIndex(text, -1)
+
+	return len(runs) > 0 && runs[len(runs)-1][1] == len(text)
+}
+
+// parseTokens turns a run of location and ID tokens, which Error() renders
+// innermost layer first, into layers ordered outermost first.
+func parseTokens(run string) []Layer {
+	var layers []Layer
+
+	for _, match := range tokenRegexp.FindAllStringSubmatch(run, -1) {
+		id, file, lineText, funcName := match[1], match[2], match[3], match[4]
+
+		if funcName == "" {
+			// An ID belongs to the location right before it, if there is one
+			if len(layers) > 0 && layers[len(layers)-1].ID == "" && layers[len(layers)-1].FuncName != "" {
+				layers[len(layers)-1].ID = id
+
+				continue
+			}
+
+			layers = append(layers, Layer{ID: id})
+
+			continue
+		}
+
+		line, _ := strconv.Atoi(lineText)
+
+		layers = append(layers, Layer{File: file, Line: line, FuncName: funcName})
+	}
+
+	slices.Reverse(layers)
+
+	return layers
+}
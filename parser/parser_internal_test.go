@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestParse(t *testing.T) { //nolint:funlen
+	testCases := []struct {
+		name      string
+		text      string
+		separator string
+		expected  Chain
+		ok        bool
+	}{
+		{
+			name:      "not a chain",
+			text:      "just some text",
+			separator: ": ",
+			expected:  Chain{},
+			ok:        false,
+		},
+		{
+			name:      "single layer",
+			text:      "config file missing [/src/config.go:12 in main.readConfig]",
+			separator: ": ",
+			expected: Chain{
+				Layers: []Layer{
+					{Message: "config file missing", File: "/src/config.go", Line: 12, FuncName: "main.readConfig"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name: "multiple layers",
+			text: "init failed: failed to read config: config file missing " +
+				"[/src/config.go:12 in main.readConfig] [/src/db.go:17 in main.initDatabase] " +
+				"[/src/main.go:24 in main.main]",
+			separator: ": ",
+			expected: Chain{
+				Layers: []Layer{
+					{Message: "init failed", File: "/src/main.go", Line: 24, FuncName: "main.main"},
+					{Message: "failed to read config", File: "/src/db.go", Line: 17, FuncName: "main.initDatabase"},
+					{Message: "config file missing", File: "/src/config.go", Line: 12, FuncName: "main.readConfig"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name:      "foreign cause",
+			text:      "failed to open: open /x: no such file [/src/a.go:12 in main.open]",
+			separator: ": ",
+			expected: Chain{
+				Layers: []Layer{
+					{Message: "failed to open", File: "/src/a.go", Line: 12, FuncName: "main.open"},
+				},
+				Cause: "open /x: no such file",
+			},
+			ok: true,
+		},
+		{
+			name:      "custom separator",
+			text:      "outer -> root [/src/a.go:1 in main.a] [/src/b.go:2 in main.b]",
+			separator: " -> ",
+			expected: Chain{
+				Layers: []Layer{
+					{Message: "outer", File: "/src/b.go", Line: 2, FuncName: "main.b"},
+					{Message: "root", File: "/src/a.go", Line: 1, FuncName: "main.a"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name:      "function only with ids",
+			text:      "outer: root [in main.a] [id=ab-1] [in main.b] [id=ab-2]",
+			separator: ": ",
+			expected: Chain{
+				Layers: []Layer{
+					{Message: "outer", FuncName: "main.b", ID: "ab-2"},
+					{Message: "root", FuncName: "main.a", ID: "ab-1"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name:      "ids without locations",
+			text:      "outer: root [id=ab-1] [id=ab-2]",
+			separator: ": ",
+			expected: Chain{
+				Layers: []Layer{
+					{Message: "outer", ID: "ab-2"},
+					{Message: "root", ID: "ab-1"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name:      "empty outer message",
+			text:      ": root [/src/a.go:1 in main.a] [/src/b.go:2 in main.b]",
+			separator: ": ",
+			expected: Chain{
+				Layers: []Layer{
+					{Message: "", File: "/src/b.go", Line: 2, FuncName: "main.b"},
+					{Message: "root", File: "/src/a.go", Line: 1, FuncName: "main.a"},
+				},
+			},
+			ok: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := Parser{Separator: tc.separator}.Parse(tc.text)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	baseErr := errors.New("no such file") //nolint:err113
+	err := ctxerrors.Wrap(ctxerrors.Wrap(baseErr, "failed to open"), "failed to load")
+
+	parsed, ok := Parser{}.Parse(err.Error())
+	require.True(t, ok)
+	require.Len(t, parsed.Layers, 2)
+	require.Equal(t, "failed to load", parsed.Layers[0].Message)
+	require.Equal(t, "failed to open", parsed.Layers[1].Message)
+	require.Equal(t, "no such file", parsed.Cause)
+
+	for _, l := range parsed.Layers {
+		require.Contains(t, l.File, "parser_internal_test.go")
+		require.NotZero(t, l.Line)
+		require.Contains(t, l.FuncName, "TestParseRoundTrip")
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	rendered := "outer: root [/src/a.go:1 in main.a] [/src/b.go:2 in main.b]"
+
+	testCases := []struct {
+		name     string
+		line     string
+		expected int
+	}{
+		{
+			name:     "unrelated line",
+			line:     "server started on :8080",
+			expected: 0,
+		},
+		{
+			name:     "bare error",
+			line:     rendered,
+			expected: 1,
+		},
+		{
+			name:     "standard log prefix",
+			line:     "2024/01/01 12:00:00 " + rendered,
+			expected: 1,
+		},
+		{
+			name:     "logfmt quoted value",
+			line:     "level=ERROR msg=failed err=" + strconv.Quote(rendered) + " took=3ms",
+			expected: 1,
+		},
+		{
+			name:     "unquoted error key",
+			line:     "ERROR request failed error=" + rendered,
+			expected: 1,
+		},
+		{
+			name:     "json record",
+			line:     `{"level":"ERROR","err":` + strconv.Quote(rendered) + `,"nested":{"errs":[` + strconv.Quote(rendered) + `]}}`,
+			expected: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := Parser{}.ParseLine(tc.line)
+			require.Len(t, actual, tc.expected)
+
+			for _, parsed := range actual {
+				require.Len(t, parsed.Layers, 2)
+				require.Equal(t, "outer", parsed.Layers[0].Message)
+				require.Equal(t, "root", parsed.Layers[1].Message)
+			}
+		})
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	input := "" +
+		"server started\n" +
+		"outer -> root [/src/a.go:1 in main.a] [/src/b.go:2 in main.b]\n"
+
+	t.Run("every line is handled", func(t *testing.T) {
+		var entries []Entry
+
+		err := Parser{Separator: " -> "}.ParseStream(strings.NewReader(input), func(entry Entry) error {
+			entries = append(entries, entry)
+
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+
+		require.Equal(t, 1, entries[0].Number)
+		require.Equal(t, "server started", entries[0].Text)
+		require.Empty(t, entries[0].Chains)
+
+		require.Equal(t, 2, entries[1].Number)
+		require.Len(t, entries[1].Chains, 1)
+		require.Equal(t, "outer", entries[1].Chains[0].Layers[0].Message)
+		require.Equal(t, "root", entries[1].Chains[0].Layers[1].Message)
+	})
+
+	t.Run("handler error stops the stream", func(t *testing.T) {
+		stopErr := errors.New("stop") //nolint:err113
+		calls := 0
+
+		err := Parser{}.ParseStream(strings.NewReader(input), func(Entry) error {
+			calls++
+
+			return stopErr
+		})
+		require.ErrorIs(t, err, stopErr)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		readErr := errors.New("read failed") //nolint:err113
+
+		err := Parser{}.ParseStream(iotest.ErrReader(readErr), func(Entry) error { return nil })
+		require.ErrorIs(t, err, readErr)
+	})
+}
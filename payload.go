@@ -0,0 +1,109 @@
+package ctxerrors
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldPayloadPrefix goes in front of the name of a payload attached with
+// WithPayload to make the key of the field it's stored in, e.g.
+// payload.response_body.
+const FieldPayloadPrefix = "payload."
+
+// Payload is raw data an error carries, see WithPayload.
+type Payload struct {
+	Name string
+	Data []byte // At most the limit WithPayload was given
+	Size int    // Of the whole data, before it was cut to the limit
+}
+
+// Truncated reports whether Data was cut to fit the limit.
+func (p Payload) Truncated() bool {
+	return len(p.Data) < p.Size
+}
+
+// MarshalJSON encodes the payload with its data as text if it's valid UTF-8
+// and in base64 under data_base64 otherwise, along with its size and whether
+// it was truncated.
+func (p Payload) MarshalJSON() ([]byte, error) {
+	encoded := struct {
+		Data       *string `json:"data,omitempty"`
+		DataBase64 []byte  `json:"data_base64,omitempty"`
+		Size       int     `json:"size"`
+		Truncated  bool    `json:"truncated"`
+	}{Size: p.Size, Truncated: p.Truncated()}
+
+	if utf8.Valid(p.Data) {
+		text := string(p.Data)
+		encoded.Data = &text
+	} else {
+		encoded.DataBase64 = p.Data
+	}
+
+	return json.Marshal(encoded)
+}
+
+// WithPayload returns err carrying data, such as a response body or a
+// command's stderr, under name, cut to its first limit bytes, without
+// modifying err, the same way WithDebugField does it:
+//
+//	return ctxerrors.WithPayload(err, "response_body", body, 4096)
+//
+// Payloads stay out of Error(), Fields, Marshal and the formatters and show up
+// in DebugFields, FieldsFor at debug verbosity and the JSON of a Marshaler
+// with Debug set, under FieldPayloadPrefix plus name, and in Payloads. data is
+// copied, so the caller can reuse its buffer. A limit of zero or less keeps
+// all of it.
+func WithPayload(err error, name string, data []byte, limit int) error {
+	// Skip WithPayload(), annotate() and wrap() to get user's caller
+	framesToSkip := 3
+
+	kept := data
+	if limit > 0 && len(kept) > limit {
+		kept = kept[:limit]
+	}
+
+	payload := Payload{Name: name, Data: slices.Clone(kept), Size: len(data)}
+	value := &debugValue{compute: func() any { return payload }}
+
+	return annotate(err, map[string]any{FieldPayloadPrefix + name: value}, framesToSkip)
+}
+
+// Payloads returns the payloads attached with WithPayload in err's chain,
+// including layers inside joined errors, sorted by name. For a name attached
+// on more than one layer the outermost wins. It returns nil if there are none.
+func Payloads(err error) []Payload {
+	var payloads []Payload
+
+	seen := map[string]bool{}
+
+	walk(err, func(current error) {
+		layer, ok := asCTXError(current)
+		if !ok {
+			return
+		}
+
+		for key, value := range layer.fields {
+			name, ok := strings.CutPrefix(key, FieldPayloadPrefix)
+			lazy, isDebug := value.(*debugValue)
+
+			if !ok || !isDebug || seen[name] {
+				continue
+			}
+
+			if payload, ok := lazy.get().(Payload); ok {
+				seen[name] = true
+				payloads = append(payloads, payload)
+			}
+		}
+	})
+
+	slices.SortFunc(payloads, func(a, b Payload) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return payloads
+}
@@ -0,0 +1,94 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPayload(t *testing.T) {
+	body := []byte(`{"error":"rate limited","retry_after":30}`)
+	stderr := []byte("fatal: not a git repository")
+
+	err := WithPayload(Wrap(errors.New("status 429"), "call api"), "response_body", body, 16) //nolint:err113
+	err = WithPayload(Wrap(err, "sync"), "stderr", stderr, 0)
+
+	body[0] = 'X'
+
+	require.Equal(t, []Payload{
+		{Name: "response_body", Data: []byte(`{"error":"rate l`), Size: len(body)},
+		{Name: "stderr", Data: stderr, Size: len(stderr)},
+	}, Payloads(err))
+
+	require.NotContains(t, err.Error(), "rate")
+	require.Empty(t, Fields(err))
+
+	debug := DebugFields(err)
+	require.Contains(t, debug, FieldPayloadPrefix+"response_body")
+	require.Contains(t, debug, FieldPayloadPrefix+"stderr")
+
+	encoded, marshalErr := json.Marshal(debug[FieldPayloadPrefix+"response_body"])
+	require.NoError(t, marshalErr)
+	require.JSONEq(t, `{"data":"{\"error\":\"rate l","size":41,"truncated":true}`, string(encoded))
+
+	require.Nil(t, Payloads(New("nothing attached")))
+}
+
+func TestWithPayloadDebugJSON(t *testing.T) {
+	body := []byte(`{"error":"rate limited","retry_after":30}`)
+	err := WithPayload(Wrap(errors.New("status 429"), "call api"), "response_body", body, 16) //nolint:err113
+
+	data, marshalErr := Marshaler{Debug: true}.Marshal(err)
+	require.NoError(t, marshalErr)
+
+	var chain struct {
+		Layers []struct {
+			Fields map[string]json.RawMessage `json:"fields"`
+		} `json:"layers"`
+	}
+
+	require.NoError(t, json.Unmarshal(data, &chain))
+	require.NotEmpty(t, chain.Layers)
+	require.JSONEq(t, `{"data":"{\"error\":\"rate l","size":41,"truncated":true}`,
+		string(chain.Layers[0].Fields[FieldPayloadPrefix+"response_body"]))
+	require.NotContains(t, string(data), "retry_after")
+
+	plain, marshalErr := Marshal(err)
+	require.NoError(t, marshalErr)
+	require.NotContains(t, string(plain), "rate l")
+	require.NotContains(t, err.Error(), "rate")
+}
+
+func TestPayloadMarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		payload  Payload
+		expected string
+	}{
+		{
+			name:     "text",
+			payload:  Payload{Name: "stderr", Data: []byte("boom"), Size: 4},
+			expected: `{"data":"boom","size":4,"truncated":false}`,
+		},
+		{
+			name:     "binary",
+			payload:  Payload{Name: "frame", Data: []byte{0xff, 0x00}, Size: 10},
+			expected: `{"data_base64":"/wA=","size":10,"truncated":true}`,
+		},
+		{
+			name:     "empty",
+			payload:  Payload{Name: "body"},
+			expected: `{"data":"","size":0,"truncated":false}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, err := json.Marshal(tc.payload)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, string(encoded))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package ctxerrors
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// packageTreeSuffix ends a PackagePolicy pattern covering a package and every
+// package under it.
+const packageTreeSuffix = "/..."
+
+// PackagePolicy sets the defaults for errors created in the packages it
+// covers, see RegisterPackagePolicy.
+type PackagePolicy struct {
+	// Package is the import path of the package the policy covers, e.g.
+	// "github.com/acme/app/internal/storage", or ending in "/..." to cover
+	// every package under it too, the way the go command matches them.
+	Package string
+	Kind    Kind           // Set unless the chain is classified already, KindUnknown for none
+	Fields  map[string]any // Attached to every layer created in the packages
+}
+
+// packagePolicyEntry pairs a registered policy with the ID used to unregister
+// it.
+type packagePolicyEntry struct {
+	id     uint64
+	policy PackagePolicy
+}
+
+var packagePolicyIDs atomic.Uint64 //nolint:gochecknoglobals
+
+// RegisterPackagePolicy makes New, Wrap and every other constructor apply
+// policy to the errors they create in the packages it covers, going by the
+// function the error is created in, so conventions like "storage errors are
+// internal and say which component failed" hold without every call site
+// having to remember them:
+//
+//	ctxerrors.RegisterPackagePolicy(ctxerrors.PackagePolicy{
+//		Package: "github.com/acme/app/internal/storage/...",
+//		Kind:    ctxerrors.KindInternal,
+//		Fields:  map[string]any{"component": "storage"},
+//	})
+//
+// The Kind is only a default: it's left out when Classify finds one in the
+// chain below already, so a more specific kind set deeper or a sentinel like
+// context.Canceled isn't shadowed, and WithKind on top still overrides it.
+// When several policies cover a package, the one with the longest Package
+// wins for the Kind and for repeated field keys. Policy fields are attached
+// before enrich hooks run, so hook fields win over them. The returned
+// function unregisters the policy.
+func RegisterPackagePolicy(policy PackagePolicy) func() {
+	id := packagePolicyIDs.Add(1)
+
+	policy.Fields = maps.Clone(policy.Fields)
+
+	updateConfig(func(c *config) {
+		policies := append(slices.Clone(c.packagePolicies), packagePolicyEntry{id: id, policy: policy})
+
+		// Least specific first, so applying them in order lets the most
+		// specific win
+		slices.SortStableFunc(policies, func(a, b packagePolicyEntry) int {
+			return cmp.Compare(len(a.policy.Package), len(b.policy.Package))
+		})
+
+		c.packagePolicies = policies
+	})
+
+	return func() {
+		updateConfig(func(c *config) {
+			c.packagePolicies = slices.DeleteFunc(slices.Clone(c.packagePolicies), func(entry packagePolicyEntry) bool {
+				return entry.id == id
+			})
+		})
+	}
+}
+
+// applyPackagePolicies attaches the Kind and fields of every policy covering
+// the package err was created in.
+func applyPackagePolicies(policies []packagePolicyEntry, err *CTXError) {
+	if len(policies) == 0 || err.funcName == "" {
+		return
+	}
+
+	pkg, _ := SplitFuncName(err.funcName)
+
+	kind := KindUnknown
+
+	for _, entry := range policies {
+		if !entry.policy.covers(pkg) {
+			continue
+		}
+
+		if entry.policy.Kind != KindUnknown {
+			kind = entry.policy.Kind
+		}
+
+		if len(entry.policy.Fields) == 0 {
+			continue
+		}
+
+		if err.fields == nil {
+			err.fields = make(map[string]any, len(entry.policy.Fields))
+		}
+
+		maps.Copy(err.fields, entry.policy.Fields)
+	}
+
+	if kind == KindUnknown || (err.err != nil && Classify(err.err) != KindUnknown) {
+		return
+	}
+
+	if err.fields == nil {
+		err.fields = make(map[string]any, 1)
+	}
+
+	err.fields[FieldKind] = kind
+}
+
+// covers reports whether the policy applies to errors created in pkg.
+func (p PackagePolicy) covers(pkg string) bool {
+	tree, ok := strings.CutSuffix(p.Package, packageTreeSuffix)
+	if !ok {
+		return pkg == p.Package
+	}
+
+	return pkg == tree || strings.HasPrefix(pkg, tree+"/")
+}
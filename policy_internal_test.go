@@ -0,0 +1,89 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterPackagePolicy(t *testing.T) {
+	baseErr := errors.New("connection reset") //nolint:err113
+
+	unregisterTree := RegisterPackagePolicy(PackagePolicy{
+		Package: "github.com/psyb0t/...",
+		Kind:    KindUnavailable,
+		Fields:  map[string]any{"component": "psyb0t", "team": "core"},
+	})
+	t.Cleanup(unregisterTree)
+
+	unregisterPackage := RegisterPackagePolicy(PackagePolicy{
+		Package: "github.com/psyb0t/ctxerrors",
+		Kind:    KindInternal,
+		Fields:  map[string]any{"component": "errors"},
+	})
+	t.Cleanup(unregisterPackage)
+
+	unregisterOther := RegisterPackagePolicy(PackagePolicy{
+		Package: "github.com/psyb0t/ctxerrors/report/...",
+		Kind:    KindNotFound,
+		Fields:  map[string]any{"component": "report"},
+	})
+	t.Cleanup(unregisterOther)
+
+	require.Equal(t, 3, CurrentConfig().PackagePolicies)
+
+	t.Run("most specific wins", func(t *testing.T) {
+		for _, err := range []error{New("lookup failed"), Wrap(baseErr, "query failed")} {
+			require.Equal(t, KindInternal, KindOf(err))
+			require.Equal(t, map[string]any{"component": "errors", "team": "core", FieldKind: KindInternal}, Fields(err))
+		}
+	})
+
+	t.Run("kind set deeper isn't shadowed", func(t *testing.T) {
+		err := Wrap(WithKind(New("no such user"), KindNotFound), "load user")
+
+		require.Equal(t, KindNotFound, KindOf(err))
+
+		layer, ok := asCTXError(err)
+		require.True(t, ok)
+		require.NotContains(t, layer.Fields(), FieldKind)
+		require.Equal(t, "errors", layer.Fields()["component"])
+	})
+
+	t.Run("classified sentinels aren't shadowed", func(t *testing.T) {
+		require.Equal(t, KindCanceled, Classify(Wrap(context.Canceled, "query failed")))
+	})
+
+	t.Run("explicit kind overrides", func(t *testing.T) {
+		require.Equal(t, KindCanceled, KindOf(WithKind(New("stopped"), KindCanceled)))
+	})
+
+	unregisterPackage()
+	unregisterTree()
+
+	require.Nil(t, Fields(New("lookup failed")))
+}
+
+func TestPackagePolicyCovers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pattern  string
+		pkg      string
+		expected bool
+	}{
+		{name: "exact package", pattern: "github.com/acme/app/storage", pkg: "github.com/acme/app/storage", expected: true},
+		{name: "exact excludes subpackages", pattern: "github.com/acme/app/storage", pkg: "github.com/acme/app/storage/sql", expected: false},
+		{name: "tree includes root", pattern: "github.com/acme/app/storage/...", pkg: "github.com/acme/app/storage", expected: true},
+		{name: "tree includes subpackages", pattern: "github.com/acme/app/storage/...", pkg: "github.com/acme/app/storage/sql", expected: true},
+		{name: "tree needs element boundary", pattern: "github.com/acme/app/storage/...", pkg: "github.com/acme/app/storagex", expected: false},
+		{name: "other package", pattern: "github.com/acme/app/storage/...", pkg: "github.com/acme/app/api", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, PackagePolicy{Package: tc.pattern, Kind: KindUnknown, Fields: nil}.covers(tc.pkg))
+		})
+	}
+}
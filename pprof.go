@@ -0,0 +1,33 @@
+package ctxerrors
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// FieldPprofLabels is the field key PprofLabelsProvider records labels under.
+const FieldPprofLabels = "pprof_labels"
+
+// PprofLabelsProvider returns a FieldProvider that records the pprof labels
+// set on the context, e.g. with pprof.Do, as a map[string]string under
+// FieldPprofLabels, so CPU and heap profiles and the errors taken during the
+// same operation can be correlated. Register it with RegisterFieldProvider.
+// Only labels carried by the context passed to NewCtx or WrapCtx are seen,
+// since the runtime doesn't expose the labels of the current goroutine.
+func PprofLabelsProvider() FieldProvider {
+	return func(ctx context.Context) map[string]any {
+		labels := make(map[string]string)
+
+		pprof.ForLabels(ctx, func(key, value string) bool {
+			labels[key] = value
+
+			return true
+		})
+
+		if len(labels) == 0 {
+			return nil
+		}
+
+		return map[string]any{FieldPprofLabels: labels}
+	}
+}
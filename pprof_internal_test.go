@@ -0,0 +1,33 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPprofLabelsProvider(t *testing.T) {
+	t.Cleanup(RegisterFieldProvider(PprofLabelsProvider()))
+
+	baseErr := errors.New("base error") //nolint:err113
+
+	t.Run("no labels", func(t *testing.T) {
+		_, ok := FieldAs[map[string]string](WrapCtx(context.Background(), baseErr, "wrapped"), FieldPprofLabels)
+		require.False(t, ok)
+	})
+
+	t.Run("labels set with pprof.Do", func(t *testing.T) {
+		var err error
+
+		pprof.Do(context.Background(), pprof.Labels("job", "import", "tenant", "acme"), func(ctx context.Context) {
+			err = NewCtx(ctx, "import failed")
+		})
+
+		labels, ok := FieldAs[map[string]string](err, FieldPprofLabels)
+		require.True(t, ok)
+		require.Equal(t, map[string]string{"job": "import", "tenant": "acme"}, labels)
+	})
+}
@@ -0,0 +1,87 @@
+package ctxerrors
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Field keys used by WrapQuery.
+const (
+	FieldQuery     = "query"
+	FieldQueryArgs = "query_args"
+)
+
+// QueryArgsPolicy controls what WrapQuery records of the query arguments,
+// which tend to hold user data that has no business ending up in logs.
+type QueryArgsPolicy int
+
+const (
+	// QueryArgsRedacted records only the type of every argument. This is the
+	// default.
+	QueryArgsRedacted QueryArgsPolicy = iota
+	// QueryArgsOmitted records nothing about the arguments.
+	QueryArgsOmitted
+	// QueryArgsVerbatim records the arguments as passed.
+	QueryArgsVerbatim
+)
+
+// SetQueryArgsPolicy sets what WrapQuery records of query arguments from now on.
+func SetQueryArgsPolicy(policy QueryArgsPolicy) {
+	updateConfig(func(c *config) {
+		c.queryArgsPolicy = policy
+	})
+}
+
+// WrapQuery wraps a database error with the SQL text and arguments of the query
+// that produced it, recorded as the FieldQuery and FieldQueryArgs fields with
+// the arguments treated as SetQueryArgsPolicy says. Well-known driver errors
+// also get a FieldKind as classified by QueryKind.
+func WrapQuery(err error, query string, args ...any) error {
+	// Skip WrapQuery() and wrap() to get user's caller
+	framesToSkip := 2
+
+	fields := map[string]any{FieldQuery: query}
+
+	switch currentConfig().queryArgsPolicy {
+	case QueryArgsOmitted:
+	case QueryArgsVerbatim:
+		fields[FieldQueryArgs] = slices.Clone(args)
+	default:
+		redacted := make([]any, len(args))
+		for i, arg := range args {
+			redacted[i] = fmt.Sprintf("%T", arg)
+		}
+
+		fields[FieldQueryArgs] = redacted
+	}
+
+	if kind := QueryKind(err); kind != KindUnknown {
+		fields[FieldKind] = kind
+	}
+
+	return wrap(err, "query failed", framesToSkip, fields)
+}
+
+// QueryKind maps the sentinel errors of database/sql and its drivers found in
+// err's chain to a Kind, e.g. sql.ErrNoRows to KindNotFound. It returns
+// KindUnknown for anything else.
+func QueryKind(err error) Kind {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return KindNotFound
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, driver.ErrBadConn):
+		return KindUnavailable
+	case errors.Is(err, sql.ErrTxDone):
+		return KindInternal
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	default:
+		return KindUnknown
+	}
+}
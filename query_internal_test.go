@@ -0,0 +1,96 @@
+package ctxerrors
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapQuery(t *testing.T) { //nolint:funlen
+	t.Cleanup(func() { SetQueryArgsPolicy(QueryArgsRedacted) })
+
+	const query = "SELECT name FROM users WHERE id = $1 AND email = $2"
+
+	testCases := []struct {
+		name           string
+		policy         QueryArgsPolicy
+		err            error
+		expectedFields map[string]any
+	}{
+		{
+			name:   "redacted by default",
+			policy: QueryArgsRedacted,
+			err:    sql.ErrNoRows,
+			expectedFields: map[string]any{
+				FieldQuery:     query,
+				FieldQueryArgs: []any{"int", "string"},
+				FieldKind:      KindNotFound,
+			},
+		},
+		{
+			name:   "omitted",
+			policy: QueryArgsOmitted,
+			err:    sql.ErrNoRows,
+			expectedFields: map[string]any{
+				FieldQuery: query,
+				FieldKind:  KindNotFound,
+			},
+		},
+		{
+			name:   "verbatim",
+			policy: QueryArgsVerbatim,
+			err:    errors.New("syntax error"), //nolint:err113
+			expectedFields: map[string]any{
+				FieldQuery:     query,
+				FieldQueryArgs: []any{42, "user@example.com"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetQueryArgsPolicy(tc.policy)
+
+			actual := WrapQuery(tc.err, query, 42, "user@example.com")
+
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(actual, &ctxErr))
+			require.Equal(t, "query failed", ctxErr.Message())
+			require.Equal(t, tc.expectedFields, ctxErr.Fields())
+			require.Contains(t, ctxErr.FuncName(), "TestWrapQuery")
+			require.ErrorIs(t, actual, tc.err)
+		})
+	}
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WrapQuery(nil, query))
+	})
+}
+
+func TestQueryKind(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected Kind
+	}{
+		{name: "nil error", err: nil, expected: KindUnknown},
+		{name: "no rows", err: Wrap(sql.ErrNoRows, "wrapped"), expected: KindNotFound},
+		{name: "conn done", err: sql.ErrConnDone, expected: KindUnavailable},
+		{name: "bad conn", err: driver.ErrBadConn, expected: KindUnavailable},
+		{name: "tx done", err: sql.ErrTxDone, expected: KindInternal},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, expected: KindDeadlineExceeded},
+		{name: "canceled", err: context.Canceled, expected: KindCanceled},
+		{name: "anything else", err: errors.New("syntax error"), expected: KindUnknown}, //nolint:err113
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, QueryKind(tc.err))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// QuotaTracker is an EnrichHook counting failures per value of a field, such
+// as a tenant or request ID, over a sliding window, so multi-tenant services
+// can throttle or single out the tenants whose failures are flooding them:
+//
+//	quota := ctxerrors.NewQuotaTracker("tenant_id", time.Minute, 50)
+//	defer ctxerrors.RegisterEnrichHook(quota)()
+//
+//	if quota.Exceeded(tenantID) {
+//		return ctxerrors.WithKind(errTooManyFailures, ctxerrors.KindUnavailable)
+//	}
+//
+// A failure counts once under its key however many times it's wrapped, from
+// the first layer whose chain carries the field when the layer's created. The
+// tracker runs after every other hook and after the layer gets its own fields
+// from WrapCtx, WithField and the like, so a key added by any of them counts
+// on that very layer. Time comes from SetClock's clock. It attaches no fields.
+type QuotaTracker struct {
+	field  string
+	window time.Duration
+	limit  int
+
+	mu        sync.Mutex // Guards the fields below
+	quotas    map[string]*quota
+	lastSweep time.Time
+}
+
+// quota is what a QuotaTracker knows about one key.
+type quota struct {
+	times   []time.Time             // Latest limit+1 failure times, oldest first
+	counted map[*CTXError]time.Time // Innermost layers of the failures counted within the window
+}
+
+// NewQuotaTracker returns a QuotaTracker counting failures by the value of
+// field, formatted with fmt.Sprint, and calling a key over quota when more
+// than limit of them happened within window.
+func NewQuotaTracker(field string, window time.Duration, limit int) *QuotaTracker {
+	return &QuotaTracker{
+		field:  field,
+		window: window,
+		limit:  max(limit, 0),
+		quotas: make(map[string]*quota),
+	}
+}
+
+// observer makes the tracker an observerHook.
+func (*QuotaTracker) observer() {}
+
+// Enrich counts err's failure under its key if err's chain carries the field
+// and the failure wasn't counted yet.
+func (q *QuotaTracker) Enrich(err *CTXError) map[string]any {
+	value, ok := lookupField[any](err, q.field)
+	if !ok {
+		return nil
+	}
+
+	key := fmt.Sprint(value)
+	root := innermostLayer(err)
+	now := Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.sweep(now)
+
+	current, ok := q.quotas[key]
+	if !ok {
+		current = &quota{
+			times:   make([]time.Time, 0, q.limit+1),
+			counted: make(map[*CTXError]time.Time),
+		}
+		q.quotas[key] = current
+	}
+
+	if counted, ok := current.counted[root]; ok && now.Sub(counted) < q.window {
+		return nil
+	}
+
+	current.counted[root] = now
+
+	if len(current.times) == q.limit+1 {
+		current.times = append(current.times[:0], current.times[1:]...)
+	}
+
+	current.times = append(current.times, now)
+
+	return nil
+}
+
+// Exceeded reports whether more than the limit of failures were counted under
+// key within the window up to now.
+func (q *QuotaTracker) Exceeded(key string) bool {
+	now := Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	current, ok := q.quotas[key]
+
+	return ok && len(current.times) == q.limit+1 && now.Sub(current.times[0]) < q.window
+}
+
+// sweep forgets the keys with no failures within the window up to now and the
+// counted failures older than it, at most once a window so it doesn't cost
+// every call a pass over all of them. q.mu must be held.
+func (q *QuotaTracker) sweep(now time.Time) {
+	if now.Sub(q.lastSweep) < q.window {
+		return
+	}
+
+	q.lastSweep = now
+
+	for key, current := range q.quotas {
+		if now.Sub(current.times[len(current.times)-1]) >= q.window {
+			delete(q.quotas, key)
+
+			continue
+		}
+
+		for root, counted := range current.counted {
+			if now.Sub(counted) >= q.window {
+				delete(current.counted, root)
+			}
+		}
+	}
+}
+
+// innermostLayer returns the innermost *CTXError layer of err's chain,
+// following Unwrap() error, which identifies the failure however it's wrapped.
+func innermostLayer(err *CTXError) *CTXError {
+	root := err
+
+	for current := err.err; current != nil; current = errors.Unwrap(current) {
+		if layer, ok := asCTXError(current); ok {
+			root = layer
+		}
+	}
+
+	return root
+}
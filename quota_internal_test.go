@@ -0,0 +1,105 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaTracker(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	SetClock(func() time.Time { return now })
+	t.Cleanup(func() { SetClock(nil) })
+
+	quota := NewQuotaTracker("tenant_id", time.Minute, 2)
+
+	unregister := RegisterEnrichHook(quota)
+	t.Cleanup(unregister)
+
+	fail := func(tenant string) error {
+		err := WithField(errors.New("connection refused"), "tenant_id", tenant) //nolint:err113
+
+		return Wrap(Wrap(err, "load user"), "handle request")
+	}
+
+	// Wrapping the same failure again doesn't count it again
+	_ = fail("acme")
+	_ = fail("acme")
+	require.False(t, quota.Exceeded("acme"))
+
+	err := fail("acme")
+	require.True(t, quota.Exceeded("acme"))
+	require.False(t, quota.Exceeded("globex"))
+	require.Empty(t, Wrap(err, "outer").(*CTXError).fields) //nolint:errorlint,forcetypeassert
+
+	// Errors without the field count for nobody
+	_ = Wrap(New("no tenant"), "wrapped")
+	require.False(t, quota.Exceeded(""))
+
+	// Every key has its own count
+	_ = fail("globex")
+	require.False(t, quota.Exceeded("globex"))
+
+	// The window slides past the burst
+	now = now.Add(2 * time.Minute)
+	require.False(t, quota.Exceeded("acme"))
+
+	_ = fail("acme")
+	require.False(t, quota.Exceeded("acme"))
+}
+
+func TestQuotaTrackerNonStringKey(t *testing.T) {
+	quota := NewQuotaTracker("account", time.Minute, 0)
+
+	unregister := RegisterEnrichHook(quota)
+	t.Cleanup(unregister)
+
+	_ = Wrap(WithField(errors.New("boom"), "account", 42), "wrapped") //nolint:err113
+
+	require.True(t, quota.Exceeded("42"))
+}
+
+func TestQuotaTrackerConstructorFields(t *testing.T) {
+	type tenantKey struct{}
+
+	unregisterProvider := RegisterFieldProvider(func(ctx context.Context) map[string]any {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return map[string]any{"tenant_id": tenant}
+		}
+
+		return nil
+	})
+	t.Cleanup(unregisterProvider)
+
+	first := NewQuotaTracker("tenant_id", time.Minute, 0)
+	quota := NewQuotaTracker("tenant_id", time.Minute, 1)
+
+	for _, tracker := range []*QuotaTracker{first, quota} {
+		t.Cleanup(RegisterEnrichHook(tracker))
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	// The outermost layer's own field counts right away
+	err := WrapCtx(ctx, errors.New("boom"), "x") //nolint:err113
+	require.True(t, first.Exceeded("acme"))
+
+	// And only once however it's wrapped
+	_ = WrapCtx(ctx, err, "again")
+	require.False(t, quota.Exceeded("acme"))
+
+	_ = WithField(errors.New("boom"), "tenant_id", "acme") //nolint:err113
+	require.True(t, quota.Exceeded("acme"))
+}
+
+func TestInnermostLayer(t *testing.T) {
+	inner := New("inner").(*CTXError)         //nolint:errorlint,forcetypeassert
+	outer := Wrap(inner, "outer").(*CTXError) //nolint:errorlint,forcetypeassert
+
+	require.Same(t, inner, innermostLayer(outer))
+	require.Same(t, inner, innermostLayer(inner))
+}
@@ -0,0 +1,58 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"time"
+)
+
+// Record is a normalized view of one layer of a chain, for reporters and UIs
+// that would otherwise call a handful of accessors per layer.
+type Record struct {
+	Type     string         // Go type, empty for *CTXError
+	Message  string         // Own message, without the layers below
+	File     string         // Empty unless a *CTXError with a location
+	Line     int            // Zero unless a *CTXError with a location
+	FuncName string         // Empty unless a *CTXError
+	ID       string         // Instance ID, see SetInstanceIDs
+	Fields   map[string]any // As the layer's Fields() hands them out
+	Kind     Kind           // Set on this layer, KindUnknown if none is
+	Time     time.Time      // When the layer was created, see SetTimestamps
+}
+
+// Records returns a Record for every layer of err's chain, outermost first,
+// including layers inside joined errors in the order walk visits them.
+// Errors that aren't *CTXError only get their type and the text they add to
+// what they wrap, and joined errors only their type. It returns nil for a nil
+// error.
+func Records(err error) []Record {
+	var records []Record
+
+	walk(err, func(current error) {
+		layer, ok := asCTXError(current)
+		if !ok {
+			record := Record{Type: fmt.Sprintf("%T", current)}
+			if _, ok := members(current); !ok {
+				record.Message = ownMessage(current)
+			}
+
+			records = append(records, record)
+
+			return
+		}
+
+		kind, _ := layer.fields[FieldKind].(Kind)
+
+		records = append(records, Record{
+			Message:  layer.msg(),
+			File:     layer.file,
+			Line:     layer.line,
+			FuncName: layer.funcName,
+			ID:       layer.id,
+			Fields:   layer.Fields(),
+			Kind:     kind,
+			Time:     layer.created,
+		})
+	})
+
+	return records
+}
@@ -0,0 +1,86 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecords(t *testing.T) {
+	require.NoError(t, SetRedactedFields("token"))
+	t.Cleanup(func() { require.NoError(t, SetRedactedFields()) })
+
+	root := error(&CTXError{
+		message:  "connection refused",
+		file:     "/src/app/db.go",
+		line:     12,
+		funcName: "app.dial",
+		fields:   map[string]any{"token": "secret", FieldKind: KindUnavailable},
+	})
+
+	err := error(&CTXError{
+		err:      errors.Join(fmt.Errorf("dial db: %w", root), io.EOF),
+		message:  "load user",
+		file:     "/src/app/user.go",
+		line:     42,
+		funcName: "app.load",
+		id:       "9f86d081-2s",
+	})
+
+	require.Equal(t, []Record{
+		{Message: "load user", File: "/src/app/user.go", Line: 42, FuncName: "app.load", ID: "9f86d081-2s"},
+		{Type: "*errors.joinError"},
+		{Type: "*fmt.wrapError", Message: "dial db"},
+		{
+			Message:  "connection refused",
+			File:     "/src/app/db.go",
+			Line:     12,
+			FuncName: "app.dial",
+			Fields:   map[string]any{"token": RedactedValue, FieldKind: KindUnavailable},
+			Kind:     KindUnavailable,
+		},
+		{Type: "*errors.errorString", Message: "EOF"},
+	}, Records(err))
+
+	t.Run("nil error", func(t *testing.T) {
+		require.Nil(t, Records(nil))
+	})
+}
+
+func TestSetTimestamps(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	t.Cleanup(func() { SetClock(nil) })
+
+	t.Run("off by default", func(t *testing.T) {
+		err := New("boom")
+
+		require.True(t, err.(*CTXError).Time().IsZero()) //nolint:errorlint,forcetypeassert
+	})
+
+	t.Run("on", func(t *testing.T) {
+		SetTimestamps(true)
+		t.Cleanup(func() { SetTimestamps(false) })
+
+		origin := New("boom")
+		now = now.Add(time.Second)
+		err := Wrap(origin, "failed")
+
+		require.Equal(t, now.Add(-time.Second), origin.(*CTXError).Time()) //nolint:errorlint,forcetypeassert
+
+		records := Records(err)
+		require.Len(t, records, 2)
+		require.Equal(t, now, records[0].Time)
+		require.Equal(t, now.Add(-time.Second), records[1].Time)
+	})
+
+	t.Run("nil layer", func(t *testing.T) {
+		var layer *CTXError
+
+		require.True(t, layer.Time().IsZero())
+	})
+}
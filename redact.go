@@ -0,0 +1,63 @@
+package ctxerrors
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// RedactedValue replaces the values of redacted fields and headers.
+const RedactedValue = "[REDACTED]"
+
+// SetRedactedFields sets the field key patterns whose values Fields replaces
+// with RedactedValue, keeping the keys visible, so secrets attached to errors
+// don't leak into logs, reporters or anything else that renders fields.
+// Patterns use path.Match syntax and match keys case-insensitively, e.g.
+// "password", "*token*" or "*_secret". Calling it with no patterns turns
+// redaction off, which is the default. The values stay untouched inside the
+// error, only the copies handed out are redacted.
+func SetRedactedFields(patterns ...string) error {
+	lowered := make([]string, len(patterns))
+
+	for i, pattern := range patterns {
+		lowered[i] = strings.ToLower(pattern)
+
+		if _, err := path.Match(lowered[i], ""); err != nil {
+			return Wrapf(err, "invalid redaction pattern %q", pattern)
+		}
+	}
+
+	updateConfig(func(c *config) {
+		c.redactedFields = slices.Clip(lowered)
+	})
+
+	return nil
+}
+
+// redactFields replaces the values of the keys in fields that match one of
+// patterns with RedactedValue, in place.
+func redactFields(fields map[string]any, patterns []string) {
+	if len(patterns) == 0 {
+		return
+	}
+
+	for key := range fields {
+		if isRedacted(key, patterns) {
+			fields[key] = RedactedValue
+		}
+	}
+}
+
+// isRedacted reports whether key matches one of patterns.
+func isRedacted(key string, patterns []string) bool {
+	lowered := strings.ToLower(key)
+
+	for _, pattern := range patterns {
+		// Patterns were validated by SetRedactedFields
+		if matched, _ := path.Match(pattern, lowered); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package ctxerrors
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetRedactedFields(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetRedactedFields()) })
+
+	fields := map[string]any{
+		"password":     "hunter2",
+		"API_TOKEN":    "abc",
+		"db_secret":    "s3cr3t",
+		"user_id":      42,
+		"secret_santa": "bob",
+	}
+
+	err := annotate(New("login failed"), fields, 0)
+
+	var ctxErr *CTXError
+
+	require.True(t, errors.As(err, &ctxErr))
+
+	t.Run("off by default", func(t *testing.T) {
+		require.Equal(t, fields, ctxErr.Fields())
+	})
+
+	t.Run("redacts matching keys", func(t *testing.T) {
+		require.NoError(t, SetRedactedFields("password", "*token*", "*_secret"))
+
+		require.Equal(t, map[string]any{
+			"password":     RedactedValue,
+			"API_TOKEN":    RedactedValue,
+			"db_secret":    RedactedValue,
+			"user_id":      42,
+			"secret_santa": "bob",
+		}, ctxErr.Fields())
+
+		// The error itself keeps the real values
+		require.Equal(t, "hunter2", ctxErr.fields["password"])
+	})
+
+	t.Run("turned off again", func(t *testing.T) {
+		require.NoError(t, SetRedactedFields())
+		require.Equal(t, fields, ctxErr.Fields())
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		require.NoError(t, SetRedactedFields("password"))
+		require.ErrorIs(t, SetRedactedFields("[token"), path.ErrBadPattern)
+
+		// The previous patterns stay in place
+		require.Equal(t, RedactedValue, ctxErr.Fields()["password"])
+	})
+}
@@ -0,0 +1,43 @@
+package ctxerrors
+
+import "strconv"
+
+// SetCollapseRepeats controls whether Error() renders a run of consecutive
+// layers with the same message once, followed by how many there are, so a
+// chain wrapped in a loop reads "retrying (×5): connection refused" rather
+// than "retrying: retrying: retrying: retrying: retrying: connection
+// refused". Only the location and ID of the outermost layer of a run are
+// rendered, so every rendered message still gets one location. The chain
+// itself is left alone, Unwrap, Messages and everything else still see every
+// layer. It's off by default.
+func SetCollapseRepeats(collapse bool) {
+	updateConfig(func(c *config) {
+		c.collapseRepeats = collapse
+	})
+}
+
+// collapseRepeats keeps the outermost layer of every run of consecutive
+// layers with the same message, in place, and returns the kept layers along
+// with the length of the run each one stands for.
+func collapseRepeats(layers []*CTXError) ([]*CTXError, []int) {
+	repeats := make([]int, 0, len(layers))
+	kept := layers[:0]
+
+	for _, layer := range layers {
+		if last := len(kept) - 1; last >= 0 && kept[last].msg() == layer.msg() {
+			repeats[last]++
+
+			continue
+		}
+
+		kept = append(kept, layer)
+		repeats = append(repeats, 1)
+	}
+
+	return kept, repeats
+}
+
+// repeatMarker returns what follows the message of a run of n layers.
+func repeatMarker(n int) string {
+	return " (×" + strconv.Itoa(n) + ")"
+}
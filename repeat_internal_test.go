@@ -0,0 +1,72 @@
+package ctxerrors
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCollapseRepeats(t *testing.T) {
+	t.Cleanup(func() {
+		SetCollapseRepeats(false)
+		SetHideLocation(false)
+	})
+
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	retried := baseErr
+	for range 5 {
+		retried = Wrap(retried, "retrying")
+	}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "run at the top",
+			err:      Wrap(retried, "sync"),
+			expected: "sync: retrying (×5): connection refused",
+		},
+		{
+			name:     "separate runs",
+			err:      Wrap(Wrap(Wrap(New("down"), "retrying"), "backoff"), "retrying"),
+			expected: "retrying: backoff: retrying: down",
+		},
+		{
+			name:     "cause isn't collapsed",
+			err:      Wrap(Wrap(errors.New("retrying"), "retrying"), "retrying"), //nolint:err113
+			expected: "retrying (×2): retrying",
+		},
+	}
+
+	SetCollapseRepeats(true)
+	SetHideLocation(true)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.err.Error())
+		})
+	}
+
+	t.Run("one location per message", func(t *testing.T) {
+		SetHideLocation(false)
+
+		text := Wrap(retried, "sync").Error()
+		require.Len(t, regexp.MustCompile(`\[\S+:\d+ in `).FindAllString(text, -1), 2)
+	})
+
+	t.Run("chain left alone", func(t *testing.T) {
+		require.Len(t, Messages(retried), 6)
+	})
+
+	t.Run("off", func(t *testing.T) {
+		SetCollapseRepeats(false)
+		SetHideLocation(true)
+
+		require.Equal(t, "retrying: retrying: retrying: retrying: retrying: connection refused", retried.Error())
+	})
+}
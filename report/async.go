@@ -0,0 +1,177 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+var (
+	// ErrQueueFull is returned by AsyncReporter.Report when the queue is full
+	// and the error was dropped.
+	ErrQueueFull = errors.New("report queue full")
+	// ErrReporterClosed is returned by AsyncReporter.Report after Close.
+	ErrReporterClosed = errors.New("reporter closed")
+)
+
+// DropPolicy decides what an AsyncReporter drops when its queue is full.
+type DropPolicy int
+
+const (
+	// DropNewest drops the error being reported, keeping what's queued. This
+	// is the default.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the longest queued error to make room for the new one.
+	DropOldest
+)
+
+// asyncReport is a queued Report call.
+type asyncReport struct {
+	ctx context.Context //nolint:containedctx
+	err error
+}
+
+// AsyncReporter queues errors and reports them in the background, so reporters
+// doing network I/O never stall the code reporting the error. The queue is
+// bounded and what doesn't fit is dropped as its DropPolicy says.
+type AsyncReporter struct {
+	reporter Reporter
+	policy   DropPolicy
+	queue    chan asyncReport
+	done     chan struct{} // Closed when the worker exits
+	dropped  atomic.Uint64
+
+	mu      sync.Mutex // Guards the fields below and sends on queue
+	closed  bool
+	pending int           // Queued or being reported
+	idle    chan struct{} // Closed while pending is zero
+}
+
+// NewAsyncReporter returns an AsyncReporter sending errors to reporter from a
+// queue of queueSize, at least one.
+func NewAsyncReporter(reporter Reporter, queueSize int, policy DropPolicy) *AsyncReporter {
+	idle := make(chan struct{})
+	close(idle)
+
+	r := &AsyncReporter{
+		reporter: reporter,
+		policy:   policy,
+		queue:    make(chan asyncReport, max(queueSize, 1)),
+		done:     make(chan struct{}),
+		idle:     idle,
+	}
+
+	go r.run()
+
+	return r
+}
+
+// Report queues err and returns without waiting for it to be reported. It
+// returns ErrQueueFull if err was dropped under DropNewest and
+// ErrReporterClosed after Close. Failures of the wrapped reporter are logged.
+// ctx is used for its values only, its cancellation doesn't stop the report.
+// A nil err isn't reported.
+func (r *AsyncReporter) Report(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrReporterClosed
+	}
+
+	item := asyncReport{ctx: context.WithoutCancel(ctx), err: err}
+
+	select {
+	case r.queue <- item:
+	default:
+		if r.policy != DropOldest {
+			r.dropped.Add(1)
+
+			return ErrQueueFull
+		}
+
+		// Only the worker receives and it can only make room, so the send
+		// below can't block
+		select {
+		case <-r.queue:
+			r.dropped.Add(1)
+			r.pending--
+		default:
+		}
+
+		r.queue <- item
+	}
+
+	if r.pending == 0 {
+		r.idle = make(chan struct{})
+	}
+
+	r.pending++
+
+	return nil
+}
+
+// Dropped returns how many errors were dropped because the queue was full.
+func (r *AsyncReporter) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Flush waits until every queued error has been reported or ctx is done.
+func (r *AsyncReporter) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	idle := r.idle
+	r.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctxerrors.Wrap(ctx.Err(), "failed to flush reports")
+	}
+}
+
+// Close stops accepting errors and waits until the queued ones have been
+// reported or ctx is done, for shutdown. Calling it more than once is fine.
+func (r *AsyncReporter) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctxerrors.Wrap(ctx.Err(), "failed to close reporter")
+	}
+}
+
+// run reports queued errors until the queue is closed.
+func (r *AsyncReporter) run() {
+	defer close(r.done)
+
+	for item := range r.queue {
+		if err := r.reporter.Report(item.ctx, item.err); err != nil {
+			slog.Error("Async report failed", "error", err)
+		}
+
+		r.mu.Lock()
+
+		r.pending--
+		if r.pending == 0 {
+			close(r.idle)
+		}
+
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,137 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// blockingReporter records reported messages, blocking each report until
+// release is closed.
+type blockingReporter struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	reported []string
+}
+
+func newBlockingReporter() *blockingReporter {
+	return &blockingReporter{release: make(chan struct{})}
+}
+
+func (r *blockingReporter) Report(_ context.Context, err error) error {
+	<-r.release
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reported = append(r.reported, ctxerrors.Messages(err)[0])
+
+	return nil
+}
+
+func (r *blockingReporter) messages() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reported
+}
+
+func TestAsyncReporter(t *testing.T) {
+	t.Run("reports in the background", func(t *testing.T) {
+		backend := newBlockingReporter()
+		async := NewAsyncReporter(backend, 4, DropNewest)
+
+		require.NoError(t, async.Report(context.Background(), ctxerrors.New("first")))
+		require.NoError(t, async.Report(context.Background(), ctxerrors.New("second")))
+		require.NoError(t, async.Report(context.Background(), nil))
+		require.Empty(t, backend.messages())
+
+		close(backend.release)
+		require.NoError(t, async.Flush(context.Background()))
+		require.Equal(t, []string{"first", "second"}, backend.messages())
+		require.NoError(t, async.Close(context.Background()))
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		backend := newBlockingReporter()
+		async := NewAsyncReporter(backend, 1, DropNewest)
+
+		// Wait for the worker to pick the first one up so the queue is empty
+		require.NoError(t, async.Report(context.Background(), ctxerrors.New("in flight")))
+		require.Eventually(t, func() bool { return len(async.queue) == 0 }, time.Second, time.Millisecond)
+
+		require.NoError(t, async.Report(context.Background(), ctxerrors.New("queued")))
+		require.ErrorIs(t, async.Report(context.Background(), ctxerrors.New("dropped")), ErrQueueFull)
+		require.Equal(t, uint64(1), async.Dropped())
+
+		close(backend.release)
+		require.NoError(t, async.Close(context.Background()))
+		require.Equal(t, []string{"in flight", "queued"}, backend.messages())
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		backend := newBlockingReporter()
+		async := NewAsyncReporter(backend, 1, DropOldest)
+
+		require.NoError(t, async.Report(context.Background(), ctxerrors.New("in flight")))
+		require.Eventually(t, func() bool { return len(async.queue) == 0 }, time.Second, time.Millisecond)
+
+		require.NoError(t, async.Report(context.Background(), ctxerrors.New("dropped")))
+		require.NoError(t, async.Report(context.Background(), ctxerrors.New("queued")))
+		require.Equal(t, uint64(1), async.Dropped())
+
+		close(backend.release)
+		require.NoError(t, async.Flush(context.Background()))
+		require.Equal(t, []string{"in flight", "queued"}, backend.messages())
+		require.NoError(t, async.Close(context.Background()))
+	})
+
+	t.Run("flush and close give up when ctx is done", func(t *testing.T) {
+		backend := newBlockingReporter()
+		async := NewAsyncReporter(backend, 1, DropNewest)
+
+		require.NoError(t, async.Report(context.Background(), ctxerrors.New("stuck")))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.ErrorIs(t, async.Flush(ctx), context.Canceled)
+		require.ErrorIs(t, async.Close(ctx), context.Canceled)
+		require.ErrorIs(t, async.Report(context.Background(), ctxerrors.New("late")), ErrReporterClosed)
+
+		close(backend.release)
+		require.NoError(t, async.Close(context.Background()))
+		require.Equal(t, []string{"stuck"}, backend.messages())
+	})
+
+	t.Run("reporting ctx cancellation doesn't stop the report", func(t *testing.T) {
+		type ctxKey struct{}
+
+		var (
+			gotValue any
+			gotErr   error
+		)
+
+		async := NewAsyncReporter(ReporterFunc(func(ctx context.Context, _ error) error {
+			gotValue = ctx.Value(ctxKey{})
+			gotErr = ctx.Err()
+
+			return errors.New("backend down") //nolint:err113
+		}), 1, DropNewest)
+
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request"))
+		require.NoError(t, async.Report(ctx, ctxerrors.New("boom")))
+		cancel()
+
+		require.NoError(t, async.Close(context.Background()))
+		require.Equal(t, "request", gotValue)
+		require.NoError(t, gotErr)
+	})
+}
@@ -0,0 +1,140 @@
+package report
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+const (
+	bugsnagEndpoint       = "https://notify.bugsnag.com"
+	bugsnagPayloadVersion = "5"
+)
+
+// BugsnagReporter reports errors to Bugsnag's notify API.
+type BugsnagReporter struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+type bugsnagPayload struct {
+	APIKey         string          `json:"apiKey"`
+	PayloadVersion string          `json:"payloadVersion"`
+	Notifier       bugsnagNotifier `json:"notifier"`
+	Events         []bugsnagEvent  `json:"events"`
+}
+
+type bugsnagNotifier struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	URL     string `json:"url"`
+}
+
+type bugsnagEvent struct {
+	Exceptions     []bugsnagException        `json:"exceptions"`
+	Severity       string                    `json:"severity"`
+	SeverityReason map[string]string         `json:"severityReason"`
+	Unhandled      bool                      `json:"unhandled"`
+	MetaData       map[string]map[string]any `json:"metaData,omitempty"`
+}
+
+type bugsnagException struct {
+	ErrorClass string         `json:"errorClass"`
+	Message    string         `json:"message"`
+	Stacktrace []bugsnagFrame `json:"stacktrace"`
+	Type       string         `json:"type"`
+}
+
+type bugsnagFrame struct {
+	File       string `json:"file"`
+	LineNumber int    `json:"lineNumber"`
+	Method     string `json:"method"`
+	InProject  bool   `json:"inProject"`
+}
+
+// NewBugsnagReporter returns a Reporter for the project identified by apiKey.
+// A nil client means http.DefaultClient.
+func NewBugsnagReporter(apiKey string, client *http.Client) *BugsnagReporter {
+	return &BugsnagReporter{
+		apiKey:   apiKey,
+		endpoint: bugsnagEndpoint,
+		client:   client,
+	}
+}
+
+// Report sends err to Bugsnag as a handled error, one exception per error in
+// the chain with the outermost first and fields as metadata. A nil err isn't
+// reported.
+func (r *BugsnagReporter) Report(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	headers := map[string]string{
+		"Bugsnag-Api-Key":         r.apiKey,
+		"Bugsnag-Payload-Version": bugsnagPayloadVersion,
+		"Bugsnag-Sent-At":         ctxerrors.Now().UTC().Format(time.RFC3339),
+	}
+
+	if err := postJSON(ctx, r.client, r.endpoint, headers, r.payload(err)); err != nil {
+		return ctxerrors.Wrap(err, "failed to report to bugsnag")
+	}
+
+	return nil
+}
+
+// payload builds the notify API payload for err.
+func (r *BugsnagReporter) payload(err error) bugsnagPayload {
+	chain := elements(err)
+
+	event := bugsnagEvent{
+		Exceptions:     make([]bugsnagException, 0, len(chain)),
+		Severity:       "error",
+		SeverityReason: map[string]string{"type": "handledError"},
+		Unhandled:      false,
+		MetaData:       nil,
+	}
+
+	for _, current := range chain {
+		exception := bugsnagException{
+			ErrorClass: current.typeName,
+			Message:    current.message,
+			Stacktrace: []bugsnagFrame{},
+			Type:       "go",
+		}
+
+		if current.layer != nil {
+			exception.Stacktrace = append(exception.Stacktrace, bugsnagFrame{
+				File:       current.layer.File(),
+				LineNumber: current.layer.Line(),
+				Method:     current.layer.FuncName(),
+				InProject:  true,
+			})
+		}
+
+		event.Exceptions = append(event.Exceptions, exception)
+	}
+
+	if merged := ctxerrors.Fields(err); merged != nil {
+		event.MetaData = map[string]map[string]any{"fields": merged}
+	}
+
+	return bugsnagPayload{
+		APIKey:         r.apiKey,
+		PayloadVersion: bugsnagPayloadVersion,
+		Notifier:       bugsnagNotifierInfo(),
+		Events:         []bugsnagEvent{event},
+	}
+}
+
+// bugsnagNotifierInfo identifies this package to Bugsnag.
+func bugsnagNotifierInfo() bugsnagNotifier {
+	return bugsnagNotifier{
+		Name:    "ctxerrors",
+		Version: "1",
+		URL:     "https://github.com/psyb0t/ctxerrors",
+	}
+}
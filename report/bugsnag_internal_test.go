@@ -0,0 +1,71 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestBugsnagReporter(t *testing.T) {
+	t.Run("sends the event", func(t *testing.T) {
+		server, requests := newBackend(t, http.StatusAccepted)
+
+		reporter := NewBugsnagReporter("api-key", server.Client())
+		reporter.endpoint = server.URL
+
+		unregister := ctxerrors.RegisterEnrichHook(ctxerrors.EnrichHookFunc(
+			func(*ctxerrors.CTXError) map[string]any { return map[string]any{"user_id": "42"} },
+		))
+		defer unregister()
+
+		baseErr := errors.New("connection refused") //nolint:err113
+		err := ctxerrors.Wrap(wrapForeign(baseErr), "load user")
+
+		require.NoError(t, reporter.Report(context.Background(), err))
+		require.Len(t, requests(), 1)
+
+		req := requests()[0]
+		require.Equal(t, "api-key", req.headers.Get("Bugsnag-Api-Key"))
+		require.Equal(t, "5", req.headers.Get("Bugsnag-Payload-Version"))
+		require.NotEmpty(t, req.headers.Get("Bugsnag-Sent-At"))
+
+		require.Equal(t, "api-key", jsonAt(t, req.body, "apiKey"))
+		require.Equal(t, "ctxerrors", jsonAt(t, req.body, "notifier", "name"))
+		require.Equal(t, "error", jsonAt(t, req.body, "events", 0, "severity"))
+		require.Equal(t, "handledError", jsonAt(t, req.body, "events", 0, "severityReason", "type"))
+		require.Equal(t, "42", jsonAt(t, req.body, "events", 0, "metaData", "fields", "user_id"))
+
+		exceptions := jsonAt(t, req.body, "events", 0, "exceptions")
+		require.Len(t, exceptions, 3)
+		require.Equal(t, "load user", jsonAt(t, exceptions, 0, "message"))
+		require.Equal(t, "*ctxerrors.CTXError", jsonAt(t, exceptions, 0, "errorClass"))
+		require.Contains(t, jsonAt(t, exceptions, 0, "stacktrace", 0, "method"), "TestBugsnagReporter")
+		require.Equal(t, "foreign", jsonAt(t, exceptions, 1, "message"))
+		require.Empty(t, jsonAt(t, exceptions, 1, "stacktrace"))
+		require.Equal(t, "connection refused", jsonAt(t, exceptions, 2, "message"))
+	})
+
+	t.Run("nil error is not sent", func(t *testing.T) {
+		server, requests := newBackend(t, http.StatusAccepted)
+
+		reporter := NewBugsnagReporter("api-key", server.Client())
+		reporter.endpoint = server.URL
+
+		require.NoError(t, reporter.Report(context.Background(), nil))
+		require.Empty(t, requests())
+	})
+
+	t.Run("bad status", func(t *testing.T) {
+		server, _ := newBackend(t, http.StatusUnauthorized)
+
+		reporter := NewBugsnagReporter("api-key", server.Client())
+		reporter.endpoint = server.URL
+
+		require.ErrorIs(t, reporter.Report(context.Background(), ctxerrors.New("boom")), ErrUnexpectedStatus)
+	})
+}
@@ -0,0 +1,42 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// element is one error in a chain as the adapters see it.
+type element struct {
+	typeName string
+	message  string
+	layer    *ctxerrors.CTXError // Nil for errors that aren't *CTXError
+}
+
+// elements walks err's chain through Unwrap, outermost first. Foreign errors
+// get the text of whatever they wrap trimmed off their own.
+func elements(err error) []element {
+	var chain []element
+
+	for err != nil {
+		current := element{
+			typeName: fmt.Sprintf("%T", err),
+			message:  err.Error(),
+			layer:    nil,
+		}
+
+		if layer, ok := err.(*ctxerrors.CTXError); ok && layer != nil { //nolint:errorlint
+			current.message = layer.Message()
+			current.layer = layer
+		} else if inner := errors.Unwrap(err); inner != nil {
+			current.message = strings.TrimSuffix(current.message, ": "+inner.Error())
+		}
+
+		chain = append(chain, current)
+		err = errors.Unwrap(err)
+	}
+
+	return chain
+}
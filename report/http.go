@@ -0,0 +1,59 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// ErrUnexpectedStatus is returned when a backend answers with a non-2xx status.
+var ErrUnexpectedStatus = errors.New("unexpected response status")
+
+// postJSON sends payload as JSON to url with the given extra headers.
+func postJSON(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	headers map[string]string,
+	payload any,
+) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return ctxerrors.Wrap(err, "failed to marshal payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return ctxerrors.Wrap(err, "failed to create request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctxerrors.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return ctxerrors.Wrapf(ErrUnexpectedStatus, "status %d from %s", resp.StatusCode, url)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+// Package report sends ctxerrors chains to error reporting backends.
+//
+// Call sites depend on the Reporter interface only, so backends can be swapped
+// or combined without touching them. The bundled adapters talk to the
+// backends' HTTP APIs directly instead of pulling in their SDKs.
+package report
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Reporter sends an error to an error reporting backend.
+type Reporter interface {
+	Report(ctx context.Context, err error) error
+}
+
+// ReporterFunc adapts an ordinary function to the Reporter interface.
+type ReporterFunc func(ctx context.Context, err error) error
+
+// Report calls f(ctx, err).
+func (f ReporterFunc) Report(ctx context.Context, err error) error {
+	return f(ctx, err)
+}
+
+// FanoutReporter reports every error to all of its reporters concurrently.
+type FanoutReporter struct {
+	reporters []Reporter
+}
+
+// NewFanoutReporter returns a Reporter multiplexing to reporters.
+func NewFanoutReporter(reporters ...Reporter) *FanoutReporter {
+	return &FanoutReporter{reporters: reporters}
+}
+
+// Report sends err to every reporter and waits for all of them. The returned
+// error joins whatever the reporters returned. A nil err isn't reported.
+func (r *FanoutReporter) Report(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	errs := make([]error, len(r.reporters))
+
+	var wg sync.WaitGroup
+
+	for i, reporter := range r.reporters {
+		wg.Go(func() {
+			errs[i] = reporter.Report(ctx, err)
+		})
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
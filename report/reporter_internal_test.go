@@ -0,0 +1,83 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestFanoutReporter(t *testing.T) {
+	t.Run("reports to every reporter", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			reported []string
+		)
+
+		record := func(name string) Reporter {
+			return ReporterFunc(func(_ context.Context, err error) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				reported = append(reported, name+": "+err.Error())
+
+				return nil
+			})
+		}
+
+		err := ctxerrors.New("boom")
+		fanout := NewFanoutReporter(record("first"), record("second"))
+
+		require.NoError(t, fanout.Report(context.Background(), err))
+		require.ElementsMatch(t, []string{"first: " + err.Error(), "second: " + err.Error()}, reported)
+	})
+
+	t.Run("joins reporter errors", func(t *testing.T) {
+		firstErr := errors.New("first failed")   //nolint:err113
+		secondErr := errors.New("second failed") //nolint:err113
+
+		fanout := NewFanoutReporter(
+			ReporterFunc(func(context.Context, error) error { return firstErr }),
+			ReporterFunc(func(context.Context, error) error { return nil }),
+			ReporterFunc(func(context.Context, error) error { return secondErr }),
+		)
+
+		err := fanout.Report(context.Background(), ctxerrors.New("boom"))
+		require.ErrorIs(t, err, firstErr)
+		require.ErrorIs(t, err, secondErr)
+	})
+
+	t.Run("nil error is not reported", func(t *testing.T) {
+		called := false
+		fanout := NewFanoutReporter(ReporterFunc(func(context.Context, error) error {
+			called = true
+
+			return nil
+		}))
+
+		require.NoError(t, fanout.Report(context.Background(), nil))
+		require.False(t, called)
+	})
+
+	t.Run("no reporters", func(t *testing.T) {
+		require.NoError(t, NewFanoutReporter().Report(context.Background(), ctxerrors.New("boom")))
+	})
+}
+
+func TestElements(t *testing.T) {
+	chain := elements(ctxerrors.Wrap(wrapForeign(ctxerrors.New("dial failed")), "load user"))
+
+	require.Len(t, chain, 3)
+	require.Equal(t, "*ctxerrors.CTXError", chain[0].typeName)
+	require.Equal(t, "load user", chain[0].message)
+	require.NotNil(t, chain[0].layer)
+	require.Equal(t, "*fmt.wrapError", chain[1].typeName)
+	require.Equal(t, "foreign", chain[1].message)
+	require.Nil(t, chain[1].layer)
+	require.Equal(t, "dial failed", chain[2].message)
+	require.Nil(t, elements(nil))
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+const rollbarEndpoint = "https://api.rollbar.com/api/1/item/"
+
+// RollbarReporter reports errors to Rollbar's item API.
+type RollbarReporter struct {
+	accessToken string
+	environment string
+	endpoint    string
+	client      *http.Client
+}
+
+type rollbarPayload struct {
+	Data rollbarData `json:"data"`
+}
+
+type rollbarData struct {
+	Environment string            `json:"environment"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Language    string            `json:"language"`
+	Timestamp   int64             `json:"timestamp"`
+	Notifier    map[string]string `json:"notifier"`
+	Body        rollbarBody       `json:"body"`
+	Custom      map[string]any    `json:"custom,omitempty"`
+}
+
+type rollbarBody struct {
+	TraceChain []rollbarTrace `json:"trace_chain"` //nolint:tagliatelle
+}
+
+type rollbarTrace struct {
+	Frames    []rollbarFrame   `json:"frames"`
+	Exception rollbarException `json:"exception"`
+}
+
+type rollbarFrame struct {
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+	Method   string `json:"method"`
+}
+
+type rollbarException struct {
+	Class   string `json:"class"`
+	Message string `json:"message"`
+}
+
+// NewRollbarReporter returns a Reporter for the project identified by
+// accessToken, reporting under environment. A nil client means
+// http.DefaultClient.
+func NewRollbarReporter(accessToken, environment string, client *http.Client) *RollbarReporter {
+	return &RollbarReporter{
+		accessToken: accessToken,
+		environment: environment,
+		endpoint:    rollbarEndpoint,
+		client:      client,
+	}
+}
+
+// Report sends err to Rollbar as a trace chain, one trace per error in the
+// chain with the outermost first and fields as custom data. A nil err isn't
+// reported.
+func (r *RollbarReporter) Report(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	headers := map[string]string{"X-Rollbar-Access-Token": r.accessToken}
+
+	if err := postJSON(ctx, r.client, r.endpoint, headers, r.payload(err)); err != nil {
+		return ctxerrors.Wrap(err, "failed to report to rollbar")
+	}
+
+	return nil
+}
+
+// payload builds the item API payload for err.
+func (r *RollbarReporter) payload(err error) rollbarPayload {
+	chain := elements(err)
+	traces := make([]rollbarTrace, 0, len(chain))
+
+	for _, current := range chain {
+		trace := rollbarTrace{
+			Frames: []rollbarFrame{},
+			Exception: rollbarException{
+				Class:   current.typeName,
+				Message: current.message,
+			},
+		}
+
+		if current.layer != nil {
+			trace.Frames = append(trace.Frames, rollbarFrame{
+				Filename: current.layer.File(),
+				Lineno:   current.layer.Line(),
+				Method:   current.layer.FuncName(),
+			})
+		}
+
+		traces = append(traces, trace)
+	}
+
+	return rollbarPayload{
+		Data: rollbarData{
+			Environment: r.environment,
+			Level:       "error",
+			Platform:    "go",
+			Language:    "go",
+			Timestamp:   ctxerrors.Now().Unix(),
+			Notifier:    map[string]string{"name": "ctxerrors", "version": "1"},
+			Body:        rollbarBody{TraceChain: traces},
+			Custom:      ctxerrors.Fields(err),
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestRollbarReporter(t *testing.T) {
+	t.Run("sends the item", func(t *testing.T) {
+		server, requests := newBackend(t, http.StatusOK)
+
+		reporter := NewRollbarReporter("token", "staging", server.Client())
+		reporter.endpoint = server.URL
+
+		err := ctxerrors.Wrap(ctxerrors.New("dial failed"), "load user")
+
+		require.NoError(t, reporter.Report(context.Background(), err))
+		require.Len(t, requests(), 1)
+
+		req := requests()[0]
+		require.Equal(t, "token", req.headers.Get("X-Rollbar-Access-Token"))
+		require.Equal(t, "staging", jsonAt(t, req.body, "data", "environment"))
+		require.Equal(t, "error", jsonAt(t, req.body, "data", "level"))
+		require.Equal(t, "go", jsonAt(t, req.body, "data", "language"))
+		require.Nil(t, jsonAt(t, req.body, "data", "custom"))
+
+		traces := jsonAt(t, req.body, "data", "body", "trace_chain")
+		require.Len(t, traces, 2)
+		require.Equal(t, "load user", jsonAt(t, traces, 0, "exception", "message"))
+		require.Equal(t, "*ctxerrors.CTXError", jsonAt(t, traces, 0, "exception", "class"))
+		require.Contains(t, jsonAt(t, traces, 0, "frames", 0, "filename"), "rollbar_internal_test.go")
+		require.NotZero(t, jsonAt(t, traces, 0, "frames", 0, "lineno"))
+		require.Equal(t, "dial failed", jsonAt(t, traces, 1, "exception", "message"))
+	})
+
+	t.Run("nil error is not sent", func(t *testing.T) {
+		server, requests := newBackend(t, http.StatusOK)
+
+		reporter := NewRollbarReporter("token", "staging", server.Client())
+		reporter.endpoint = server.URL
+
+		require.NoError(t, reporter.Report(context.Background(), nil))
+		require.Empty(t, requests())
+	})
+
+	t.Run("unreachable backend", func(t *testing.T) {
+		server, _ := newBackend(t, http.StatusOK)
+
+		reporter := NewRollbarReporter("token", "staging", server.Client())
+		reporter.endpoint = server.URL
+		server.Close()
+
+		require.Error(t, reporter.Report(context.Background(), ctxerrors.New("boom")))
+	})
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"context"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Severity says how urgently an error needs someone's attention, which is
+// what RoutingReporter picks a reporter by.
+type Severity int
+
+// Severities, least severe first.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+// FieldSeverity is the field key an error's Severity can be set under to
+// override the one its Kind gives it, e.g. with
+// ctxerrors.WithField(err, report.FieldSeverity, report.SeverityCritical).
+const FieldSeverity = "severity"
+
+// String returns the severity's name, e.g. "warning".
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultSeverities returns the severities errors get by Kind: internal and
+// unclassified errors are errors, unavailable dependencies and timeouts are
+// warnings, and errors the caller caused, cancellation and end of data are
+// info. Nothing is critical unless it says so in its FieldSeverity field.
+func DefaultSeverities() map[ctxerrors.Kind]Severity {
+	return map[ctxerrors.Kind]Severity{
+		ctxerrors.KindUnknown:          SeverityError,
+		ctxerrors.KindInternal:         SeverityError,
+		ctxerrors.KindUnavailable:      SeverityWarning,
+		ctxerrors.KindDeadlineExceeded: SeverityWarning,
+		ctxerrors.KindNotFound:         SeverityInfo,
+		ctxerrors.KindInvalidArgument:  SeverityInfo,
+		ctxerrors.KindAlreadyExists:    SeverityInfo,
+		ctxerrors.KindPermissionDenied: SeverityInfo,
+		ctxerrors.KindUnauthenticated:  SeverityInfo,
+		ctxerrors.KindCanceled:         SeverityInfo,
+		ctxerrors.KindEndOfData:        SeverityInfo,
+	}
+}
+
+// RoutingReporter sends every error to the reporter configured for its
+// severity, so the destination is decided once, where the reporters are set
+// up, instead of at every call site:
+//
+//	reporter := report.NewRoutingReporter(map[report.Severity]report.Reporter{
+//		report.SeverityCritical: report.NewFanoutReporter(pagerDuty, sentry),
+//		report.SeverityError:    sentry,
+//		report.SeverityWarning:  logOnly,
+//	}, nil)
+//
+// An error whose severity has no reporter goes to the one of the closest
+// lower severity that has, so in the example above a critical error without
+// the pager route would still reach Sentry. Errors below every configured
+// severity are dropped.
+type RoutingReporter struct {
+	routes     map[Severity]Reporter
+	severities map[ctxerrors.Kind]Severity
+}
+
+// NewRoutingReporter returns a RoutingReporter sending errors to routes by
+// severity, which errors get by Kind from severities, DefaultSeverities() if
+// nil. Kinds missing from severities are errors.
+func NewRoutingReporter(routes map[Severity]Reporter, severities map[ctxerrors.Kind]Severity) *RoutingReporter {
+	if severities == nil {
+		severities = DefaultSeverities()
+	}
+
+	return &RoutingReporter{routes: routes, severities: severities}
+}
+
+// Report sends err to the reporter for its severity and returns what that
+// returns, or nil if there's none. A nil err isn't reported.
+func (r *RoutingReporter) Report(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	reporter, ok := r.Route(err)
+	if !ok {
+		return nil
+	}
+
+	return reporter.Report(ctx, err)
+}
+
+// Route returns the reporter err would be sent to, and false if it would be
+// dropped.
+func (r *RoutingReporter) Route(err error) (Reporter, bool) {
+	for severity := r.SeverityOf(err); severity >= SeverityInfo; severity-- {
+		if reporter, ok := r.routes[severity]; ok && reporter != nil {
+			return reporter, true
+		}
+	}
+
+	return nil, false
+}
+
+// SeverityOf returns the severity set in err's FieldSeverity field, or else
+// the one its Kind gets.
+func (r *RoutingReporter) SeverityOf(err error) Severity {
+	if severity, ok := ctxerrors.FieldAs[Severity](err, FieldSeverity); ok {
+		return severity
+	}
+
+	severity, ok := r.severities[ctxerrors.KindOf(err)]
+	if !ok {
+		return SeverityError
+	}
+
+	return severity
+}
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestRoutingReporter(t *testing.T) {
+	received := map[string][]error{}
+
+	backend := func(name string) Reporter {
+		return ReporterFunc(func(_ context.Context, err error) error {
+			received[name] = append(received[name], err)
+
+			return nil
+		})
+	}
+
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	critical := ctxerrors.WithField(ctxerrors.New("disk full"), FieldSeverity, SeverityCritical)
+	internal := ctxerrors.Wrap(baseErr, "load user")
+	unavailable := ctxerrors.WithKind(ctxerrors.Wrap(baseErr, "call billing"), ctxerrors.KindUnavailable)
+	notFound := ctxerrors.WithKind(ctxerrors.New("no such user"), ctxerrors.KindNotFound)
+
+	testCases := []struct {
+		name     string
+		routes   map[Severity]Reporter
+		expected map[string][]error
+	}{
+		{
+			name: "every severity routed",
+			routes: map[Severity]Reporter{
+				SeverityCritical: backend("pager"),
+				SeverityError:    backend("tracker"),
+				SeverityWarning:  backend("log"),
+			},
+			expected: map[string][]error{
+				"pager":   {critical},
+				"tracker": {internal},
+				"log":     {unavailable},
+			},
+		},
+		{
+			name: "falls back to lower severity",
+			routes: map[Severity]Reporter{
+				SeverityError: backend("tracker"),
+				SeverityInfo:  backend("log"),
+			},
+			expected: map[string][]error{
+				"tracker": {critical, internal},
+				"log":     {unavailable, notFound},
+			},
+		},
+		{
+			name:     "nothing routed",
+			routes:   nil,
+			expected: map[string][]error{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clear(received)
+
+			reporter := NewRoutingReporter(tc.routes, nil)
+
+			require.NoError(t, reporter.Report(context.Background(), nil))
+
+			for _, err := range []error{critical, internal, unavailable, notFound} {
+				require.NoError(t, reporter.Report(context.Background(), err))
+			}
+
+			require.Equal(t, tc.expected, received)
+		})
+	}
+}
+
+func TestRoutingReporterSeverityOf(t *testing.T) {
+	reporter := NewRoutingReporter(nil, map[ctxerrors.Kind]Severity{
+		ctxerrors.KindNotFound: SeverityWarning,
+	})
+
+	notFound := ctxerrors.WithKind(ctxerrors.New("no such user"), ctxerrors.KindNotFound)
+
+	require.Equal(t, SeverityWarning, reporter.SeverityOf(notFound))
+	require.Equal(t, SeverityError, reporter.SeverityOf(ctxerrors.New("boom")))
+	require.Equal(t, SeverityInfo, reporter.SeverityOf(ctxerrors.WithField(notFound, FieldSeverity, SeverityInfo)))
+	require.Equal(t, "critical", SeverityCritical.String())
+	require.Equal(t, "unknown", Severity(42).String())
+}
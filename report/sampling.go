@@ -0,0 +1,113 @@
+package report
+
+import (
+	"context"
+	"sync"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// FieldSuppressed is the field key SamplingReporter records how many errors
+// with the same fingerprint it suppressed since the last one it forwarded
+// under.
+const FieldSuppressed = "suppressed"
+
+// sample is what a SamplingReporter knows about one fingerprint.
+type sample struct {
+	seen       uint64 // Reports so far
+	suppressed uint64 // Reports suppressed since the last forwarded one
+}
+
+// SamplingReporter forwards the first few errors of every ctxerrors.Fingerprint
+// to its reporter and then only a fraction of them, so an incident storm
+// doesn't run up the reporting bill or hit the backend's rate limits. The next
+// error it forwards after suppressing some carries how many in its
+// FieldSuppressed field, so the backend still sees the real volume.
+type SamplingReporter struct {
+	reporter Reporter
+	first    uint64
+	rate     float64
+
+	mu      sync.Mutex // Guards samples
+	samples map[string]*sample
+}
+
+// NewSamplingReporter returns a SamplingReporter forwarding the first errors
+// of every fingerprint to reporter and then rate of them, between 0 and 1,
+// e.g. 5 and 0.01 for the first five and then one in a hundred. The fraction
+// is spread evenly over the reports rather than left to chance, so the
+// hundredth error after the first five is the one forwarded.
+func NewSamplingReporter(reporter Reporter, first int, rate float64) *SamplingReporter {
+	return &SamplingReporter{
+		reporter: reporter,
+		first:    uint64(max(first, 0)),
+		rate:     min(max(rate, 0), 1),
+		samples:  make(map[string]*sample),
+	}
+}
+
+// Report forwards err to the reporter if it's sampled and returns what that
+// returns, or nil right away if it isn't. A nil err isn't reported.
+func (r *SamplingReporter) Report(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	suppressed, forward := r.sample(ctxerrors.Fingerprint(err))
+	if !forward {
+		return nil
+	}
+
+	if suppressed > 0 {
+		err = ctxerrors.WithField(err, FieldSuppressed, suppressed)
+	}
+
+	return r.reporter.Report(ctx, err)
+}
+
+// Suppressed returns how many errors with fingerprint were suppressed since
+// the last one forwarded.
+func (r *SamplingReporter) Suppressed(fingerprint string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, ok := r.samples[fingerprint]; ok {
+		return current.suppressed
+	}
+
+	return 0
+}
+
+// sample counts a report of fingerprint and decides whether it's forwarded,
+// returning how many were suppressed before it if it is.
+func (r *SamplingReporter) sample(fingerprint string) (uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.samples[fingerprint]
+	if !ok {
+		current = &sample{}
+		r.samples[fingerprint] = current
+	}
+
+	current.seen++
+
+	forward := current.seen <= r.first
+	if !forward {
+		// Forward whenever the sampled share of the reports past the first
+		// ones reaches another whole report
+		past := float64(current.seen - r.first)
+		forward = uint64(past*r.rate) > uint64((past-1)*r.rate)
+	}
+
+	if !forward {
+		current.suppressed++
+
+		return 0, false
+	}
+
+	suppressed := current.suppressed
+	current.suppressed = 0
+
+	return suppressed, true
+}
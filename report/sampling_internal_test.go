@@ -0,0 +1,54 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+//go:noinline
+func samplingStorm() error {
+	return ctxerrors.Wrap(errors.New("connection refused"), "load user") //nolint:err113
+}
+
+//go:noinline
+func samplingOther() error {
+	return ctxerrors.New("something else")
+}
+
+func TestSamplingReporter(t *testing.T) {
+	var forwarded []error
+
+	backend := ReporterFunc(func(_ context.Context, err error) error {
+		forwarded = append(forwarded, err)
+
+		return nil
+	})
+
+	sampling := NewSamplingReporter(backend, 2, 0.25)
+
+	require.NoError(t, sampling.Report(context.Background(), nil))
+
+	for range 11 {
+		require.NoError(t, sampling.Report(context.Background(), samplingStorm()))
+	}
+
+	require.NoError(t, sampling.Report(context.Background(), samplingOther()))
+
+	// The first two, then every fourth past them, and the other fingerprint
+	require.Len(t, forwarded, 5)
+
+	suppressed := make([]any, 0, len(forwarded))
+	for _, err := range forwarded {
+		suppressed = append(suppressed, ctxerrors.Fields(err)[FieldSuppressed])
+	}
+
+	require.Equal(t, []any{nil, nil, uint64(3), uint64(3), nil}, suppressed)
+	require.Equal(t, "something else", ctxerrors.Messages(forwarded[4])[0])
+	require.Equal(t, uint64(1), sampling.Suppressed(ctxerrors.Fingerprint(samplingStorm())))
+	require.Zero(t, sampling.Suppressed("unknown"))
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// SeenStore remembers which fingerprints were reported recently, so
+// DedupReporters sharing it report each at most once per window, within a
+// process with MemorySeenStore or across a fleet with a shared store. A Redis
+// implementation is a single SET fingerprint 1 NX PX ttl, first being whether
+// it set the key.
+type SeenStore interface {
+	// MarkSeen records fingerprint as seen for ttl unless it already is,
+	// reporting whether it wasn't, atomically so that of any number of
+	// concurrent callers only one gets true.
+	MarkSeen(ctx context.Context, fingerprint string, ttl time.Duration) (first bool, err error)
+}
+
+// MemorySeenStore is an in-memory SeenStore for reporters in one process.
+// Time comes from ctxerrors.SetClock's clock.
+type MemorySeenStore struct {
+	mu        sync.Mutex // Guards the fields below
+	expiries  map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemorySeenStore returns an empty MemorySeenStore.
+func NewMemorySeenStore() *MemorySeenStore {
+	return &MemorySeenStore{expiries: make(map[string]time.Time)}
+}
+
+// MarkSeen records fingerprint as seen for ttl unless it already is,
+// reporting whether it wasn't. It never fails.
+func (s *MemorySeenStore) MarkSeen(_ context.Context, fingerprint string, ttl time.Duration) (bool, error) {
+	now := ctxerrors.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now, ttl)
+
+	if expiry, ok := s.expiries[fingerprint]; ok && now.Before(expiry) {
+		return false, nil
+	}
+
+	s.expiries[fingerprint] = now.Add(ttl)
+
+	return true, nil
+}
+
+// sweep forgets the expired fingerprints, at most once per ttl so it doesn't
+// cost every call a pass over all of them. s.mu must be held.
+func (s *MemorySeenStore) sweep(now time.Time, ttl time.Duration) {
+	if now.Sub(s.lastSweep) < ttl {
+		return
+	}
+
+	s.lastSweep = now
+
+	for fingerprint, expiry := range s.expiries {
+		if !now.Before(expiry) {
+			delete(s.expiries, fingerprint)
+		}
+	}
+}
+
+// DedupReporter forwards an error to its reporter only if no error with the
+// same ctxerrors.Fingerprint was forwarded within its window by any
+// DedupReporter sharing its SeenStore, so a failure repeating on every request
+// or on every instance of a service opens one issue instead of thousands.
+type DedupReporter struct {
+	reporter Reporter
+	store    SeenStore
+	window   time.Duration
+}
+
+// NewDedupReporter returns a DedupReporter forwarding to reporter the first
+// error of every fingerprint per window, as store tells.
+func NewDedupReporter(reporter Reporter, store SeenStore, window time.Duration) *DedupReporter {
+	return &DedupReporter{reporter: reporter, store: store, window: window}
+}
+
+// Report forwards err to the reporter if its fingerprint wasn't seen within
+// the window and returns what that returns, or nil right away if it was. If
+// the store fails err is forwarded anyway, a duplicate report beating a lost
+// one, and the store's error is returned along with the reporter's. A nil err
+// isn't reported.
+func (r *DedupReporter) Report(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	first, storeErr := r.store.MarkSeen(ctx, ctxerrors.Fingerprint(err), r.window)
+	if storeErr != nil {
+		storeErr = ctxerrors.Wrap(storeErr, "mark fingerprint seen")
+	} else if !first {
+		return nil
+	}
+
+	return errors.Join(storeErr, r.reporter.Report(ctx, err))
+}
@@ -0,0 +1,98 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+//go:noinline
+func seenStorm() error {
+	return ctxerrors.Wrap(errors.New("connection refused"), "load user") //nolint:err113
+}
+
+//go:noinline
+func seenOther() error {
+	return ctxerrors.New("something else")
+}
+
+type failingSeenStore struct{}
+
+func (failingSeenStore) MarkSeen(context.Context, string, time.Duration) (bool, error) {
+	return false, errors.New("redis down") //nolint:err113
+}
+
+func TestDedupReporter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	ctxerrors.SetClock(func() time.Time { return now })
+	t.Cleanup(func() { ctxerrors.SetClock(nil) })
+
+	var forwarded []string
+
+	backend := func(name string) Reporter {
+		return ReporterFunc(func(_ context.Context, err error) error {
+			forwarded = append(forwarded, name+": "+ctxerrors.Messages(err)[0])
+
+			return nil
+		})
+	}
+
+	// Two reporters sharing a store report a fingerprint once between them
+	store := NewMemorySeenStore()
+	first := NewDedupReporter(backend("first"), store, time.Minute)
+	second := NewDedupReporter(backend("second"), store, time.Minute)
+
+	require.NoError(t, first.Report(context.Background(), nil))
+	require.NoError(t, first.Report(context.Background(), seenStorm()))
+	require.NoError(t, second.Report(context.Background(), seenStorm()))
+	require.NoError(t, first.Report(context.Background(), seenStorm()))
+	require.NoError(t, second.Report(context.Background(), seenOther()))
+
+	require.Equal(t, []string{"first: load user", "second: something else"}, forwarded)
+
+	// Once the window is over it's reported again
+	now = now.Add(time.Minute)
+	require.NoError(t, second.Report(context.Background(), seenStorm()))
+	require.Equal(t, "second: load user", forwarded[2])
+
+	t.Run("store failure", func(t *testing.T) {
+		forwarded = nil
+
+		err := NewDedupReporter(backend("failing"), failingSeenStore{}, time.Minute).
+			Report(context.Background(), seenStorm())
+
+		require.ErrorContains(t, err, "redis down")
+		require.Equal(t, []string{"failing: load user"}, forwarded)
+	})
+}
+
+func TestMemorySeenStore(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	ctxerrors.SetClock(func() time.Time { return now })
+	t.Cleanup(func() { ctxerrors.SetClock(nil) })
+
+	store := NewMemorySeenStore()
+
+	first, err := store.MarkSeen(context.Background(), "abc", time.Minute)
+	require.NoError(t, err)
+	require.True(t, first)
+
+	first, _ = store.MarkSeen(context.Background(), "abc", time.Minute)
+	require.False(t, first)
+
+	now = now.Add(2 * time.Minute)
+
+	first, _ = store.MarkSeen(context.Background(), "def", time.Minute)
+	require.True(t, first)
+	require.NotContains(t, store.expiries, "abc")
+
+	first, _ = store.MarkSeen(context.Background(), "abc", time.Minute)
+	require.True(t, first)
+}
@@ -0,0 +1,66 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+	"github.com/psyb0t/ctxerrors/sentry"
+)
+
+// ErrInvalidDSN is returned when a Sentry DSN can't be parsed.
+var ErrInvalidDSN = errors.New("invalid sentry DSN")
+
+// SentryReporter reports errors to Sentry's store endpoint.
+type SentryReporter struct {
+	storeURL   string
+	authHeader string
+	client     *http.Client
+}
+
+// NewSentryReporter returns a Reporter for the project identified by dsn, e.g.
+// "https://public@o0.ingest.sentry.io/42". A nil client means http.DefaultClient.
+func NewSentryReporter(dsn string, client *http.Client) (*SentryReporter, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, ctxerrors.Wrap(errors.Join(ErrInvalidDSN, err), "failed to parse DSN")
+	}
+
+	projectDir, projectID := path.Split(strings.TrimSuffix(parsed.Path, "/"))
+	if parsed.Scheme == "" || parsed.Host == "" || parsed.User.Username() == "" || projectID == "" {
+		return nil, ctxerrors.Wrap(ErrInvalidDSN, "DSN needs a scheme, key, host and project ID")
+	}
+
+	storeURL := url.URL{
+		Scheme: parsed.Scheme,
+		Host:   parsed.Host,
+		Path:   path.Join(projectDir, "api", projectID, "store") + "/",
+	}
+
+	return &SentryReporter{
+		storeURL:   storeURL.String(),
+		authHeader: "Sentry sentry_version=7, sentry_client=ctxerrors/1, sentry_key=" + parsed.User.Username(),
+		client:     client,
+	}, nil
+}
+
+// Report sends err to Sentry as an event built by sentry.ToSentryEvent. A nil
+// err isn't reported.
+func (r *SentryReporter) Report(ctx context.Context, err error) error {
+	event := sentry.ToSentryEvent(err)
+	if event == nil {
+		return nil
+	}
+
+	headers := map[string]string{"X-Sentry-Auth": r.authHeader}
+
+	if err := postJSON(ctx, r.client, r.storeURL, headers, event); err != nil {
+		return ctxerrors.Wrap(err, "failed to report to sentry")
+	}
+
+	return nil
+}
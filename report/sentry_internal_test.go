@@ -0,0 +1,103 @@
+package report
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestNewSentryReporter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dsn      string
+		storeURL string
+		valid    bool
+	}{
+		{
+			name:     "sentry.io",
+			dsn:      "https://public@o0.ingest.sentry.io/42",
+			storeURL: "https://o0.ingest.sentry.io/api/42/store/",
+			valid:    true,
+		},
+		{
+			name:     "self-hosted with path prefix",
+			dsn:      "http://public@sentry.internal:9000/sentry/7/",
+			storeURL: "http://sentry.internal:9000/sentry/api/7/store/",
+			valid:    true,
+		},
+		{
+			name:  "missing key",
+			dsn:   "https://o0.ingest.sentry.io/42",
+			valid: false,
+		},
+		{
+			name:  "missing project",
+			dsn:   "https://public@o0.ingest.sentry.io/",
+			valid: false,
+		},
+		{
+			name:  "garbage",
+			dsn:   "://nope",
+			valid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter, err := NewSentryReporter(tc.dsn, nil)
+			if !tc.valid {
+				require.ErrorIs(t, err, ErrInvalidDSN)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.storeURL, reporter.storeURL)
+			require.Contains(t, reporter.authHeader, "sentry_key=public")
+		})
+	}
+}
+
+func TestSentryReporter(t *testing.T) {
+	t.Run("sends the event", func(t *testing.T) {
+		server, requests := newBackend(t, http.StatusOK)
+
+		reporter, err := NewSentryReporter("http://public@"+server.Listener.Addr().String()+"/42", server.Client())
+		require.NoError(t, err)
+
+		require.NoError(t, reporter.Report(context.Background(), ctxerrors.Wrap(ctxerrors.New("inner"), "outer")))
+		require.Len(t, requests(), 1)
+
+		req := requests()[0]
+		require.Equal(t, "/api/42/store/", req.path)
+		require.Contains(t, req.headers.Get("X-Sentry-Auth"), "sentry_key=public")
+		require.Equal(t, "application/json", req.headers.Get("Content-Type"))
+		require.Equal(t, "inner", jsonAt(t, req.body, "exception", "values", 0, "value"))
+		require.Equal(t, "outer", jsonAt(t, req.body, "exception", "values", 1, "value"))
+	})
+
+	t.Run("nil error is not sent", func(t *testing.T) {
+		server, requests := newBackend(t, http.StatusOK)
+
+		reporter, err := NewSentryReporter("http://public@"+server.Listener.Addr().String()+"/42", server.Client())
+		require.NoError(t, err)
+
+		require.NoError(t, reporter.Report(context.Background(), nil))
+		require.Empty(t, requests())
+	})
+
+	t.Run("bad status", func(t *testing.T) {
+		server, _ := newBackend(t, http.StatusTooManyRequests)
+
+		reporter, err := NewSentryReporter("http://public@"+server.Listener.Addr().String()+"/42", server.Client())
+		require.NoError(t, err)
+
+		err = reporter.Report(context.Background(), ctxerrors.New("boom"))
+		require.ErrorIs(t, err, ErrUnexpectedStatus)
+		require.Contains(t, err.Error(), "status 429")
+	})
+}
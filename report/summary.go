@@ -0,0 +1,230 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// summaryColumnSeparator goes between the columns of the summary table.
+const summaryColumnSeparator = " | "
+
+// SummaryRow is one location in a SummaryReporter summary.
+type SummaryRow struct {
+	FuncName string // Empty for errors without a *CTXError layer
+	File     string
+	Line     int
+	Count    uint64
+	Sample   string // As ctxerrors.Short renders the first error seen here
+}
+
+// summaryKey identifies a location errors are counted under.
+type summaryKey struct {
+	funcName string
+	file     string
+	line     int
+}
+
+// SummaryReporter counts reported errors by the location they started at, the
+// innermost *CTXError layer of their chain, and logs a table of the locations
+// that produced the most every interval, for services without an external
+// error tracker that still want to know what keeps failing. The table goes in
+// the record's table attribute, along with when counting started and how many
+// errors and locations there were in total:
+//
+//	count | function                         | location              | sample
+//	1200  | github.com/acme/app.(*Store).Get | /src/app/store.go:88  | get user: connection refused
+//	89    | github.com/acme/app.parseConfig  | /src/app/config.go:31 | invalid port
+//
+// Close it on shutdown to stop the background flushes and log what's left.
+type SummaryReporter struct {
+	logger *slog.Logger
+	top    int
+	stop   chan struct{}
+	done   chan struct{} // Closed when the flusher exits
+
+	mu        sync.Mutex // Guards the fields below
+	rows      map[summaryKey]*SummaryRow
+	since     time.Time
+	closeOnce sync.Once
+}
+
+// NewSummaryReporter returns a SummaryReporter logging the top locations,
+// all of them if top is zero or less, to logger, slog.Default() if nil, every
+// interval, at least a second.
+func NewSummaryReporter(logger *slog.Logger, interval time.Duration, top int) *SummaryReporter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	r := &SummaryReporter{
+		logger: logger,
+		top:    top,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		rows:   map[summaryKey]*SummaryRow{},
+		since:  ctxerrors.Now(),
+	}
+
+	go r.run(max(interval, time.Second))
+
+	return r
+}
+
+// Report counts err under the location it started at. A nil err isn't
+// counted.
+func (r *SummaryReporter) Report(_ context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var key summaryKey
+
+	for _, current := range elements(err) {
+		if current.layer != nil {
+			key = summaryKey{funcName: current.layer.FuncName(), file: current.layer.File(), line: current.layer.Line()}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.rows[key]
+	if !ok {
+		row = &SummaryRow{FuncName: key.funcName, File: key.file, Line: key.line, Count: 0, Sample: ctxerrors.Short(err)}
+		r.rows[key] = row
+	}
+
+	row.Count++
+
+	return nil
+}
+
+// Flush logs the summary of what was counted since the last flush, if
+// anything was, starts counting afresh and returns the rows it logged, most
+// errors first.
+func (r *SummaryReporter) Flush() []SummaryRow {
+	r.mu.Lock()
+	counted, since := r.rows, r.since
+	r.rows, r.since = map[summaryKey]*SummaryRow{}, ctxerrors.Now()
+	r.mu.Unlock()
+
+	if len(counted) == 0 {
+		return nil
+	}
+
+	rows := make([]SummaryRow, 0, len(counted))
+
+	var total uint64
+
+	for _, row := range counted {
+		rows = append(rows, *row)
+		total += row.Count
+	}
+
+	slices.SortFunc(rows, func(a, b SummaryRow) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.FuncName, b.FuncName),
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+		)
+	})
+
+	if r.top > 0 && len(rows) > r.top {
+		rows = rows[:r.top]
+	}
+
+	r.logger.Info("Error summary",
+		"since", since,
+		"errors", total,
+		"locations", len(counted),
+		"table", summaryTable(rows),
+	)
+
+	return rows
+}
+
+// Close stops the background flushes and flushes once more, waiting for a
+// flush in progress until ctx is done. Calling it more than once is fine.
+func (r *SummaryReporter) Close(ctx context.Context) error {
+	r.closeOnce.Do(func() {
+		close(r.stop)
+	})
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctxerrors.Wrap(ctx.Err(), "failed to close reporter")
+	}
+}
+
+// run flushes every interval until Close, then one last time.
+func (r *SummaryReporter) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush()
+		case <-r.stop:
+			r.Flush()
+
+			return
+		}
+	}
+}
+
+// summaryTable renders rows as lined-up columns under a header, like
+// ctxerrors.FormatTable does.
+func summaryTable(rows []SummaryRow) string {
+	cells := [][4]string{{"count", "function", "location", "sample"}}
+
+	for _, row := range rows {
+		location := ""
+		if row.File != "" {
+			location = row.File + ":" + strconv.Itoa(row.Line)
+		}
+
+		cells = append(cells, [4]string{strconv.FormatUint(row.Count, 10), row.FuncName, location, row.Sample})
+	}
+
+	var widths [4]int
+
+	for _, row := range cells {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var builder strings.Builder
+
+	for _, row := range cells {
+		var line strings.Builder
+
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString(summaryColumnSeparator)
+			}
+
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+		}
+
+		builder.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+
+	return builder.String()
+}
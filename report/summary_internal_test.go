@@ -0,0 +1,74 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+//go:noinline
+func summaryGet() error {
+	return ctxerrors.New("connection refused")
+}
+
+//go:noinline
+func summaryParse() error {
+	return ctxerrors.New("invalid port")
+}
+
+func TestSummaryReporter(t *testing.T) {
+	var buffer bytes.Buffer
+
+	reporter := NewSummaryReporter(slog.New(slog.NewJSONHandler(&buffer, nil)), time.Hour, 2)
+	ctx := context.Background()
+
+	for range 3 {
+		require.NoError(t, reporter.Report(ctx, ctxerrors.Wrap(summaryGet(), "get user")))
+	}
+
+	require.NoError(t, reporter.Report(ctx, summaryParse()))
+	require.NoError(t, reporter.Report(ctx, errors.New("plain"))) //nolint:err113
+	require.NoError(t, reporter.Report(ctx, errors.New("plain"))) //nolint:err113
+	require.NoError(t, reporter.Report(ctx, nil))
+
+	rows := reporter.Flush()
+
+	require.Len(t, rows, 2)
+	require.Equal(t, "github.com/psyb0t/ctxerrors/report.summaryGet", rows[0].FuncName)
+	require.Equal(t, uint64(3), rows[0].Count)
+	require.Equal(t, "get user: connection refused", rows[0].Sample)
+	require.Equal(t, "summary_internal_test.go", rows[0].File[strings.LastIndexByte(rows[0].File, '/')+1:])
+	require.Empty(t, rows[1].FuncName)
+	require.Equal(t, uint64(2), rows[1].Count)
+	require.Equal(t, "plain", rows[1].Sample)
+
+	var record map[string]any
+
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	require.Equal(t, "Error summary", record["msg"])
+	require.InDelta(t, 6, record["errors"], 0)
+	require.InDelta(t, 3, record["locations"], 0)
+
+	table := strings.Split(record["table"].(string), "\n") //nolint:forcetypeassert
+	require.Len(t, table, 4)
+	require.Equal(t, "count | function", table[0][:16])
+	require.True(t, strings.HasPrefix(table[1], "3     | github.com/psyb0t/ctxerrors/report.summaryGet | "))
+
+	buffer.Reset()
+	require.Nil(t, reporter.Flush())
+	require.Zero(t, buffer.Len())
+
+	require.NoError(t, reporter.Report(ctx, summaryParse()))
+	require.NoError(t, reporter.Close(ctx))
+	require.NoError(t, reporter.Close(ctx))
+	require.Contains(t, buffer.String(), "invalid port")
+}
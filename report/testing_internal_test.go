@@ -0,0 +1,82 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// capturedRequest is what a test backend received.
+type capturedRequest struct {
+	path    string
+	headers http.Header
+	body    map[string]any
+}
+
+// newBackend starts a test server answering with status and recording every
+// request it gets.
+func newBackend(t *testing.T, status int) (*httptest.Server, func() []capturedRequest) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []capturedRequest
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var body map[string]any
+
+		require.NoError(t, json.Unmarshal(data, &body))
+
+		mu.Lock()
+		requests = append(requests, capturedRequest{path: r.URL.Path, headers: r.Header, body: body})
+		mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []capturedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return requests
+	}
+}
+
+// wrapForeign wraps err the way code outside ctxerrors does.
+func wrapForeign(err error) error {
+	return fmt.Errorf("foreign: %w", err)
+}
+
+// jsonAt walks nested JSON objects and arrays by key or index.
+func jsonAt(t *testing.T, value any, keys ...any) any {
+	t.Helper()
+
+	for _, key := range keys {
+		switch k := key.(type) {
+		case string:
+			object, ok := value.(map[string]any)
+			require.True(t, ok, "not an object at %v", k)
+
+			value = object[k]
+		case int:
+			array, ok := value.([]any)
+			require.True(t, ok, "not an array at %v", k)
+			require.Greater(t, len(array), k)
+
+			value = array[k]
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,109 @@
+package ctxerrors
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Field keys used by WrapRequest.
+const (
+	FieldHTTPMethod  = "http_method"
+	FieldHTTPURL     = "http_url"
+	FieldHTTPHeaders = "http_headers"
+	FieldRemoteAddr  = "remote_addr"
+)
+
+// RequestOptions controls what WrapRequest records of a request.
+type RequestOptions struct {
+	// Headers lists the headers to record. None are recorded if it's empty.
+	Headers []string
+	// Redact lists the headers whose values are replaced with RedactedValue.
+	// Nil means Authorization, Proxy-Authorization, Cookie, Set-Cookie and
+	// X-Api-Key.
+	Redact []string
+	// IncludeQuery keeps the query string in the recorded URL. It's dropped by
+	// default since that's where tokens like to hide.
+	IncludeQuery bool
+}
+
+// defaultRedactedHeaders are the headers redacted when RequestOptions.Redact
+// is nil.
+var defaultRedactedHeaders = []string{ //nolint:gochecknoglobals
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// WrapRequest wraps the error of an HTTP handler with the request it was
+// handling, e.g. "GET /users/42", recording the method, URL, selected headers
+// and remote address as the FieldHTTPMethod, FieldHTTPURL, FieldHTTPHeaders
+// and FieldRemoteAddr fields. Passwords in the URL are always masked.
+func WrapRequest(err error, r *http.Request, opts RequestOptions) error {
+	// Skip WrapRequest() and wrap() to get user's caller
+	framesToSkip := 2
+
+	if r == nil {
+		return wrap(err, "", framesToSkip, nil)
+	}
+
+	path := "/"
+	fields := map[string]any{
+		FieldHTTPMethod: r.Method,
+		FieldRemoteAddr: r.RemoteAddr,
+	}
+
+	if r.URL != nil {
+		url := *r.URL
+		if !opts.IncludeQuery {
+			url.RawQuery = ""
+			url.ForceQuery = false
+		}
+
+		fields[FieldHTTPURL] = url.Redacted()
+
+		if url.Path != "" {
+			path = url.Path
+		}
+	}
+
+	if headers := requestHeaders(r.Header, opts); len(headers) > 0 {
+		fields[FieldHTTPHeaders] = headers
+	}
+
+	return wrap(err, r.Method+" "+path, framesToSkip, fields)
+}
+
+// requestHeaders picks the headers opts asks for out of header, redacting
+// the ones it says to.
+func requestHeaders(header http.Header, opts RequestOptions) map[string]string {
+	redact := opts.Redact
+	if redact == nil {
+		redact = defaultRedactedHeaders
+	}
+
+	headers := make(map[string]string, len(opts.Headers))
+
+	for _, name := range opts.Headers {
+		name = http.CanonicalHeaderKey(name)
+
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		if slices.ContainsFunc(redact, func(redacted string) bool {
+			return strings.EqualFold(redacted, name)
+		}) {
+			headers[name] = RedactedValue
+
+			continue
+		}
+
+		headers[name] = strings.Join(values, ", ")
+	}
+
+	return headers
+}
@@ -0,0 +1,48 @@
+package ctxerrors
+
+import "runtime"
+
+// Resolver turns a program counter into the Frame it belongs to. pc is the
+// address of a call instruction, one less than the return address
+// runtime.Callers reports. A zero Frame means it couldn't be resolved.
+type Resolver interface {
+	Resolve(pc uintptr) Frame
+}
+
+// ResolverFunc adapts an ordinary function to the Resolver interface.
+type ResolverFunc func(pc uintptr) Frame
+
+// Resolve calls f(pc).
+func (f ResolverFunc) Resolve(pc uintptr) Frame {
+	return f(pc)
+}
+
+// RuntimeResolver resolves program counters with the runtime's own symbol
+// table. It's what's used unless SetResolver says otherwise, and what custom
+// resolvers can fall back to.
+type RuntimeResolver struct{}
+
+// Resolve returns the frame of the function containing pc.
+func (RuntimeResolver) Resolve(pc uintptr) Frame {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return Frame{}
+	}
+
+	file, line := fn.FileLine(pc)
+
+	return Frame{File: file, Line: line, FuncName: fn.Name()}
+}
+
+// SetResolver sets how the locations of errors created from now on and the
+// frames Callers returns are resolved, for environments where the runtime's
+// symbol table is too slow or stripped, such as a precomputed PC table or
+// remote symbolization. Source mapping, path normalization and the like still
+// apply to what it returns. Custom resolvers see one PC per frame, so inlined
+// calls show up as the function they were inlined into. Pass nil to go back to
+// RuntimeResolver, which is the default.
+func SetResolver(resolver Resolver) {
+	updateConfig(func(c *config) {
+		c.resolver = resolver
+	})
+}
@@ -0,0 +1,130 @@
+package ctxerrors
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// tableResolver resolves PCs from a precomputed table, falling back to the
+// runtime and recording what it had to look up there.
+type tableResolver struct {
+	table  map[uintptr]Frame
+	misses []uintptr
+}
+
+func (r *tableResolver) Resolve(pc uintptr) Frame {
+	if frame, ok := r.table[pc]; ok {
+		return frame
+	}
+
+	r.misses = append(r.misses, pc)
+	frame := RuntimeResolver{}.Resolve(pc)
+	frame.File = "symbolized/" + path.Base(frame.File)
+
+	return frame
+}
+
+//go:noinline
+func resolverOrigin() error {
+	return New("resolved")
+}
+
+func TestSetResolver(t *testing.T) {
+	t.Cleanup(func() {
+		SetResolver(nil)
+		SetCaptureMode(CaptureFull)
+		SetCallerDepth(0)
+		SetSourceMapper(nil)
+	})
+
+	var ctxErr *CTXError
+
+	t.Run("resolves locations", func(t *testing.T) {
+		resolver := &tableResolver{}
+		SetResolver(resolver)
+
+		require.True(t, errors.As(resolverOrigin(), &ctxErr))
+		require.Equal(t, "symbolized/resolver_internal_test.go", ctxErr.file)
+		require.NotZero(t, ctxErr.line)
+		require.Equal(t, "github.com/psyb0t/ctxerrors.resolverOrigin", ctxErr.funcName)
+		require.Len(t, resolver.misses, 1)
+
+		// The table answers without the runtime now
+		resolver.table = map[uintptr]Frame{
+			resolver.misses[0]: {File: "table.go", Line: 7, FuncName: "table.Func"},
+		}
+
+		for range 2 {
+			require.True(t, errors.As(resolverOrigin(), &ctxErr))
+			require.Equal(t, "table.go", ctxErr.file)
+			require.Equal(t, 7, ctxErr.line)
+			require.Equal(t, "table.Func", ctxErr.funcName)
+		}
+	})
+
+	t.Run("source mapping still applies", func(t *testing.T) {
+		SetResolver(ResolverFunc(func(uintptr) Frame {
+			return Frame{File: "generated.go", Line: 3, FuncName: "gen.Func"}
+		}))
+		SetSourceMapper(func(file string, line int) (string, int) {
+			return "template.tmpl", line * 10
+		})
+
+		require.True(t, errors.As(New("mapped"), &ctxErr))
+		require.Equal(t, "template.tmpl", ctxErr.file)
+		require.Equal(t, 30, ctxErr.line)
+
+		SetSourceMapper(nil)
+	})
+
+	t.Run("function only", func(t *testing.T) {
+		SetResolver(ResolverFunc(func(uintptr) Frame {
+			return Frame{File: "generated.go", Line: 3, FuncName: "gen.Func"}
+		}))
+		SetCaptureMode(CaptureFuncOnly)
+
+		require.True(t, errors.As(New("func only"), &ctxErr))
+		require.Empty(t, ctxErr.file)
+		require.Zero(t, ctxErr.line)
+		require.Equal(t, "gen.Func", ctxErr.funcName)
+
+		SetCaptureMode(CaptureFull)
+	})
+
+	t.Run("unresolved", func(t *testing.T) {
+		SetResolver(ResolverFunc(func(uintptr) Frame { return Frame{} }))
+
+		require.True(t, errors.As(New("unresolved"), &ctxErr))
+		require.Empty(t, ctxErr.file)
+		require.Zero(t, ctxErr.line)
+		require.Empty(t, ctxErr.funcName)
+	})
+
+	t.Run("callers", func(t *testing.T) {
+		SetResolver(nil)
+		SetCallerDepth(2)
+
+		require.True(t, errors.As(New("with callers"), &ctxErr))
+
+		runtimeFrames := ctxErr.Callers()
+		require.Len(t, runtimeFrames, 2)
+
+		SetResolver(&tableResolver{})
+
+		frames := ctxErr.Callers()
+		require.Len(t, frames, 2)
+
+		for i, frame := range frames {
+			require.Equal(t, "symbolized/"+path.Base(runtimeFrames[i].File), frame.File)
+			require.Equal(t, runtimeFrames[i].Line, frame.Line)
+			require.Equal(t, runtimeFrames[i].FuncName, frame.FuncName)
+		}
+	})
+}
+
+func TestRuntimeResolver(t *testing.T) {
+	require.Equal(t, Frame{}, RuntimeResolver{}.Resolve(0))
+}
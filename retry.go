@@ -0,0 +1,124 @@
+package ctxerrors
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Field keys used by WrapAttempt and WithRetryAfter.
+const (
+	FieldAttempt     = "attempt"
+	FieldMaxAttempts = "max_attempts"
+	FieldRetryAfter  = "retry_after"
+)
+
+// WrapAttempt wraps the error of a retried operation with the attempt that
+// produced it, e.g. "attempt 2/5", also recorded as the FieldAttempt and
+// FieldMaxAttempts fields. A maxAttempts of zero or less means unbounded.
+func WrapAttempt(err error, attempt, maxAttempts int) error {
+	// Skip WrapAttempt() and wrap() to get user's caller
+	framesToSkip := 2
+
+	message := fmt.Sprintf("attempt %d", attempt)
+	fields := map[string]any{FieldAttempt: attempt}
+
+	if maxAttempts > 0 {
+		message = fmt.Sprintf("attempt %d/%d", attempt, maxAttempts)
+		fields[FieldMaxAttempts] = maxAttempts
+	}
+
+	return wrap(err, message, framesToSkip, fields)
+}
+
+// JoinAttempts summarizes the failures of a retried operation as a single
+// "failed after N attempts" error joining every non-nil error in errs, which
+// are usually WrapAttempt results. It returns nil if every error is nil.
+func JoinAttempts(errs []error) error {
+	// Skip JoinAttempts() and wrap() to get user's caller
+	framesToSkip := 2
+
+	joined := errors.Join(errs...)
+	if joined == nil {
+		return nil
+	}
+
+	failed := 0
+
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+
+	return wrap(joined, fmt.Sprintf("failed after %d attempts", failed), framesToSkip, nil)
+}
+
+// Attempts returns every layer created by WrapAttempt in err's chain,
+// including those inside joined errors, ordered by attempt.
+func Attempts(err error) []*CTXError {
+	var attempts []*CTXError
+
+	walk(err, func(current error) {
+		if layer, ok := asCTXError(current); ok {
+			if _, ok := layer.fields[FieldAttempt].(int); ok {
+				attempts = append(attempts, layer)
+			}
+		}
+	})
+
+	slices.SortStableFunc(attempts, func(a, b *CTXError) int {
+		return cmp.Compare(a.fields[FieldAttempt].(int), b.fields[FieldAttempt].(int)) //nolint:forcetypeassert
+	})
+
+	return attempts
+}
+
+// WithRetryAfter wraps err with how long callers should back off before trying
+// again, e.g. "retry after 30s", also recorded as the FieldRetryAfter field.
+// It returns nil if err is nil.
+func WithRetryAfter(err error, d time.Duration) error {
+	// Skip WithRetryAfter() and wrap() to get user's caller
+	framesToSkip := 2
+
+	if isNilError(err) {
+		return nil
+	}
+
+	message := "retry after " + d.String()
+
+	return wrap(err, message, framesToSkip, map[string]any{FieldRetryAfter: d})
+}
+
+// RetryAfter returns the backoff set by the outermost WithRetryAfter layer in
+// err's chain, including those inside joined errors, unless SetFieldPrecedence
+// says otherwise.
+func RetryAfter(err error) (time.Duration, bool) {
+	return lookupField[time.Duration](err, FieldRetryAfter)
+}
+
+// Retryable reports whether trying the operation that failed with err again
+// might help, for retry loops that would otherwise sniff error strings: not
+// for a nil err, nor for one Classify puts in a kind retrying can't fix, the
+// caller's own mistakes, cancellation and the end of data, unless a
+// WithRetryAfter layer says when to try again, which is taken at its word.
+// Everything else, unavailable and unclassified errors included, is.
+func Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if _, ok := RetryAfter(err); ok {
+		return true
+	}
+
+	switch Classify(err) {
+	case KindNotFound, KindInvalidArgument, KindAlreadyExists, KindPermissionDenied,
+		KindUnauthenticated, KindCanceled, KindEndOfData:
+		return false
+	default:
+		return true
+	}
+}
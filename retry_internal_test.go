@@ -0,0 +1,257 @@
+package ctxerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapAttempt(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	testCases := []struct {
+		name            string
+		err             error
+		attempt         int
+		maxAttempts     int
+		expectedMessage string
+		expectedFields  map[string]any
+	}{
+		{
+			name:            "bounded",
+			err:             baseErr,
+			attempt:         2,
+			maxAttempts:     5,
+			expectedMessage: "attempt 2/5",
+			expectedFields:  map[string]any{FieldAttempt: 2, FieldMaxAttempts: 5},
+		},
+		{
+			name:            "unbounded",
+			err:             baseErr,
+			attempt:         7,
+			maxAttempts:     0,
+			expectedMessage: "attempt 7",
+			expectedFields:  map[string]any{FieldAttempt: 7},
+		},
+		{
+			name:    "nil error",
+			err:     nil,
+			attempt: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := WrapAttempt(tc.err, tc.attempt, tc.maxAttempts)
+
+			if tc.err == nil {
+				require.NoError(t, actual)
+
+				return
+			}
+
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(actual, &ctxErr))
+			require.Equal(t, tc.expectedMessage, ctxErr.Message())
+			require.Equal(t, tc.expectedFields, ctxErr.Fields())
+			require.Contains(t, ctxErr.FuncName(), "TestWrapAttempt")
+			require.ErrorIs(t, actual, tc.err)
+		})
+	}
+}
+
+func TestJoinAttempts(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	t.Run("no failures", func(t *testing.T) {
+		require.NoError(t, JoinAttempts(nil))
+		require.NoError(t, JoinAttempts([]error{nil, nil}))
+	})
+
+	t.Run("summarizes attempts", func(t *testing.T) {
+		errs := make([]error, 0, 3)
+		for attempt := 1; attempt <= 3; attempt++ {
+			errs = append(errs, WrapAttempt(baseErr, attempt, 3))
+		}
+
+		actual := JoinAttempts(errs)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(actual, &ctxErr))
+		require.Equal(t, "failed after 3 attempts", ctxErr.Message())
+		require.Contains(t, ctxErr.FuncName(), "TestJoinAttempts")
+		require.ErrorIs(t, actual, baseErr)
+		require.Contains(t, actual.Error(), "attempt 1/3")
+		require.Contains(t, actual.Error(), "attempt 3/3")
+	})
+}
+
+func TestAttempts(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected []int
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "no attempts",
+			err:      Wrap(baseErr, "plain"),
+			expected: nil,
+		},
+		{
+			name:     "single attempt",
+			err:      Wrap(WrapAttempt(baseErr, 4, 5), "outer"),
+			expected: []int{4},
+		},
+		{
+			name: "joined attempts are sorted",
+			err: JoinAttempts([]error{
+				WrapAttempt(baseErr, 3, 3),
+				WrapAttempt(baseErr, 1, 3),
+				nil,
+				WrapAttempt(baseErr, 2, 3),
+			}),
+			expected: []int{1, 2, 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []int
+			for _, layer := range Attempts(tc.err) {
+				actual = append(actual, layer.Fields()[FieldAttempt].(int)) //nolint:forcetypeassert
+			}
+
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	baseErr := errors.New("rate limited") //nolint:err113
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, WithRetryAfter(nil, time.Second))
+	})
+
+	t.Run("annotates error", func(t *testing.T) {
+		actual := WithRetryAfter(baseErr, 30*time.Second)
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(actual, &ctxErr))
+		require.Equal(t, "retry after 30s", ctxErr.Message())
+		require.Equal(t, map[string]any{FieldRetryAfter: 30 * time.Second}, ctxErr.Fields())
+		require.Contains(t, ctxErr.FuncName(), "TestWithRetryAfter")
+		require.ErrorIs(t, actual, baseErr)
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	baseErr := errors.New("rate limited") //nolint:err113
+
+	testCases := []struct {
+		name          string
+		err           error
+		expected      time.Duration
+		expectedFound bool
+	}{
+		{
+			name:          "nil error",
+			err:           nil,
+			expected:      0,
+			expectedFound: false,
+		},
+		{
+			name:          "no retry after",
+			err:           Wrap(baseErr, "plain"),
+			expected:      0,
+			expectedFound: false,
+		},
+		{
+			name:          "deep in chain",
+			err:           Wrap(WithRetryAfter(baseErr, time.Minute), "outer"),
+			expected:      time.Minute,
+			expectedFound: true,
+		},
+		{
+			name:          "outermost wins",
+			err:           WithRetryAfter(WithRetryAfter(baseErr, time.Minute), time.Second),
+			expected:      time.Second,
+			expectedFound: true,
+		},
+		{
+			name:          "inside joined errors",
+			err:           errors.Join(baseErr, WithRetryAfter(baseErr, 5*time.Second)),
+			expected:      5 * time.Second,
+			expectedFound: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, found := RetryAfter(tc.err)
+
+			require.Equal(t, tc.expectedFound, found)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	baseErr := errors.New("connection reset") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "unclassified",
+			err:      Wrap(baseErr, "call upstream"),
+			expected: true,
+		},
+		{
+			name:     "unavailable",
+			err:      WithKind(baseErr, KindUnavailable),
+			expected: true,
+		},
+		{
+			name:     "caller's mistake",
+			err:      WithKind(Wrap(baseErr, "call upstream"), KindInvalidArgument),
+			expected: false,
+		},
+		{
+			name:     "sentinel",
+			err:      Wrap(context.Canceled, "call upstream"),
+			expected: false,
+		},
+		{
+			name:     "retry after wins",
+			err:      WithRetryAfter(WithKind(baseErr, KindPermissionDenied), time.Second),
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Retryable(tc.err))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Package retryhooks lets retry libraries act on what ctxerrors knows about
+// an error, whether retrying can help and how long to back off, so the
+// classification made where the error originated drives the retries of the
+// client. CheckRetry and Backoff plug straight into hashicorp/go-retryablehttp:
+//
+//	client := retryablehttp.NewClient()
+//	client.CheckRetry = retryhooks.CheckRetry
+//	client.Backoff = retryhooks.Backoff
+//
+// and Delay fits any other retry loop, e.g. a cenkalti/backoff operation:
+//
+//	if !ctxerrors.Retryable(err) {
+//		return backoff.Permanent(err)
+//	}
+//	if d, ok := ctxerrors.RetryAfter(err); ok {
+//		return backoff.RetryAfter(int(d.Seconds()))
+//	}
+//
+// Servers can hand their classification to HTTP clients by setting the
+// msgheaders headers on their error responses, which both hooks read.
+package retryhooks
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/psyb0t/ctxerrors"
+	"github.com/psyb0t/ctxerrors/msgheaders"
+)
+
+// CheckRetry is a go-retryablehttp CheckRetry. It stops when ctx is done and
+// otherwise retries when ctxerrors.Retryable says so of the request's error or
+// of the chain the response carries in msgheaders headers if it has a kind or
+// a RetryAfter, and else on 429s and 5xx responses other than 501s.
+func CheckRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return false, ctxerrors.Wrap(ctxErr, "request context done")
+	}
+
+	if err != nil {
+		return ctxerrors.Retryable(err), nil
+	}
+
+	if resp == nil {
+		return false, nil
+	}
+
+	if remote := responseError(resp); remote != nil && classified(remote) {
+		return ctxerrors.Retryable(remote), nil
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented, nil
+}
+
+// Backoff is a go-retryablehttp Backoff. It waits as long as the chain the
+// response carries in msgheaders headers says with ctxerrors.RetryAfter, or
+// its Retry-After header on 429s and 503s, and else backs off exponentially
+// from minDelay to maxDelay, like Delay.
+func Backoff(minDelay, maxDelay time.Duration, attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := ctxerrors.RetryAfter(responseError(resp)); ok {
+			return d
+		}
+
+		if d, ok := retryAfterHeader(resp); ok {
+			return d
+		}
+	}
+
+	return Delay(nil, attempt, minDelay, maxDelay)
+}
+
+// Delay returns how long to wait before attempt, counted from zero, after
+// err: what ctxerrors.RetryAfter says if anything, or else minDelay doubled
+// for every attempt, at most maxDelay.
+func Delay(err error, attempt int, minDelay, maxDelay time.Duration) time.Duration {
+	if d, ok := ctxerrors.RetryAfter(err); ok {
+		return d
+	}
+
+	delay := minDelay
+	for range max(attempt, 0) {
+		if delay >= maxDelay/2 {
+			return maxDelay
+		}
+
+		delay *= 2
+	}
+
+	return min(delay, maxDelay)
+}
+
+// responseError returns the chain resp carries in msgheaders headers, or nil.
+func responseError(resp *http.Response) error {
+	remote, err := msgheaders.Extract(resp.Header.Get)
+	if err != nil {
+		return nil
+	}
+
+	return remote
+}
+
+// classified reports whether err says anything ctxerrors.Retryable goes by.
+func classified(err error) bool {
+	_, ok := ctxerrors.RetryAfter(err)
+
+	return ok || ctxerrors.Classify(err) != ctxerrors.KindUnknown
+}
+
+// retryAfterHeader returns the wait the Retry-After header of a 429 or 503
+// response asks for, in seconds or as an HTTP date.
+func retryAfterHeader(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(ctxerrors.Now()), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,173 @@
+package retryhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+	"github.com/psyb0t/ctxerrors/msgheaders"
+)
+
+// response returns a response with status and the msgheaders headers of err,
+// if it isn't nil.
+func response(t *testing.T, status int, err error) *http.Response {
+	t.Helper()
+
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	require.NoError(t, msgheaders.Inject(err, resp.Header.Set))
+
+	return resp
+}
+
+func TestCheckRetry(t *testing.T) {
+	baseErr := errors.New("connection reset") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		resp     *http.Response
+		err      error
+		expected bool
+	}{
+		{
+			name:     "retryable error",
+			err:      ctxerrors.Wrap(baseErr, "call upstream"),
+			expected: true,
+		},
+		{
+			name:     "caller's mistake",
+			err:      ctxerrors.WithKind(baseErr, ctxerrors.KindPermissionDenied),
+			expected: false,
+		},
+		{
+			name:     "no response",
+			expected: false,
+		},
+		{
+			name:     "server error",
+			resp:     response(t, http.StatusBadGateway, nil),
+			expected: true,
+		},
+		{
+			name:     "not implemented",
+			resp:     response(t, http.StatusNotImplemented, nil),
+			expected: false,
+		},
+		{
+			name:     "too many requests",
+			resp:     response(t, http.StatusTooManyRequests, nil),
+			expected: true,
+		},
+		{
+			name:     "client error",
+			resp:     response(t, http.StatusBadRequest, nil),
+			expected: false,
+		},
+		{
+			name:     "classified by the server",
+			resp:     response(t, http.StatusInternalServerError, ctxerrors.WithKind(baseErr, ctxerrors.KindInvalidArgument)),
+			expected: false,
+		},
+		{
+			name:     "unclassified chain falls back to status",
+			resp:     response(t, http.StatusConflict, ctxerrors.New("stale version")),
+			expected: false,
+		},
+		{
+			name:     "retry after from the server",
+			resp:     response(t, http.StatusConflict, ctxerrors.WithRetryAfter(ctxerrors.New("locked"), time.Second)),
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := CheckRetry(context.Background(), tc.resp, tc.err)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		actual, err := CheckRetry(ctx, nil, baseErr)
+		require.ErrorIs(t, err, context.Canceled)
+		require.False(t, actual)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	ctxerrors.SetClock(func() time.Time { return now })
+	t.Cleanup(func() { ctxerrors.SetClock(nil) })
+
+	header := func(status int, retryAfter string) *http.Response {
+		resp := response(t, status, nil)
+		resp.Header.Set("Retry-After", retryAfter)
+
+		return resp
+	}
+
+	testCases := []struct {
+		name     string
+		resp     *http.Response
+		attempt  int
+		expected time.Duration
+	}{
+		{
+			name:     "no response",
+			attempt:  2,
+			expected: 4 * time.Second,
+		},
+		{
+			name:     "capped",
+			attempt:  10,
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "retry after from the server",
+			resp:     response(t, http.StatusServiceUnavailable, ctxerrors.WithRetryAfter(ctxerrors.New("draining"), 90*time.Second)),
+			attempt:  0,
+			expected: 90 * time.Second,
+		},
+		{
+			name:     "retry after seconds",
+			resp:     header(http.StatusTooManyRequests, "7"),
+			attempt:  0,
+			expected: 7 * time.Second,
+		},
+		{
+			name:     "retry after date",
+			resp:     header(http.StatusServiceUnavailable, now.Add(time.Minute).Format(http.TimeFormat)),
+			attempt:  0,
+			expected: time.Minute,
+		},
+		{
+			name:     "retry after ignored on other statuses",
+			resp:     header(http.StatusInternalServerError, "7"),
+			attempt:  1,
+			expected: 2 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Backoff(time.Second, 30*time.Second, tc.attempt, tc.resp))
+		})
+	}
+}
+
+func TestDelay(t *testing.T) {
+	require.Equal(t, time.Second, Delay(nil, 0, time.Second, time.Minute))
+	require.Equal(t, 8*time.Second, Delay(errors.New("boom"), 3, time.Second, time.Minute)) //nolint:err113
+	require.Equal(t, time.Minute, Delay(nil, 100, time.Second, time.Minute))
+	require.Equal(t, time.Second, Delay(nil, -1, time.Second, time.Minute))
+	require.Equal(t, 5*time.Minute, Delay(ctxerrors.WithRetryAfter(ctxerrors.New("quota"), 5*time.Minute), 0, time.Second, time.Minute))
+}
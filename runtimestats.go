@@ -0,0 +1,78 @@
+package ctxerrors
+
+import (
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+// Field keys used by RuntimeStatsHook.
+const (
+	FieldHeapBytes  = "heap_bytes"
+	FieldGoroutines = "goroutines"
+	FieldGCCycles   = "gc_cycles"
+	FieldGCPauseMax = "gc_pause_max"
+)
+
+// runtimeStats maps the runtime metrics RuntimeStatsHook reads to the field
+// keys they're recorded under.
+var runtimeStats = []struct { //nolint:gochecknoglobals
+	metric string
+	field  string
+}{
+	{metric: "/memory/classes/heap/objects:bytes", field: FieldHeapBytes},
+	{metric: "/sched/goroutines:goroutines", field: FieldGoroutines},
+	{metric: "/gc/cycles/total:gc-cycles", field: FieldGCCycles},
+	{metric: "/sched/pauses/total/gc:seconds", field: FieldGCPauseMax},
+}
+
+// RuntimeStatsHook returns an EnrichHook that records the state of the runtime
+// at the moment every error is created: heap bytes in use by objects as
+// FieldHeapBytes, the goroutine count as FieldGoroutines, completed GC cycles
+// as FieldGCCycles and the longest GC pause so far as FieldGCPauseMax, for
+// errors that only show up under resource pressure. It reads runtime/metrics,
+// which doesn't stop the world the way runtime.ReadMemStats does.
+func RuntimeStatsHook() EnrichHook {
+	return EnrichHookFunc(func(*CTXError) map[string]any {
+		samples := make([]metrics.Sample, len(runtimeStats))
+		for i, stat := range runtimeStats {
+			samples[i].Name = stat.metric
+		}
+
+		metrics.Read(samples)
+
+		fields := make(map[string]any, len(samples))
+
+		for i, sample := range samples {
+			switch sample.Value.Kind() {
+			case metrics.KindUint64:
+				fields[runtimeStats[i].field] = sample.Value.Uint64()
+			case metrics.KindFloat64Histogram:
+				fields[runtimeStats[i].field] = histogramMax(sample.Value.Float64Histogram())
+			case metrics.KindFloat64, metrics.KindBad:
+				// Not among the metrics read, or unsupported by this Go version
+			}
+		}
+
+		return fields
+	})
+}
+
+// histogramMax returns the upper bound of the highest non-empty bucket of a
+// histogram of seconds.
+func histogramMax(histogram *metrics.Float64Histogram) time.Duration {
+	for i := len(histogram.Counts) - 1; i >= 0; i-- {
+		if histogram.Counts[i] == 0 {
+			continue
+		}
+
+		bound := histogram.Buckets[i+1]
+		if math.IsInf(bound, 1) {
+			bound = histogram.Buckets[i]
+		}
+
+		return time.Duration(bound * float64(time.Second))
+	}
+
+	return 0
+}
@@ -0,0 +1,67 @@
+package ctxerrors
+
+import (
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeStatsHook(t *testing.T) {
+	runtime.GC()
+
+	unregister := RegisterEnrichHook(RuntimeStatsHook())
+	t.Cleanup(unregister)
+
+	var ctxErr *CTXError
+
+	require.ErrorAs(t, New("under pressure"), &ctxErr)
+
+	fields := ctxErr.Fields()
+	require.NotZero(t, fields[FieldHeapBytes])
+	require.NotZero(t, fields[FieldGoroutines])
+	require.NotZero(t, fields[FieldGCCycles])
+	require.IsType(t, time.Duration(0), fields[FieldGCPauseMax])
+}
+
+func TestHistogramMax(t *testing.T) {
+	testCases := []struct {
+		name      string
+		histogram *metrics.Float64Histogram
+		expected  time.Duration
+	}{
+		{
+			name: "empty",
+			histogram: &metrics.Float64Histogram{
+				Counts:  []uint64{0, 0},
+				Buckets: []float64{0, 0.001, 0.002},
+			},
+			expected: 0,
+		},
+		{
+			name: "highest non-empty bucket",
+			histogram: &metrics.Float64Histogram{
+				Counts:  []uint64{3, 1, 0},
+				Buckets: []float64{0, 0.001, 0.002, 0.004},
+			},
+			expected: 2 * time.Millisecond,
+		},
+		{
+			name: "unbounded last bucket",
+			histogram: &metrics.Float64Histogram{
+				Counts:  []uint64{0, 1},
+				Buckets: []float64{0, 0.5, math.Inf(1)},
+			},
+			expected: 500 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, histogramMax(tc.histogram))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package ctxerrors
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// escapeMessage returns message with everything that could corrupt a terminal
+// or a log line escaped Go-style: control characters other than tab (e.g.
+// "\n", "\x1b"), bidirectional text controls that can make text display
+// differently from what it is (e.g. "\u202e") and bytes that aren't valid
+// UTF-8 (e.g. "\xff"). It returns message itself if there's nothing to escape.
+func escapeMessage(message string) string {
+	if !needsEscaping(message) {
+		return message
+	}
+
+	var builder strings.Builder
+
+	builder.Grow(len(message) + len(message)/4) //nolint:mnd
+
+	for i := 0; i < len(message); {
+		escaped, size := escapeRune(message[i:])
+		builder.WriteString(escaped)
+
+		i += size
+	}
+
+	return builder.String()
+}
+
+// escapeRune returns the first rune of message the way escapeMessage renders
+// it, along with its size in message.
+func escapeRune(message string) (string, int) {
+	r, size := utf8.DecodeRuneInString(message)
+
+	switch {
+	case r == utf8.RuneError && size == 1:
+		return `\x` + strconv.FormatUint(uint64(message[0]), 16), size //nolint:mnd
+	case mustEscape(r):
+		// QuoteRune picks the shortest escape, drop its quotes
+		quoted := strconv.QuoteRuneToASCII(r)
+
+		return quoted[1 : len(quoted)-1], size
+	default:
+		return message[:size], size
+	}
+}
+
+// needsEscaping reports whether escapeMessage would change message, without
+// decoding runes for plain ASCII text.
+func needsEscaping(message string) bool {
+	for i := range len(message) {
+		if b := message[i]; b >= utf8.RuneSelf || mustEscape(rune(b)) {
+			return needsEscapingSlow(message[i:])
+		}
+	}
+
+	return false
+}
+
+// needsEscapingSlow decodes message rune by rune to tell whether escapeMessage
+// would change it.
+func needsEscapingSlow(message string) bool {
+	for i := 0; i < len(message); {
+		r, size := utf8.DecodeRuneInString(message[i:])
+		if (r == utf8.RuneError && size == 1) || mustEscape(r) {
+			return true
+		}
+
+		i += size
+	}
+
+	return false
+}
+
+// mustEscape reports whether r gets escaped.
+func mustEscape(r rune) bool {
+	return (unicode.IsControl(r) && r != '\t') || unicode.Is(unicode.Bidi_Control, r)
+}
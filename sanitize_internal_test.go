@@ -0,0 +1,75 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "plain ASCII",
+			message:  "failed to load config",
+			expected: "failed to load config",
+		},
+		{
+			name:     "tab is kept",
+			message:  "col1\tcol2",
+			expected: "col1\tcol2",
+		},
+		{
+			name:     "multi-byte UTF-8 is kept",
+			message:  "não encontrado 見つかりません",
+			expected: "não encontrado 見つかりません",
+		},
+		{
+			name:     "newlines",
+			message:  "line1\nline2\r\n",
+			expected: `line1\nline2\r\n`,
+		},
+		{
+			name:     "terminal escape",
+			message:  "\x1b[31mred\x1b[0m",
+			expected: `\x1b[31mred\x1b[0m`,
+		},
+		{
+			name:     "C1 control",
+			message:  "next\u0085line",
+			expected: `next\u0085line`,
+		},
+		{
+			name:     "bidi override",
+			message:  "user \u202egnp.exe",
+			expected: `user \u202egnp.exe`,
+		},
+		{
+			name:     "invalid UTF-8",
+			message:  "bad \xff\xfe bytes",
+			expected: `bad \xff\xfe bytes`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, escapeMessage(tc.message))
+			require.Equal(t, tc.expected != tc.message, needsEscaping(tc.message))
+		})
+	}
+}
+
+func TestErrorEscapesMessages(t *testing.T) {
+	t.Cleanup(func() { SetHideLocation(false) })
+
+	SetHideLocation(true)
+
+	err := Wrap(errors.New("payload: {\"a\":\n1}"), "user said \x1b[2J") //nolint:err113
+
+	require.Equal(t, `user said \x1b[2J: payload: {"a":\n1}`, err.Error())
+	require.Equal(t, "user said \x1b[2J", err.(*CTXError).Message()) //nolint:errorlint,forcetypeassert
+}
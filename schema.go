@@ -0,0 +1,117 @@
+package ctxerrors
+
+// wireSchema is the JSON Schema of version 1 of the wire format.
+const wireSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://github.com/psyb0t/ctxerrors/wire/v1.schema.json",
+  "title": "ctxerrors error chain",
+  "description": "An error chain as ctxerrors.Marshal encodes it, wire format version 1.",
+  "type": "object",
+  "required": ["v", "layers"],
+  "properties": {
+    "v": {
+      "description": "Wire format version.",
+      "const": 1
+    },
+    "fingerprint": {
+      "description": "Hash of the functions and error types the chain went through, ignoring messages and line numbers.",
+      "type": "string"
+    },
+    "stack_hash": {
+      "description": "Hash of the locations and captured callers of the chain's layers, ignoring messages.",
+      "type": "string"
+    },
+    "layers": {
+      "$ref": "#/$defs/layers"
+    }
+  },
+  "$defs": {
+    "layers": {
+      "description": "The errors of a chain, outermost first. A layer with joined members is the last of its chain.",
+      "type": "array",
+      "items": {
+        "$ref": "#/$defs/layer"
+      }
+    },
+    "layer": {
+      "description": "One error in a chain.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "type": {
+          "description": "Go type of an error that isn't a *ctxerrors.CTXError, e.g. \"*fs.PathError\". Absent for *ctxerrors.CTXError layers.",
+          "type": "string"
+        },
+        "message": {
+          "description": "The layer's own message, without those of the layers below it.",
+          "type": "string"
+        },
+        "file": {
+          "description": "Source file the layer was created in.",
+          "type": "string"
+        },
+        "line": {
+          "description": "Line in file the layer was created at.",
+          "type": "integer",
+          "minimum": 0
+        },
+        "func": {
+          "description": "Fully qualified name of the function the layer was created in.",
+          "type": "string"
+        },
+        "id": {
+          "description": "Instance ID of the layer.",
+          "type": "string"
+        },
+        "fields": {
+          "description": "Structured fields of the layer. Values are whatever JSON the sender encoded them as.",
+          "type": "object"
+        },
+        "details": {
+          "description": "Structured details of a well-known error that isn't a *ctxerrors.CTXError, e.g. the op and path of a *fs.PathError or the code of a PostgreSQL error.",
+          "type": "object"
+        },
+        "stack": {
+          "description": "Captured callers above the layer's location, nearest first.",
+          "type": "array",
+          "items": {
+            "$ref": "#/$defs/frame"
+          }
+        },
+        "joined": {
+          "description": "Chains of the members of a joined error.",
+          "type": "array",
+          "items": {
+            "$ref": "#/$defs/layers"
+          }
+        }
+      }
+    },
+    "frame": {
+      "description": "One stack frame.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "file": {
+          "type": "string"
+        },
+        "line": {
+          "type": "integer",
+          "minimum": 0
+        },
+        "func": {
+          "type": "string"
+        }
+      }
+    }
+  }
+}
+`
+
+// WireSchema returns the JSON Schema of the WireVersion format Marshal
+// writes, for consumers in other languages to validate payloads and generate
+// types from. The ctxerrors command prints it with -schema, e.g. for a
+// go:generate directive keeping a copy in a repository.
+func WireSchema() []byte {
+	return []byte(wireSchema)
+}
@@ -0,0 +1,51 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWireSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct {
+			Const int `json:"const"`
+		} `json:"properties"`
+		Defs map[string]struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"$defs"`
+	}
+
+	require.NoError(t, json.Unmarshal(WireSchema(), &schema))
+	require.Equal(t, WireVersion, schema.Properties["v"].Const)
+
+	// Every field of the wire types has to be described, and nothing else
+	types := map[string]reflect.Type{
+		"layer": reflect.TypeFor[wireLayer](),
+		"frame": reflect.TypeFor[wireFrame](),
+	}
+
+	for def, typ := range types {
+		var names []string
+
+		for i := range typ.NumField() {
+			names = append(names, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+		}
+
+		require.ElementsMatch(t, names, slices.Collect(maps.Keys(schema.Defs[def].Properties)), def)
+	}
+
+	chainType := reflect.TypeFor[wireChain]()
+	require.Equal(t, chainType.NumField(), len(schema.Properties))
+
+	t.Run("returns a copy", func(t *testing.T) {
+		WireSchema()[0] = 'x'
+
+		require.True(t, json.Valid(WireSchema()))
+	})
+}
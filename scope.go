@@ -0,0 +1,103 @@
+package ctxerrors
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// scopeMu makes WithScopedConfig scopes run one at a time.
+var scopeMu sync.Mutex //nolint:gochecknoglobals
+
+// WithScopedConfig runs fn with the settings in cfg and restores the ones in
+// effect before once it returns or panics, so tests and special subsystems
+// don't have to change and restore every setting by hand:
+//
+//	cfg := ctxerrors.CurrentConfig()
+//	cfg.HideLocation = true
+//	cfg.Separator = " | "
+//
+//	_ = ctxerrors.WithScopedConfig(cfg, func() {
+//		body = renderErrorPage(err)
+//	})
+//
+// Start from CurrentConfig, cfg sets everything it holds a value for, a zero
+// Config turns off sentinel kinds among others. The settings made of functions,
+// interfaces and registrations, which Config only counts or flags, are left as
+// they are. The scoped settings are published as one snapshot, so no goroutine
+// ever sees a mix of them and the previous ones, but like every setting they're
+// package-wide: errors created and rendered on other goroutines while fn runs
+// get them too. Scopes run one at a time, a scope waits for any other to end,
+// so fn mustn't start one of its own. When the scope ends the settings Config
+// holds values for go back to what they were, anything fn set among them
+// included, while registrations made in the meantime, such as enrich hooks,
+// stay. It returns an error without running fn if cfg has an invalid
+// redaction pattern.
+func WithScopedConfig(cfg Config, fn func()) error {
+	redacted := make([]string, len(cfg.RedactedFields))
+
+	for i, pattern := range cfg.RedactedFields {
+		redacted[i] = strings.ToLower(pattern)
+
+		if _, err := path.Match(redacted[i], ""); err != nil {
+			return Wrapf(err, "invalid redaction pattern %q", pattern)
+		}
+	}
+
+	scopeMu.Lock()
+	defer scopeMu.Unlock()
+
+	var previous *config
+
+	updateConfig(func(c *config) {
+		previous = currentConfig()
+		c.apply(cfg, redacted)
+	})
+
+	defer func() {
+		// Only the scoped settings are restored, a blind store of the
+		// previous snapshot would drop whatever got registered meanwhile
+		updateConfig(func(c *config) {
+			c.apply(previous.export(), previous.redactedFields)
+		})
+	}()
+
+	fn()
+
+	return nil
+}
+
+// apply sets the settings of c that cfg holds values for, with redacted as
+// the validated, lowercased redaction patterns, normalizing them like their
+// Set functions do.
+func (c *config) apply(cfg Config, redacted []string) {
+	separator := cfg.Separator
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+
+	c.hideLocation = cfg.HideLocation
+	c.captureMode = cfg.CaptureMode
+	c.normalizePaths = cfg.NormalizePaths
+	c.instanceIDs = cfg.InstanceIDs
+	c.separator = separator
+	c.queryArgsPolicy = cfg.QueryArgsPolicy
+	c.callerDepth = max(cfg.CallerDepth, 0)
+	c.redactedFields = slices.Clip(redacted)
+	c.fieldPrecedence = cfg.FieldPrecedence
+	c.deterministic = cfg.Deterministic
+	c.sentinelKinds = slices.Clone(cfg.SentinelKinds)
+	c.anonymizePaths = cfg.AnonymizePaths
+	c.maxLayerMessage = max(cfg.MaxLayerMessage, 0)
+	c.maxTotalMessage = max(cfg.MaxTotalMessage, 0)
+	c.countActivity = cfg.CountActivity
+	c.vetMode = cfg.VetMode
+	c.timestamps = cfg.Timestamps
+	c.collapseRepeats = cfg.CollapseRepeats
+	c.funcNameStyle = cfg.FuncNameStyle
+	c.warnDepth = max(cfg.WarnDepth, 0)
+	c.formatCheck = cfg.FormatCheck
+	c.inferOps = cfg.InferOps
+	c.ancestry = cfg.Ancestry
+}
@@ -0,0 +1,98 @@
+package ctxerrors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithScopedConfig(t *testing.T) {
+	before := CurrentConfig()
+
+	cfg := CurrentConfig()
+	cfg.HideLocation = true
+	cfg.Separator = " | "
+	cfg.CallerDepth = 3
+	cfg.RedactedFields = []string{"*Token*"}
+	cfg.FuncNameStyle = FuncNameShort
+
+	var (
+		text   string
+		inside Config
+	)
+
+	require.NoError(t, WithScopedConfig(cfg, func() {
+		text = Wrap(New("inner"), "outer").Error()
+		inside = CurrentConfig()
+
+		// Whatever the callback sets is undone too
+		SetInstanceIDs(true)
+	}))
+
+	require.Equal(t, "outer | inner", text)
+
+	cfg.RedactedFields = []string{"*token*"}
+	require.Equal(t, cfg, inside)
+	require.Equal(t, before, CurrentConfig())
+
+	t.Run("restored after a panic", func(t *testing.T) {
+		require.Panics(t, func() {
+			_ = WithScopedConfig(cfg, func() { panic("boom") })
+		})
+
+		require.Equal(t, before, CurrentConfig())
+	})
+
+	t.Run("hooks are left alone", func(t *testing.T) {
+		unregister := RegisterEnrichHook(EnrichHookFunc(func(*CTXError) map[string]any { return nil }))
+		t.Cleanup(unregister)
+
+		scoped := CurrentConfig()
+		scoped.EnrichHooks = 0
+
+		require.NoError(t, WithScopedConfig(scoped, func() {
+			require.Equal(t, 1, CurrentConfig().EnrichHooks)
+		}))
+	})
+
+	t.Run("registrations made meanwhile are kept", func(t *testing.T) {
+		scoped := CurrentConfig()
+		scoped.HideLocation = true
+
+		var unregister func()
+
+		require.NoError(t, WithScopedConfig(scoped, func() {
+			registered := make(chan func())
+
+			go func() {
+				registered <- RegisterEnrichHook(EnrichHookFunc(func(*CTXError) map[string]any { return nil }))
+			}()
+
+			unregister = <-registered
+		}))
+		t.Cleanup(unregister)
+
+		require.Equal(t, 1, CurrentConfig().EnrichHooks)
+		require.False(t, CurrentConfig().HideLocation)
+	})
+
+	t.Run("empty separator", func(t *testing.T) {
+		scoped := CurrentConfig()
+		scoped.Separator = ""
+
+		require.NoError(t, WithScopedConfig(scoped, func() {
+			require.Equal(t, DefaultSeparator, CurrentConfig().Separator)
+		}))
+	})
+
+	t.Run("invalid redaction pattern", func(t *testing.T) {
+		scoped := CurrentConfig()
+		scoped.RedactedFields = []string{"["}
+
+		ran := false
+		err := WithScopedConfig(scoped, func() { ran = true })
+
+		require.ErrorContains(t, err, `invalid redaction pattern "["`)
+		require.False(t, ran)
+	})
+}
@@ -0,0 +1,259 @@
+// Package sentry converts ctxerrors chains into Sentry events.
+//
+// The types mirror Sentry's event payload protocol and marshal to the JSON it
+// expects, so the result can be sent to the store endpoint as is or copied
+// into the official SDK's types without this module depending on it.
+package sentry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+const (
+	eventIDBytes = 16   // Sentry event IDs are 32 hex characters
+	platform     = "go" // Platform reported with every event
+)
+
+// Sentry event levels.
+const (
+	levelDebug   = "debug"
+	levelInfo    = "info"
+	levelWarning = "warning"
+	levelError   = "error"
+	levelFatal   = "fatal"
+)
+
+// fieldSeverity is the key of report.FieldSeverity, which can't be imported
+// from here since report imports this package.
+const fieldSeverity = "severity"
+
+// Event is a Sentry event carrying an error chain.
+type Event struct {
+	EventID     string            `json:"event_id"` //nolint:tagliatelle
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Contexts    map[string]any    `json:"contexts,omitempty"`
+	Exception   ExceptionList     `json:"exception"`
+}
+
+// linksContext is the event context links set with ctxerrors.WithLink go in.
+const linksContext = "links"
+
+// ExceptionList holds the exceptions of an event, oldest (innermost cause)
+// first as Sentry expects.
+type ExceptionList struct {
+	Values []Exception `json:"values"`
+}
+
+// Exception is one error in the chain.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace holds the frames of an exception, caller first.
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a single stack frame.
+type Frame struct {
+	Function string `json:"function,omitempty"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"` //nolint:tagliatelle
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"` //nolint:tagliatelle
+}
+
+// ToSentryEvent maps err to a Sentry event. Every *CTXError layer becomes an
+// exception with its captured location as the stack frame and every other
+// error in the chain becomes an exception typed after its Go type. The
+// fingerprint is built from the functions that created the layers so events
+// group by code path rather than by message, and fields become tags with the
+// outermost layer winning on duplicate keys. ctxerrors.WithLink links go in a
+// links context by label instead, since Sentry cuts tag values too short for
+// URLs and renders context ones as clickable links. The level comes from the
+// severity set under report.FieldSeverity, with report.SeverityCritical
+// becoming "fatal", or else from KindOf the way report.DefaultSeverities rates
+// kinds. It returns nil for a nil error.
+func ToSentryEvent(err error) *Event {
+	if err == nil {
+		return nil
+	}
+
+	event := &Event{
+		EventID:     newEventID(),
+		Timestamp:   ctxerrors.Now().UTC(),
+		Level:       levelOf(err),
+		Platform:    platform,
+		Fingerprint: nil,
+		Tags:        nil,
+		Contexts:    nil,
+		Exception:   ExceptionList{Values: nil},
+	}
+
+	tags := map[string]string{}
+	for key, value := range ctxerrors.Fields(err) {
+		if !strings.HasPrefix(key, ctxerrors.FieldLinkPrefix) {
+			tags[key] = fmt.Sprint(value)
+		}
+	}
+
+	if links := ctxerrors.Links(err); len(links) > 0 {
+		context := make(map[string]string, len(links))
+		for _, link := range links {
+			context[link.Label] = link.URL
+		}
+
+		event.Contexts = map[string]any{linksContext: context}
+	}
+
+	for err != nil {
+		var exception Exception
+
+		if ctxErr, ok := err.(*ctxerrors.CTXError); ok && ctxErr != nil { //nolint:errorlint
+			exception = layerException(ctxErr)
+			event.Fingerprint = append(event.Fingerprint, ctxErr.FuncName())
+		} else {
+			exception = foreignException(err)
+		}
+
+		event.Exception.Values = append(event.Exception.Values, exception)
+		err = errors.Unwrap(err)
+	}
+
+	// Sentry wants the oldest exception first
+	slices.Reverse(event.Exception.Values)
+
+	if len(tags) > 0 {
+		event.Tags = tags
+	}
+
+	return event
+}
+
+// levelOf returns the Sentry level of err, see ToSentryEvent.
+func levelOf(err error) string {
+	if severity, ok := ctxerrors.FieldAs[fmt.Stringer](err, fieldSeverity); ok {
+		if level, ok := severityLevel(severity.String()); ok {
+			return level
+		}
+	}
+
+	if severity, ok := ctxerrors.FieldAs[string](err, fieldSeverity); ok {
+		if level, ok := severityLevel(severity); ok {
+			return level
+		}
+	}
+
+	switch ctxerrors.KindOf(err) {
+	case ctxerrors.KindUnavailable, ctxerrors.KindDeadlineExceeded:
+		return levelWarning
+	case ctxerrors.KindNotFound, ctxerrors.KindInvalidArgument, ctxerrors.KindAlreadyExists,
+		ctxerrors.KindPermissionDenied, ctxerrors.KindUnauthenticated, ctxerrors.KindCanceled,
+		ctxerrors.KindEndOfData:
+		return levelInfo
+	default:
+		return levelError
+	}
+}
+
+// severityLevel returns the Sentry level of a severity named like
+// report.Severity.String names them, or a Sentry level itself.
+func severityLevel(severity string) (string, bool) {
+	switch severity {
+	case "critical", levelFatal:
+		return levelFatal, true
+	case levelError, levelWarning, levelInfo, levelDebug:
+		return severity, true
+	default:
+		return "", false
+	}
+}
+
+// layerException maps a single *CTXError layer to an exception, with the
+// layer's location and the callers SetCallerDepth captured above it as the
+// stack trace.
+func layerException(layer *ctxerrors.CTXError) Exception {
+	module, _ := ctxerrors.SplitFuncName(layer.FuncName())
+
+	callers := layer.Callers()
+	frames := make([]Frame, 0, len(callers)+1)
+
+	// Sentry wants the caller first, so the layer's own frame goes last
+	for i := len(callers) - 1; i >= 0; i-- {
+		frames = append(frames, stackFrame(callers[i]))
+	}
+
+	frames = append(frames, stackFrame(ctxerrors.Frame{
+		File:     layer.File(),
+		Line:     layer.Line(),
+		FuncName: layer.FuncName(),
+	}))
+
+	return Exception{
+		Type:       fmt.Sprintf("%T", layer),
+		Value:      layer.Message(),
+		Module:     module,
+		Stacktrace: &Stacktrace{Frames: frames},
+	}
+}
+
+// stackFrame maps a resolved frame to a Sentry one, in the app unless it's in
+// the standard library.
+func stackFrame(frame ctxerrors.Frame) Frame {
+	module, function := ctxerrors.SplitFuncName(frame.FuncName)
+	root, _, _ := strings.Cut(module, "/")
+
+	return Frame{
+		Function: function,
+		Module:   module,
+		Filename: fileName(frame.File),
+		AbsPath:  frame.File,
+		Lineno:   frame.Line,
+		InApp:    module == "main" || strings.Contains(root, "."),
+	}
+}
+
+// foreignException maps an error that isn't a *CTXError to an exception with
+// the text of whatever it wraps trimmed off its own.
+func foreignException(err error) Exception {
+	value := err.Error()
+	if inner := errors.Unwrap(err); inner != nil {
+		value = strings.TrimSuffix(value, ": "+inner.Error())
+	}
+
+	return Exception{
+		Type:       fmt.Sprintf("%T", err),
+		Value:      value,
+		Module:     "",
+		Stacktrace: nil,
+	}
+}
+
+// fileName returns the last element of a slash-separated path.
+func fileName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// newEventID returns a random Sentry event ID.
+func newEventID() string {
+	buf := make([]byte, eventIDBytes)
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,188 @@
+package sentry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestToSentryEvent(t *testing.T) {
+	t.Run("nil error", func(t *testing.T) {
+		require.Nil(t, ToSentryEvent(nil))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		baseErr := errors.New("connection refused") //nolint:err113
+		err := ctxerrors.Wrap(fmt.Errorf("dial db: %w", baseErr), "failed to load user")
+
+		event := ToSentryEvent(err)
+		require.NotNil(t, event)
+		require.Len(t, event.EventID, 32)
+		require.NotZero(t, event.Timestamp)
+		require.Equal(t, "error", event.Level)
+		require.Equal(t, "go", event.Platform)
+
+		values := event.Exception.Values
+		require.Len(t, values, 3)
+
+		// Oldest first
+		require.Equal(t, "*errors.errorString", values[0].Type)
+		require.Equal(t, "connection refused", values[0].Value)
+		require.Nil(t, values[0].Stacktrace)
+
+		require.Equal(t, "*fmt.wrapError", values[1].Type)
+		require.Equal(t, "dial db", values[1].Value)
+
+		require.Equal(t, "*ctxerrors.CTXError", values[2].Type)
+		require.Equal(t, "failed to load user", values[2].Value)
+		require.Equal(t, "github.com/psyb0t/ctxerrors/sentry", values[2].Module)
+		require.NotNil(t, values[2].Stacktrace)
+		require.Len(t, values[2].Stacktrace.Frames, 1)
+
+		frame := values[2].Stacktrace.Frames[0]
+		require.Equal(t, "TestToSentryEvent.func2", frame.Function)
+		require.Equal(t, "github.com/psyb0t/ctxerrors/sentry", frame.Module)
+		require.Equal(t, "sentry_internal_test.go", frame.Filename)
+		require.Contains(t, frame.AbsPath, "/sentry/sentry_internal_test.go")
+		require.NotZero(t, frame.Lineno)
+		require.True(t, frame.InApp)
+
+		require.Equal(t, []string{"github.com/psyb0t/ctxerrors/sentry.TestToSentryEvent.func2"}, event.Fingerprint)
+		require.Nil(t, event.Tags)
+	})
+
+	t.Run("fields become tags", func(t *testing.T) {
+		unregister := ctxerrors.RegisterEnrichHook(ctxerrors.EnrichHookFunc(
+			func(err *ctxerrors.CTXError) map[string]any {
+				return map[string]any{"layer": err.Message(), "user_id": 42}
+			},
+		))
+		defer unregister()
+
+		event := ToSentryEvent(ctxerrors.Wrap(ctxerrors.New("inner"), "outer"))
+		require.Equal(t, map[string]string{"layer": "outer", "user_id": "42"}, event.Tags)
+		require.Len(t, event.Fingerprint, 2)
+	})
+
+	t.Run("links become a context", func(t *testing.T) {
+		err := ctxerrors.WithField(ctxerrors.New("db down"), "user_id", 42)
+		err = ctxerrors.WithLink(err, "runbook", "https://runbooks.example.com/db")
+
+		event := ToSentryEvent(err)
+		require.Equal(t, map[string]string{"user_id": "42"}, event.Tags)
+		require.Equal(t, map[string]any{
+			"links": map[string]string{"runbook": "https://runbooks.example.com/db"},
+		}, event.Contexts)
+	})
+
+	t.Run("timestamp comes from the ctxerrors clock", func(t *testing.T) {
+		frozen := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+
+		ctxerrors.SetClock(func() time.Time { return frozen })
+		t.Cleanup(func() { ctxerrors.SetClock(nil) })
+
+		require.Equal(t, frozen, ToSentryEvent(ctxerrors.New("boom")).Timestamp)
+	})
+
+	t.Run("marshals to the sentry protocol", func(t *testing.T) {
+		data, err := json.Marshal(ToSentryEvent(ctxerrors.New("boom")))
+		require.NoError(t, err)
+
+		var payload map[string]any
+
+		require.NoError(t, json.Unmarshal(data, &payload))
+		require.Contains(t, payload, "event_id")
+		require.Contains(t, payload, "timestamp")
+		require.Equal(t, "error", payload["level"])
+
+		exception, ok := payload["exception"].(map[string]any)
+		require.True(t, ok)
+
+		values, ok := exception["values"].([]any)
+		require.True(t, ok)
+		require.Len(t, values, 1)
+	})
+}
+
+type severity string
+
+func (s severity) String() string {
+	return string(s)
+}
+
+func TestToSentryEventLevel(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "unclassified",
+			err:      ctxerrors.New("boom"),
+			expected: "error",
+		},
+		{
+			name:     "unavailable kind",
+			err:      ctxerrors.WithKind(ctxerrors.New("db down"), ctxerrors.KindUnavailable),
+			expected: "warning",
+		},
+		{
+			name:     "not found kind",
+			err:      ctxerrors.WithKind(ctxerrors.New("no user"), ctxerrors.KindNotFound),
+			expected: "info",
+		},
+		{
+			name:     "critical severity",
+			err:      ctxerrors.WithField(ctxerrors.New("disk gone"), fieldSeverity, severity("critical")),
+			expected: "fatal",
+		},
+		{
+			name: "severity overrides kind",
+			err: ctxerrors.WithField(
+				ctxerrors.WithKind(ctxerrors.New("no user"), ctxerrors.KindNotFound), fieldSeverity, severity("warning"),
+			),
+			expected: "warning",
+		},
+		{
+			name:     "sentry level string",
+			err:      ctxerrors.WithField(ctxerrors.New("trace"), fieldSeverity, "debug"),
+			expected: "debug",
+		},
+		{
+			name:     "unknown severity falls back to kind",
+			err:      ctxerrors.WithField(ctxerrors.New("boom"), fieldSeverity, "loud"),
+			expected: "error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ToSentryEvent(tc.err).Level)
+		})
+	}
+}
+
+func TestToSentryEventStacktrace(t *testing.T) {
+	ctxerrors.SetCallerDepth(2)
+	t.Cleanup(func() { ctxerrors.SetCallerDepth(0) })
+
+	event := ToSentryEvent(ctxerrors.New("boom"))
+
+	frames := event.Exception.Values[0].Stacktrace.Frames
+	require.Len(t, frames, 3)
+
+	// Caller first, the layer's own frame last
+	require.Equal(t, "tRunner", frames[1].Function)
+	require.Equal(t, "testing", frames[1].Module)
+	require.False(t, frames[1].InApp)
+
+	require.Equal(t, "TestToSentryEventStacktrace", frames[2].Function)
+	require.Equal(t, "github.com/psyb0t/ctxerrors/sentry", frames[2].Module)
+	require.True(t, frames[2].InApp)
+}
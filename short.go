@@ -0,0 +1,126 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Separators of the Short and Full formats, which settings don't change.
+const (
+	shortSeparator       = ": "
+	shortMemberSeparator = "; "
+)
+
+// Short renders err for people: just the messages of its chain, outermost
+// first, separated by ": ", with layers adding no message left out and the
+// members of joined errors rendered the same way and separated by "; ":
+//
+//	load user: query users: connection refused
+//
+// Unlike Error() its format is fixed, SetSeparator, SetHideLocation, message
+// limits and the like don't change it, so CLIs can show it to users while
+// logging Full. Messages are escaped like Error() escapes them. It returns an
+// empty string for a nil error.
+func Short(err error) string {
+	var builder strings.Builder
+
+	writeShort(&builder, err)
+
+	return builder.String()
+}
+
+// writeShort writes the Short rendering of err to builder.
+func writeShort(builder *strings.Builder, err error) {
+	for first := true; err != nil; {
+		var message string
+
+		if joined, ok := members(err); ok {
+			rendered := make([]string, 0, len(joined))
+
+			for _, member := range joined {
+				if text := Short(member); text != "" {
+					rendered = append(rendered, text)
+				}
+			}
+
+			message = strings.Join(rendered, shortMemberSeparator)
+			err = nil
+		} else if layer, ok := asCTXError(err); ok {
+			message = escapeMessage(layer.msg())
+			err = layer.err
+		} else {
+			message = escapeMessage(ownMessage(err))
+			err = errors.Unwrap(err)
+		}
+
+		if message == "" {
+			continue
+		}
+
+		if !first {
+			builder.WriteString(shortSeparator)
+		}
+
+		builder.WriteString(message)
+
+		first = false
+	}
+}
+
+// Full renders err for machines and logs: Short followed by the location of
+// every *CTXError layer in its chain, outermost first and including those
+// inside joined errors, each with its instance ID and fields:
+//
+//	load user: connection refused [user.go:42 in app.(*Users).Load id=3f9c user_id=7] [db.go:12 in app/db.Query]
+//
+// Locations always come with full function names and fields sorted by key as
+// logfmt values, marshaled and redacted like Fields does. Like Short its format
+// is fixed whatever the settings say, SetHideLocation included. It returns an
+// empty string for a nil error.
+func Full(err error) string {
+	var builder strings.Builder
+
+	writeShort(&builder, err)
+
+	walk(err, func(current error) {
+		layer, ok := asCTXError(current)
+		if !ok {
+			return
+		}
+
+		if builder.Len() > 0 {
+			builder.WriteByte(' ')
+		}
+
+		writeFullLayer(&builder, layer)
+	})
+
+	return builder.String()
+}
+
+// writeFullLayer writes the bracketed location, ID and fields of layer as Full
+// renders them to builder.
+func writeFullLayer(builder *strings.Builder, layer *CTXError) {
+	builder.WriteString("[")
+
+	if layer.file != "" {
+		builder.WriteString(layer.file + ":" + strconv.Itoa(layer.line) + " ")
+	}
+
+	builder.WriteString("in " + layer.funcName)
+
+	if layer.id != "" {
+		builder.WriteString(" id=" + logfmtValue(layer.id))
+	}
+
+	fields := layer.Fields()
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		builder.WriteString(" " + logfmtValue(key) + "=" + logfmtValue(fmt.Sprint(fields[key])))
+	}
+
+	builder.WriteString("]")
+}
@@ -0,0 +1,98 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShort(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: "",
+		},
+		{
+			name:     "plain error",
+			err:      baseErr,
+			expected: "connection refused",
+		},
+		{
+			name:     "chain",
+			err:      Wrap(Wrap(baseErr, "query users"), "load user"),
+			expected: "load user: query users: connection refused",
+		},
+		{
+			name:     "empty messages left out",
+			err:      Wrap(WithField(Wrap(baseErr, ""), "user_id", 7), "load user"),
+			expected: "load user: connection refused",
+		},
+		{
+			name:     "foreign wrapper in between",
+			err:      Wrap(fmt.Errorf("retry exhausted: %w", Wrap(baseErr, "query users")), "load user"),
+			expected: "load user: retry exhausted: query users: connection refused",
+		},
+		{
+			name:     "joined errors",
+			err:      Wrap(errors.Join(Wrap(baseErr, "primary"), New("replica down")), "query users"),
+			expected: "query users: primary: connection refused; replica down",
+		},
+		{
+			name:     "escaped",
+			err:      New("bad\nline"),
+			expected: `bad\nline`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Short(tc.err))
+		})
+	}
+
+	t.Run("ignores settings", func(t *testing.T) {
+		SetSeparator(" -> ")
+		t.Cleanup(func() { SetSeparator(DefaultSeparator) })
+
+		require.Equal(t, "load user: connection refused", Short(Wrap(baseErr, "load user")))
+	})
+}
+
+func TestFull(t *testing.T) {
+	require.Empty(t, Full(nil))
+	require.Equal(t, "connection refused", Full(errors.New("connection refused"))) //nolint:err113
+
+	require.NoError(t, SetRedactedFields("password"))
+	SetHideLocation(true)
+	t.Cleanup(func() {
+		_ = SetRedactedFields()
+		SetHideLocation(false)
+	})
+
+	inner := New("connection refused")
+	outer := WithField(Wrap(inner, "load user"), "user_id", 7)
+	outer = WithField(outer, "password", "hunter2")
+	outer = WithField(outer, "note", "two words")
+
+	innerLayer, _ := asCTXError(inner)
+	outerLayer, _ := asCTXError(outer)
+
+	location := func(layer *CTXError) string {
+		return layer.file + ":" + strconv.Itoa(layer.line) + " in " + layer.funcName
+	}
+
+	require.Equal(t,
+		"load user: connection refused ["+location(outerLayer)+
+			` note="two words" password=`+RedactedValue+" user_id=7] ["+location(innerLayer)+"]",
+		Full(outer))
+}
@@ -0,0 +1,72 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Simplify converts err's chain into one made of the standard library's own
+// errors, for handing errors to systems that only know Error() and would
+// otherwise lose what the *CTXError layers carry. Every *CTXError layer
+// becomes a fmt.Errorf wrapping what's below it, its message followed by its
+// location, ID and fields bracketed the way Full renders them:
+//
+//	load user [user.go:42 in app.(*Users).Load user_id=7]: query [db.go:12 in app/db.Query]: connection refused
+//
+// Joined errors become errors.Join of their simplified members and other
+// errors with *CTXError layers below them a fmt.Errorf of the text they add,
+// while errors with none below them are kept as they are, so errors.Is and
+// errors.As still find sentinels and typed causes. Layers referencing errors
+// with %w keep their text but not their matching. It returns nil if err is
+// nil.
+func Simplify(err error) error {
+	if err == nil || !hasCTXLayer(err) {
+		return err
+	}
+
+	if joined, ok := members(err); ok {
+		simplified := make([]error, 0, len(joined))
+		for _, member := range joined {
+			simplified = append(simplified, Simplify(member))
+		}
+
+		return errors.Join(simplified...)
+	}
+
+	var builder strings.Builder
+
+	inner := errors.Unwrap(err)
+
+	if layer, ok := asCTXError(err); ok {
+		if message := layer.msg(); message != "" {
+			builder.WriteString(message + " ")
+		}
+
+		writeFullLayer(&builder, layer)
+
+		inner = layer.err
+	} else {
+		builder.WriteString(ownMessage(err))
+	}
+
+	if inner == nil {
+		return errors.New(builder.String()) //nolint:err113
+	}
+
+	return fmt.Errorf("%s: %w", builder.String(), Simplify(inner))
+}
+
+// hasCTXLayer reports whether there's a *CTXError layer in err's chain,
+// including inside joined errors.
+func hasCTXLayer(err error) bool {
+	found := false
+
+	walk(err, func(current error) {
+		if _, ok := asCTXError(current); ok {
+			found = true
+		}
+	})
+
+	return found
+}
@@ -0,0 +1,61 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimplify(t *testing.T) {
+	baseErr := &fs.PathError{Op: "open", Path: "/etc/app.yaml", Err: fs.ErrNotExist}
+
+	inner := Wrap(baseErr, "read config")
+	outer := WithField(Wrap(fmt.Errorf("retry exhausted: %w", inner), "load"), "attempts", 3)
+
+	innerLayer, _ := asCTXError(inner)
+	outerLayer, _ := asCTXError(outer)
+
+	location := func(layer *CTXError) string {
+		return layer.file + ":" + strconv.Itoa(layer.line) + " in " + layer.funcName
+	}
+
+	simplified := Simplify(outer)
+
+	require.Equal(t,
+		"load ["+location(outerLayer)+" attempts=3]: retry exhausted: read config ["+location(innerLayer)+
+			"]: open /etc/app.yaml: file does not exist",
+		simplified.Error())
+	require.ErrorIs(t, simplified, fs.ErrNotExist)
+	require.ErrorIs(t, simplified, baseErr)
+	require.False(t, hasCTXLayer(simplified))
+
+	var pathErr *fs.PathError
+	require.ErrorAs(t, simplified, &pathErr)
+
+	t.Run("joined", func(t *testing.T) {
+		first := New("primary down")
+		firstLayer, _ := asCTXError(first)
+
+		simplified := Simplify(errors.Join(first, baseErr))
+
+		require.Equal(t, "primary down ["+location(firstLayer)+"]\n"+baseErr.Error(), simplified.Error())
+		require.ErrorIs(t, simplified, baseErr)
+		require.False(t, hasCTXLayer(simplified))
+	})
+
+	t.Run("empty message", func(t *testing.T) {
+		err := Wrap(baseErr, "")
+		layer, _ := asCTXError(err)
+
+		require.Equal(t, "["+location(layer)+"]: "+baseErr.Error(), Simplify(err).Error())
+	})
+
+	t.Run("nothing to simplify", func(t *testing.T) {
+		require.NoError(t, Simplify(nil))
+		require.Same(t, baseErr, Simplify(baseErr))
+	})
+}
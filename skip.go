@@ -0,0 +1,65 @@
+package ctxerrors
+
+// FieldComponent is the field key helpers generated by cmd/ctxerrors-gen
+// record the component they were generated for under.
+const FieldComponent = "component"
+
+// NewSkip is like New but records the location skip frames above its caller,
+// for helpers creating errors on their callers' behalf: NewSkip(1, ...) in a
+// helper records where the helper was called.
+func NewSkip(skip int, message string) error {
+	// Skip NewSkip() and newLayer() to get user's caller
+	framesToSkip := 2 + max(skip, 0)
+
+	layer := newLayer(nil, message, framesToSkip)
+
+	return finishLayer(layer, nil)
+}
+
+// NewfSkip is like Newf but records the location skip frames above its caller,
+// like NewSkip.
+func NewfSkip(skip int, format string, args ...any) error {
+	// Skip NewfSkip() and newLayer() to get user's caller
+	framesToSkip := 2 + max(skip, 0)
+
+	message, refs := formatMessage(format, args...)
+
+	layer := newLayer(nil, message, framesToSkip)
+	layer.refs = refs
+
+	checkFormat(layer, format)
+
+	return finishLayer(layer, nil)
+}
+
+// WrapSkip is like Wrap but records the location skip frames above its
+// caller, like NewSkip.
+func WrapSkip(err error, skip int, message string) error {
+	// Skip WrapSkip() and wrap() to get user's caller
+	framesToSkip := 2 + max(skip, 0)
+
+	return wrap(err, message, framesToSkip, nil)
+}
+
+// WrapfSkip is like Wrapf but records the location skip frames above its
+// caller, like NewSkip.
+func WrapfSkip(err error, skip int, format string, args ...any) error {
+	// Skip WrapfSkip() and newLayer() to get user's caller
+	framesToSkip := 2 + max(skip, 0)
+
+	if err == nil {
+		// Skip WrapfSkip() and logNilWrap() instead
+		logNilWrap(framesToSkip)
+
+		return nil
+	}
+
+	message, refs := formatMessage(format, args...)
+
+	layer := newLayer(err, message, framesToSkip)
+	layer.refs = refs
+
+	checkFormat(layer, format)
+
+	return finishLayer(layer, nil)
+}
@@ -0,0 +1,73 @@
+package ctxerrors
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func skipHelper(skip int, err error) []error {
+	return []error{
+		NewSkip(skip, "new"),
+		NewfSkip(skip, "newf %w", io.EOF),
+		WrapSkip(err, skip, "wrap"),
+		WrapfSkip(err, skip, "wrapf %w", io.EOF),
+	}
+}
+
+//go:noinline
+func skipCaller(skip int, err error) []error {
+	return skipHelper(skip, err)
+}
+
+func TestSkip(t *testing.T) {
+	baseErr := errors.New("base error") //nolint:err113
+
+	testCases := []struct {
+		name             string
+		skip             int
+		expectedFuncName string
+	}{
+		{
+			name:             "no skip",
+			skip:             0,
+			expectedFuncName: "skipHelper",
+		},
+		{
+			name:             "negative skip",
+			skip:             -1,
+			expectedFuncName: "skipHelper",
+		},
+		{
+			name:             "skips the helper",
+			skip:             1,
+			expectedFuncName: "skipCaller",
+		},
+		{
+			name:             "skips two frames",
+			skip:             2,
+			expectedFuncName: "TestSkip",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, err := range skipCaller(tc.skip, baseErr) {
+				var ctxErr *CTXError
+
+				require.True(t, errors.As(err, &ctxErr))
+				require.Contains(t, ctxErr.FuncName(), tc.expectedFuncName)
+			}
+		})
+	}
+
+	errs := skipHelper(0, baseErr)
+	require.ErrorIs(t, errs[1], io.EOF)
+	require.ErrorIs(t, errs[2], baseErr)
+	require.ErrorIs(t, errs[3], io.EOF)
+	require.ErrorIs(t, errs[3], baseErr)
+	require.NoError(t, WrapSkip(nil, 1, "wrap"))
+}
@@ -0,0 +1,96 @@
+package ctxerrors
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SourceAttr returns a slog.SourceKey attribute holding where err originated,
+// the location of the innermost *CTXError layer in its chain, following
+// Unwrap() error, as the *slog.Source the standard handlers render like the
+// source of a record, so log viewers that already understand it point at the
+// error instead of the logging call:
+//
+//	logger.Error("request failed", "error", err, ctxerrors.SourceAttr(err))
+//
+// Pair it with a handler that doesn't AddSource, or use SourceHandler, or the
+// record ends up with two. It returns the zero Attr, which handlers drop, if
+// there's no *CTXError layer.
+func SourceAttr(err error) slog.Attr {
+	layers := ctxLayers(err)
+	if len(layers) == 0 {
+		return slog.Attr{}
+	}
+
+	origin := layers[len(layers)-1]
+
+	return slog.Any(slog.SourceKey, &slog.Source{
+		Function: origin.funcName,
+		File:     origin.file,
+		Line:     origin.line,
+	})
+}
+
+// sourceHandler is the slog.Handler SourceHandler returns.
+type sourceHandler struct {
+	handler slog.Handler
+}
+
+// SourceHandler returns a slog.Handler passing records on to handler with the
+// source of those that carry an error pointing at where the error originated,
+// as SourceAttr tells, rather than at the logging call:
+//
+//	logger := slog.New(ctxerrors.SourceHandler(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{AddSource: true})))
+//
+// The first attribute of a record whose value is an error with a *CTXError
+// layer counts; attributes added with With and inside groups aren't looked at,
+// and under a WithGroup group the source lands in the group like any other
+// attribute. Such records lose their call site, so handler doesn't add a
+// source of its own next to the error's. Other records pass through untouched.
+func SourceHandler(handler slog.Handler) slog.Handler {
+	return sourceHandler{handler: handler}
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (h sourceHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle passes record on with its source replaced as SourceHandler says.
+func (h sourceHandler) Handle(ctx context.Context, record slog.Record) error {
+	var source slog.Attr
+
+	record.Attrs(func(attr slog.Attr) bool {
+		if err, ok := attr.Value.Any().(error); ok && attr.Value.Kind() == slog.KindAny {
+			source = SourceAttr(err)
+		}
+
+		return source.Key == ""
+	})
+
+	if source.Key == "" {
+		return h.handler.Handle(ctx, record) //nolint:wrapcheck
+	}
+
+	sourced := slog.NewRecord(record.Time, record.Level, record.Message, 0)
+	sourced.AddAttrs(source)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		sourced.AddAttrs(attr)
+
+		return true
+	})
+
+	return h.handler.Handle(ctx, sourced) //nolint:wrapcheck
+}
+
+// WithAttrs returns a SourceHandler wrapping the wrapped handler with attrs.
+func (h sourceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return sourceHandler{handler: h.handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a SourceHandler wrapping the wrapped handler with the
+// group name.
+func (h sourceHandler) WithGroup(name string) slog.Handler {
+	return sourceHandler{handler: h.handler.WithGroup(name)}
+}
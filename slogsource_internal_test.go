@@ -0,0 +1,69 @@
+package ctxerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceAttr(t *testing.T) {
+	inner := New("connection refused")
+	err := Wrap(inner, "load user")
+	origin, _ := asCTXError(inner)
+
+	attr := SourceAttr(err)
+
+	require.Equal(t, slog.SourceKey, attr.Key)
+	require.Equal(t, &slog.Source{Function: origin.funcName, File: origin.file, Line: origin.line}, attr.Value.Any())
+
+	require.True(t, SourceAttr(errors.New("plain")).Equal(slog.Attr{})) //nolint:err113
+	require.True(t, SourceAttr(nil).Equal(slog.Attr{}))
+}
+
+func TestSourceHandler(t *testing.T) {
+	var buffer bytes.Buffer
+
+	logger := slog.New(SourceHandler(slog.NewJSONHandler(&buffer, &slog.HandlerOptions{AddSource: true})))
+
+	decode := func() map[string]any {
+		var entry map[string]any
+
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &entry))
+		buffer.Reset()
+
+		return entry
+	}
+
+	err := New("connection refused")
+	origin, _ := asCTXError(err)
+
+	logger.Error("request failed", "user", 7, "error", Wrap(err, "load user"))
+
+	entry := decode()
+	require.Equal(t, map[string]any{
+		"function": origin.funcName,
+		"file":     origin.file,
+		"line":     float64(origin.line),
+	}, entry[slog.SourceKey])
+	require.Equal(t, float64(7), entry["user"])
+	require.Contains(t, entry, "error")
+
+	t.Run("records without errors keep their call site", func(t *testing.T) {
+		logger.Info("all good", "error", errors.New("plain")) //nolint:err113
+
+		source, _ := decode()[slog.SourceKey].(map[string]any)
+		require.Equal(t, "github.com/psyb0t/ctxerrors.TestSourceHandler.func2", source["function"])
+	})
+
+	t.Run("with attrs", func(t *testing.T) {
+		logger.With("service", "users").Error("request failed", "error", err)
+
+		entry := decode()
+		require.Equal(t, "users", entry["service"])
+		require.Equal(t, origin.file, entry[slog.SourceKey].(map[string]any)["file"]) //nolint:forcetypeassert
+	})
+}
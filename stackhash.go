@@ -0,0 +1,42 @@
+package ctxerrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// StackHash returns a short hash of where the *CTXError layers in err's chain,
+// including inside joined errors, were created and of the callers captured
+// above them, but not of messages, fields or other errors. Next to
+// Fingerprint, which leaves out line numbers and callers, it tells the same
+// message coming from different code paths apart from one code path failing
+// with different messages. It hashes resolved frames rather than raw PCs, so
+// it's stable across processes and machines running the same build and a
+// chain Unmarshal rebuilt hashes like the original. It's empty for a nil
+// error or one without *CTXError layers.
+func StackHash(err error) string {
+	hash := sha256.New()
+	found := false
+
+	walk(err, func(current error) {
+		layer, ok := asCTXError(current)
+		if !ok {
+			return
+		}
+
+		found = true
+
+		fmt.Fprintf(hash, "%s:%d %s\n", layer.file, layer.line, layer.funcName)
+
+		for _, frame := range layer.Callers() {
+			fmt.Fprintf(hash, "\t%s:%d %s\n", frame.File, frame.Line, frame.FuncName)
+		}
+	})
+
+	if !found {
+		return ""
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)[:fingerprintBytes])
+}
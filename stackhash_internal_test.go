@@ -0,0 +1,67 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:noinline
+func stackHashOrigin(message string) error {
+	return New(message)
+}
+
+//go:noinline
+func stackHashCaller(message string) error {
+	return stackHashOrigin(message)
+}
+
+func TestStackHash(t *testing.T) {
+	t.Cleanup(func() { SetCallerDepth(0) })
+
+	SetCallerDepth(3)
+
+	require.Empty(t, StackHash(nil))
+	require.Empty(t, StackHash(errors.New("plain"))) //nolint:err113
+
+	// Created on the same line so the captured callers match
+	errs := make([]error, 0, 2)
+	for _, id := range []string{"1", "2"} {
+		errs = append(errs, stackHashOrigin("user "+id+" not found"))
+	}
+
+	direct, otherMessage := errs[0], errs[1]
+
+	viaCaller := stackHashCaller("user 1 not found")
+
+	require.Len(t, StackHash(direct), 2*fingerprintBytes)
+
+	// Same code path, different messages
+	require.Equal(t, StackHash(direct), StackHash(otherMessage))
+
+	// Same message, different code paths, which Fingerprint can't tell apart
+	require.NotEqual(t, StackHash(direct), StackHash(viaCaller))
+	require.Equal(t, Fingerprint(direct), Fingerprint(viaCaller))
+
+	t.Run("survives the wire", func(t *testing.T) {
+		err := Wrap(viaCaller, "load user")
+
+		data, marshalErr := Marshal(err)
+		require.NoError(t, marshalErr)
+
+		decoded, unmarshalErr := Unmarshal(data)
+		require.NoError(t, unmarshalErr)
+		require.Equal(t, StackHash(err), StackHash(decoded))
+
+		var chain struct {
+			Fingerprint string `json:"fingerprint"`
+			StackHash   string `json:"stack_hash"`
+		}
+
+		require.NoError(t, json.Unmarshal(data, &chain))
+		require.Equal(t, Fingerprint(err), chain.Fingerprint)
+		require.Equal(t, StackHash(err), chain.StackHash)
+	})
+}
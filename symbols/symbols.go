@@ -0,0 +1,166 @@
+// Package symbols maps the locations captured in binaries built with
+// -trimpath back to the source files on disk.
+//
+// -trimpath records files as module path, version and file name, e.g.
+// "golang.org/x/text@v0.14.0/unicode/norm/normalize.go", and standard library
+// files relative to GOROOT/src, so error locations stay short and reproducible
+// but can't be opened from a terminal or an editor anymore. A Map generated at
+// build time from `go list -m -json all` restores the directories, either live
+// through SourceMapper or offline on errors recovered from logs with the parser
+// package. Binaries stripped with -ldflags "-s -w" keep the runtime's own
+// function and line tables, so the function names and lines in their errors
+// need no help.
+package symbols
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+	"github.com/psyb0t/ctxerrors/parser"
+)
+
+// Map holds the source directories of a build.
+type Map struct {
+	// Modules maps a module as -trimpath records it, path@version or just
+	// the path for the main module and local replacements, to its directory.
+	Modules map[string]string `json:"modules"`
+	// GOROOT is the root of the Go installation the binary was built with.
+	GOROOT string `json:"goroot,omitempty"`
+}
+
+// goListModule is the part of a `go list -m -json` module object Map needs.
+type goListModule struct {
+	Path    string
+	Version string
+	Dir     string
+	Replace *goListModule
+}
+
+// FromGoList builds a Map from the output of `go list -m -json all`, run in the
+// main module at build time. goroot is the GOROOT of that build, as
+// `go env GOROOT` prints it, or empty to leave standard library files alone.
+func FromGoList(r io.Reader, goroot string) (*Map, error) {
+	m := &Map{Modules: map[string]string{}, GOROOT: goroot}
+	decoder := json.NewDecoder(r)
+
+	for {
+		var module goListModule
+
+		err := decoder.Decode(&module)
+		if errors.Is(err, io.EOF) {
+			return m, nil
+		}
+
+		if err != nil {
+			return nil, ctxerrors.Wrap(err, "failed to decode go list output")
+		}
+
+		// Replacements are recorded under their own path and version
+		if module.Replace != nil && module.Replace.Version != "" {
+			module = *module.Replace
+		} else if module.Replace != nil {
+			module.Dir = module.Replace.Dir
+			module.Version = ""
+		}
+
+		if module.Dir == "" {
+			// Not downloaded, so no errors can come from it
+			continue
+		}
+
+		key := module.Path
+		if module.Version != "" {
+			key += "@" + module.Version
+		}
+
+		m.Modules[key] = module.Dir
+	}
+}
+
+// Load reads a Map written by Write.
+func Load(r io.Reader) (*Map, error) {
+	var m Map
+
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, ctxerrors.Wrap(err, "failed to decode symbol map")
+	}
+
+	return &m, nil
+}
+
+// Write writes m as JSON, to ship next to the binary or keep with the build.
+func (m *Map) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(m); err != nil {
+		return ctxerrors.Wrap(err, "failed to encode symbol map")
+	}
+
+	return nil
+}
+
+// Resolve returns the source file a file recorded by -trimpath came from, with
+// forward slashes like ctxerrors records files. Files that belong to no module
+// in m and aren't from the standard library come back unchanged.
+func (m *Map) Resolve(file string) string {
+	if file == "" || path.IsAbs(file) || filepath.IsAbs(file) {
+		return file
+	}
+
+	// The longest module path wins, modules can be nested
+	best := ""
+
+	for module := range m.Modules {
+		if len(module) > len(best) && strings.HasPrefix(file, module+"/") {
+			best = module
+		}
+	}
+
+	if best != "" {
+		return joinSlash(m.Modules[best], strings.TrimPrefix(file, best+"/"))
+	}
+
+	// Only the standard library has import paths without a dot in the first
+	// element
+	first, _, _ := strings.Cut(file, "/")
+	if m.GOROOT != "" && !strings.Contains(first, ".") {
+		return joinSlash(m.GOROOT, "src/"+file)
+	}
+
+	return file
+}
+
+// SourceMapper returns a ctxerrors.SourceMapper resolving locations through m,
+// for SetSourceMapper in the binary itself.
+func (m *Map) SourceMapper() ctxerrors.SourceMapper {
+	return func(file string, line int) (string, int) {
+		return m.Resolve(file), line
+	}
+}
+
+// Resymbolize returns a copy of chain, as the parser package recovers it from
+// logs, with every layer's file resolved through m.
+func (m *Map) Resymbolize(chain parser.Chain) parser.Chain {
+	layers := make([]parser.Layer, len(chain.Layers))
+
+	for i, layer := range chain.Layers {
+		layer.File = m.Resolve(layer.File)
+		layers[i] = layer
+	}
+
+	chain.Layers = layers
+
+	return chain
+}
+
+// joinSlash joins dir and a slash-separated rest into a path with forward
+// slashes.
+func joinSlash(dir, rest string) string {
+	return strings.TrimSuffix(filepath.ToSlash(dir), "/") + "/" + rest
+}
@@ -0,0 +1,154 @@
+package symbols
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+	"github.com/psyb0t/ctxerrors/parser"
+)
+
+// goListOutput is what `go list -m -json all` prints, trimmed down.
+const goListOutput = `{
+	"Path": "github.com/acme/app",
+	"Main": true,
+	"Dir": "/home/dev/app"
+}
+{
+	"Path": "github.com/acme/app/tools",
+	"Dir": "/home/dev/app/tools"
+}
+{
+	"Path": "golang.org/x/text",
+	"Version": "v0.14.0",
+	"Dir": "/go/pkg/mod/golang.org/x/text@v0.14.0"
+}
+{
+	"Path": "github.com/acme/lib",
+	"Version": "v1.2.0",
+	"Replace": {
+		"Path": "github.com/fork/lib",
+		"Version": "v1.2.1",
+		"Dir": "/go/pkg/mod/github.com/fork/lib@v1.2.1"
+	}
+}
+{
+	"Path": "github.com/acme/local",
+	"Version": "v0.1.0",
+	"Replace": {
+		"Path": "../local",
+		"Dir": "/home/dev/local"
+	}
+}
+{
+	"Path": "github.com/acme/unused",
+	"Version": "v0.0.1"
+}
+`
+
+func TestFromGoList(t *testing.T) {
+	m, err := FromGoList(strings.NewReader(goListOutput), "/usr/local/go")
+	require.NoError(t, err)
+
+	require.Equal(t, &Map{
+		Modules: map[string]string{
+			"github.com/acme/app":        "/home/dev/app",
+			"github.com/acme/app/tools":  "/home/dev/app/tools",
+			"golang.org/x/text@v0.14.0":  "/go/pkg/mod/golang.org/x/text@v0.14.0",
+			"github.com/fork/lib@v1.2.1": "/go/pkg/mod/github.com/fork/lib@v1.2.1",
+			"github.com/acme/local":      "/home/dev/local",
+		},
+		GOROOT: "/usr/local/go",
+	}, m)
+
+	_, err = FromGoList(strings.NewReader("{nope"), "")
+	require.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	m, err := FromGoList(strings.NewReader(goListOutput), "/usr/local/go/")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		file     string
+		expected string
+	}{
+		{"github.com/acme/app/handler.go", "/home/dev/app/handler.go"},
+		{"github.com/acme/app/internal/db/db.go", "/home/dev/app/internal/db/db.go"},
+		{"github.com/acme/app/tools/gen.go", "/home/dev/app/tools/gen.go"},
+		{"golang.org/x/text@v0.14.0/unicode/norm/normalize.go", "/go/pkg/mod/golang.org/x/text@v0.14.0/unicode/norm/normalize.go"},
+		{"github.com/fork/lib@v1.2.1/lib.go", "/go/pkg/mod/github.com/fork/lib@v1.2.1/lib.go"},
+		{"github.com/acme/local/local.go", "/home/dev/local/local.go"},
+		{"net/http/server.go", "/usr/local/go/src/net/http/server.go"},
+		{"github.com/someone/else/x.go", "github.com/someone/else/x.go"},
+		{"/already/absolute.go", "/already/absolute.go"},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.file, func(t *testing.T) {
+			require.Equal(t, tc.expected, m.Resolve(tc.file))
+		})
+	}
+
+	t.Run("no GOROOT", func(t *testing.T) {
+		require.Equal(t, "net/http/server.go", (&Map{}).Resolve("net/http/server.go"))
+	})
+}
+
+func TestWriteLoad(t *testing.T) {
+	m, err := FromGoList(strings.NewReader(goListOutput), "/usr/local/go")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, m.Write(&buf))
+
+	loaded, err := Load(&buf)
+	require.NoError(t, err)
+	require.Equal(t, m, loaded)
+
+	_, err = Load(strings.NewReader("nope"))
+	require.Error(t, err)
+}
+
+func TestSourceMapper(t *testing.T) {
+	m := &Map{Modules: map[string]string{"github.com/psyb0t": "/src/psyb0t"}}
+
+	mapper := m.SourceMapper()
+	file, line := mapper("github.com/psyb0t/ctxerrors/symbols/symbols.go", 12)
+	require.Equal(t, "/src/psyb0t/ctxerrors/symbols/symbols.go", file)
+	require.Equal(t, 12, line)
+
+	ctxerrors.SetSourceMapper(mapper)
+	t.Cleanup(func() { ctxerrors.SetSourceMapper(nil) })
+
+	// Outside -trimpath builds files are absolute and stay as they are
+	var ctxErr *ctxerrors.CTXError
+
+	require.True(t, errors.As(ctxerrors.New("boom"), &ctxErr))
+	require.True(t, strings.HasPrefix(ctxErr.File(), "/"))
+	require.True(t, strings.HasSuffix(ctxErr.File(), "/symbols/symbols_internal_test.go"))
+}
+
+func TestResymbolize(t *testing.T) {
+	m := &Map{Modules: map[string]string{"github.com/acme/app": "/home/dev/app"}}
+
+	chain, ok := parser.Parser{}.Parse(
+		"load user: connection refused [github.com/acme/app/db.go:12 in app.query] [in app.handle]",
+	)
+	require.True(t, ok)
+
+	resymbolized := m.Resymbolize(chain)
+	require.Equal(t, []parser.Layer{
+		{Message: "load user", FuncName: "app.handle"},
+		{Message: "connection refused", File: "/home/dev/app/db.go", Line: 12, FuncName: "app.query"},
+	}, resymbolized.Layers)
+
+	// The original is left alone
+	require.Equal(t, "github.com/acme/app/db.go", chain.Layers[1].File)
+}
@@ -0,0 +1,263 @@
+// Package syslog formats ctxerrors chains as RFC 5424 syslog messages, with
+// severities picked by Kind and the error's location and fields as structured
+// data, for appliances and embedded deployments that only speak syslog.
+package syslog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Severity is an RFC 5424 severity.
+type Severity int
+
+// Severities from RFC 5424, most severe first.
+const (
+	SeverityEmergency Severity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInformational
+	SeverityDebug
+)
+
+// Facility is an RFC 5424 facility.
+type Facility int
+
+// Facilities applications log under. The others are for the system.
+const (
+	FacilityUser   Facility = 1
+	FacilityDaemon Facility = 3
+	FacilityLocal0 Facility = 16
+	FacilityLocal1 Facility = 17
+	FacilityLocal2 Facility = 18
+	FacilityLocal3 Facility = 19
+	FacilityLocal4 Facility = 20
+	FacilityLocal5 Facility = 21
+	FacilityLocal6 Facility = 22
+	FacilityLocal7 Facility = 23
+)
+
+// DefaultSDID is the structured data ID errors are described under. 32473 is
+// the private enterprise number RFC 5612 reserves for documentation, set
+// Writer.SDID to one under your own number if your collector cares.
+const DefaultSDID = "ctxerr@32473"
+
+// Header field length limits from RFC 5424.
+const (
+	maxHostname  = 255
+	maxAppName   = 48
+	maxProcID    = 128
+	maxMsgID     = 32
+	maxParamName = 32
+)
+
+// nilValue is what RFC 5424 puts in place of an unknown header field.
+const nilValue = "-"
+
+// timestampLayout is RFC 3339 with the microseconds RFC 5424 allows.
+const timestampLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// bom marks MSG as UTF-8, as RFC 5424 asks.
+const bom = "\ufeff"
+
+// DefaultSeverities returns the severities errors get by Kind: internal and
+// unclassified errors are errors, unavailable dependencies and timeouts are
+// warnings, errors the caller caused are notices, and cancellation and end of
+// data are informational.
+func DefaultSeverities() map[ctxerrors.Kind]Severity {
+	return map[ctxerrors.Kind]Severity{
+		ctxerrors.KindUnknown:          SeverityError,
+		ctxerrors.KindInternal:         SeverityError,
+		ctxerrors.KindUnavailable:      SeverityWarning,
+		ctxerrors.KindDeadlineExceeded: SeverityWarning,
+		ctxerrors.KindNotFound:         SeverityNotice,
+		ctxerrors.KindInvalidArgument:  SeverityNotice,
+		ctxerrors.KindAlreadyExists:    SeverityNotice,
+		ctxerrors.KindPermissionDenied: SeverityNotice,
+		ctxerrors.KindUnauthenticated:  SeverityNotice,
+		ctxerrors.KindCanceled:         SeverityInformational,
+		ctxerrors.KindEndOfData:        SeverityInformational,
+	}
+}
+
+// Writer writes errors to Out as RFC 5424 messages, one Write per message, so
+// Out can be a UDP connection, or a TCP one wrapped in whatever framing the
+// collector wants.
+type Writer struct {
+	Out io.Writer
+	// Facility is the facility messages are sent under. Zero, which is the
+	// kernel's, means FacilityUser.
+	Facility Facility
+	// Hostname, AppName, ProcID and MsgID fill the header. Empty means the
+	// host name, the executable's name, the process ID and no message ID.
+	Hostname string
+	AppName  string
+	ProcID   string
+	MsgID    string
+	// SDID is the ID of the structured data element. Empty means DefaultSDID.
+	SDID string
+	// Severities maps a chain's Kind to its severity. Nil means
+	// DefaultSeverities, and kinds it doesn't have are errors.
+	Severities map[ctxerrors.Kind]Severity
+}
+
+// Report writes err, so a Writer can be used as a report.Reporter. A nil err
+// isn't written.
+func (w Writer) Report(_ context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, writeErr := w.Out.Write(w.Format(err)); writeErr != nil {
+		return ctxerrors.Wrap(writeErr, "failed to write syslog message")
+	}
+
+	return nil
+}
+
+// Format returns err as an RFC 5424 message timestamped with ctxerrors.Now.
+// The structured data element holds the file, line and function of the
+// innermost *CTXError layer, where the error started, its instance ID, and
+// every field from ctxerrors.Fields, sorted by key, with anything not allowed
+// in parameter names replaced by underscores. The message is err.Error().
+func (w Writer) Format(err error) []byte {
+	facility := w.Facility
+	if facility == 0 {
+		facility = FacilityUser
+	}
+
+	hostname := w.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	appName := w.AppName
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+
+	procID := w.ProcID
+	if procID == "" {
+		procID = strconv.Itoa(os.Getpid())
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ",
+		int(facility)*8+int(w.SeverityOf(err)), //nolint:mnd
+		ctxerrors.Now().Format(timestampLayout),
+		headerField(hostname, maxHostname),
+		headerField(appName, maxAppName),
+		headerField(procID, maxProcID),
+		headerField(w.MsgID, maxMsgID),
+	)
+
+	b.WriteString(w.structuredData(err))
+	b.WriteString(" " + bom + err.Error())
+
+	return []byte(b.String())
+}
+
+// SeverityOf returns the severity err gets by its Kind.
+func (w Writer) SeverityOf(err error) Severity {
+	severities := w.Severities
+	if severities == nil {
+		severities = DefaultSeverities()
+	}
+
+	severity, ok := severities[ctxerrors.KindOf(err)]
+	if !ok {
+		return SeverityError
+	}
+
+	return severity
+}
+
+// structuredData renders the structured data element describing err, or the
+// nil value if there's nothing to put in it.
+func (w Writer) structuredData(err error) string {
+	var params []string
+
+	param := func(name, value string) {
+		params = append(params, paramName(name)+`="`+paramValue(value)+`"`)
+	}
+
+	if origin := ctxerrors.LastContext(err); origin != nil {
+		param("file", origin.File())
+		param("line", strconv.Itoa(origin.Line()))
+		param("func", origin.FuncName())
+
+		if id := origin.ID(); id != "" {
+			param("id", id)
+		}
+	}
+
+	fields := ctxerrors.Fields(err)
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		param(key, fmt.Sprint(fields[key]))
+	}
+
+	if len(params) == 0 {
+		return nilValue
+	}
+
+	sdID := w.SDID
+	if sdID == "" {
+		sdID = DefaultSDID
+	}
+
+	return "[" + paramName(sdID) + " " + strings.Join(params, " ") + "]"
+}
+
+// headerField returns value as a header field, printable US-ASCII without
+// spaces cut to limit, or the nil value if it's empty.
+func headerField(value string, limit int) string {
+	field := []byte(value)
+
+	for i, c := range field {
+		if c <= ' ' || c > '~' {
+			field[i] = '_'
+		}
+	}
+
+	if len(field) == 0 {
+		return nilValue
+	}
+
+	return string(field[:min(len(field), limit)])
+}
+
+// paramName returns name with the characters RFC 5424 doesn't allow in names
+// replaced by underscores, cut to the 32 bytes it allows.
+func paramName(name string) string {
+	field := []byte(name)
+
+	for i, c := range field {
+		if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+			field[i] = '_'
+		}
+	}
+
+	if len(field) == 0 {
+		return "_"
+	}
+
+	return string(field[:min(len(field), maxParamName)])
+}
+
+// paramValue escapes the characters RFC 5424 wants escaped in values.
+func paramValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
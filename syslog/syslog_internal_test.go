@@ -0,0 +1,104 @@
+package syslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestWriterFormat(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+	ctxerrors.SetClock(func() time.Time { return clock })
+	t.Cleanup(func() { ctxerrors.SetClock(nil) })
+
+	writer := Writer{Hostname: "box", AppName: "app", ProcID: "42", MsgID: "ERR"}
+
+	t.Run("plain error", func(t *testing.T) {
+		require.Equal(t,
+			"<11>1 2024-05-01T12:00:00.123456Z box app 42 ERR - \ufeffboom",
+			string(writer.Format(errors.New("boom")))) //nolint:err113
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		origin := ctxerrors.New("config missing")
+		err := ctxerrors.WithField(ctxerrors.Wrap(origin, "init failed"), "path", `C:\etc "x" [1]`)
+		err = ctxerrors.WithKind(err, ctxerrors.KindNotFound)
+
+		layer := origin.(*ctxerrors.CTXError) //nolint:errorlint,forcetypeassert
+
+		require.Equal(t,
+			"<13>1 2024-05-01T12:00:00.123456Z box app 42 ERR "+
+				`[ctxerr@32473 file="`+layer.File()+`" line="`+strconv.Itoa(layer.Line())+`"`+
+				` func="`+layer.FuncName()+`"`+
+				` kind="not_found" path="C:\\etc \"x\" [1\]"] `+"\ufeff"+err.Error(),
+			string(writer.Format(err)))
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		message := string(Writer{Facility: FacilityLocal0}.Format(errors.New("boom"))) //nolint:err113
+
+		require.Regexp(t, regexp.MustCompile(`^<131>1 \S+ \S+ \S+ `+strconv.Itoa(os.Getpid())+` - - `), message)
+	})
+
+	t.Run("custom severities", func(t *testing.T) {
+		writer := Writer{Severities: map[ctxerrors.Kind]Severity{ctxerrors.KindNotFound: SeverityDebug}}
+
+		require.Equal(t, SeverityDebug, writer.SeverityOf(ctxerrors.WithKind(ctxerrors.New("x"), ctxerrors.KindNotFound)))
+		require.Equal(t, SeverityError, writer.SeverityOf(ctxerrors.WithKind(ctxerrors.New("x"), ctxerrors.KindCanceled)))
+	})
+}
+
+func TestDefaultSeverities(t *testing.T) {
+	var writer Writer
+
+	tests := []struct {
+		kind     ctxerrors.Kind
+		expected Severity
+	}{
+		{ctxerrors.KindUnknown, SeverityError},
+		{ctxerrors.KindInternal, SeverityError},
+		{ctxerrors.KindUnavailable, SeverityWarning},
+		{ctxerrors.KindInvalidArgument, SeverityNotice},
+		{ctxerrors.KindCanceled, SeverityInformational},
+		{ctxerrors.Kind("custom"), SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			require.Equal(t, tt.expected, writer.SeverityOf(ctxerrors.WithKind(ctxerrors.New("x"), tt.kind)))
+		})
+	}
+}
+
+func TestWriterReport(t *testing.T) {
+	var buf bytes.Buffer
+
+	writer := Writer{Out: &buf, Hostname: "box", AppName: "app", ProcID: "1"}
+
+	require.NoError(t, writer.Report(context.Background(), nil))
+	require.Zero(t, buf.Len())
+
+	require.NoError(t, writer.Report(context.Background(), errors.New("boom"))) //nolint:err113
+	require.Contains(t, buf.String(), " box app 1 - - \ufeffboom")
+}
+
+func TestHeaderField(t *testing.T) {
+	require.Equal(t, "-", headerField("", maxMsgID))
+	require.Equal(t, "my_host", headerField("my host", maxHostname))
+	require.Equal(t, "abc", headerField("abcdef", 3))
+}
+
+func TestParamName(t *testing.T) {
+	require.Equal(t, "a_b_c_d", paramName(`a=b]c"d`))
+	require.Equal(t, "_", paramName(""))
+	require.Len(t, paramName("a_very_long_field_name_that_goes_on"), maxParamName)
+}
@@ -0,0 +1,81 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tableColumnSeparator goes between the columns of FormatTable output.
+const tableColumnSeparator = " | "
+
+// FormatTable renders err's chain as a table with a row per layer, outermost
+// first, including layers inside joined errors in the order walk visits
+// them, and the columns lined up so deep chains can be scanned in terminals,
+// dashboards and incident docs:
+//
+//	message            | function | location
+//	load user          | app.load | /src/app/user.go:42
+//	dial db            |          |
+//	connection refused | app.dial | /src/app/db.go:12
+//
+// Errors that aren't *CTXError only fill in the text they add to what they
+// wrap, and joined errors say how many errors they join. Messages are escaped
+// like Error() escapes them. It returns an empty string for a nil error.
+func FormatTable(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	style := currentConfig().funcNameStyle
+	rows := [][3]string{{"message", "function", "location"}}
+
+	walk(err, func(current error) {
+		if layer, ok := asCTXError(current); ok {
+			location := ""
+			if layer.file != "" {
+				location = layer.file + ":" + strconv.Itoa(layer.line)
+			}
+
+			rows = append(rows, [3]string{escapeMessage(layer.msg()), formatFuncName(layer.funcName, style), location})
+
+			return
+		}
+
+		if joined, ok := members(current); ok {
+			rows = append(rows, [3]string{fmt.Sprintf("(%d joined errors)", len(joined)), "", ""})
+
+			return
+		}
+
+		rows = append(rows, [3]string{escapeMessage(ownMessage(current)), "", ""})
+	})
+
+	var widths [3]int
+
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var builder strings.Builder
+
+	for _, row := range rows {
+		var line strings.Builder
+
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString(tableColumnSeparator)
+			}
+
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+		}
+
+		builder.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+
+	return builder.String()
+}
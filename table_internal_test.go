@@ -0,0 +1,50 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatTable(t *testing.T) {
+	root := error(&CTXError{
+		message:  "connection refused",
+		file:     "/src/app/db.go",
+		line:     12,
+		funcName: "app.dial",
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		require.Empty(t, FormatTable(nil))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		err := error(&CTXError{
+			err:      fmt.Errorf("dial db: %w", root),
+			message:  "load user",
+			file:     "/src/app/user.go",
+			line:     42,
+			funcName: "app.load",
+		})
+
+		require.Equal(t, ""+
+			"message            | function | location\n"+
+			"load user          | app.load | /src/app/user.go:42\n"+
+			"dial db            |          |\n"+
+			"connection refused | app.dial | /src/app/db.go:12\n",
+			FormatTable(err))
+	})
+
+	t.Run("joined errors", func(t *testing.T) {
+		err := errors.Join(root, errors.New("cache: ünavailable\n")) //nolint:err113
+
+		require.Equal(t, ""+
+			"message              | function | location\n"+
+			"(2 joined errors)    |          |\n"+
+			"connection refused   | app.dial | /src/app/db.go:12\n"+
+			`cache: ünavailable\n |          |`+"\n",
+			FormatTable(err))
+	})
+}
@@ -0,0 +1,150 @@
+// Package temporal converts ctxerrors chains to and from what a Temporal
+// ApplicationError carries, so failures crossing workflow and activity
+// boundaries keep their kind, retryability and fields instead of arriving as
+// a bare message. It doesn't depend on the Temporal SDK, Failure maps onto
+// its constructor and accessors field by field:
+//
+//	failure, _ := temporal.ToFailure(err)
+//	return sdktemporal.NewApplicationErrorWithOptions(failure.Message, failure.Type,
+//		sdktemporal.ApplicationErrorOptions{NonRetryable: failure.NonRetryable, Details: []any{failure.Details}})
+//
+// and on the other side of the boundary:
+//
+//	var appErr *sdktemporal.ApplicationError
+//	if errors.As(err, &appErr) {
+//		var details temporal.Details
+//		_ = appErr.Details(&details)
+//
+//		err, _ = temporal.FromFailure(temporal.Failure{
+//			Message: appErr.Message(), Type: appErr.Type(),
+//			NonRetryable: appErr.NonRetryable(), Details: details,
+//		})
+//	}
+package temporal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// Failure is what an ApplicationError is made of.
+type Failure struct {
+	Message      string
+	Type         string // The Kind if there is one, for retry policies to match on
+	NonRetryable bool
+	Details      Details
+}
+
+// Details is the details payload of a Failure. It encodes to JSON, which is
+// what Temporal's default data converter uses.
+type Details struct {
+	Kind   ctxerrors.Kind  `json:"kind,omitempty"`
+	Fields map[string]any  `json:"fields,omitempty"`
+	Chain  json.RawMessage `json:"chain,omitempty"` // As ctxerrors.Marshal encodes it
+}
+
+// DefaultNonRetryable returns the kinds that aren't worth retrying by
+// default: the ones saying the request itself is wrong, which another
+// attempt won't fix, and cancellation.
+func DefaultNonRetryable() map[ctxerrors.Kind]bool {
+	return map[ctxerrors.Kind]bool{
+		ctxerrors.KindNotFound:         true,
+		ctxerrors.KindInvalidArgument:  true,
+		ctxerrors.KindAlreadyExists:    true,
+		ctxerrors.KindPermissionDenied: true,
+		ctxerrors.KindUnauthenticated:  true,
+		ctxerrors.KindCanceled:         true,
+	}
+}
+
+// Converter converts errors to Failures with its own settings.
+type Converter struct {
+	// NonRetryable says which kinds make a Failure non-retryable. Nil means
+	// DefaultNonRetryable.
+	NonRetryable map[ctxerrors.Kind]bool
+}
+
+// ToFailure converts err with the default Converter.
+func ToFailure(err error) (*Failure, error) {
+	return Converter{}.ToFailure(err)
+}
+
+// ToFailure converts err to a Failure: its text as the message, its Kind, or
+// the Go type of the innermost error that isn't a *CTXError if it has none,
+// as the type, non-retryable if NonRetryable says so for the kind, and its
+// kind, merged fields and encoded chain as details. It returns nil for a nil
+// error.
+func (c Converter) ToFailure(err error) (*Failure, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	chain, marshalErr := ctxerrors.Marshal(err)
+	if marshalErr != nil {
+		return nil, ctxerrors.Wrap(marshalErr, "failed to marshal error chain")
+	}
+
+	nonRetryable := c.NonRetryable
+	if nonRetryable == nil {
+		nonRetryable = DefaultNonRetryable()
+	}
+
+	kind := ctxerrors.KindOf(err)
+
+	failureType := string(kind)
+	if kind == ctxerrors.KindUnknown {
+		failureType = causeType(err)
+	}
+
+	return &Failure{
+		Message:      err.Error(),
+		Type:         failureType,
+		NonRetryable: nonRetryable[kind],
+		Details: Details{
+			Kind:   kind,
+			Fields: ctxerrors.Fields(err),
+			Chain:  chain,
+		},
+	}, nil
+}
+
+// FromFailure rebuilds the chain carried in failure's details like
+// ctxerrors.Unmarshal does. Failures without one, e.g. from workers not using
+// this package, become a *ctxerrors.RemoteError with the failure's type and
+// message, with the details' kind and fields if they have any.
+func FromFailure(failure Failure) (error, error) { //nolint:revive
+	if len(failure.Details.Chain) > 0 {
+		return ctxerrors.Unmarshal(failure.Details.Chain)
+	}
+
+	var err error = &ctxerrors.RemoteError{Type: failure.Type, Message: failure.Message}
+
+	if failure.Details.Kind != ctxerrors.KindUnknown {
+		err = ctxerrors.WithKind(err, failure.Details.Kind)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(failure.Details.Fields)) {
+		err = ctxerrors.WithField(err, key, failure.Details.Fields[key])
+	}
+
+	return err, nil
+}
+
+// causeType returns the type of the innermost error in err's chain that isn't
+// a *CTXError, or of the *CTXError layers if they're all there is.
+func causeType(err error) string {
+	causeType := ""
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if _, ok := err.(*ctxerrors.CTXError); !ok || causeType == "" { //nolint:errorlint
+			causeType = fmt.Sprintf("%T", err)
+		}
+	}
+
+	return causeType
+}
@@ -0,0 +1,113 @@
+package temporal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestToFailure(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	testCases := []struct {
+		name                 string
+		converter            Converter
+		err                  error
+		expectedType         string
+		expectedNonRetryable bool
+	}{
+		{
+			name:                 "retryable kind",
+			err:                  ctxerrors.WithKind(ctxerrors.Wrap(baseErr, "charge card"), ctxerrors.KindUnavailable),
+			expectedType:         "unavailable",
+			expectedNonRetryable: false,
+		},
+		{
+			name:                 "non-retryable kind",
+			err:                  ctxerrors.WithKind(ctxerrors.Wrap(baseErr, "charge card"), ctxerrors.KindInvalidArgument),
+			expectedType:         "invalid_argument",
+			expectedNonRetryable: true,
+		},
+		{
+			name:                 "no kind",
+			err:                  ctxerrors.Wrap(fmt.Errorf("open config: %w", baseErr), "load"),
+			expectedType:         "*errors.errorString",
+			expectedNonRetryable: false,
+		},
+		{
+			name:                 "own table",
+			converter:            Converter{NonRetryable: map[ctxerrors.Kind]bool{ctxerrors.KindUnavailable: true}},
+			err:                  ctxerrors.WithKind(ctxerrors.Wrap(baseErr, "charge card"), ctxerrors.KindUnavailable),
+			expectedType:         "unavailable",
+			expectedNonRetryable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			failure, err := tc.converter.ToFailure(tc.err)
+			require.NoError(t, err)
+			require.Equal(t, tc.err.Error(), failure.Message)
+			require.Equal(t, tc.expectedType, failure.Type)
+			require.Equal(t, tc.expectedNonRetryable, failure.NonRetryable)
+		})
+	}
+
+	t.Run("nil error", func(t *testing.T) {
+		failure, err := ToFailure(nil)
+		require.NoError(t, err)
+		require.Nil(t, failure)
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	original := ctxerrors.WithField(
+		ctxerrors.WithKind(ctxerrors.Wrap(ctxerrors.New("card declined"), "charge card"), ctxerrors.KindInvalidArgument),
+		"order_id", "ord_42",
+	)
+
+	failure, err := ToFailure(original)
+	require.NoError(t, err)
+	require.Equal(t, "ord_42", failure.Details.Fields["order_id"])
+
+	// Through JSON like Temporal's default data converter does it
+	payload, err := json.Marshal(failure.Details)
+	require.NoError(t, err)
+
+	var details Details
+
+	require.NoError(t, json.Unmarshal(payload, &details))
+
+	decoded, err := FromFailure(Failure{
+		Message:      failure.Message,
+		Type:         failure.Type,
+		NonRetryable: failure.NonRetryable,
+		Details:      details,
+	})
+	require.NoError(t, err)
+	require.Equal(t, original.Error(), decoded.Error())
+	require.Equal(t, ctxerrors.KindInvalidArgument, ctxerrors.KindOf(decoded))
+	require.Equal(t, "ord_42", ctxerrors.Fields(decoded)["order_id"])
+}
+
+func TestFromFailureWithoutChain(t *testing.T) {
+	decoded, err := FromFailure(Failure{
+		Message: "payment gateway timeout",
+		Type:    "GatewayTimeout",
+		Details: Details{Kind: ctxerrors.KindDeadlineExceeded, Fields: map[string]any{"gateway": "stripe"}},
+	})
+	require.NoError(t, err)
+
+	var remote *ctxerrors.RemoteError
+
+	require.ErrorAs(t, decoded, &remote)
+	require.Equal(t, "GatewayTimeout", remote.Type)
+	require.Equal(t, "payment gateway timeout", remote.Message)
+	require.Equal(t, ctxerrors.KindDeadlineExceeded, ctxerrors.KindOf(decoded))
+	require.Equal(t, "stripe", ctxerrors.Fields(decoded)["gateway"])
+}
@@ -0,0 +1,27 @@
+package ctxerrors
+
+// TextFormatVersion is the version of the text grammar CTXError.Error()
+// renders. It only goes up when the grammar changes in a way the parser
+// package can't read back, so log tooling can tell output it understands from
+// output it doesn't.
+//
+// Version 1, in ABNF, with separator being whatever SetSeparator set:
+//
+//	text      = messages tokens
+//	messages  = message *( separator message ) [ separator cause ]
+//	tokens    = 1*( SP token )        ; innermost layer first
+//	token     = location [ SP id ] / id
+//	location  = "[" [ file ":" line SP ] "in" SP func "]"
+//	id        = "[id=" *idchar "]"
+//	file      = *( any char but "[" and "]" )
+//	line      = 1*DIGIT
+//	func      = 1*( any char but "[", "]" and whitespace )
+//	idchar    = any char but "[" and "]"
+//
+// There's one message per layer, outermost first, escaped the way Error()
+// documents. The cause is the Error() text of the innermost error if it isn't
+// a *CTXError. A layer renders no location when locations are hidden or it
+// sits below a WrapStackBoundary layer, and no id when it has no instance ID.
+// Repeated layers collapsed by SetCollapseRepeats keep their " (×N)" marker
+// in the message.
+const TextFormatVersion = 1
@@ -0,0 +1,64 @@
+package ctxerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTextFormatVersion pins Error() output to version 1 of the grammar. If
+// one of these has to change, so does TextFormatVersion, and the parser
+// package has to learn the new version.
+func TestTextFormatVersion(t *testing.T) {
+	require.Equal(t, 1, TextFormatVersion)
+
+	inner := &CTXError{
+		err:      errors.New("no such file"), //nolint:err113
+		message:  "failed to open",
+		file:     "/src/db.go",
+		line:     17,
+		funcName: "main.open",
+		id:       "abc",
+	}
+	outer := &CTXError{
+		err:      inner,
+		message:  "init failed",
+		file:     "/src/main.go",
+		line:     24,
+		funcName: "main.main",
+	}
+	bare := &CTXError{message: "bare", funcName: "main.bare"}
+
+	testCases := []struct {
+		name     string
+		err      *CTXError
+		opts     textOptions
+		expected string
+	}{
+		{
+			name:     "locations and ID",
+			err:      outer,
+			opts:     textOptions{separator: DefaultSeparator},
+			expected: "init failed: failed to open: no such file [/src/db.go:17 in main.open] [id=abc] [/src/main.go:24 in main.main]",
+		},
+		{
+			name:     "hidden locations",
+			err:      outer,
+			opts:     textOptions{separator: " <- ", hideLocation: true},
+			expected: "init failed <- failed to open <- no such file [id=abc]",
+		},
+		{
+			name:     "function only",
+			err:      bare,
+			opts:     textOptions{separator: DefaultSeparator},
+			expected: "bare [in main.bare]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.err.text(tc.opts))
+		})
+	}
+}
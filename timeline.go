@@ -0,0 +1,67 @@
+package ctxerrors
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timelineSeparator goes between the layers of FormatTimeline output.
+const timelineSeparator = " → "
+
+// FormatTimeline renders when the *CTXError layers of err's chain, following
+// Unwrap() error, were created relative to the innermost one, innermost
+// first, so it's plain where the time went in a failure involving latency:
+//
+//	+0ms open conn → +1.2s exec query → +1.2s handler
+//
+// It needs SetTimestamps on when the layers were created, those without a
+// timestamp are left out. Layers without a message show their function. It
+// returns an empty string if no layer has a timestamp.
+func FormatTimeline(err error) string {
+	layers := ctxLayers(err)
+
+	var (
+		builder strings.Builder
+		origin  time.Time
+	)
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+		if layer.created.IsZero() {
+			continue
+		}
+
+		if origin.IsZero() {
+			origin = layer.created
+		} else {
+			builder.WriteString(timelineSeparator)
+		}
+
+		message := escapeMessage(layer.msg())
+		if message == "" {
+			message = formatFuncName(layer.funcName, FuncNameShort)
+		}
+
+		builder.WriteString(formatDelta(layer.created.Sub(origin)))
+		builder.WriteString(" ")
+		builder.WriteString(message)
+	}
+
+	return builder.String()
+}
+
+// formatDelta renders d signed, in whole milliseconds under a second and
+// rounded to a tenth of a second from there.
+func formatDelta(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	if d < time.Second {
+		return sign + strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+	}
+
+	return sign + d.Round(100*time.Millisecond).String() //nolint:mnd
+}
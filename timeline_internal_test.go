@@ -0,0 +1,52 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatTimeline(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	SetClock(func() time.Time { return now })
+	t.Cleanup(func() {
+		SetClock(nil)
+		SetTimestamps(false)
+	})
+
+	require.Empty(t, FormatTimeline(nil))
+	require.Empty(t, FormatTimeline(Wrap(New("open conn"), "exec query")))
+
+	SetTimestamps(true)
+
+	origin := New("open conn")
+	now = now.Add(1234 * time.Millisecond)
+	query := Wrap(origin, "exec query")
+	now = now.Add(35 * time.Millisecond)
+	annotated := WithField(fmt.Errorf("tx: %w", query), "user_id", 42)
+	now = now.Add(2 * time.Minute)
+	handler := Wrap(annotated, "handler")
+
+	require.Equal(t,
+		"+0ms open conn → +1.2s exec query → +1.3s ctxerrors.TestFormatTimeline → +2m1.3s handler",
+		FormatTimeline(handler),
+	)
+
+	t.Run("layers without timestamps are left out", func(t *testing.T) {
+		SetTimestamps(false)
+		t.Cleanup(func() { SetTimestamps(true) })
+
+		require.Equal(t, "+0ms open conn → +1.2s exec query", FormatTimeline(Wrap(query, "untimed")))
+	})
+}
+
+func TestFormatDelta(t *testing.T) {
+	require.Equal(t, "+0ms", formatDelta(0))
+	require.Equal(t, "+999ms", formatDelta(999*time.Millisecond))
+	require.Equal(t, "+1s", formatDelta(time.Second))
+	require.Equal(t, "+1.2s", formatDelta(1249*time.Millisecond))
+	require.Equal(t, "-35ms", formatDelta(-35*time.Millisecond))
+}
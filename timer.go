@@ -0,0 +1,78 @@
+package ctxerrors
+
+import (
+	"context"
+	"time"
+)
+
+// Field keys used by Timer and StartOp.
+const (
+	FieldOperation = "operation"
+	FieldElapsed   = "elapsed"
+)
+
+// timerKey is the context key StartOp stores its Timer under.
+type timerKey struct{}
+
+// Timer measures how long an operation has been running, so errors it ends
+// in can tell a fast validation failure from a slow timeout.
+type Timer struct {
+	name  string
+	start time.Time
+}
+
+// StartTimer starts timing the operation name, by the SetClock clock.
+func StartTimer(name string) Timer {
+	return Timer{name: name, start: Now()}
+}
+
+// Name returns the name of the operation.
+func (t Timer) Name() string {
+	return t.name
+}
+
+// Elapsed returns how long the operation has been running.
+func (t Timer) Elapsed() time.Duration {
+	return Now().Sub(t.start)
+}
+
+// Wrap is like Wrap but also records the operation's name and how long it ran
+// before failing as the FieldOperation and FieldElapsed fields.
+func (t Timer) Wrap(err error, message string) error {
+	// Skip Timer.Wrap() and wrap() to get user's caller
+	framesToSkip := 2
+
+	return wrap(err, message, framesToSkip, t.fields())
+}
+
+// fields returns the fields the timer records on errors.
+func (t Timer) fields() map[string]any {
+	return map[string]any{FieldOperation: t.name, FieldElapsed: t.Elapsed()}
+}
+
+// StartOp starts timing the operation name and returns a context carrying the
+// timer, so errors NewCtx and WrapCtx create under it record the operation and
+// how long it ran before failing as the FieldOperation and FieldElapsed
+// fields, the innermost operation's if they're nested. Registered
+// FieldProviders win over them.
+func StartOp(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, timerKey{}, StartTimer(name))
+}
+
+// OpTimer returns the Timer of the innermost operation StartOp started in ctx.
+func OpTimer(ctx context.Context) (Timer, bool) {
+	if ctx == nil {
+		return Timer{}, false
+	}
+
+	timer, ok := ctx.Value(timerKey{}).(Timer)
+
+	return timer, ok
+}
+
+// Elapsed returns how long the operation err happened in had been running,
+// as recorded by the outermost layer with FieldElapsed in its chain (see
+// SetFieldPrecedence).
+func Elapsed(err error) (time.Duration, bool) {
+	return lookupField[time.Duration](err, FieldElapsed)
+}
@@ -0,0 +1,95 @@
+package ctxerrors
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimer(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	t.Cleanup(func() { SetClock(nil) })
+
+	timer := StartTimer("reindex")
+	now = now.Add(3 * time.Second)
+
+	require.Equal(t, "reindex", timer.Name())
+	require.Equal(t, 3*time.Second, timer.Elapsed())
+
+	err := timer.Wrap(io.EOF, "failed to read batch")
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, "failed to read batch", err.(*CTXError).Message()) //nolint:errorlint,forcetypeassert
+	require.Contains(t, err.Error(), "timer_internal_test.go")
+	require.Equal(t, map[string]any{FieldOperation: "reindex", FieldElapsed: 3 * time.Second}, Fields(err))
+
+	elapsed, ok := Elapsed(err)
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, elapsed)
+}
+
+func TestStartOp(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	t.Cleanup(func() { SetClock(nil) })
+
+	t.Run("no operation", func(t *testing.T) {
+		_, ok := OpTimer(context.Background())
+		require.False(t, ok)
+
+		_, ok = Elapsed(NewCtx(context.Background(), "boom"))
+		require.False(t, ok)
+	})
+
+	t.Run("wrap under operation", func(t *testing.T) {
+		ctx := StartOp(context.Background(), "reindex")
+		now = now.Add(time.Minute)
+
+		timer, ok := OpTimer(ctx)
+		require.True(t, ok)
+		require.Equal(t, "reindex", timer.Name())
+
+		err := WrapCtx(ctx, io.EOF, "failed to read batch")
+		require.Equal(t, "reindex", Fields(err)[FieldOperation])
+
+		elapsed, ok := Elapsed(err)
+		require.True(t, ok)
+		require.Equal(t, time.Minute, elapsed)
+	})
+
+	t.Run("nested operations", func(t *testing.T) {
+		ctx := StartOp(context.Background(), "reindex")
+		now = now.Add(time.Minute)
+
+		ctx = StartOp(ctx, "fetch")
+		now = now.Add(time.Second)
+
+		err := NewCtx(ctx, "timeout")
+		require.Equal(t, map[string]any{FieldOperation: "fetch", FieldElapsed: time.Second}, Fields(err))
+	})
+
+	t.Run("providers win", func(t *testing.T) {
+		unregister := RegisterFieldProvider(func(context.Context) map[string]any {
+			return map[string]any{FieldOperation: "provided"}
+		})
+		t.Cleanup(unregister)
+
+		err := NewCtx(StartOp(context.Background(), "reindex"), "boom")
+		require.Equal(t, "provided", Fields(err)[FieldOperation])
+	})
+
+	t.Run("wire round trip", func(t *testing.T) {
+		data, err := Marshal(NewCtx(StartOp(context.Background(), "reindex"), "boom"))
+		require.NoError(t, err)
+
+		decoded, err := Unmarshal(data)
+		require.NoError(t, err)
+
+		elapsed, ok := Elapsed(decoded)
+		require.True(t, ok)
+		require.Zero(t, elapsed)
+	})
+}
@@ -0,0 +1,85 @@
+package ctxerrors
+
+// PublicError is what an API tells its clients about an error: a stable code
+// to branch on and a message fit for customers, none of the internals.
+type PublicError struct {
+	Code    string
+	Message string
+}
+
+// Error returns the message, or the code if there's no message.
+func (e PublicError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+
+	return e.Message
+}
+
+// DefaultPublicError returns what a Translator without a Fallback translates
+// the errors none of its rules match to.
+func DefaultPublicError() PublicError {
+	return PublicError{Code: "internal", Message: "internal error"}
+}
+
+// TranslationRule says which errors translate to Public. An error matches if
+// it meets every condition set. A rule without any matches every error, as a
+// catch-all.
+type TranslationRule struct {
+	Is     error                // In the chain, as IsAny sees it
+	As     func(err error) bool // E.g. MatchType[*pgconn.PgError]()
+	Kind   Kind                 // What Classify says
+	Public PublicError
+}
+
+// matches reports whether err meets every condition of the rule.
+func (r TranslationRule) matches(err error) bool {
+	return (r.Is == nil || IsAny(err, r.Is)) &&
+		(r.As == nil || r.As(err)) &&
+		(r.Kind == KindUnknown || Classify(err) == r.Kind)
+}
+
+// MatchType returns a TranslationRule.As condition matching errors with an
+// error of type T in their chain, as Delegate finds it.
+func MatchType[T error]() func(err error) bool {
+	return func(err error) bool {
+		_, ok := Delegate[T](err)
+
+		return ok
+	}
+}
+
+// Translator maps internal errors to what a public API tells its clients, so
+// the mapping lives in one place instead of in every handler:
+//
+//	translator := ctxerrors.Translator{Rules: []ctxerrors.TranslationRule{
+//		{Is: ErrUserNotFound, Public: ctxerrors.PublicError{Code: "user_not_found", Message: "No such user."}},
+//		{Kind: ctxerrors.KindInvalidArgument, Public: ctxerrors.PublicError{Code: "bad_request", Message: "Invalid request."}},
+//	}}
+type Translator struct {
+	// Rules are tried in order, the first one matching wins.
+	Rules []TranslationRule
+	// Fallback is what errors no rule matches translate to. The zero value
+	// means DefaultPublicError.
+	Fallback PublicError
+}
+
+// Translate returns what the first rule err matches says to tell clients, or
+// the Fallback. It returns the zero PublicError for a nil err.
+func (t Translator) Translate(err error) PublicError {
+	if err == nil {
+		return PublicError{}
+	}
+
+	for _, rule := range t.Rules {
+		if rule.matches(err) {
+			return rule.Public
+		}
+	}
+
+	if t.Fallback == (PublicError{}) {
+		return DefaultPublicError()
+	}
+
+	return t.Fallback
+}
@@ -0,0 +1,96 @@
+package ctxerrors
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslator(t *testing.T) {
+	errUserNotFound := errors.New("user not found") //nolint:err113
+
+	notFound := PublicError{Code: "user_not_found", Message: "No such user."}
+	missingFile := PublicError{Code: "missing_file", Message: "The file is gone."}
+	badRequest := PublicError{Code: "bad_request", Message: "Invalid request."}
+	gone := PublicError{Code: "gone"}
+
+	translator := Translator{Rules: []TranslationRule{
+		{Is: errUserNotFound, Public: notFound},
+		{As: MatchType[*fs.PathError](), Kind: KindNotFound, Public: missingFile},
+		{Kind: KindInvalidArgument, Public: badRequest},
+		{Kind: KindNotFound, Public: gone},
+	}}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected PublicError
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: PublicError{},
+		},
+		{
+			name:     "sentinel",
+			err:      Wrap(errUserNotFound, "load profile"),
+			expected: notFound,
+		},
+		{
+			name:     "sentinel in a joined error",
+			err:      errors.Join(New("audit failed"), Wrap(errUserNotFound, "load profile")),
+			expected: notFound,
+		},
+		{
+			name:     "type and classified kind",
+			err:      Wrap(&fs.PathError{Op: "open", Path: "/data/report.csv", Err: os.ErrNotExist}, "load report"),
+			expected: missingFile,
+		},
+		{
+			name:     "type without the kind",
+			err:      Wrap(&fs.PathError{Op: "open", Path: "/data/report.csv", Err: os.ErrPermission}, "load report"),
+			expected: DefaultPublicError(),
+		},
+		{
+			name:     "kind",
+			err:      WithKind(New("page size too big"), KindInvalidArgument),
+			expected: badRequest,
+		},
+		{
+			name:     "later rule",
+			err:      WithKind(New("no such order"), KindNotFound),
+			expected: gone,
+		},
+		{
+			name:     "no rule matches",
+			err:      New("database on fire"),
+			expected: DefaultPublicError(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, translator.Translate(tc.err))
+		})
+	}
+
+	t.Run("fallback", func(t *testing.T) {
+		fallback := PublicError{Code: "oops", Message: "Something went wrong."}
+
+		require.Equal(t, fallback, Translator{Fallback: fallback}.Translate(New("database on fire")))
+	})
+
+	t.Run("catch-all rule", func(t *testing.T) {
+		translator := Translator{Rules: []TranslationRule{{Public: gone}}}
+
+		require.Equal(t, gone, translator.Translate(New("database on fire")))
+	})
+}
+
+func TestPublicErrorError(t *testing.T) {
+	require.Equal(t, "No such user.", PublicError{Code: "user_not_found", Message: "No such user."}.Error())
+	require.Equal(t, "gone", PublicError{Code: "gone"}.Error())
+}
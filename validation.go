@@ -0,0 +1,113 @@
+package ctxerrors
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// FieldValidationField is the field key the name of the field a
+// ValidationErrors violation is about is stored under.
+const FieldValidationField = "field"
+
+// ValidationErrors collects the violations found validating input, each a
+// *CTXError with its own message, field name and location, so a handler can
+// report them all at once instead of bailing out on the first:
+//
+//	var violations ctxerrors.ValidationErrors
+//	if req.Email == "" {
+//		violations.Add("email", "is required")
+//	}
+//	if req.Age < 0 {
+//		violations.Addf("age", "must not be negative, got %d", req.Age)
+//	}
+//	if err := violations.Err(); err != nil {
+//		return err
+//	}
+//
+// The zero value is ready to use. It's not safe for concurrent use.
+type ValidationErrors struct {
+	violations []error
+}
+
+// Add records a violation of field with message, located at the caller.
+func (v *ValidationErrors) Add(field, message string) {
+	// Skip Add() and newLayer() to get user's caller
+	framesToSkip := 2
+
+	v.add(field, newLayer(nil, message, framesToSkip))
+}
+
+// Addf records a violation of field with a formatted message, %w included,
+// located at the caller.
+func (v *ValidationErrors) Addf(field, format string, args ...any) {
+	// Skip Addf() and newLayer() to get user's caller
+	framesToSkip := 2
+
+	message, refs := formatMessage(format, args...)
+
+	violation := newLayer(nil, message, framesToSkip)
+	violation.refs = refs
+
+	v.add(field, violation)
+}
+
+// add records violation, a layer still being created, as being about field.
+func (v *ValidationErrors) add(field string, violation *CTXError) {
+	v.violations = append(v.violations, finishLayer(violation, map[string]any{FieldValidationField: field}))
+}
+
+// Empty reports whether no violations were recorded.
+func (v *ValidationErrors) Empty() bool {
+	return len(v.violations) == 0
+}
+
+// Len returns how many violations were recorded.
+func (v *ValidationErrors) Len() int {
+	return len(v.violations)
+}
+
+// Err returns the violations as one error, or nil if there are none: a layer
+// located at the caller saying how many there are, classified as
+// KindInvalidArgument, wrapping the violations joined with errors.Join.
+func (v *ValidationErrors) Err() error {
+	if v.Empty() {
+		return nil
+	}
+
+	// Skip Err() and wrap() to get user's caller
+	framesToSkip := 2
+
+	message := "validation failed: " + strconv.Itoa(len(v.violations)) + " violation"
+	if len(v.violations) > 1 {
+		message += "s"
+	}
+
+	return wrap(errors.Join(v.violations...), message, framesToSkip, map[string]any{FieldKind: KindInvalidArgument})
+}
+
+// Map returns the violations' messages by field name, for API responses.
+// Messages of violations of the same field are joined with "; " in the order
+// they were recorded. It returns nil if there are none.
+func (v *ValidationErrors) Map() map[string]string {
+	if v.Empty() {
+		return nil
+	}
+
+	messages := make(map[string][]string, len(v.violations))
+
+	for _, violation := range v.violations {
+		layer, _ := asCTXError(violation)
+		field, _ := layer.fields[FieldValidationField].(string)
+
+		messages[field] = append(messages[field], layer.msg())
+	}
+
+	fields := make(map[string]string, len(messages))
+
+	for field, fieldMessages := range messages {
+		fields[field] = strings.Join(fieldMessages, "; ")
+	}
+
+	return fields
+}
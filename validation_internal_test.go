@@ -0,0 +1,66 @@
+package ctxerrors
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationErrors(t *testing.T) {
+	var violations ValidationErrors
+
+	require.True(t, violations.Empty())
+	require.Zero(t, violations.Len())
+	require.NoError(t, violations.Err())
+	require.Nil(t, violations.Map())
+
+	violations.Add("email", "is required")
+	violations.Addf("age", "must not be negative, got %d", -3)
+	violations.Addf("email", "can't be read: %w", io.ErrUnexpectedEOF)
+
+	require.False(t, violations.Empty())
+	require.Equal(t, 3, violations.Len())
+
+	require.Equal(t, map[string]string{
+		"email": "is required; can't be read: unexpected EOF",
+		"age":   "must not be negative, got -3",
+	}, violations.Map())
+
+	err := violations.Err()
+	require.Error(t, err)
+	require.Equal(t, KindInvalidArgument, KindOf(err))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Contains(t, err.Error(), "validation failed: 3 violations")
+
+	var outer *CTXError
+
+	require.True(t, errors.As(err, &outer))
+	require.Contains(t, outer.FuncName(), "TestValidationErrors")
+
+	var fields []string
+
+	walk(err, func(current error) {
+		layer, ok := asCTXError(current)
+		if !ok || layer == outer {
+			return
+		}
+
+		require.Contains(t, layer.FuncName(), "TestValidationErrors")
+
+		field, _ := layer.fields[FieldValidationField].(string)
+		fields = append(fields, field)
+	})
+
+	require.Equal(t, []string{"email", "age", "email"}, fields)
+
+	t.Run("single violation", func(t *testing.T) {
+		var violations ValidationErrors
+
+		violations.Add("name", "is too long")
+
+		require.Contains(t, violations.Err().Error(), "validation failed: 1 violation")
+		require.NotContains(t, violations.Err().Error(), "violations")
+	})
+}
@@ -0,0 +1,89 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VetMode says what SetVet does about a wrap adding no context.
+type VetMode int
+
+const (
+	// VetFail reports it to the TestingT as a test failure and carries on.
+	// This is the default.
+	VetFail VetMode = iota
+	// VetPanic panics with the report instead, so the stack shows exactly
+	// which code path made the wrap.
+	VetPanic
+)
+
+// SetVet makes every wrap that creates a layer adding no context, an empty
+// message and no fields (kinds and codes included), frame notes or origin,
+// get reported to t as SetVetMode says for the rest of t, so teams can
+// enforce that wrapping adds something a reader can use rather than noise.
+// Fields added by enrich hooks and field providers count. The previous
+// behavior is restored when t finishes. Like the other settings it's
+// package-wide, so don't combine it with t.Parallel.
+func SetVet(t TestingT) {
+	t.Helper()
+
+	previous := currentConfig().vet
+
+	updateConfig(func(c *config) {
+		c.vet = t
+	})
+
+	t.Cleanup(func() {
+		updateConfig(func(c *config) {
+			c.vet = previous
+		})
+	})
+}
+
+// SetVetMode sets what SetVet does about wraps adding no context for the rest
+// of t. The default is VetFail. The previous mode is restored when t finishes.
+func SetVetMode(t TestingT, mode VetMode) {
+	t.Helper()
+
+	previous := currentConfig().vetMode
+
+	updateConfig(func(c *config) {
+		c.vetMode = mode
+	})
+
+	t.Cleanup(func() {
+		updateConfig(func(c *config) {
+			c.vetMode = previous
+		})
+	})
+}
+
+// vetLayer reports layer, which a wrap has just created, to the SetVet
+// TestingT if it adds no context and to the SetWarningHook hook if it follows
+// a discouraged pattern.
+func vetLayer(cfg *config, layer *CTXError) {
+	if cfg.warningHook != nil {
+		warnWrap(cfg.warningHook, cfg.warnDepth, layer)
+	}
+
+	t := cfg.vet
+	if t == nil || layer.addsContext() {
+		return
+	}
+
+	if cfg.vetMode == VetPanic {
+		panic(fmt.Sprintf("ctxerrors: wrap at %s:%d in %s adds no context, give it a message or fields",
+			layer.file, layer.line, layer.funcName))
+	}
+
+	t.Errorf("ctxerrors: wrap at %s:%d in %s adds no context, give it a message or fields",
+		layer.file, layer.line, layer.funcName)
+}
+
+// addsContext reports whether the layer carries anything a reader can use
+// besides its location: a message, fields, frame notes or an origin. A lazy
+// message counts without being formatted.
+func (e *CTXError) addsContext() bool {
+	return e.lazy != nil || strings.TrimSpace(e.message) != "" ||
+		len(e.fields) > 0 || len(e.notes) > 0 || len(e.origin) > 0
+}
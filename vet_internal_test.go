@@ -0,0 +1,109 @@
+package ctxerrors
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVet(t *testing.T) {
+	recorder := &recordingT{}
+
+	SetVet(recorder)
+	t.Cleanup(func() {
+		updateConfig(func(c *config) {
+			c.vet = nil
+		})
+	})
+
+	t.Run("reports wraps adding no context", func(t *testing.T) {
+		recorder.errors = nil
+
+		err := Wrap(io.EOF, "")
+		layer, _ := asCTXError(err)
+
+		require.ErrorIs(t, err, io.EOF)
+		require.Equal(t, []string{
+			"ctxerrors: wrap at " + layer.file + ":" + strconv.Itoa(layer.line) + " in " + layer.funcName +
+				" adds no context, give it a message or fields",
+		}, recorder.errors)
+
+		_ = Wrapf(io.EOF, "  ")
+		_ = WrapCtx(context.Background(), io.EOF, "")
+		_ = WrapSkip(io.EOF, 0, "")
+		_ = WrapAt(io.EOF, "", "main.go", 12, "main.main")
+		_ = WrapFrom(io.EOF, io.ReadAll, "")
+		_ = WrapDepth(io.EOF, 2, "")
+		require.Len(t, recorder.errors, 7)
+	})
+
+	t.Run("accepts wraps adding context", func(t *testing.T) {
+		recorder.errors = nil
+
+		_ = Wrap(io.EOF, "read config")
+		_ = Wrapf(io.EOF, "read %s", "config")
+		_ = WithKind(io.EOF, KindNotFound)
+		_ = WithField(io.EOF, "path", "/etc/app.yaml")
+		_ = WrapCtx(StartOp(context.Background(), "reindex"), io.EOF, "")
+		_ = AnnotateFrame(io.EOF, 0, "inside retry loop")
+		_ = WrapLazyf(io.EOF, "read %s", "config")
+
+		require.Empty(t, recorder.errors)
+	})
+
+	t.Run("panics in panic mode", func(t *testing.T) {
+		recorder.errors = nil
+
+		SetVetMode(t, VetPanic)
+
+		require.PanicsWithValue(t, "ctxerrors: wrap at main.go:12 in main.main adds no context, give it a message or fields",
+			func() { _ = WrapAt(io.EOF, "", "main.go", 12, "main.main") })
+		require.NotPanics(t, func() { _ = Wrap(io.EOF, "read config") })
+		require.Empty(t, recorder.errors)
+	})
+
+	t.Run("enrich hook fields count", func(t *testing.T) {
+		recorder.errors = nil
+
+		unregister := RegisterEnrichHook(EnrichHookFunc(func(*CTXError) map[string]any {
+			return map[string]any{"host": "web-1"}
+		}))
+		t.Cleanup(unregister)
+
+		_ = Wrap(io.EOF, "")
+
+		require.Empty(t, recorder.errors)
+	})
+}
+
+func TestSetVetRestores(t *testing.T) {
+	t.Run("vetted", func(t *testing.T) {
+		SetVet(t)
+
+		require.NotNil(t, currentConfig().vet)
+	})
+
+	require.Nil(t, currentConfig().vet)
+
+	// Not reported once the test that turned it on is done
+	require.ErrorIs(t, Wrap(io.EOF, ""), io.EOF)
+
+	t.Run("nested", func(t *testing.T) {
+		SetVet(t)
+		SetVetMode(t, VetPanic)
+
+		t.Run("inner", func(t *testing.T) {
+			SetVet(t)
+			SetVetMode(t, VetFail)
+		})
+
+		require.Same(t, t, currentConfig().vet)
+		require.Equal(t, VetPanic, currentConfig().vetMode)
+	})
+
+	require.Nil(t, currentConfig().vet)
+	require.Equal(t, VetFail, currentConfig().vetMode)
+}
@@ -0,0 +1,120 @@
+package ctxerrors
+
+import "time"
+
+// ErrorView is a read-only view of a *CTXError layer, for handing error
+// details to plugins, templates and other code that should be able to look at
+// them but not build on them. It has getters only and isn't an error itself,
+// so it can't be wrapped, annotated or returned in place of the original, and
+// everything it returns is a copy. The zero ErrorView is empty.
+type ErrorView struct {
+	layer *CTXError
+}
+
+// View returns a read-only view of the outermost *CTXError layer in err's
+// chain, following Unwrap() error, or false if there's none.
+func View(err error) (ErrorView, bool) {
+	layers := ctxLayers(err)
+	if len(layers) == 0 {
+		return ErrorView{}, false
+	}
+
+	return ErrorView{layer: layers[0]}, true
+}
+
+// View returns a read-only view of this layer.
+func (e *CTXError) View() ErrorView {
+	return ErrorView{layer: e}
+}
+
+// IsZero reports whether the view is empty.
+func (v ErrorView) IsZero() bool {
+	return v.layer == nil
+}
+
+// Text returns the Error() text of the layer and everything below it.
+func (v ErrorView) Text() string {
+	return v.layer.Error()
+}
+
+// Message returns the context message of the layer only.
+func (v ErrorView) Message() string {
+	return v.layer.Message()
+}
+
+// File returns the file the layer was created in.
+func (v ErrorView) File() string {
+	return v.layer.File()
+}
+
+// Line returns the line the layer was created on.
+func (v ErrorView) Line() int {
+	return v.layer.Line()
+}
+
+// FuncName returns the function the layer was created in.
+func (v ErrorView) FuncName() string {
+	return v.layer.FuncName()
+}
+
+// ID returns the instance ID of the layer, empty unless SetInstanceIDs is on.
+func (v ErrorView) ID() string {
+	return v.layer.ID()
+}
+
+// Time returns when the layer was created, the zero time unless
+// SetTimestamps is on.
+func (v ErrorView) Time() time.Time {
+	return v.layer.Time()
+}
+
+// Fields returns a copy of the fields of the layer only.
+func (v ErrorView) Fields() map[string]any {
+	return v.layer.Fields()
+}
+
+// Kind returns the KindOf the layer and everything below it.
+func (v ErrorView) Kind() Kind {
+	if v.layer == nil {
+		return KindUnknown
+	}
+
+	return KindOf(v.layer)
+}
+
+// Callers returns the frames captured above the location of the layer.
+func (v ErrorView) Callers() []Frame {
+	return v.layer.Callers()
+}
+
+// Origin returns the Origin LinkOrigin attached to the layer, if any.
+func (v ErrorView) Origin() Origin {
+	return v.layer.Origin()
+}
+
+// Next returns a view of the next *CTXError layer down the chain, following
+// Unwrap() error, or false if there's none.
+func (v ErrorView) Next() (ErrorView, bool) {
+	if v.layer == nil {
+		return ErrorView{}, false
+	}
+
+	return View(v.layer.err)
+}
+
+// Layers returns views of the layer and every *CTXError layer below it,
+// following Unwrap() error, outermost first.
+func (v ErrorView) Layers() []ErrorView {
+	if v.layer == nil {
+		return nil
+	}
+
+	layers := ctxLayers(v.layer)
+	views := make([]ErrorView, 0, len(layers))
+
+	for _, layer := range layers {
+		views = append(views, ErrorView{layer: layer})
+	}
+
+	return views
+}
@@ -0,0 +1,67 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestView(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+	inner := WithField(Wrap(baseErr, "query users"), "table", "users")
+	err := WithKind(Wrap(fmt.Errorf("retry: %w", inner), "load user"), KindUnavailable)
+
+	view, ok := View(err)
+	require.True(t, ok)
+	require.False(t, view.IsZero())
+
+	layer, _ := asCTXError(err)
+
+	require.Equal(t, err.Error(), view.Text())
+	require.Equal(t, "load user", view.Message())
+	require.Equal(t, layer.File(), view.File())
+	require.Equal(t, layer.Line(), view.Line())
+	require.Equal(t, layer.FuncName(), view.FuncName())
+	require.Equal(t, KindUnavailable, view.Kind())
+
+	next, ok := view.Next()
+	require.True(t, ok)
+	require.Equal(t, "query users", next.Message())
+	require.Equal(t, map[string]any{"table": "users"}, next.Fields())
+
+	_, ok = next.Next()
+	require.False(t, ok)
+
+	// Changing what a view returns doesn't touch the error
+	next.Fields()["table"] = "orders"
+	require.Equal(t, "users", Fields(err)["table"])
+
+	require.Len(t, view.Layers(), 2)
+
+	var tmpl strings.Builder
+	require.NoError(t, template.Must(template.New("error").Parse(
+		`{{range .Layers}}{{.Message}} ({{.Kind}});{{end}}`)).Execute(&tmpl, view))
+	require.Equal(t, "load user (unavailable);query users ();", tmpl.String())
+
+	t.Run("no ctxerror", func(t *testing.T) {
+		view, ok := View(baseErr)
+
+		require.False(t, ok)
+		require.True(t, view.IsZero())
+		require.Empty(t, view.Text())
+		require.Empty(t, view.Message())
+		require.Equal(t, KindUnknown, view.Kind())
+		require.Nil(t, view.Layers())
+
+		_, ok = view.Next()
+		require.False(t, ok)
+	})
+
+	t.Run("method", func(t *testing.T) {
+		require.Equal(t, view, layer.View())
+	})
+}
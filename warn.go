@@ -0,0 +1,98 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// WarningKind says which discouraged pattern a Warning is about.
+type WarningKind string
+
+// Patterns SetWarningHook warns about.
+const (
+	// WarnEmptyMessage is a wrap with an empty message and no fields, frame
+	// notes or origin either, which adds a location but nothing a reader can
+	// use.
+	WarnEmptyMessage WarningKind = "empty_message"
+	// WarnRepeatedWrap is a wrap of a layer created at the same location,
+	// usually a retry loop or recursion wrapping its own error again.
+	WarnRepeatedWrap WarningKind = "repeated_wrap"
+	// WarnDepthExceeded is a wrap making a chain deeper than SetWarnDepth
+	// allows.
+	WarnDepthExceeded WarningKind = "depth_exceeded"
+)
+
+// Warning is a discouraged pattern SetWarningHook reports.
+type Warning struct {
+	Kind    WarningKind
+	Message string    // What's wrong and where, for logging as is
+	Err     *CTXError // The layer the wrap created, to be treated as read-only
+}
+
+// WarningHook is called with every Warning, see SetWarningHook.
+type WarningHook func(warning Warning)
+
+// SetWarningHook makes every wrap call hook whenever it creates a layer in a
+// way the package considers harmful: adding no context, on top of a layer
+// from the same location, or past the SetWarnDepth limit. It's a soft
+// deprecation mechanism for large codebases converging on good usage, e.g.
+// logging warnings in staging or counting them in metrics, unlike SetVet it
+// never fails anything. A panicking hook is logged and ignored. Pass nil to
+// stop warning, which is the default.
+func SetWarningHook(hook WarningHook) {
+	updateConfig(func(c *config) {
+		c.warningHook = hook
+	})
+}
+
+// SetWarnDepth sets how many *CTXError layers stacked directly on each other a
+// chain can have before wraps adding more get a WarnDepthExceeded warning.
+// Zero or less means no limit, which is the default.
+func SetWarnDepth(depth int) {
+	updateConfig(func(c *config) {
+		c.warnDepth = max(depth, 0)
+	})
+}
+
+// warnWrap calls the SetWarningHook hook for every discouraged pattern layer,
+// which a wrap has just created, follows.
+func warnWrap(hook WarningHook, maxDepth int, layer *CTXError) {
+	location := fmt.Sprintf("%s:%d in %s", layer.file, layer.line, layer.funcName)
+
+	if !layer.addsContext() {
+		callWarningHook(hook, Warning{
+			Kind:    WarnEmptyMessage,
+			Message: "ctxerrors: wrap at " + location + " has an empty message",
+			Err:     layer,
+		})
+	}
+
+	if inner, ok := asCTXError(layer.err); ok && layer.file != "" &&
+		inner.file == layer.file && inner.line == layer.line && inner.funcName == layer.funcName {
+		callWarningHook(hook, Warning{
+			Kind:    WarnRepeatedWrap,
+			Message: "ctxerrors: wrap at " + location + " wraps an error wrapped at the same location",
+			Err:     layer,
+		})
+	}
+
+	if depth := layer.depth(); maxDepth > 0 && depth > maxDepth {
+		callWarningHook(hook, Warning{
+			Kind:    WarnDepthExceeded,
+			Message: fmt.Sprintf("ctxerrors: wrap at %s makes the chain %d layers deep, over %d", location, depth, maxDepth),
+			Err:     layer,
+		})
+	}
+}
+
+// callWarningHook calls hook, recovering from any panic in it.
+func callWarningHook(hook WarningHook, warning Warning) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Warning hook panicked", "panic", r)
+			countActivity(&activity.hookFailures)
+		}
+	}()
+
+	hook(warning)
+}
@@ -0,0 +1,105 @@
+package ctxerrors
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWarningHook(t *testing.T) {
+	var warnings []Warning
+
+	SetWarningHook(func(warning Warning) {
+		warnings = append(warnings, warning)
+	})
+	t.Cleanup(func() {
+		SetWarningHook(nil)
+		SetWarnDepth(0)
+	})
+
+	kinds := func() []WarningKind {
+		result := make([]WarningKind, 0, len(warnings))
+		for _, warning := range warnings {
+			result = append(result, warning.Kind)
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		depth    int
+		wrap     func() error
+		expected []WarningKind
+	}{
+		{
+			name:     "good wrap",
+			wrap:     func() error { return Wrap(io.EOF, "read config") },
+			expected: []WarningKind{},
+		},
+		{
+			name:     "empty message",
+			wrap:     func() error { return Wrapf(io.EOF, " ") },
+			expected: []WarningKind{WarnEmptyMessage},
+		},
+		{
+			name: "repeated wrap",
+			wrap: func() error {
+				err := io.EOF
+				for range 2 {
+					err = Wrap(err, "retry")
+				}
+
+				return err
+			},
+			expected: []WarningKind{WarnRepeatedWrap},
+		},
+		{
+			name:  "depth exceeded",
+			depth: 2,
+			wrap: func() error {
+				err := Wrap(io.EOF, "read")
+				err = Wrap(err, "load")
+
+				return Wrap(err, "start")
+			},
+			expected: []WarningKind{WarnDepthExceeded},
+		},
+		{
+			name: "no depth limit",
+			wrap: func() error {
+				err := Wrap(io.EOF, "read")
+				err = Wrap(err, "load")
+
+				return Wrap(err, "start")
+			},
+			expected: []WarningKind{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings = nil
+			SetWarnDepth(tt.depth)
+
+			err := tt.wrap()
+
+			require.Equal(t, tt.expected, kinds())
+
+			for _, warning := range warnings {
+				require.Same(t, err, warning.Err)
+				require.Contains(t, warning.Message, warning.Err.file)
+			}
+		})
+	}
+}
+
+func TestSetWarningHookPanic(t *testing.T) {
+	SetWarningHook(func(Warning) { panic("boom") })
+	t.Cleanup(func() { SetWarningHook(nil) })
+
+	require.NotPanics(t, func() {
+		require.ErrorIs(t, Wrap(io.EOF, ""), io.EOF)
+	})
+}
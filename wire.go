@@ -0,0 +1,359 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// WireVersion is the version of the format Marshal writes. Unmarshal reads it
+// and, once there are older ones, at least the one before it, so services
+// running different ctxerrors versions can keep exchanging chains.
+const WireVersion = 1
+
+// ErrUnsupportedWireVersion is returned by Unmarshal for data in a format
+// version it can't read.
+var ErrUnsupportedWireVersion = errors.New("unsupported wire format version")
+
+// wireHeader is the part of every format version that says which one it is.
+type wireHeader struct {
+	Version int `json:"v"`
+}
+
+// wireChain is version 1 of the wire format.
+type wireChain struct {
+	Version     int         `json:"v"`
+	Fingerprint string      `json:"fingerprint,omitempty"`
+	StackHash   string      `json:"stack_hash,omitempty"`
+	Layers      []wireLayer `json:"layers"` // Outermost first
+}
+
+// wireLayer is one error in a wireChain. A layer with Joined members is the
+// last of its chain.
+type wireLayer struct {
+	Type     string                     `json:"type,omitempty"` // Empty for *CTXError
+	Message  string                     `json:"message,omitempty"`
+	File     string                     `json:"file,omitempty"`
+	Line     int                        `json:"line,omitempty"`
+	FuncName string                     `json:"func,omitempty"`
+	ID       string                     `json:"id,omitempty"`
+	Fields   map[string]json.RawMessage `json:"fields,omitempty"`
+	Details  map[string]json.RawMessage `json:"details,omitempty"` // CauseDetails of non-*CTXError layers
+	Stack    []wireFrame                `json:"stack,omitempty"`
+	Joined   [][]wireLayer              `json:"joined,omitempty"`
+}
+
+// wireFrame is a Frame in a wireLayer's stack.
+type wireFrame struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	FuncName string `json:"func,omitempty"`
+}
+
+// RemoteError stands in for an error Unmarshal can't rebuild as it was, which
+// is every error that isn't a *CTXError or a joined error.
+type RemoteError struct {
+	Type    string // Go type of the original error, e.g. "*fs.PathError"
+	Message string // Message of the original error without its cause's
+	Err     error  // The original error's cause, if any
+
+	// Details are the original error's CauseDetails, if it had any, as JSON
+	// decodes them.
+	Details map[string]any
+}
+
+// Error returns the message followed by the cause's, separated by ": ".
+func (e *RemoteError) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Err.Error()
+	default:
+		return e.Message + ": " + e.Err.Error()
+	}
+}
+
+// Unwrap returns the cause.
+func (e *RemoteError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON encodes the chain starting at e like Marshal does.
+func (e *CTXError) MarshalJSON() ([]byte, error) {
+	return Marshal(e)
+}
+
+// Marshaler encodes error chains like Marshal does but with its own settings.
+type Marshaler struct {
+	// MaxBytes caps the size of the encoded chain, for transports with hard
+	// limits like headers or queue messages. What doesn't fit is given up in
+	// order of least diagnostic value: captured callers first, outermost
+	// layers' before the origin's, then fields, biggest first, then the
+	// details of other errors, then the longest messages get cut with a
+	// "…(+N bytes)" marker. Zero means no limit.
+	MaxBytes int
+
+	// Debug includes the fields WithDebugField and WithPayload attached,
+	// computing them, the way DebugFields does, for debug logs and tools that
+	// need the full picture. Error() leaves them out either way.
+	Debug bool
+}
+
+// Marshal encodes err's chain as JSON in the WireVersion format, for sending
+// it to another service: its Fingerprint and StackHash for aggregation tools
+// to group by, the message, location, ID, fields and captured callers of
+// every *CTXError layer, the type, own message and CauseDetails of every
+// other error and the members of joined errors. Fields are handed out like
+// Fields does, so marshalers and redaction apply, and values JSON can't
+// encode are sent as fmt.Sprint renders them.
+func Marshal(err error) ([]byte, error) {
+	return Marshaler{}.Marshal(err)
+}
+
+// Marshal encodes err's chain like the package-level Marshal does, within
+// MaxBytes if set and with the debug fields if Debug is. It returns
+// ErrOverBudget if even the chain stripped down to its structure doesn't fit.
+func (m Marshaler) Marshal(err error) ([]byte, error) {
+	chain := wireChain{
+		Version:     WireVersion,
+		Fingerprint: Fingerprint(err),
+		StackHash:   StackHash(err),
+		Layers:      encodeLayers(err, m.Debug),
+	}
+
+	data, marshalErr := json.Marshal(chain)
+	if marshalErr != nil {
+		return nil, Wrap(marshalErr, "failed to marshal error chain")
+	}
+
+	if m.MaxBytes <= 0 || len(data) <= m.MaxBytes {
+		return data, nil
+	}
+
+	return fitBudget(&chain, m.MaxBytes)
+}
+
+// Unmarshal rebuilds the chain Marshal encoded, in the current or an earlier
+// supported format version: *CTXError layers come back as *CTXError with the
+// same message, location, ID, fields and callers, joined errors as
+// errors.Join does them and every other error as a *RemoteError. Field values
+// come back as JSON decodes them, e.g. numbers as float64, except for the
+// package's own kind, retry and attempt fields. It returns nil for a chain
+// without layers.
+func Unmarshal(data []byte) (error, error) { //nolint:revive
+	var header wireHeader
+
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, Wrap(err, "failed to unmarshal error chain")
+	}
+
+	switch header.Version {
+	case 1:
+		var chain wireChain
+
+		if err := json.Unmarshal(data, &chain); err != nil {
+			return nil, Wrap(err, "failed to unmarshal error chain")
+		}
+
+		return decodeLayers(chain.Layers)
+	default:
+		return nil, Wrapf(ErrUnsupportedWireVersion, "version %d", header.Version)
+	}
+}
+
+// encodeLayers returns the wire layers of err's chain, outermost first, with
+// the debug fields of its *CTXError layers if debug is set.
+func encodeLayers(err error, debug bool) []wireLayer {
+	var layers []wireLayer
+
+	for err != nil {
+		if joined, ok := members(err); ok {
+			layer := wireLayer{Type: fmt.Sprintf("%T", err), Joined: make([][]wireLayer, 0, len(joined))}
+			for _, member := range joined {
+				layer.Joined = append(layer.Joined, encodeLayers(member, debug))
+			}
+
+			return append(layers, layer)
+		}
+
+		if ctxErr, ok := asCTXError(err); ok {
+			layers = append(layers, encodeCTXLayer(ctxErr, debug))
+			err = ctxErr.err
+
+			continue
+		}
+
+		// Rewrite's cause goes out as its rewritten text alone
+		if rewritten, ok := err.(*rewrittenError); ok { //nolint:errorlint
+			return append(layers, wireLayer{Type: fmt.Sprintf("%T", rewritten.err), Message: rewritten.message})
+		}
+
+		layers = append(layers, wireLayer{
+			Type:    fmt.Sprintf("%T", err),
+			Message: ownMessage(err),
+			Details: encodeValues(causeDetails(err)),
+		})
+
+		wrapper, ok := err.(interface{ Unwrap() error }) //nolint:errorlint
+		if !ok {
+			break
+		}
+
+		err = wrapper.Unwrap()
+	}
+
+	return layers
+}
+
+// encodeCTXLayer returns the wire layer of a single *CTXError, with its debug
+// fields if debug is set.
+func encodeCTXLayer(e *CTXError, debug bool) wireLayer {
+	layer := wireLayer{
+		Message:  e.msg(),
+		File:     e.file,
+		Line:     e.line,
+		FuncName: e.funcName,
+		ID:       e.id,
+	}
+
+	layer.Fields = encodeValues(e.renderedFields(debug))
+
+	for _, frame := range e.Callers() {
+		layer.Stack = append(layer.Stack, wireFrame(frame))
+	}
+
+	return layer
+}
+
+// encodeValues returns the JSON encodings of values, falling back to how
+// fmt.Sprint renders the ones JSON can't encode, or nil if there are none.
+func encodeValues(values map[string]any) map[string]json.RawMessage {
+	if len(values) == 0 {
+		return nil
+	}
+
+	encoded := make(map[string]json.RawMessage, len(values))
+
+	for key, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			data, _ = json.Marshal(fmt.Sprint(value)) //nolint:errchkjson
+		}
+
+		encoded[key] = data
+	}
+
+	return encoded
+}
+
+// decodeLayers rebuilds the chain of wire layers, outermost first.
+func decodeLayers(layers []wireLayer) (error, error) { //nolint:revive
+	var inner error
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+
+		switch {
+		case len(layer.Joined) > 0:
+			joined := make([]error, 0, len(layer.Joined))
+
+			for _, memberLayers := range layer.Joined {
+				member, err := decodeLayers(memberLayers)
+				if err != nil {
+					return nil, err
+				}
+
+				joined = append(joined, member)
+			}
+
+			inner = errors.Join(joined...)
+		case layer.Type == "":
+			ctxErr, err := decodeCTXLayer(layer, inner)
+			if err != nil {
+				return nil, err
+			}
+
+			inner = ctxErr
+		default:
+			remote := &RemoteError{Type: layer.Type, Message: layer.Message, Err: inner}
+
+			if len(layer.Details) > 0 {
+				remote.Details = make(map[string]any, len(layer.Details))
+
+				for key, data := range layer.Details {
+					var value any
+
+					if err := json.Unmarshal(data, &value); err != nil {
+						return nil, Wrapf(err, "failed to unmarshal detail %q", key)
+					}
+
+					remote.Details[key] = value
+				}
+			}
+
+			inner = remote
+		}
+	}
+
+	return inner, nil
+}
+
+// decodeCTXLayer rebuilds a single *CTXError wrapping inner.
+func decodeCTXLayer(layer wireLayer, inner error) (*CTXError, error) {
+	ctxErr := &CTXError{
+		err:      inner,
+		message:  layer.Message,
+		file:     layer.File,
+		line:     layer.Line,
+		funcName: layer.FuncName,
+		id:       layer.ID,
+	}
+
+	if len(layer.Fields) > 0 {
+		ctxErr.fields = make(map[string]any, len(layer.Fields))
+
+		for key, data := range layer.Fields {
+			var value any
+
+			if err := json.Unmarshal(data, &value); err != nil {
+				return nil, Wrapf(err, "failed to unmarshal field %q", key)
+			}
+
+			ctxErr.fields[key] = typedFieldValue(key, value)
+		}
+	}
+
+	for _, frame := range layer.Stack {
+		ctxErr.frames = append(ctxErr.frames, Frame(frame))
+	}
+
+	return ctxErr, nil
+}
+
+// typedFieldValue turns the JSON-decoded value of one of the package's own
+// fields back into the type its getters look for, so KindOf, RetryAfter and
+// Attempts work on decoded chains too.
+func typedFieldValue(key string, value any) any {
+	switch v := value.(type) {
+	case string:
+		if key == FieldKind {
+			return Kind(v)
+		}
+	case float64:
+		if v != math.Trunc(v) {
+			return value
+		}
+
+		switch key {
+		case FieldRetryAfter, FieldElapsed:
+			return time.Duration(v)
+		case FieldAttempt, FieldMaxAttempts:
+			return int(v)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,142 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	t.Cleanup(func() {
+		SetCallerDepth(0)
+		SetInstanceIDs(false)
+	})
+
+	SetCallerDepth(2)
+	SetInstanceIDs(true)
+
+	root := WithField(New("connection refused"), "retries", 3)
+	foreign := fmt.Errorf("dial db: %w", root)
+	joined := errors.Join(Wrap(io.EOF, "read header"), errors.New("plain")) //nolint:err113
+	retried := WithRetryAfter(WrapAttempt(Wrap(errors.Join(foreign, joined), "load user"), 2, 5), 3*time.Second)
+	original := WithKind(retried, KindUnavailable)
+
+	data, err := Marshal(original)
+	require.NoError(t, err)
+
+	decoded, err := Unmarshal(data)
+	require.NoError(t, err)
+
+	require.Equal(t, original.Error(), decoded.Error())
+	require.Equal(t, Messages(original), Messages(decoded))
+	require.Equal(t, KindUnavailable, KindOf(decoded))
+	require.Len(t, Attempts(decoded), 1)
+
+	retryAfter, ok := RetryAfter(decoded)
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, retryAfter)
+
+	var originalRoot, decodedRoot *CTXError
+
+	require.True(t, errors.As(root, &originalRoot))
+	require.True(t, errors.As(decoded, &decodedRoot))
+
+	value, ok := FieldInt(decoded, "retries")
+	require.True(t, ok)
+	require.Equal(t, 3, value)
+
+	var remote *RemoteError
+
+	require.True(t, errors.As(decoded, &remote))
+	require.Equal(t, "*fmt.wrapError", remote.Type)
+	require.Equal(t, "dial db", remote.Message)
+
+	var innermost *CTXError
+
+	require.True(t, errors.As(remote.Err, &innermost))
+	require.Equal(t, originalRoot.File(), innermost.File())
+	require.Equal(t, originalRoot.Line(), innermost.Line())
+	require.Equal(t, originalRoot.FuncName(), innermost.FuncName())
+	require.Equal(t, originalRoot.ID(), innermost.ID())
+	require.Equal(t, originalRoot.Callers(), innermost.Callers())
+	require.Len(t, innermost.Callers(), 2)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	err := Wrap(errors.New("base error"), "outer") //nolint:err113
+
+	viaMethod, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+
+	direct, marshalErr := Marshal(err)
+	require.NoError(t, marshalErr)
+
+	require.JSONEq(t, string(direct), string(viaMethod))
+
+	var wire map[string]any
+
+	require.NoError(t, json.Unmarshal(direct, &wire))
+	require.InDelta(t, float64(WireVersion), wire["v"], 0)
+}
+
+func TestMarshalFields(t *testing.T) {
+	require.NoError(t, SetRedactedFields("password"))
+	t.Cleanup(func() { require.NoError(t, SetRedactedFields()) })
+
+	err := annotate(New("boom"), map[string]any{
+		"password": "hunter2",
+		"channel":  make(chan int),
+		"tags":     []string{"a", "b"},
+	}, 0)
+
+	data, marshalErr := Marshal(err)
+	require.NoError(t, marshalErr)
+
+	decoded, unmarshalErr := Unmarshal(data)
+	require.NoError(t, unmarshalErr)
+
+	fields := Fields(decoded)
+	require.Equal(t, RedactedValue, fields["password"])
+	require.IsType(t, "", fields["channel"])
+	require.Equal(t, []any{"a", "b"}, fields["tags"])
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		data, err := Marshal(nil)
+		require.NoError(t, err)
+
+		decoded, err := Unmarshal(data)
+		require.NoError(t, err)
+		require.NoError(t, decoded)
+	})
+
+	t.Run("unsupported versions", func(t *testing.T) {
+		for _, data := range []string{`{"v": 0, "layers": []}`, `{"layers": []}`, `{"v": 99}`} {
+			_, err := Unmarshal([]byte(data))
+			require.ErrorIs(t, err, ErrUnsupportedWireVersion, data)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := Unmarshal([]byte(`nope`))
+		require.Error(t, err)
+
+		_, err = Unmarshal([]byte(`{"v": 1, "layers": "nope"}`))
+		require.Error(t, err)
+	})
+}
+
+func TestRemoteError(t *testing.T) {
+	cause := errors.New("cause") //nolint:err113
+
+	require.Equal(t, "outer: cause", (&RemoteError{Message: "outer", Err: cause}).Error())
+	require.Equal(t, "cause", (&RemoteError{Err: cause}).Error())
+	require.Equal(t, "alone", (&RemoteError{Message: "alone"}).Error())
+	require.ErrorIs(t, &RemoteError{Message: "outer", Err: cause}, cause)
+}
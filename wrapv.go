@@ -0,0 +1,91 @@
+package ctxerrors
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+)
+
+// badKey is the key a value without one is recorded under, as slog does it.
+const badKey = "!BADKEY"
+
+// Wrapv wraps err like Wrap and takes alternating keys and values like
+// slog.Logger.Info, or slog.Attr values, which become both fields of the new
+// layer and a suffix of its message, so one call gives humans and machines
+// the same context:
+//
+//	ctxerrors.Wrapv(err, "failed to load user", "user_id", 42, "region", "eu")
+//	// failed to load user (user_id=42 region=eu)
+//
+// Values in the message are rendered after RegisterFieldMarshaler marshalers
+// and SetRedactedFields redaction, quoted when they hold spaces, quotes or
+// anything else logfmt wouldn't take bare, while the fields keep the values
+// themselves. A value without a key goes under "!BADKEY" like in slog.
+func Wrapv(err error, message string, kv ...any) error {
+	// Skip Wrapv() and wrap() to get user's caller
+	framesToSkip := 2
+
+	fields := kvFields(kv)
+	if len(fields) == 0 {
+		return wrap(err, message, framesToSkip, nil)
+	}
+
+	return wrap(err, message+" ("+kvSuffix(kv)+")", framesToSkip, fields)
+}
+
+// kvFields returns the fields kv holds.
+func kvFields(kv []any) map[string]any {
+	if len(kv) == 0 {
+		return nil
+	}
+
+	return maps.Collect(kvPairs(kv))
+}
+
+// kvSuffix renders kv as space-separated key=value pairs in the order given.
+func kvSuffix(kv []any) string {
+	cfg := currentConfig()
+	pairs := make([]string, 0, len(kv))
+
+	for key, value := range kvPairs(kv) {
+		rendered := RedactedValue
+		if !isRedacted(key, cfg.redactedFields) {
+			rendered = fmt.Sprint(cfg.marshalField(value))
+		}
+
+		pairs = append(pairs, key+"="+logfmtValue(rendered))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// kvPairs yields the keys and values of kv the way slog reads them.
+func kvPairs(kv []any) func(yield func(key string, value any) bool) {
+	return func(yield func(key string, value any) bool) {
+		for i := 0; i < len(kv); i++ {
+			var (
+				key   string
+				value any
+			)
+
+			switch current := kv[i].(type) {
+			case slog.Attr:
+				key, value = current.Key, current.Value.Any()
+			case string:
+				if i+1 == len(kv) {
+					key, value = badKey, current
+				} else {
+					key, value = current, kv[i+1]
+					i++
+				}
+			default:
+				key, value = badKey, current
+			}
+
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
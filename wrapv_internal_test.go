@@ -0,0 +1,88 @@
+package ctxerrors
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapv(t *testing.T) {
+	baseErr := errors.New("connection refused") //nolint:err113
+
+	testCases := []struct {
+		name            string
+		kv              []any
+		expectedMessage string
+		expectedFields  map[string]any
+	}{
+		{
+			name:            "no pairs",
+			kv:              nil,
+			expectedMessage: "load user",
+			expectedFields:  nil,
+		},
+		{
+			name:            "pairs in order",
+			kv:              []any{"user_id", 42, "region", "eu"},
+			expectedMessage: "load user (user_id=42 region=eu)",
+			expectedFields:  map[string]any{"user_id": 42, "region": "eu"},
+		},
+		{
+			name:            "quoted value",
+			kv:              []any{"name", "John Doe"},
+			expectedMessage: `load user (name="John Doe")`,
+			expectedFields:  map[string]any{"name": "John Doe"},
+		},
+		{
+			name:            "attr",
+			kv:              []any{slog.Int("attempt", 3), "region", "eu"},
+			expectedMessage: "load user (attempt=3 region=eu)",
+			expectedFields:  map[string]any{"attempt": int64(3), "region": "eu"},
+		},
+		{
+			name:            "dangling value",
+			kv:              []any{"user_id", 42, "orphan"},
+			expectedMessage: "load user (user_id=42 !BADKEY=orphan)",
+			expectedFields:  map[string]any{"user_id": 42, badKey: "orphan"},
+		},
+		{
+			name:            "non-string key",
+			kv:              []any{7, "user_id", 42},
+			expectedMessage: "load user (!BADKEY=7 user_id=42)",
+			expectedFields:  map[string]any{badKey: 7, "user_id": 42},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := Wrapv(baseErr, "load user", tc.kv...)
+
+			var ctxErr *CTXError
+
+			require.True(t, errors.As(actual, &ctxErr))
+			require.Equal(t, tc.expectedMessage, ctxErr.Message())
+			require.Equal(t, tc.expectedFields, ctxErr.Fields())
+			require.Contains(t, ctxErr.FuncName(), "TestWrapv")
+			require.ErrorIs(t, actual, baseErr)
+		})
+	}
+
+	t.Run("nil error", func(t *testing.T) {
+		require.NoError(t, Wrapv(nil, "load user", "user_id", 42))
+	})
+
+	t.Run("redacted value", func(t *testing.T) {
+		require.NoError(t, SetRedactedFields("password"))
+		t.Cleanup(func() { require.NoError(t, SetRedactedFields()) })
+
+		actual := Wrapv(baseErr, "login", "user", "bob", "password", "hunter2")
+
+		var ctxErr *CTXError
+
+		require.True(t, errors.As(actual, &ctxErr))
+		require.Equal(t, "login (user=bob password="+RedactedValue+")", ctxErr.Message())
+		require.NotContains(t, actual.Error(), "hunter2")
+	})
+}
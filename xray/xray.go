@@ -0,0 +1,100 @@
+// Package xray converts ctxerrors chains into AWS X-Ray segment cause objects,
+// so services instrumented with X-Ray can attach the full chain to a segment.
+package xray
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+// exceptionIDBytes is the size of an X-Ray exception ID, rendered as 16 hex digits.
+const exceptionIDBytes = 8
+
+// Cause is the cause object of an X-Ray segment or subsegment.
+type Cause struct {
+	WorkingDirectory string      `json:"working_directory"` //nolint:tagliatelle
+	Paths            []string    `json:"paths,omitempty"`
+	Exceptions       []Exception `json:"exceptions"`
+}
+
+// Exception is one error in the chain.
+type Exception struct {
+	ID      string       `json:"id"`
+	Message string       `json:"message"`
+	Type    string       `json:"type"`
+	Remote  bool         `json:"remote"`
+	Cause   string       `json:"cause,omitempty"`
+	Stack   []StackFrame `json:"stack,omitempty"`
+}
+
+// StackFrame is a single location.
+type StackFrame struct {
+	Path  string `json:"path"`
+	Line  int    `json:"line"`
+	Label string `json:"label"`
+}
+
+// ToCause maps err to an X-Ray cause. Every error in the chain becomes an
+// exception, outermost first, whose cause is the ID of the exception it wraps.
+// *CTXError layers carry their location as a single stack frame and their file
+// is listed in the cause's paths. It returns nil for a nil error.
+func ToCause(err error) *Cause {
+	if err == nil {
+		return nil
+	}
+
+	workingDirectory, _ := os.Getwd()
+
+	cause := &Cause{
+		WorkingDirectory: workingDirectory,
+		Paths:            nil,
+		Exceptions:       nil,
+	}
+
+	seenPaths := map[string]bool{}
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		exception := Exception{
+			ID:      newExceptionID(),
+			Message: err.Error(),
+			Type:    fmt.Sprintf("%T", err),
+			Remote:  false,
+			Cause:   "",
+			Stack:   nil,
+		}
+
+		if layer, ok := err.(*ctxerrors.CTXError); ok && layer != nil { //nolint:errorlint
+			exception.Message = layer.Message()
+			exception.Stack = []StackFrame{{Path: layer.File(), Line: layer.Line(), Label: layer.FuncName()}}
+
+			if file := layer.File(); file != "" && !seenPaths[file] {
+				seenPaths[file] = true
+				cause.Paths = append(cause.Paths, file)
+			}
+		} else if inner := errors.Unwrap(err); inner != nil {
+			exception.Message = strings.TrimSuffix(exception.Message, ": "+inner.Error())
+		}
+
+		if len(cause.Exceptions) > 0 {
+			cause.Exceptions[len(cause.Exceptions)-1].Cause = exception.ID
+		}
+
+		cause.Exceptions = append(cause.Exceptions, exception)
+	}
+
+	return cause
+}
+
+// newExceptionID returns a random exception ID.
+func newExceptionID() string {
+	buf := make([]byte, exceptionIDBytes)
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
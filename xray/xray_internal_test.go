@@ -0,0 +1,72 @@
+package xray
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/psyb0t/ctxerrors"
+)
+
+func TestToCause(t *testing.T) {
+	t.Run("nil error", func(t *testing.T) {
+		require.Nil(t, ToCause(nil))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		baseErr := errors.New("connection refused") //nolint:err113
+		err := ctxerrors.Wrap(fmt.Errorf("dial db: %w", ctxerrors.Wrap(baseErr, "connect")), "load user")
+
+		cause := ToCause(err)
+
+		workingDirectory, wdErr := os.Getwd()
+		require.NoError(t, wdErr)
+		require.Equal(t, workingDirectory, cause.WorkingDirectory)
+
+		require.Len(t, cause.Paths, 1)
+		require.Contains(t, cause.Paths[0], "xray_internal_test.go")
+
+		exceptions := cause.Exceptions
+		require.Len(t, exceptions, 4)
+
+		require.Equal(t, "load user", exceptions[0].Message)
+		require.Equal(t, "*ctxerrors.CTXError", exceptions[0].Type)
+		require.Len(t, exceptions[0].Stack, 1)
+		require.Equal(t, cause.Paths[0], exceptions[0].Stack[0].Path)
+		require.NotZero(t, exceptions[0].Stack[0].Line)
+		require.Contains(t, exceptions[0].Stack[0].Label, "TestToCause")
+
+		require.Equal(t, "dial db", exceptions[1].Message)
+		require.Empty(t, exceptions[1].Stack)
+		require.Equal(t, "connect", exceptions[2].Message)
+		require.Equal(t, "connection refused", exceptions[3].Message)
+
+		for i, exception := range exceptions {
+			require.Len(t, exception.ID, 16)
+
+			if i == len(exceptions)-1 {
+				require.Empty(t, exception.Cause)
+
+				continue
+			}
+
+			require.Equal(t, exceptions[i+1].ID, exception.Cause)
+		}
+	})
+
+	t.Run("json layout", func(t *testing.T) {
+		data, err := json.Marshal(ToCause(ctxerrors.New("boom")))
+		require.NoError(t, err)
+
+		var cause map[string]any
+
+		require.NoError(t, json.Unmarshal(data, &cause))
+		require.Contains(t, cause, "working_directory")
+		require.Contains(t, cause, "paths")
+		require.Contains(t, cause, "exceptions")
+	})
+}
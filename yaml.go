@@ -0,0 +1,168 @@
+package ctxerrors
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// yamlIndent is how many spaces each nesting level of ToYAML output adds.
+const yamlIndent = 2
+
+// yamlReserved are the plain scalars YAML parsers would read as something
+// other than a string.
+var yamlReserved = map[string]bool{ //nolint:gochecknoglobals
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true, "~": true,
+}
+
+// ToYAML renders err's chain as a YAML document for humans, e.g. for a CLI's
+// --debug output or to attach to a support ticket: the full error text, then
+// every layer outermost first with its message, location, ID, fields and
+// captured callers, the type of every error that isn't a *CTXError and the
+// members of joined errors, each with its own layers. Fields are handed out
+// like Fields does, so marshalers and redaction apply, and values other than
+// strings are written as their JSON, which is valid YAML. It returns an empty
+// string for a nil error.
+func ToYAML(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("error: " + yamlString(err.Error()) + "\n")
+	writeYAMLLayers(&builder, encodeLayers(err, false), "", 0)
+
+	return builder.String()
+}
+
+// writeYAMLLayers writes a layers key prefixed with head and its items
+// indented by indent.
+func writeYAMLLayers(builder *strings.Builder, layers []wireLayer, head string, indent int) {
+	builder.WriteString(head + "layers:\n")
+
+	pad := strings.Repeat(" ", indent+yamlIndent)
+	nested := pad + strings.Repeat(" ", 2*yamlIndent)
+
+	for _, layer := range layers {
+		prefix := pad + "- "
+
+		entry := func(key, value string) {
+			builder.WriteString(prefix + key + ":" + value + "\n")
+			prefix = pad + "  "
+		}
+
+		if layer.Type != "" {
+			entry("type", " "+yamlString(layer.Type))
+		}
+
+		if layer.Type == "" || layer.Message != "" {
+			entry("message", " "+yamlString(layer.Message))
+		}
+
+		if layer.File != "" {
+			entry("file", " "+yamlString(layer.File))
+			entry("line", " "+strconv.Itoa(layer.Line))
+		}
+
+		if layer.FuncName != "" {
+			entry("func", " "+yamlString(layer.FuncName))
+		}
+
+		if layer.ID != "" {
+			entry("id", " "+yamlString(layer.ID))
+		}
+
+		if len(layer.Fields) > 0 {
+			entry("fields", "")
+
+			for _, key := range slices.Sorted(maps.Keys(layer.Fields)) {
+				builder.WriteString(nested + yamlString(key) + ": " + yamlValue(layer.Fields[key]) + "\n")
+			}
+		}
+
+		if len(layer.Details) > 0 {
+			entry("details", "")
+
+			for _, key := range slices.Sorted(maps.Keys(layer.Details)) {
+				builder.WriteString(nested + yamlString(key) + ": " + yamlValue(layer.Details[key]) + "\n")
+			}
+		}
+
+		if len(layer.Stack) > 0 {
+			entry("callers", "")
+
+			for _, frame := range layer.Stack {
+				location := "in " + frame.FuncName
+				if frame.File != "" {
+					location = frame.File + ":" + strconv.Itoa(frame.Line) + " " + location
+				}
+
+				builder.WriteString(nested + "- " + yamlString(location) + "\n")
+			}
+		}
+
+		if len(layer.Joined) > 0 {
+			entry("joined", "")
+
+			for _, member := range layer.Joined {
+				writeYAMLLayers(builder, member, nested+"- ", len(nested)+yamlIndent)
+			}
+		}
+	}
+}
+
+// yamlValue renders the JSON encoding of a field value, strings as YAML
+// scalars and everything else as the JSON itself.
+func yamlValue(raw json.RawMessage) string {
+	var text string
+
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return yamlString(text)
+	}
+
+	return string(raw)
+}
+
+// yamlString renders s as a plain scalar if YAML reads that back as the same
+// string, or double-quoted otherwise.
+func yamlString(s string) string {
+	if isPlainYAML(s) {
+		return s
+	}
+
+	// Go's escapes are all valid in YAML double-quoted scalars
+	return strconv.Quote(s)
+}
+
+// isPlainYAML reports whether s can be written as a plain scalar. It's
+// conservative and quotes anything that might be read as another type or
+// trip the YAML syntax.
+func isPlainYAML(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) || yamlReserved[strings.ToLower(s)] {
+		return false
+	}
+
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`.+0123456789") {
+		return false
+	}
+
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
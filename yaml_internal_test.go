@@ -0,0 +1,110 @@
+package ctxerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToYAML(t *testing.T) {
+	require.NoError(t, SetRedactedFields("token"))
+	t.Cleanup(func() { require.NoError(t, SetRedactedFields()) })
+
+	root := &CTXError{
+		message:  "connection refused",
+		file:     "/src/app/db.go",
+		line:     12,
+		funcName: "app.dial",
+		fields:   map[string]any{"addr": "db:5432", "retries": 3, "token": "secret"},
+		frames: []Frame{
+			{File: "/src/app/main.go", Line: 7, FuncName: "main.main"},
+			{FuncName: "runtime.main"},
+		},
+	}
+
+	other := error(&CTXError{
+		message:  "cache: miss",
+		file:     "/src/app/cache.go",
+		line:     3,
+		funcName: "app.get",
+		id:       "9f86d081-2s",
+	})
+
+	err := error(&CTXError{
+		err:      errors.Join(fmt.Errorf("dial db: %w", root), other),
+		message:  "load user",
+		file:     "/src/app/user.go",
+		line:     42,
+		funcName: "app.load",
+		fields:   map[string]any{"kind": KindNotFound, "ids": []int{1, 2}},
+	})
+
+	expected := "error: " + yamlString(err.Error()) + `
+layers:
+  - message: load user
+    file: /src/app/user.go
+    line: 42
+    func: app.load
+    fields:
+      ids: [1,2]
+      kind: not_found
+  - type: "*errors.joinError"
+    joined:
+      - layers:
+          - type: "*fmt.wrapError"
+            message: dial db
+          - message: connection refused
+            file: /src/app/db.go
+            line: 12
+            func: app.dial
+            fields:
+              addr: db:5432
+              retries: 3
+              token: "[REDACTED]"
+            callers:
+              - /src/app/main.go:7 in main.main
+              - in runtime.main
+      - layers:
+          - message: "cache: miss"
+            file: /src/app/cache.go
+            line: 3
+            func: app.get
+            id: "9f86d081-2s"
+`
+	require.Equal(t, expected, ToYAML(err))
+	require.Empty(t, ToYAML(nil))
+}
+
+func TestYAMLString(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"load user", "load user"},
+		{"/src/app/db.go", "/src/app/db.go"},
+		{"", `""`},
+		{"true", `"true"`},
+		{"No", `"No"`},
+		{"42", `"42"`},
+		{"1e3", `"1e3"`},
+		{"-dash", `"-dash"`},
+		{"*type", `"*type"`},
+		{"a: b", `"a: b"`},
+		{"trailing:", `"trailing:"`},
+		{"x #comment", `"x #comment"`},
+		{" padded", `" padded"`},
+		{"line\nbreak", `"line\nbreak"`},
+		{"tab\there", `"tab\there"`},
+		{"quote\"d", `quote"d`},
+		{"\"quoted\"", `"\"quoted\""`},
+		{"héllo", `"héllo"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			require.Equal(t, tc.expected, yamlString(tc.input))
+		})
+	}
+}